@@ -21,7 +21,101 @@ type Config struct {
 	JWTSessionLength int    `toml:"jwt_session_length"`
 	JWTCookieDomain  string `toml:"jwt_cookie_domain"`
 	JWTSecureCookie  bool   `toml:"jwt_secure_cookie"`
-	AllowedOrigin    string `toml:"allowed_origin"`
+	// RefreshTokenLength is the refresh token lifetime in hours.
+	RefreshTokenLength int `toml:"refresh_token_length"`
+	// TrashRetention is how long deleted tasks are kept in the trash, in hours.
+	TrashRetention int `toml:"trash_retention"`
+	// TrashPurgeInterval is how often the trash is purged, in minutes.
+	TrashPurgeInterval int `toml:"trash_purge_interval"`
+	// StreamHeartbeat is how often an idle event stream sends a heartbeat, in seconds.
+	StreamHeartbeat int `toml:"stream_heartbeat"`
+	// StreamReplaySize is the number of the latest events of a user kept for resuming event streams.
+	StreamReplaySize int `toml:"stream_replay_size"`
+	// StreamReplayWindow is how long the events of a user without event streams are kept for resuming ones, in seconds.
+	StreamReplayWindow int `toml:"stream_replay_window"`
+	// OutboxInterval is how often the outbox is checked for events left to relay, in seconds.
+	// The events are relayed as soon as they are saved as well.
+	OutboxInterval int `toml:"outbox_interval"`
+	// WebhookInterval is how often the due webhook deliveries are attempted, in seconds.
+	WebhookInterval int `toml:"webhook_interval"`
+	// WebhookTimeout is how long a webhook receiver has to respond, in seconds.
+	WebhookTimeout int `toml:"webhook_timeout"`
+	// WebhookMaxAttempts is the number of attempts after which a webhook delivery fails.
+	WebhookMaxAttempts int `toml:"webhook_max_attempts"`
+	// WebhookBackoff is the wait before the first retry of a webhook delivery, doubled for every next one, in seconds.
+	WebhookBackoff int `toml:"webhook_backoff"`
+	// WebhookMaxBackoff is the longest wait between the retries of a webhook delivery, in seconds.
+	WebhookMaxBackoff int `toml:"webhook_max_backoff"`
+	// WebhookAllowedNetworks are the CIDRs of the networks the webhooks may be delivered to although they are not
+	// public. The loopback, private, link-local and other special-purpose addresses are refused otherwise.
+	WebhookAllowedNetworks []string `toml:"webhook_allowed_networks"`
+	// Mailer is how the emails are sent: "smtp", "file" to append them to MailFile or "log" to log them.
+	Mailer       string `toml:"mailer"`
+	MailFrom     string `toml:"mail_from"`
+	MailFile     string `toml:"mail_file"`
+	SMTPHost     string `toml:"smtp_host"`
+	SMTPPort     int    `toml:"smtp_port"`
+	SMTPUsername string `toml:"smtp_username"`
+	SMTPPassword string `toml:"smtp_password"`
+	// AppURL is the address of the web app the links in the emails lead to.
+	AppURL string `toml:"app_url"`
+	// PasswordResetTTL is how long a password reset link can be used, in minutes.
+	PasswordResetTTL int `toml:"password_reset_ttl"`
+	// PasswordResetResend is how long a user has to wait before another password reset email is sent, in seconds.
+	PasswordResetResend int `toml:"password_reset_resend"`
+	// PasswordResetAccountRequests and PasswordResetIPRequests are the numbers of password reset requests
+	// for an email and from an IP address, each within PasswordResetRequestWindow of the last, in minutes,
+	// after which the next ones are refused until the window has passed. Zero turns it off.
+	PasswordResetAccountRequests int `toml:"password_reset_account_requests"`
+	PasswordResetIPRequests      int `toml:"password_reset_ip_requests"`
+	PasswordResetRequestWindow   int `toml:"password_reset_request_window"`
+	// EmailVerificationKey signs the email verification links.
+	EmailVerificationKey string `toml:"email_verification_key"`
+	// EmailVerificationTTL is how long an email verification link can be used, in hours.
+	EmailVerificationTTL int `toml:"email_verification_ttl"`
+	// EmailVerificationResend is how long a user has to wait before another verification email is sent, in seconds.
+	EmailVerificationResend int `toml:"email_verification_resend"`
+	// UnverifiedAccess is what the users who have not verified their email may do: "full" access,
+	// "limited" to viewing their tasks and lists, or "none", not even logging in.
+	// The users registered before the email verification are unverified too.
+	UnverifiedAccess string `toml:"unverified_access"`
+	// PasswordMinLength is the least number of characters of a new password.
+	PasswordMinLength int `toml:"password_min_length"`
+	// PasswordBlocklist is the path of a file of common or breached passwords, one per line, which may not be chosen.
+	PasswordBlocklist string `toml:"password_blocklist"`
+	// PasswordForbidEmail rejects the new passwords containing the email of the user.
+	PasswordForbidEmail bool `toml:"password_forbid_email"`
+	// PasswordHash is the algorithm the passwords are hashed with, "bcrypt" or "argon2id".
+	// The passwords hashed with another algorithm or cost are hashed again on login.
+	PasswordHash       string `toml:"password_hash"`
+	PasswordBcryptCost int    `toml:"password_bcrypt_cost"`
+	// PasswordArgon2Time is the number of passes, PasswordArgon2Memory the memory in KiB
+	// and PasswordArgon2Threads the parallelism of argon2id.
+	PasswordArgon2Time    int `toml:"password_argon2_time"`
+	PasswordArgon2Memory  int `toml:"password_argon2_memory"`
+	PasswordArgon2Threads int `toml:"password_argon2_threads"`
+	// LoginAttemptsStore is where the failed login attempts are counted, "mongo" to share them
+	// between the replicas or "memory" for a single one.
+	LoginAttemptsStore string `toml:"login_attempts_store"`
+	// LoginAccountFreeAttempts and LoginIPFreeAttempts are the numbers of failed login attempts with an email
+	// and from an IP address before the next ones are slowed down, LoginAccountLockoutThreshold and
+	// LoginIPLockoutThreshold the numbers of them which lock the email or the IP address out. Zero turns it off.
+	LoginAccountFreeAttempts     int `toml:"login_account_free_attempts"`
+	LoginAccountLockoutThreshold int `toml:"login_account_lockout_threshold"`
+	LoginIPFreeAttempts          int `toml:"login_ip_free_attempts"`
+	LoginIPLockoutThreshold      int `toml:"login_ip_lockout_threshold"`
+	// LoginBackoff is the wait after the first failed login attempt which is not free, doubled by every next one
+	// up to LoginMaxBackoff, in seconds.
+	LoginBackoff    int `toml:"login_backoff"`
+	LoginMaxBackoff int `toml:"login_max_backoff"`
+	// LoginLockoutDuration is how long a lockout lasts and LoginAttemptWindow how long the failed login attempts
+	// are remembered, in minutes.
+	LoginLockoutDuration int `toml:"login_lockout_duration"`
+	LoginAttemptWindow   int `toml:"login_attempt_window"`
+	// TrustedProxies are the addresses or CIDRs of the proxies whose X-Forwarded-For header tells the IP address
+	// of the client. None are trusted if empty, the address of the connection is the client's.
+	TrustedProxies []string `toml:"trusted_proxies"`
+	AllowedOrigin  string   `toml:"allowed_origin"`
 }
 
 // NewConfig returns app config.
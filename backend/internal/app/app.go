@@ -1,9 +1,12 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
@@ -11,25 +14,266 @@ import (
 	"github.com/ozaitsev92/tododdd/config"
 	v1 "github.com/ozaitsev92/tododdd/internal/controller/http/v1"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
+	"github.com/ozaitsev92/tododdd/internal/domain/user"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/mailer"
+	feedRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/mongo"
+	historyRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/history/mongo"
+	listRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/mongo"
+	loginMemoryRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/memory"
+	loginRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/mongo"
+	outboxRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/mongo"
+	resetRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/mongo"
+	sessionRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/mongo"
 	taskRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/mongo"
+	transaction "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/transaction/mongo"
 	userRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/user/mongo"
+	webhookRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/mongo"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/sender"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/stream"
 	"github.com/ozaitsev92/tododdd/internal/usecase"
 	"github.com/ozaitsev92/tododdd/pkg/httpserver"
 	"github.com/ozaitsev92/tododdd/pkg/logger"
+	"github.com/ozaitsev92/tododdd/pkg/worker"
 )
 
 // Run creates objects via constructors.
 func Run(cfg config.Config) {
 	l := logger.New(cfg)
 
+	// Access policy
+	listRepo := listRepository.NewRepository(cfg)
+	err := listRepo.EnsureIndexes(context.Background())
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - listRepo.EnsureIndexes: %w", err))
+	}
+
+	accessPolicy := usecase.NewAccessPolicy(listRepo)
+
 	// Task Use case
+	taskRepo := taskRepository.NewRepository(cfg)
+	err = taskRepo.EnsureIndexes(context.Background())
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - taskRepo.EnsureIndexes: %w", err))
+	}
+
+	historyRepo := historyRepository.NewRepository(cfg)
+	err = historyRepo.EnsureIndexes(context.Background())
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - historyRepo.EnsureIndexes: %w", err))
+	}
+
+	outboxRepo := outboxRepository.NewRepository(cfg)
+	err = outboxRepo.EnsureIndexes(context.Background())
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - outboxRepo.EnsureIndexes: %w", err))
+	}
+
+	transactor := transaction.NewTransactor(cfg)
+
 	taskUseCase := usecase.NewTaskUseCase(
-		taskRepository.NewRepository(cfg),
+		taskRepo,
+		historyRepo,
+		outboxRepo,
+		transactor,
+		accessPolicy,
+	)
+
+	// Outbox Use case
+	outboxUseCase := usecase.NewOutboxUseCase(outboxRepo)
+	taskUseCase.OnEventsAdded(outboxUseCase.Notify)
+
+	// Task event stream
+	taskHub := stream.NewTaskHub(cfg)
+	outboxUseCase.AddPublisher(taskHub)
+
+	// Webhook Use case
+	webhookRepo := webhookRepository.NewRepository(cfg)
+	err = webhookRepo.EnsureIndexes(context.Background())
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - webhookRepo.EnsureIndexes: %w", err))
+	}
+
+	deliveryRepo := webhookRepository.NewDeliveryRepository(cfg)
+	err = deliveryRepo.EnsureIndexes(context.Background())
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - deliveryRepo.EnsureIndexes: %w", err))
+	}
+
+	webhookSender, err := sender.NewHTTPSender(cfg)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - sender.NewHTTPSender: %w", err))
+	}
+
+	webhookBackoff := webhook.Backoff{
+		Base:        time.Duration(cfg.WebhookBackoff) * time.Second,
+		Max:         time.Duration(cfg.WebhookMaxBackoff) * time.Second,
+		MaxAttempts: cfg.WebhookMaxAttempts,
+	}
+	err = webhookBackoff.Validate()
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - webhookBackoff.Validate: %w", err))
+	}
+
+	// The lease outlasts an attempt, so a delivery is attempted again only if its worker is gone
+	webhookUseCase := usecase.NewWebhookUseCase(
+		webhookRepo,
+		deliveryRepo,
+		webhookSender,
+		webhookBackoff,
+		2*webhookSender.Timeout(),
+		accessPolicy,
 	)
+	outboxUseCase.AddPublisher(webhookUseCase)
 
 	// User Use case
+	unverifiedAccess := usecase.UnverifiedAccess(cfg.UnverifiedAccess)
+	if !unverifiedAccess.IsValid() {
+		l.Fatal(fmt.Errorf("app - Run - unknown unverified access policy %q", cfg.UnverifiedAccess))
+	}
+
+	passwords, err := newPasswords(cfg)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - newPasswords: %w", err))
+	}
+
+	sessionRepo := sessionRepository.NewRepository(cfg)
+	err = sessionRepo.EnsureIndexes(context.Background())
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - sessionRepo.EnsureIndexes: %w", err))
+	}
+
+	resetRepo := resetRepository.NewRepository(cfg)
+	err = resetRepo.EnsureIndexes(context.Background())
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - resetRepo.EnsureIndexes: %w", err))
+	}
+
+	feedRepo := feedRepository.NewRepository(cfg)
+	err = feedRepo.EnsureIndexes(context.Background())
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - feedRepo.EnsureIndexes: %w", err))
+	}
+
+	userRepo := userRepository.NewRepository(cfg)
 	userUseCase := usecase.NewUserUseCase(
-		userRepository.NewRepository(cfg),
+		userRepo,
+		taskRepo,
+		listRepo,
+		sessionRepo,
+		resetRepo,
+		webhookRepo,
+		deliveryRepo,
+		feedRepo,
+		transactor,
+		unverifiedAccess,
+		passwords,
+	)
+
+	// List Use case
+	listUseCase := usecase.NewListUseCase(
+		listRepo,
+		taskRepo,
+		userUseCase,
+		accessPolicy,
+	)
+
+	// Task Batch Use case
+	taskBatchUseCase := usecase.NewTaskBatchUseCase(
+		taskUseCase,
+		transactor,
+	)
+
+	// Task Import Use case
+	taskImportUseCase := usecase.NewTaskImportUseCase(
+		taskUseCase,
+	)
+
+	// Session Use case
+	sessionUseCase := usecase.NewSessionUseCase(
+		sessionRepo,
+		time.Duration(cfg.RefreshTokenLength)*time.Hour,
+	)
+
+	// The failed login attempts and the password reset requests are counted in the same store
+	loginRepo, err := newLoginRepository(cfg)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - newLoginRepository: %w", err))
+	}
+
+	// Password Reset Use case
+	mailSender, err := mailer.New(cfg, l)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - mailer.New: %w", err))
+	}
+
+	resetWindow := time.Duration(cfg.PasswordResetRequestWindow) * time.Minute
+	passwordResetUseCase := usecase.NewPasswordResetUseCase(
+		resetRepo,
+		userRepo,
+		sessionRepo,
+		transactor,
+		mailSender,
+		time.Duration(cfg.PasswordResetTTL)*time.Minute,
+		time.Duration(cfg.PasswordResetResend)*time.Second,
+		cfg.AppURL+"/reset-password",
+		passwords,
+		loginRepo,
+		login.Policy{
+			LockoutThreshold: cfg.PasswordResetAccountRequests,
+			LockoutDuration:  resetWindow,
+			Window:           resetWindow,
+		},
+		login.Policy{
+			LockoutThreshold: cfg.PasswordResetIPRequests,
+			LockoutDuration:  resetWindow,
+			Window:           resetWindow,
+		},
+	)
+
+	// Email Verification Use case
+	// The links signed with a guessable key could be forged by anyone
+	err = checkEmailVerificationKey(cfg.EmailVerificationKey)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - checkEmailVerificationKey: %w", err))
+	}
+
+	emailVerificationUseCase := usecase.NewEmailVerificationUseCase(
+		userRepo,
+		mailSender,
+		[]byte(cfg.EmailVerificationKey),
+		time.Duration(cfg.EmailVerificationTTL)*time.Hour,
+		time.Duration(cfg.EmailVerificationResend)*time.Second,
+		cfg.AppURL+"/verify-email",
+	)
+
+	// Login Guard Use case
+	loginWindow := time.Duration(cfg.LoginAttemptWindow) * time.Minute
+	loginGuardUseCase := usecase.NewLoginGuardUseCase(
+		loginRepo,
+		login.Policy{
+			FreeAttempts:     cfg.LoginAccountFreeAttempts,
+			Backoff:          time.Duration(cfg.LoginBackoff) * time.Second,
+			MaxBackoff:       time.Duration(cfg.LoginMaxBackoff) * time.Second,
+			LockoutThreshold: cfg.LoginAccountLockoutThreshold,
+			LockoutDuration:  time.Duration(cfg.LoginLockoutDuration) * time.Minute,
+			Window:           loginWindow,
+		},
+		login.Policy{
+			FreeAttempts:     cfg.LoginIPFreeAttempts,
+			Backoff:          time.Duration(cfg.LoginBackoff) * time.Second,
+			MaxBackoff:       time.Duration(cfg.LoginMaxBackoff) * time.Second,
+			LockoutThreshold: cfg.LoginIPLockoutThreshold,
+			LockoutDuration:  time.Duration(cfg.LoginLockoutDuration) * time.Minute,
+			Window:           loginWindow,
+		},
+	)
+
+	// Feed Use case
+	feedUseCase := usecase.NewFeedUseCase(
+		feedRepo,
+		taskRepo,
 	)
 
 	// JWT service
@@ -42,13 +286,88 @@ func Run(cfg config.Config) {
 
 	// HTTP Server
 	handler := gin.New()
-	v1.NewRouter(handler, cfg, l, jwtService, taskUseCase, userUseCase)
+	err = handler.SetTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - handler.SetTrustedProxies: %w", err))
+	}
+
+	v1.NewRouter(handler, cfg, l, jwtService, taskUseCase, userUseCase, sessionUseCase, listUseCase, taskBatchUseCase, taskImportUseCase, taskHub, webhookUseCase, feedUseCase, passwordResetUseCase, emailVerificationUseCase, loginGuardUseCase)
 	httpServer := httpserver.New(
 		handler,
 		httpserver.Port(cfg.BindAddr),
 		httpserver.ReadTimeout(time.Duration(cfg.ReadTimeout)*time.Second),
 		httpserver.WriteTimeout(time.Duration(cfg.WriteTimeout)*time.Second),
 		httpserver.ShutdownTimeout(time.Duration(cfg.GracefulTimeout)*time.Second),
+		httpserver.OnShutdown(taskHub.Close),
+	)
+
+	// Trash purger
+	// No retention would empty the trash on the first purge
+	if cfg.TrashRetention <= 0 {
+		l.Fatal(fmt.Errorf("app - Run - trash retention must be positive, got %d", cfg.TrashRetention))
+	}
+
+	trashRetention := time.Duration(cfg.TrashRetention) * time.Hour
+	trashPurger := worker.New(
+		func(ctx context.Context) error {
+			n, err := taskUseCase.PurgeTrash(ctx, trashRetention)
+			if err == nil && n > 0 {
+				l.Info(fmt.Sprintf("app - Run - trashPurger: %d tasks purged", n))
+			}
+
+			return err
+		},
+		worker.Interval(time.Duration(cfg.TrashPurgeInterval)*time.Minute),
+		worker.ShutdownTimeout(time.Duration(cfg.GracefulTimeout)*time.Second),
+		worker.ErrorHandler(func(err error) {
+			l.Error(fmt.Errorf("app - Run - trashPurger: %w", err))
+		}),
+	)
+
+	// Outbox relay
+	outboxRelay := worker.New(
+		func(ctx context.Context) error {
+			_, err := outboxUseCase.Relay(ctx)
+
+			return err
+		},
+		worker.Interval(time.Duration(cfg.OutboxInterval)*time.Second),
+		worker.Trigger(outboxUseCase.Pending()),
+		worker.ShutdownTimeout(time.Duration(cfg.GracefulTimeout)*time.Second),
+		worker.ErrorHandler(func(err error) {
+			l.Error(fmt.Errorf("app - Run - outboxRelay: %w", err))
+		}),
+	)
+
+	// Password reset sender
+	passwordResetSender := worker.New(
+		func(ctx context.Context) error {
+			_, err := passwordResetUseCase.SendRequested(ctx)
+
+			return err
+		},
+		worker.Trigger(passwordResetUseCase.Pending()),
+		worker.ShutdownTimeout(time.Duration(cfg.GracefulTimeout)*time.Second),
+		worker.ErrorHandler(func(err error) {
+			l.Error(fmt.Errorf("app - Run - passwordResetSender: %w", err))
+		}),
+	)
+
+	// Webhook deliverer
+	webhookDeliverer := worker.New(
+		func(ctx context.Context) error {
+			n, err := webhookUseCase.DeliverDue(ctx)
+			if n > 0 {
+				l.Info(fmt.Sprintf("app - Run - webhookDeliverer: %d deliveries made", n))
+			}
+
+			return err
+		},
+		worker.Interval(time.Duration(cfg.WebhookInterval)*time.Second),
+		worker.ShutdownTimeout(time.Duration(cfg.GracefulTimeout)*time.Second),
+		worker.ErrorHandler(func(err error) {
+			l.Error(fmt.Errorf("app - Run - webhookDeliverer: %w", err))
+		}),
 	)
 
 	// Waiting signal
@@ -63,8 +382,97 @@ func Run(cfg config.Config) {
 	}
 
 	// Shutdown
-	err := httpServer.Shutdown()
+	err = httpServer.Shutdown()
 	if err != nil {
 		l.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err))
 	}
+
+	err = trashPurger.Shutdown()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - trashPurger.Shutdown: %w", err))
+	}
+
+	err = outboxRelay.Shutdown()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - outboxRelay.Shutdown: %w", err))
+	}
+
+	err = passwordResetSender.Shutdown()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - passwordResetSender.Shutdown: %w", err))
+	}
+
+	err = webhookDeliverer.Shutdown()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - webhookDeliverer.Shutdown: %w", err))
+	}
+}
+
+// minEmailVerificationKeyLength is the least number of bytes of the email verification key.
+const minEmailVerificationKeyLength = 32
+
+// placeholderKeys are the keys found in examples, which are as good as no key.
+var placeholderKeys = []string{"change-me", "changeme", "secret", "todo"}
+
+// checkEmailVerificationKey returns an error if the key is too short or a placeholder.
+func checkEmailVerificationKey(key string) error {
+	if slices.Contains(placeholderKeys, strings.ToLower(key)) {
+		return fmt.Errorf("email verification key %q is a placeholder", key)
+	}
+
+	if len(key) < minEmailVerificationKeyLength {
+		return fmt.Errorf("email verification key is shorter than %d bytes", minEmailVerificationKeyLength)
+	}
+
+	return nil
+}
+
+// newPasswords returns how the passwords are checked and hashed according to the config.
+func newPasswords(cfg config.Config) (user.Passwords, error) {
+	var common []string
+	if cfg.PasswordBlocklist != "" {
+		f, err := os.Open(cfg.PasswordBlocklist)
+		if err != nil {
+			return user.Passwords{}, err
+		}
+		defer f.Close()
+
+		common, err = user.ReadCommonPasswords(f)
+		if err != nil {
+			return user.Passwords{}, err
+		}
+	}
+
+	hasher := user.PasswordHasher{
+		Algorithm:     user.HashAlgorithm(cfg.PasswordHash),
+		BcryptCost:    cfg.PasswordBcryptCost,
+		Argon2Time:    cfg.PasswordArgon2Time,
+		Argon2Memory:  cfg.PasswordArgon2Memory,
+		Argon2Threads: cfg.PasswordArgon2Threads,
+	}
+	if err := hasher.Validate(); err != nil {
+		return user.Passwords{}, err
+	}
+
+	return user.Passwords{
+		Policy: user.NewPasswordPolicy(cfg.PasswordMinLength, common, cfg.PasswordForbidEmail),
+		Hasher: hasher,
+	}, nil
+}
+
+// newLoginRepository returns the store of the failed login attempts chosen in the config, Mongo if none is.
+func newLoginRepository(cfg config.Config) (login.Repository, error) {
+	switch cfg.LoginAttemptsStore {
+	case "", "mongo":
+		repo := loginRepository.NewRepository(cfg)
+		if err := repo.EnsureIndexes(context.Background()); err != nil {
+			return nil, err
+		}
+
+		return repo, nil
+	case "memory":
+		return loginMemoryRepository.NewRepository(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown login attempts store %q", cfg.LoginAttemptsStore)
+	}
 }
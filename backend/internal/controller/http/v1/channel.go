@@ -0,0 +1,335 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/middleware"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/stream"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// maxChannelMessageSize is the largest message a client can send to a channel, in bytes.
+	maxChannelMessageSize = 64 << 10
+)
+
+var (
+	errOriginNotAllowed        = errors.New("origin is not allowed")
+	errInvalidChannelOperation = errors.New("channel operation is invalid")
+	errTaskNotInList           = errors.New("task is not in the list of the channel")
+)
+
+type channelRoutes struct {
+	l             logger.Interface
+	jwtService    *jwt.JWTService
+	u             *usecase.UserUseCase
+	t             *usecase.TaskUseCase
+	li            *usecase.ListUseCase
+	hub           *stream.TaskHub
+	presence      *stream.PresenceHub
+	allowedOrigin string
+	heartbeat     time.Duration
+}
+
+// todo: refactor. too many params
+func newChannelRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase, t *usecase.TaskUseCase, li *usecase.ListUseCase, hub *stream.TaskHub, allowedOrigin string, heartbeat time.Duration) {
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+	r := &channelRoutes{l, jwtService, u, t, li, hub, stream.NewPresenceHub(), allowedOrigin, heartbeat}
+
+	h := handler.Group("/lists")
+	h.Use(middleware.JwtMiddleware(u, jwtService), middleware.VerifiedMiddleware(u))
+	{
+		h.GET("/:id/channel", r.listChannel)
+	}
+}
+
+// channelRequest is a message a client sends to the collaboration channel of a list.
+// Type is presence or one of the task operations create, update_text, complete, uncomplete, delete and move.
+type channelRequest struct {
+	Type string `json:"type"`
+	// Ref is echoed in the ack or the error answering the message.
+	Ref      string     `json:"ref"`
+	ID       *uuid.UUID `json:"id"`
+	Text     string     `json:"text"`
+	Version  *int64     `json:"version"`
+	ParentID *uuid.UUID `json:"parent_id"`
+	After    *uuid.UUID `json:"after"`
+	Before   *uuid.UUID `json:"before"`
+	TaskID   *uuid.UUID `json:"task_id"`
+	State    string     `json:"state"`
+}
+
+// channelConn serializes the writes to a WebSocket connection.
+type channelConn struct {
+	mu sync.Mutex
+	ws *websocket.Conn
+}
+
+func (c *channelConn) send(m model.ChannelMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return websocket.JSON.Send(c.ws, m)
+}
+
+// listChannel upgrades the request to a WebSocket collaboration channel of the list. A member sends
+// task operations, which are applied with the permissions of the member, and what they are viewing or
+// editing, and receives the changes of the tasks of the list and the presence of the other members.
+func (r *channelRoutes) listChannel(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	listId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		r.l.Error(err, "http - v1 - listChannel")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid list id"})
+
+		return
+	}
+
+	_, err = r.li.GetList(c.Request.Context(), listId, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - listChannel")
+		c.AbortWithStatusJSON(listErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	s := websocket.Server{
+		Handshake: r.checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			r.serve(ws, listId, uuid.MustParse(userID))
+		},
+	}
+	s.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkOrigin refuses the browsers of other sites, which would otherwise connect with the cookie of the user.
+func (r *channelRoutes) checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin != "" && origin != r.allowedOrigin {
+		return errOriginNotAllowed
+	}
+
+	return nil
+}
+
+func (r *channelRoutes) serve(ws *websocket.Conn, listId uuid.UUID, userId uuid.UUID) {
+	defer ws.Close()
+
+	// The channel outlives the read and write timeouts of the server.
+	_ = ws.SetDeadline(time.Time{})
+	ws.MaxPayloadBytes = maxChannelMessageSize
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := r.hub.SubscribeList(listId)
+	if err != nil {
+		r.l.Error(err, "http - v1 - listChannel")
+
+		return
+	}
+	defer events.Close()
+
+	presence, err := r.presence.Subscribe(listId)
+	if err != nil {
+		r.l.Error(err, "http - v1 - listChannel")
+
+		return
+	}
+	defer presence.Close()
+
+	sessionId := r.presence.Join(listId, userId)
+	defer r.presence.Leave(listId, sessionId)
+
+	conn := &channelConn{ws: ws}
+	if err := conn.send(model.ChannelMessage{Type: "welcome", SessionID: sessionId.String()}); err != nil {
+		return
+	}
+
+	go func() {
+		defer cancel()
+		r.read(ctx, conn, listId, sessionId, userId)
+	}()
+
+	heartbeat := time.NewTicker(r.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var m model.ChannelMessage
+
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events.C:
+			if !ok {
+				return
+			}
+			m = model.ToChannelMessageFromEvent(e.Data)
+		case p, ok := <-presence.C:
+			if !ok {
+				return
+			}
+			m = model.ChannelMessage{Type: "presence", Members: model.ToResponseFromPresence(p.Data)}
+		case <-heartbeat.C:
+			// Members removed from the list lose the channel
+			if _, err := r.li.GetList(ctx, listId, userId); err != nil {
+				_ = conn.send(model.ChannelMessage{Type: "error", Status: listErrorStatus(err), Error: err.Error()})
+
+				return
+			}
+			m = model.ChannelMessage{Type: "ping"}
+		}
+
+		if err := conn.send(m); err != nil {
+			return
+		}
+	}
+}
+
+// read handles the messages of the client until the connection is closed.
+func (r *channelRoutes) read(ctx context.Context, conn *channelConn, listId uuid.UUID, sessionId uuid.UUID, userId uuid.UUID) {
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(conn.ws, &data); err != nil {
+			return
+		}
+
+		var request channelRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			if conn.send(model.ChannelMessage{Type: "error", Status: http.StatusBadRequest, Error: "Invalid message"}) != nil {
+				return
+			}
+
+			continue
+		}
+
+		m, ok := r.handle(ctx, request, listId, sessionId, userId)
+		if !ok {
+			continue
+		}
+
+		if err := conn.send(m); err != nil {
+			return
+		}
+	}
+}
+
+// handle applies a message of the client and returns the answer to it, if any.
+func (r *channelRoutes) handle(ctx context.Context, request channelRequest, listId uuid.UUID, sessionId uuid.UUID, userId uuid.UUID) (model.ChannelMessage, bool) {
+	if request.Type == "presence" {
+		err := r.presence.Set(listId, sessionId, request.TaskID, stream.PresenceState(request.State))
+		if err != nil {
+			return model.ChannelMessage{Type: "error", Ref: request.Ref, Status: http.StatusBadRequest, Error: err.Error()}, true
+		}
+
+		return model.ChannelMessage{}, false
+	}
+
+	// The policy for unverified users is checked for every operation, as the user can verify their email meanwhile.
+	err := r.authorizeUnverified(ctx, userId)
+	if err != nil {
+		return model.ChannelMessage{Type: "error", Ref: request.Ref, Status: http.StatusForbidden, Error: err.Error()}, true
+	}
+
+	t, err := r.apply(ctx, request, listId, userId)
+	if err != nil {
+		r.l.Error(err, "http - v1 - listChannel")
+
+		status := taskErrorStatus(err)
+		switch {
+		case errors.Is(err, errInvalidChannelOperation):
+			status = http.StatusBadRequest
+		case errors.Is(err, errTaskNotInList):
+			status = http.StatusNotFound
+		}
+
+		return model.ChannelMessage{Type: "error", Ref: request.Ref, Status: status, Error: err.Error()}, true
+	}
+
+	m := model.ChannelMessage{Type: "ack", Ref: request.Ref}
+	if t != nil {
+		response := model.ToResponseFromTask(*t)
+		m.Task = &response
+	}
+
+	return m, true
+}
+
+// authorizeUnverified returns usecase.ErrEmailNotVerified if the user may not change tasks before verifying their email.
+func (r *channelRoutes) authorizeUnverified(ctx context.Context, userId uuid.UUID) error {
+	u, err := r.u.GetUserByID(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	return r.u.AuthorizeUnverified(u, usecase.ActionEdit)
+}
+
+// apply applies a task operation with the TaskUseCase. Tasks are created in the list, and only the tasks
+// of the list can be changed, so the channel of a list does not edit the tasks of the other lists.
+func (r *channelRoutes) apply(ctx context.Context, request channelRequest, listId uuid.UUID, userId uuid.UUID) (*task.Task, error) {
+	version := task.AnyVersion
+	if request.Version != nil {
+		version = *request.Version
+	}
+
+	if request.Type == "create" {
+		opts := []task.Option{task.WithList(listId)}
+		if request.ParentID != nil {
+			opts = append(opts, task.WithParent(*request.ParentID))
+		}
+
+		t, err := r.t.CreateTask(ctx, request.Text, userId, opts...)
+
+		return &t, err
+	}
+
+	if request.ID == nil {
+		return nil, errInvalidChannelOperation
+	}
+
+	t, err := r.t.GetTask(ctx, *request.ID, userId)
+	if err != nil {
+		return nil, err
+	}
+	if t.ListID == nil || *t.ListID != listId {
+		return nil, errTaskNotInList
+	}
+
+	switch request.Type {
+	case "update_text":
+		t, err = r.t.UpdateTask(ctx, *request.ID, version, request.Text, userId)
+	case "complete":
+		t, err = r.t.MarkTaskCompleted(ctx, *request.ID, userId)
+	case "uncomplete":
+		t, err = r.t.MarkTaskNotCompleted(ctx, *request.ID, userId)
+	case "move":
+		t, err = r.t.MoveTask(ctx, *request.ID, request.After, request.Before, userId)
+	case "delete":
+		return nil, r.t.DeleteTask(ctx, *request.ID, version, userId, usecase.DeleteSubtasks)
+	default:
+		return nil, errInvalidChannelOperation
+	}
+
+	return &t, err
+}
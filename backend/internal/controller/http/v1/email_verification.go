@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+)
+
+type emailVerificationRoutes struct {
+	l logger.Interface
+	v *usecase.EmailVerificationUseCase
+}
+
+func newEmailVerificationRoutes(handler *gin.RouterGroup, l logger.Interface, v *usecase.EmailVerificationUseCase) {
+	r := &emailVerificationRoutes{l, v}
+
+	// The users may not be allowed to log in before verifying, the token or the email identifies them.
+	h := handler.Group("/users/verification")
+	{
+		h.POST("", r.verifyEmail)
+		h.POST("/resend", r.resendVerification)
+	}
+}
+
+// verifyEmail verifies the email of the user with a mailed token.
+func (r *emailVerificationRoutes) verifyEmail(c *gin.Context) {
+	type verifyEmailRequest struct {
+		Token string `json:"token" binding:"required"`
+	}
+	var request verifyEmailRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - verifyEmail")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	u, err := r.v.VerifyEmail(c.Request.Context(), request.Token)
+	if err != nil {
+		r.l.Error(err, "http - v1 - verifyEmail")
+
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidVerificationToken) {
+			status = http.StatusBadRequest
+		}
+		c.AbortWithStatusJSON(status, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromUser(u))
+}
+
+// resendVerification mails another verification link. The response is the same whether an unverified user
+// is registered with the email or not, unless a link has been sent to them too recently.
+func (r *emailVerificationRoutes) resendVerification(c *gin.Context) {
+	type resendVerificationRequest struct {
+		Email string `json:"email" binding:"required"`
+	}
+	var request resendVerificationRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - resendVerification")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	wait, err := r.v.ResendVerification(c.Request.Context(), request.Email)
+	if err != nil {
+		if errors.Is(err, usecase.ErrVerificationThrottled) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"Error": err.Error()})
+
+			return
+		}
+
+		r.l.Error(err, "http - v1 - resendVerification")
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{})
+}
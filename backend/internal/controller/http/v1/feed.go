@@ -0,0 +1,197 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/middleware"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/ical"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+)
+
+const (
+	feedProdID      = "-//tododdd//Tasks//EN"
+	feedName        = "Tasks"
+	feedContentType = "text/calendar; charset=utf-8"
+)
+
+// feedPriorities are the iCalendar priorities of the task priorities, see ical.Todo.
+var feedPriorities = map[task.Priority]int{
+	task.PriorityHigh:   1,
+	task.PriorityMedium: 5,
+	task.PriorityLow:    9,
+}
+
+type feedRoutes struct {
+	l          logger.Interface
+	jwtService *jwt.JWTService
+	u          *usecase.UserUseCase
+	f          *usecase.FeedUseCase
+}
+
+func newFeedRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase, f *usecase.FeedUseCase) {
+	r := &feedRoutes{l, jwtService, u, f}
+
+	h := handler.Group("/users/current/feed")
+	h.Use(middleware.JwtMiddleware(u, jwtService), middleware.VerifiedMiddleware(u))
+	{
+		h.GET("", r.showFeed)
+		h.POST("", r.createFeed)
+		h.DELETE("", r.revokeFeed)
+	}
+
+	// Calendar clients cannot log in, the token in the URL authorizes them.
+	handler.GET("/feeds/:token/tasks.ics", r.taskFeed)
+}
+
+// feedPath returns the path of the feed with the raw token.
+func feedPath(raw string) string {
+	return "/v1/feeds/" + raw + "/tasks.ics"
+}
+
+func (r *feedRoutes) showFeed(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	f, err := r.f.GetFeed(c.Request.Context(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - showFeed")
+		if errors.Is(err, feed.ErrFeedNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"Error": err.Error()})
+
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromFeed(f))
+}
+
+// createFeed creates the calendar feed of the user, or replaces it with a new URL.
+func (r *feedRoutes) createFeed(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	raw, f, err := r.f.CreateFeed(c.Request.Context(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - createFeed")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	response := model.ToResponseFromFeed(f)
+	response.URL = feedPath(raw)
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (r *feedRoutes) revokeFeed(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	err := r.f.RevokeFeed(c.Request.Context(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - revokeFeed")
+		if errors.Is(err, feed.ErrFeedNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"Error": err.Error()})
+
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// taskFeed renders the tasks with a due date of the feed owner as an iCalendar of to-dos. The ETag is
+// the hash of the calendar and Last-Modified the last change of a task, conditional requests are answered
+// with 304 Not Modified when the calendar has not changed.
+func (r *feedRoutes) taskFeed(c *gin.Context) {
+	tasks, lastModified, err := r.f.GetFeedTasks(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidFeedToken) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"Error": "Not Found"})
+
+			return
+		}
+
+		r.l.Error(err, "http - v1 - taskFeed")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	calendar := ical.Calendar{
+		ProdID: feedProdID,
+		Name:   feedName,
+		Todos:  make([]ical.Todo, 0, len(tasks)),
+	}
+	for _, t := range tasks {
+		calendar.Todos = append(calendar.Todos, toTodoFromTask(t))
+	}
+
+	var body bytes.Buffer
+	if err := ical.Encode(&body, calendar); err != nil {
+		r.l.Error(err, "http - v1 - taskFeed")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+
+	c.Header(etagHeader, strconv.Quote(hex.EncodeToString(sum[:16])))
+	c.Header("Content-Type", feedContentType)
+	// The URL is private, shared caches must not keep the feed.
+	c.Header("Cache-Control", "private, no-cache")
+	http.ServeContent(c.Writer, c.Request, "", lastModified, bytes.NewReader(body.Bytes()))
+}
+
+func toTodoFromTask(t task.Task) ical.Todo {
+	status := ical.StatusNeedsAction
+	if t.Completed {
+		status = ical.StatusCompleted
+	}
+
+	return ical.Todo{
+		UID:          t.ID.String(),
+		Summary:      t.Text,
+		Status:       status,
+		Due:          t.DueAt,
+		Priority:     feedPriorities[t.Priority],
+		Categories:   t.Tags,
+		Sequence:     max(t.Version-1, 0),
+		Created:      t.CreatedAt,
+		LastModified: t.UpdatedAt,
+	}
+}
@@ -11,7 +11,9 @@ import (
 )
 
 const (
-	jwtCookieName = "jwt-token"
+	jwtCookieName     = "jwt-token"
+	refreshCookieName = "refresh-token"
+	refreshCookiePath = "/v1/users"
 )
 
 var (
@@ -108,3 +110,35 @@ func (s *JWTService) ExpiredAuthCookie() http.Cookie {
 	d.MaxAge = -1
 	return d
 }
+
+func (s *JWTService) GetRefreshTokenFromRequest(r *http.Request) (string, error) {
+	refreshCookie, err := r.Cookie(refreshCookieName)
+	if err != nil {
+		return "", err
+	}
+
+	return refreshCookie.Value, nil
+}
+
+// RefreshCookie is only sent to the /v1/users endpoints that refresh or end a session.
+func (s *JWTService) RefreshCookie(token string, expiresAt time.Time) http.Cookie {
+	d := s.defaultCookie
+	d.Name = refreshCookieName
+	d.Value = token
+	d.Path = refreshCookiePath
+	d.Secure = true
+	d.HttpOnly = true
+	d.MaxAge = int(time.Until(expiresAt).Seconds())
+	return d
+}
+
+func (s *JWTService) ExpiredRefreshCookie() http.Cookie {
+	d := s.defaultCookie
+	d.Name = refreshCookieName
+	d.Value = ""
+	d.Path = refreshCookiePath
+	d.Secure = true
+	d.HttpOnly = true
+	d.MaxAge = -1
+	return d
+}
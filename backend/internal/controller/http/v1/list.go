@@ -0,0 +1,270 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/middleware"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+)
+
+type listRoutes struct {
+	l          logger.Interface
+	jwtService *jwt.JWTService
+	u          *usecase.UserUseCase
+	li         *usecase.ListUseCase
+}
+
+func newListRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase, li *usecase.ListUseCase) {
+	r := &listRoutes{l, jwtService, u, li}
+
+	h := handler.Group("/lists")
+	h.Use(middleware.JwtMiddleware(u, jwtService), middleware.VerifiedMiddleware(u))
+	{
+		h.GET("", r.index)
+		h.POST("", r.createList)
+		h.GET("/:id", r.showList)
+		h.PUT("/:id", r.renameList)
+		h.DELETE("/:id", r.deleteList)
+		h.POST("/:id/members", r.inviteMember)
+		h.PUT("/:id/members/:userId", r.setMemberRole)
+		h.DELETE("/:id/members/:userId", r.removeMember)
+	}
+}
+
+// listErrorStatus maps an error of the ListUseCase to an HTTP status.
+func listErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrUnauthorizedAction):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrInviteeNotFound), errors.Is(err, list.ErrMemberNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, list.ErrMemberAlreadyExists), errors.Is(err, list.ErrLastOwner):
+		return http.StatusConflict
+	case errors.Is(err, list.ErrInvalidName), errors.Is(err, list.ErrInvalidRole):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (r *listRoutes) index(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	lists, err := r.li.GetListsForUser(c.Request.Context(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - index")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromListCollection(lists))
+}
+
+func (r *listRoutes) createList(c *gin.Context) {
+	type createListRequest struct {
+		Name string `json:"name" binding:"required"`
+	}
+	var request createListRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - createList")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	l, err := r.li.CreateList(c.Request.Context(), request.Name, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - createList")
+		c.AbortWithStatusJSON(listErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.ToResponseFromList(l))
+}
+
+func (r *listRoutes) showList(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	l, err := r.li.GetList(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - showList")
+		c.AbortWithStatusJSON(listErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromList(l))
+}
+
+func (r *listRoutes) renameList(c *gin.Context) {
+	type renameListRequest struct {
+		Name string `json:"name" binding:"required"`
+	}
+	var request renameListRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - renameList")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	l, err := r.li.RenameList(c.Request.Context(), uuid.MustParse(id), request.Name, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - renameList")
+		c.AbortWithStatusJSON(listErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromList(l))
+}
+
+func (r *listRoutes) deleteList(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	err := r.li.DeleteList(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - deleteList")
+		c.AbortWithStatusJSON(listErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (r *listRoutes) inviteMember(c *gin.Context) {
+	type inviteMemberRequest struct {
+		Email string `json:"email" binding:"required"`
+		Role  string `json:"role" binding:"required"`
+	}
+	var request inviteMemberRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - inviteMember")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	l, err := r.li.InviteMember(c.Request.Context(), uuid.MustParse(id), request.Email, list.Role(request.Role), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - inviteMember")
+		c.AbortWithStatusJSON(listErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromList(l))
+}
+
+func (r *listRoutes) setMemberRole(c *gin.Context) {
+	type setMemberRoleRequest struct {
+		Role string `json:"role" binding:"required"`
+	}
+	var request setMemberRoleRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - setMemberRole")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	id := c.Param("id")
+	memberID := c.Param("userId")
+
+	l, err := r.li.SetMemberRole(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(memberID), list.Role(request.Role), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - setMemberRole")
+		c.AbortWithStatusJSON(listErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromList(l))
+}
+
+func (r *listRoutes) removeMember(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id := c.Param("id")
+	memberID := c.Param("userId")
+
+	l, err := r.li.RemoveMember(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(memberID), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - removeMember")
+		c.AbortWithStatusJSON(listErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromList(l))
+}
@@ -0,0 +1,22 @@
+package v1
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// The metrics are registered once with the default registry served on /metrics.
+var (
+	loginFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tododdd_login_failures_total",
+		Help: "The number of login attempts with invalid credentials.",
+	})
+	loginThrottled = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tododdd_login_throttled_total",
+		Help: "The number of login attempts refused before checking the password, as too many have failed.",
+	})
+	loginLockouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tododdd_login_lockouts_total",
+		Help: "The number of lockouts started by failed login attempts, by the scope locked out.",
+	}, []string{"scope"})
+)
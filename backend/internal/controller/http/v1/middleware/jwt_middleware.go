@@ -25,6 +25,7 @@ func JwtMiddleware(u *usecase.UserUseCase, jwtService *jwt.JWTService) gin.Handl
 		}
 
 		c.Set("userID", u.ID.String())
+		c.Set("user", u)
 
 		c.Next()
 	}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ozaitsev92/tododdd/internal/domain/user"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+// VerifiedMiddleware applies the policy for the users who have not verified their email yet to the requests
+// for their tasks and lists, and for the webhooks and feeds publishing them. GET and HEAD requests view them,
+// all the others change them.
+// It has to follow the JwtMiddleware, which sets the current user.
+func VerifiedMiddleware(u *usecase.UserUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		current, ok := c.Get("user")
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+			return
+		}
+
+		action := usecase.ActionEdit
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			action = usecase.ActionView
+		}
+
+		err := u.AuthorizeUnverified(current.(user.User), action)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"Error": err.Error()})
+
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/stream"
+)
+
+// ChannelMessage is a message the collaboration channel of a list sends to a client.
+type ChannelMessage struct {
+	// Type is one of welcome, ack, error, event, presence and ping.
+	Type string `json:"type"`
+	// Ref is the ref of the client message an ack or an error answers.
+	Ref       string `json:"ref,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	// Event is the type of the task event, like task.created.
+	Event   string     `json:"event,omitempty"`
+	ActorID string     `json:"actor_id,omitempty"`
+	Task    *Task      `json:"task,omitempty"`
+	Members []Presence `json:"members,omitempty"`
+	Status  int        `json:"status,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// Presence -.
+type Presence struct {
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	TaskID    *string   `json:"task_id"`
+	State     string    `json:"state"`
+	Since     time.Time `json:"since"`
+}
+
+// ToChannelMessageFromEvent -.
+func ToChannelMessageFromEvent(e task.Event) ChannelMessage {
+	t := ToResponseFromTask(e.Task)
+
+	return ChannelMessage{
+		Type:    "event",
+		Event:   string(e.Type),
+		ActorID: e.ActorID.String(),
+		Task:    &t,
+	}
+}
+
+// ToResponseFromPresence -.
+func ToResponseFromPresence(members []stream.Presence) []Presence {
+	response := make([]Presence, 0, len(members))
+	for _, p := range members {
+		response = append(response, Presence{
+			SessionID: p.SessionID.String(),
+			UserID:    p.UserID.String(),
+			TaskID:    uuidToStringPtr(p.TaskID),
+			State:     string(p.State),
+			Since:     p.Since,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+)
+
+// Feed -.
+type Feed struct {
+	// URL is only returned when the feed is created, the token in it is not kept.
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ToResponseFromFeed -.
+func ToResponseFromFeed(f feed.Feed) Feed {
+	return Feed{
+		CreatedAt: f.CreatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/history"
+)
+
+// State -.
+type State struct {
+	Text      string     `json:"text"`
+	Completed bool       `json:"completed"`
+	ListID    *string    `json:"list_id"`
+	ParentID  *string    `json:"parent_id"`
+	DueAt     *time.Time `json:"due_at"`
+	// ReminderOffset is in seconds before DueAt.
+	ReminderOffset *int64     `json:"reminder_offset"`
+	Recurrence     *string    `json:"recurrence"`
+	Tags           []string   `json:"tags"`
+	DeletedAt      *time.Time `json:"deleted_at"`
+	Position       float64    `json:"position"`
+	Priority       string     `json:"priority"`
+	Important      *bool      `json:"important"`
+	Urgent         *bool      `json:"urgent"`
+}
+
+// Event -.
+type Event struct {
+	ID        string `json:"id"`
+	TaskID    string `json:"task_id"`
+	ActorID   string `json:"actor_id"`
+	Operation string `json:"operation"`
+	// Changes are the names of the fields the event changed.
+	Changes    []string  `json:"changes"`
+	Before     *State    `json:"before"`
+	After      *State    `json:"after"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// ToResponseFromHistory -.
+func ToResponseFromHistory(events []history.Event) []Event {
+	response := make([]Event, 0, len(events))
+	for _, e := range events {
+		changes := e.ChangedFields()
+		if changes == nil {
+			changes = []string{}
+		}
+
+		response = append(response, Event{
+			ID:         e.ID.String(),
+			TaskID:     e.TaskID.String(),
+			ActorID:    e.ActorID.String(),
+			Operation:  string(e.Operation),
+			Changes:    changes,
+			Before:     toResponseFromState(e.Before),
+			After:      toResponseFromState(e.After),
+			OccurredAt: e.OccurredAt,
+		})
+	}
+	return response
+}
+
+func toResponseFromState(s *history.State) *State {
+	if s == nil {
+		return nil
+	}
+
+	var reminderOffset *int64
+	if s.ReminderOffset != nil {
+		seconds := int64(s.ReminderOffset.Seconds())
+		reminderOffset = &seconds
+	}
+
+	var recurrence *string
+	if s.Recurrence != "" {
+		recurrence = &s.Recurrence
+	}
+
+	tags := []string{}
+	if s.Tags != nil {
+		tags = s.Tags
+	}
+
+	important, urgent := eisenhowerToBoolPtrs(s.Eisenhower)
+
+	return &State{
+		Text:           s.Text,
+		Completed:      s.Completed,
+		ListID:         uuidToStringPtr(s.ListID),
+		ParentID:       uuidToStringPtr(s.ParentID),
+		DueAt:          s.DueAt,
+		ReminderOffset: reminderOffset,
+		Recurrence:     recurrence,
+		Tags:           tags,
+		DeletedAt:      s.DeletedAt,
+		Position:       s.Position,
+		Priority:       s.Priority.String(),
+		Important:      important,
+		Urgent:         urgent,
+	}
+}
+
+func uuidToStringPtr(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+
+	s := id.String()
+
+	return &s
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+)
+
+// Member -.
+type Member struct {
+	UserID  string    `json:"user_id"`
+	Role    string    `json:"role"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// List -.
+type List struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Members   []Member  `json:"members"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToResponseFromList -.
+func ToResponseFromList(l list.List) List {
+	members := make([]Member, 0, len(l.Members))
+	for _, m := range l.Members {
+		members = append(members, Member{
+			UserID:  m.UserID.String(),
+			Role:    string(m.Role),
+			AddedAt: m.AddedAt,
+		})
+	}
+
+	return List{
+		ID:        l.ID.String(),
+		Name:      l.Name,
+		Members:   members,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
+
+// ToResponseFromListCollection -.
+func ToResponseFromListCollection(lists []list.List) []List {
+	response := make([]List, 0, len(lists))
+	for _, l := range lists {
+		response = append(response, ToResponseFromList(l))
+	}
+	return response
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+// Tag -.
+type Tag struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// ToResponseFromTagCollection -.
+func ToResponseFromTagCollection(tags []task.TagCount) []Tag {
+	response := make([]Tag, 0, len(tags))
+	for _, t := range tags {
+		response = append(response, Tag{Tag: t.Tag, Count: t.Count})
+	}
+	return response
+}
@@ -3,28 +3,85 @@ package model
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/internal/domain/task"
 )
 
 // Task -.
 type Task struct {
-	ID        string    `json:"id"`
-	Text      string    `json:"text"`
-	Completed bool      `json:"completed"`
-	UserID    string    `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string     `json:"id"`
+	Text      string     `json:"text"`
+	Completed bool       `json:"completed"`
+	UserID    string     `json:"user_id"`
+	ListID    *string    `json:"list_id"`
+	ParentID  *string    `json:"parent_id"`
+	DueAt     *time.Time `json:"due_at"`
+	// ReminderOffset is in seconds before DueAt.
+	ReminderOffset *int64     `json:"reminder_offset"`
+	Recurrence     *string    `json:"recurrence"`
+	Tags           []string   `json:"tags"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	Position       float64    `json:"position"`
+	Priority       string     `json:"priority"`
+	Important      *bool      `json:"important"`
+	Urgent         *bool      `json:"urgent"`
+	Version        int64      `json:"version"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 }
 
 // ToResponseFromTask -.
 func ToResponseFromTask(t task.Task) Task {
+	var reminderOffset *int64
+	if t.ReminderOffset != nil {
+		seconds := int64(t.ReminderOffset.Seconds())
+		reminderOffset = &seconds
+	}
+
+	var recurrence *string
+	if t.Recurrence != nil {
+		rule := t.Recurrence.String()
+		recurrence = &rule
+	}
+
+	var listID *string
+	if t.ListID != nil {
+		id := t.ListID.String()
+		listID = &id
+	}
+
+	var parentID *string
+	if t.ParentID != nil {
+		id := t.ParentID.String()
+		parentID = &id
+	}
+
+	tags := []string{}
+	if t.Tags != nil {
+		tags = t.Tags
+	}
+
+	important, urgent := eisenhowerToBoolPtrs(t.Eisenhower)
+
 	return Task{
-		ID:        t.ID.String(),
-		Text:      t.Text,
-		Completed: t.Completed,
-		UserID:    t.UserID.String(),
-		CreatedAt: t.CreatedAt,
-		UpdatedAt: t.UpdatedAt,
+		ID:             t.ID.String(),
+		Text:           t.Text,
+		Completed:      t.Completed,
+		UserID:         t.UserID.String(),
+		ListID:         listID,
+		ParentID:       parentID,
+		DueAt:          t.DueAt,
+		ReminderOffset: reminderOffset,
+		Recurrence:     recurrence,
+		Tags:           tags,
+		DeletedAt:      t.DeletedAt,
+		Position:       t.Position,
+		Priority:       t.Priority.String(),
+		Important:      important,
+		Urgent:         urgent,
+		Version:        t.Version,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
 	}
 }
 
@@ -36,3 +93,162 @@ func ToResponseFromTaskCollection(tasks []task.Task) []Task {
 	}
 	return response
 }
+
+// Matrix -.
+type Matrix struct {
+	DoFirst   []Task `json:"do_first"`
+	Schedule  []Task `json:"schedule"`
+	Delegate  []Task `json:"delegate"`
+	Eliminate []Task `json:"eliminate"`
+}
+
+// ToResponseFromMatrix -.
+func ToResponseFromMatrix(groups map[task.Quadrant][]task.Task) Matrix {
+	return Matrix{
+		DoFirst:   ToResponseFromTaskCollection(groups[task.QuadrantDoFirst]),
+		Schedule:  ToResponseFromTaskCollection(groups[task.QuadrantSchedule]),
+		Delegate:  ToResponseFromTaskCollection(groups[task.QuadrantDelegate]),
+		Eliminate: ToResponseFromTaskCollection(groups[task.QuadrantEliminate]),
+	}
+}
+
+func eisenhowerToBoolPtrs(e *task.Eisenhower) (*bool, *bool) {
+	if e == nil {
+		return nil, nil
+	}
+
+	important, urgent := e.Important, e.Urgent
+
+	return &important, &urgent
+}
+
+// Fragment -.
+type Fragment struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match"`
+}
+
+// SearchResult -.
+type SearchResult struct {
+	Task
+	Score      float64    `json:"score"`
+	Highlights []Fragment `json:"highlights"`
+}
+
+// ToResponseFromSearchResults -.
+func ToResponseFromSearchResults(results []task.SearchResult) []SearchResult {
+	response := make([]SearchResult, 0, len(results))
+	for _, r := range results {
+		highlights := make([]Fragment, 0, len(r.Highlights))
+		for _, f := range r.Highlights {
+			highlights = append(highlights, Fragment{Text: f.Text, Match: f.Match})
+		}
+
+		response = append(response, SearchResult{
+			Task:       ToResponseFromTask(r.Task),
+			Score:      r.Score,
+			Highlights: highlights,
+		})
+	}
+	return response
+}
+
+// Progress -.
+type Progress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+// BatchResult -.
+type BatchResult struct {
+	Status int    `json:"status"`
+	Task   *Task  `json:"task,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportResult -.
+type ImportResult struct {
+	// Row is the position of the row in the imported file, the first being 1.
+	Row    int    `json:"row"`
+	Status string `json:"status"`
+	Task   *Task  `json:"task,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportReport -.
+type ImportReport struct {
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	Skipped   int            `json:"skipped"`
+	Failed    int            `json:"failed"`
+	Rows      []ImportResult `json:"rows"`
+}
+
+// Occurrences -.
+type Occurrences struct {
+	DueAt []time.Time `json:"due_at"`
+}
+
+// TaskNode -.
+type TaskNode struct {
+	Task
+	Progress *Progress  `json:"progress,omitempty"`
+	Subtasks []TaskNode `json:"subtasks"`
+}
+
+// ToResponseFromProgress -.
+func ToResponseFromProgress(p task.Progress) Progress {
+	return Progress{
+		Completed: p.Completed,
+		Total:     p.Total,
+	}
+}
+
+// ToResponseFromTaskTree nests the tasks under their parents. Tasks whose parent is not
+// in the collection become roots. The order of the collection is kept on every level.
+func ToResponseFromTaskTree(tasks []task.Task) []TaskNode {
+	ids := make(map[uuid.UUID]bool, len(tasks))
+	for _, t := range tasks {
+		ids[t.ID] = true
+	}
+
+	var roots []task.Task
+	children := make(map[uuid.UUID][]task.Task)
+	for _, t := range tasks {
+		if t.ParentID != nil && ids[*t.ParentID] {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	response := make([]TaskNode, 0, len(roots))
+	for _, t := range roots {
+		node, _ := toTaskNode(t, children)
+		response = append(response, node)
+	}
+	return response
+}
+
+func toTaskNode(t task.Task, children map[uuid.UUID][]task.Task) (TaskNode, []task.Task) {
+	node := TaskNode{
+		Task:     ToResponseFromTask(t),
+		Subtasks: make([]TaskNode, 0, len(children[t.ID])),
+	}
+
+	var subtasks []task.Task
+	for _, child := range children[t.ID] {
+		childNode, childSubtasks := toTaskNode(child, children)
+		node.Subtasks = append(node.Subtasks, childNode)
+		subtasks = append(subtasks, child)
+		subtasks = append(subtasks, childSubtasks...)
+	}
+
+	if len(subtasks) > 0 {
+		p := ToResponseFromProgress(task.NewProgress(subtasks))
+		node.Progress = &p
+	}
+
+	return node, subtasks
+}
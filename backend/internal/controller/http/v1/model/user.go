@@ -9,6 +9,7 @@ import (
 type User struct {
 	ID        string    `json:"id"`
 	Email     string    `json:"email"`
+	Verified  bool      `json:"verified"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -17,6 +18,7 @@ func ToResponseFromUser(u user.User) User {
 	return User{
 		ID:        u.ID.String(),
 		Email:     u.Email,
+		Verified:  u.IsVerified(),
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
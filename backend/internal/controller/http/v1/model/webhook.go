@@ -0,0 +1,95 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+)
+
+// Webhook -.
+type Webhook struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret is only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Attempt -.
+type Attempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// Delivery -.
+type Delivery struct {
+	ID            string     `json:"id"`
+	EventID       string     `json:"event_id"`
+	Event         string     `json:"event"`
+	Status        string     `json:"status"`
+	Attempts      []Attempt  `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ToResponseFromWebhook -.
+func ToResponseFromWebhook(w webhook.Webhook) Webhook {
+	events := make([]string, 0, len(w.Events))
+	for _, e := range w.Events {
+		events = append(events, string(e))
+	}
+
+	return Webhook{
+		ID:        w.ID.String(),
+		URL:       w.URL,
+		Events:    events,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// ToResponseFromWebhookCollection -.
+func ToResponseFromWebhookCollection(webhooks []webhook.Webhook) []Webhook {
+	response := make([]Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		response = append(response, ToResponseFromWebhook(w))
+	}
+	return response
+}
+
+// ToResponseFromDeliveryCollection -.
+func ToResponseFromDeliveryCollection(deliveries []webhook.Delivery) []Delivery {
+	response := make([]Delivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		attempts := make([]Attempt, 0, len(d.Attempts))
+		for _, a := range d.Attempts {
+			attempts = append(attempts, Attempt{
+				At:         a.At,
+				StatusCode: a.StatusCode,
+				Error:      a.Error,
+				DurationMs: a.Duration.Milliseconds(),
+			})
+		}
+
+		var nextAttemptAt *time.Time
+		if d.Status == webhook.StatusPending {
+			next := d.NextAttemptAt
+			nextAttemptAt = &next
+		}
+
+		response = append(response, Delivery{
+			ID:            d.ID.String(),
+			EventID:       d.EventID.String(),
+			Event:         string(d.EventType),
+			Status:        string(d.Status),
+			Attempts:      attempts,
+			NextAttemptAt: nextAttemptAt,
+			CreatedAt:     d.CreatedAt,
+		})
+	}
+	return response
+}
@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
+	"github.com/ozaitsev92/tododdd/internal/domain/user"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+)
+
+// resetQueueRetryAfter is how many seconds to wait before retrying a password reset request the queue
+// had no room for, a sending round usually empties it.
+const resetQueueRetryAfter = 5
+
+type passwordResetRoutes struct {
+	l          logger.Interface
+	jwtService *jwt.JWTService
+	p          *usecase.PasswordResetUseCase
+}
+
+func newPasswordResetRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, p *usecase.PasswordResetUseCase) {
+	r := &passwordResetRoutes{l, jwtService, p}
+
+	h := handler.Group("/users/password")
+	{
+		h.POST("/forgot", r.forgotPassword)
+		h.POST("/reset", r.resetPassword)
+	}
+}
+
+// forgotPassword mails a password reset link. The response is the same whether a user is registered
+// with the email or not, so the registered emails are not disclosed. The requests made too often for an email
+// or from an IP address are throttled, and those the queue has no room for are to be retried.
+func (r *passwordResetRoutes) forgotPassword(c *gin.Context) {
+	type forgotPasswordRequest struct {
+		Email string `json:"email" binding:"required"`
+	}
+	var request forgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - forgotPassword")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	wait, err := r.p.RequestPasswordReset(c.Request.Context(), request.Email, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, usecase.ErrResetRequestsThrottled) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"Error": err.Error()})
+
+			return
+		}
+
+		r.l.Error(err, "http - v1 - forgotPassword")
+
+		switch {
+		case errors.Is(err, usecase.ErrResetRequestsQueued):
+			c.Header("Retry-After", strconv.Itoa(resetQueueRetryAfter))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"Error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{})
+}
+
+// resetPassword sets a new password with a mailed token. The sessions of the user are ended,
+// so the cookies of the current one are cleared.
+func (r *passwordResetRoutes) resetPassword(c *gin.Context) {
+	type resetPasswordRequest struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	var request resetPasswordRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - resetPassword")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	err := r.p.ResetPassword(c.Request.Context(), request.Token, request.Password)
+	if err != nil {
+		r.l.Error(err, "http - v1 - resetPassword")
+
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidResetToken) || errors.Is(err, user.ErrInvalidPassword) {
+			status = http.StatusBadRequest
+		}
+		c.AbortWithStatusJSON(status, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	setCookie(c, r.jwtService.ExpiredAuthCookie())
+	setCookie(c, r.jwtService.ExpiredRefreshCookie())
+	c.JSON(http.StatusOK, gin.H{})
+}
@@ -2,18 +2,20 @@ package v1
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ozaitsev92/tododdd/config"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/middleware"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/stream"
 	"github.com/ozaitsev92/tododdd/internal/usecase"
 	"github.com/ozaitsev92/tododdd/pkg/logger"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // todo: refactor. too many params
-func NewRouter(handler *gin.Engine, cfg config.Config, l logger.Interface, jwtService *jwt.JWTService, t *usecase.TaskUseCase, u *usecase.UserUseCase) {
+func NewRouter(handler *gin.Engine, cfg config.Config, l logger.Interface, jwtService *jwt.JWTService, t *usecase.TaskUseCase, u *usecase.UserUseCase, s *usecase.SessionUseCase, li *usecase.ListUseCase, b *usecase.TaskBatchUseCase, im *usecase.TaskImportUseCase, hub *stream.TaskHub, w *usecase.WebhookUseCase, f *usecase.FeedUseCase, pr *usecase.PasswordResetUseCase, v *usecase.EmailVerificationUseCase, g *usecase.LoginGuardUseCase) {
 	// Options
 	handler.Use(gin.Logger())
 	handler.Use(gin.Recovery())
@@ -28,7 +30,16 @@ func NewRouter(handler *gin.Engine, cfg config.Config, l logger.Interface, jwtSe
 	// Routers
 	h := handler.Group("/v1")
 	{
-		newTaskRoutes(h, l, jwtService, u, t)
-		newUserRoutes(h, l, jwtService, u)
+		newTaskRoutes(h, l, jwtService, u, t, b)
+		newTransferRoutes(h, l, jwtService, u, t, im)
+		newTagRoutes(h, l, jwtService, u, t)
+		newUserRoutes(h, l, jwtService, u, s, v, g)
+		newPasswordResetRoutes(h, l, jwtService, pr)
+		newEmailVerificationRoutes(h, l, v)
+		newListRoutes(h, l, jwtService, u, li)
+		newStreamRoutes(h, l, jwtService, u, hub, time.Duration(cfg.StreamHeartbeat)*time.Second)
+		newChannelRoutes(h, l, jwtService, u, t, li, hub, cfg.AllowedOrigin, time.Duration(cfg.StreamHeartbeat)*time.Second)
+		newWebhookRoutes(h, l, jwtService, u, w)
+		newFeedRoutes(h, l, jwtService, u, f)
 	}
 }
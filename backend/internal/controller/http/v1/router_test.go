@@ -4,29 +4,56 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"mime/quotedprintable"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	netmail "net/mail"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/ory/dockertest/v3"
 	"github.com/ozaitsev92/tododdd/config"
 	v1 "github.com/ozaitsev92/tododdd/internal/controller/http/v1"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
 	"github.com/ozaitsev92/tododdd/internal/domain/task"
 	"github.com/ozaitsev92/tododdd/internal/domain/user"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
 	"github.com/ozaitsev92/tododdd/internal/usecase"
 	"github.com/ozaitsev92/tododdd/pkg/mongodb"
-
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/net/websocket"
+
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/mailer"
+	feedRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/mongo"
+	historyRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/history/mongo"
+	listRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/mongo"
+	loginRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/mongo"
+	outboxRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/mongo"
+	resetRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/mongo"
+	sessionRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/mongo"
 	taskRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/mongo"
 	taskConverter "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/mongo/converter"
+	transaction "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/transaction/mongo"
 	userRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/user/mongo"
 	userConverter "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/user/mongo/converter"
+	webhookRepository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/mongo"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/sender"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/stream"
 )
 
 var (
@@ -34,7 +61,8 @@ var (
 )
 
 const (
-	jwtCookieName = "jwt-token"
+	jwtCookieName     = "jwt-token"
+	refreshCookieName = "refresh-token"
 )
 
 type mockLogger struct{}
@@ -54,20 +82,191 @@ func newJsonRequest(method, url string, payload map[string]string) *http.Request
 	return req
 }
 
+func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+
+	return nil
+}
+
+// waitForFile returns the content of the file once it has been written, which is done in the background.
+func waitForFile(path string) ([]byte, error) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		b, err := os.ReadFile(path)
+		if (err == nil && len(b) > 0) || time.Now().After(deadline) {
+			return b, err
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 func setNewRouter() (*gin.Engine, config.Config, *jwt.JWTService) {
+	return setNewRouterWithAccess(usecase.UnverifiedAccessFull)
+}
+
+// setNewRouterWithAccess creates a router applying the policy to the users who have not verified their email.
+func setNewRouterWithAccess(unverifiedAccess usecase.UnverifiedAccess) (*gin.Engine, config.Config, *jwt.JWTService) {
+	return setNewRouterWithConfig(func(cfg *config.Config) {
+		cfg.UnverifiedAccess = string(unverifiedAccess)
+	})
+}
+
+// setNewRouterWithConfig creates a router with the test config changed by the function.
+func setNewRouterWithConfig(configure func(cfg *config.Config)) (*gin.Engine, config.Config, *jwt.JWTService) {
 	cfg := config.Config{}
 	cfg.MongoDBName = "todo_test"
-	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s/?directConnection=true", MONGODB_PORT)
 	cfg.JWTSessionLength = 30
+	cfg.RefreshTokenLength = 24
+	cfg.AllowedOrigin = "http://localhost:8081"
+	cfg.AppURL = "http://localhost:8081"
+	cfg.MailFrom = "Todo <noreply@example.com>"
+	cfg.MailFile = filepath.Join(os.TempDir(), "tododdd-"+uuid.NewString()+".eml")
+	cfg.EmailVerificationKey = "test-key"
+	configure(&cfg)
 
 	l := new(mockLogger)
 
+	listRepo := listRepository.NewRepository(cfg)
+	accessPolicy := usecase.NewAccessPolicy(listRepo)
+
+	taskRepo := taskRepository.NewRepository(cfg)
+	outboxRepo := outboxRepository.NewRepository(cfg)
+	transactor := transaction.NewTransactor(cfg)
 	taskUseCase := usecase.NewTaskUseCase(
-		taskRepository.NewRepository(cfg),
+		taskRepo,
+		historyRepository.NewRepository(cfg),
+		outboxRepo,
+		transactor,
+		accessPolicy,
 	)
 
+	// The events are relayed right away
+	outboxUseCase := usecase.NewOutboxUseCase(outboxRepo)
+	taskUseCase.OnEventsAdded(func() {
+		_, _ = outboxUseCase.Relay(context.Background())
+	})
+
+	sessionRepo := sessionRepository.NewRepository(cfg)
+	resetRepo := resetRepository.NewRepository(cfg)
+	webhookRepo := webhookRepository.NewRepository(cfg)
+	deliveryRepo := webhookRepository.NewDeliveryRepository(cfg)
+	feedRepo := feedRepository.NewRepository(cfg)
+
+	userRepo := userRepository.NewRepository(cfg)
 	userUseCase := usecase.NewUserUseCase(
-		userRepository.NewRepository(cfg),
+		userRepo,
+		taskRepo,
+		listRepo,
+		sessionRepo,
+		resetRepo,
+		webhookRepo,
+		deliveryRepo,
+		feedRepo,
+		transactor,
+		usecase.UnverifiedAccess(cfg.UnverifiedAccess),
+		user.Passwords{},
+	)
+
+	listUseCase := usecase.NewListUseCase(
+		listRepo,
+		taskRepo,
+		userUseCase,
+		accessPolicy,
+	)
+
+	taskBatchUseCase := usecase.NewTaskBatchUseCase(
+		taskUseCase,
+		transactor,
+	)
+
+	taskImportUseCase := usecase.NewTaskImportUseCase(taskUseCase)
+
+	taskHub := stream.NewTaskHub(cfg)
+	outboxUseCase.AddPublisher(taskHub)
+
+	webhookSender, _ := sender.NewHTTPSender(cfg)
+	webhookUseCase := usecase.NewWebhookUseCase(
+		webhookRepo,
+		deliveryRepo,
+		webhookSender,
+		webhook.Backoff{Base: time.Second, Max: time.Minute, MaxAttempts: 3},
+		time.Minute,
+		accessPolicy,
+	)
+	outboxUseCase.AddPublisher(webhookUseCase)
+
+	sessionUseCase := usecase.NewSessionUseCase(
+		sessionRepo,
+		time.Duration(cfg.RefreshTokenLength)*time.Hour,
+	)
+
+	loginRepo := loginRepository.NewRepository(cfg)
+
+	passwordResetUseCase := usecase.NewPasswordResetUseCase(
+		resetRepo,
+		userRepo,
+		sessionRepo,
+		transactor,
+		mailer.NewFileMailer(cfg),
+		time.Hour,
+		0,
+		cfg.AppURL+"/reset-password",
+		user.Passwords{},
+		loginRepo,
+		login.Policy{
+			LockoutThreshold: cfg.PasswordResetAccountRequests,
+			LockoutDuration:  time.Duration(cfg.PasswordResetRequestWindow) * time.Minute,
+			Window:           time.Duration(cfg.PasswordResetRequestWindow) * time.Minute,
+		},
+		login.Policy{
+			LockoutThreshold: cfg.PasswordResetIPRequests,
+			LockoutDuration:  time.Duration(cfg.PasswordResetRequestWindow) * time.Minute,
+			Window:           time.Duration(cfg.PasswordResetRequestWindow) * time.Minute,
+		},
+	)
+
+	// The password reset mails are sent right away
+	go func() {
+		for range passwordResetUseCase.Pending() {
+			_, _ = passwordResetUseCase.SendRequested(context.Background())
+		}
+	}()
+
+	feedUseCase := usecase.NewFeedUseCase(
+		feedRepo,
+		taskRepo,
+	)
+
+	emailVerificationUseCase := usecase.NewEmailVerificationUseCase(
+		userRepo,
+		mailer.NewFileMailer(cfg),
+		[]byte(cfg.EmailVerificationKey),
+		time.Hour,
+		time.Minute,
+		cfg.AppURL+"/verify-email",
+	)
+
+	loginGuardUseCase := usecase.NewLoginGuardUseCase(
+		loginRepo,
+		login.Policy{
+			FreeAttempts:     cfg.LoginAccountFreeAttempts,
+			LockoutThreshold: cfg.LoginAccountLockoutThreshold,
+			LockoutDuration:  time.Duration(cfg.LoginLockoutDuration) * time.Minute,
+			Window:           time.Duration(cfg.LoginAttemptWindow) * time.Minute,
+		},
+		login.Policy{
+			FreeAttempts:     cfg.LoginIPFreeAttempts,
+			LockoutThreshold: cfg.LoginIPLockoutThreshold,
+			LockoutDuration:  time.Duration(cfg.LoginLockoutDuration) * time.Minute,
+			Window:           time.Duration(cfg.LoginAttemptWindow) * time.Minute,
+		},
 	)
 
 	jwtService := jwt.NewJWTService(
@@ -81,7 +280,7 @@ func setNewRouter() (*gin.Engine, config.Config, *jwt.JWTService) {
 
 	handler := gin.Default()
 
-	v1.NewRouter(handler, cfg, l, jwtService, taskUseCase, userUseCase)
+	v1.NewRouter(handler, cfg, l, jwtService, taskUseCase, userUseCase, sessionUseCase, listUseCase, taskBatchUseCase, taskImportUseCase, taskHub, webhookUseCase, feedUseCase, passwordResetUseCase, emailVerificationUseCase, loginGuardUseCase)
 
 	return handler, cfg, jwtService
 }
@@ -92,7 +291,12 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Could not connect to docker: %s", err)
 	}
 
-	resource, err := pool.Run("mongo", "latest", []string{})
+	// The tasks are saved in transactions, which need a replica set.
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "mongo",
+		Tag:        "latest",
+		Cmd:        []string{"--replSet", "rs0"},
+	})
 	if err != nil {
 		log.Fatalf("Could not start resource: %s", err)
 	}
@@ -100,7 +304,11 @@ func TestMain(m *testing.M) {
 	err = pool.Retry(func() error {
 		MONGODB_PORT = resource.GetPort("27017/tcp")
 		_, err := net.Dial("tcp", net.JoinHostPort("localhost", MONGODB_PORT))
-		return err
+		if err != nil {
+			return err
+		}
+
+		return initReplicaSet(fmt.Sprintf("mongodb://localhost:%s/?directConnection=true", MONGODB_PORT))
 	})
 
 	if err != nil {
@@ -116,6 +324,40 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+// initReplicaSet makes the server a single member replica set and returns nil once it is the primary.
+func initReplicaSet(url string) error {
+	ctx := context.Background()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(url))
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(ctx)
+
+	admin := client.Database("admin")
+
+	var commandErr mongo.CommandError
+	err = admin.RunCommand(ctx, bson.D{{Key: "replSetInitiate", Value: bson.D{}}}).Err()
+	if err != nil && !(errors.As(err, &commandErr) && commandErr.Name == "AlreadyInitialized") {
+		return err
+	}
+
+	var hello struct {
+		IsWritablePrimary bool `bson:"isWritablePrimary"`
+	}
+
+	err = admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return err
+	}
+
+	if !hello.IsWritablePrimary {
+		return errors.New("the replica set has no primary yet")
+	}
+
+	return nil
+}
+
 func TestRepositoryHealthz(t *testing.T) {
 	router, _, _ := setNewRouter()
 
@@ -236,6 +478,73 @@ func TestRepositoryLogin(t *testing.T) {
 	}
 }
 
+func TestRepositoryRefresh(t *testing.T) {
+	router, cfg, _ := setNewRouter()
+
+	rawPassword := "Password123"
+	u, err := user.NewUser("refresh@example.com", rawPassword)
+	if err != nil {
+		t.Errorf("/v1/users/refresh failed to create a new user: err = '%v'", err)
+	}
+
+	// Add the user to the users collection
+	collection := mongodb.NewOrGetSingleton(cfg).Collection("users")
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = collection.InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/users/refresh failed to save a new user: err = '%v'", err)
+	}
+
+	// Log in to get a refresh token
+	payload := map[string]string{
+		"password": rawPassword,
+		"email":    u.Email,
+	}
+	req := newJsonRequest("POST", "/v1/users/login", payload)
+
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	loginCookie := findCookie(w.Result().Cookies(), refreshCookieName)
+	if loginCookie == nil || loginCookie.Value == "" {
+		t.Fatalf("/v1/users/login response must have a '%s' cookie", refreshCookieName)
+	}
+
+	// Exchange the refresh token: should succeed
+	req = newJsonRequest("POST", "/v1/users/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: loginCookie.Name, Value: loginCookie.Value})
+
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/users/refresh got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	refreshCookie := findCookie(w.Result().Cookies(), refreshCookieName)
+	if refreshCookie == nil || refreshCookie.Value == "" || refreshCookie.Value == loginCookie.Value {
+		t.Errorf("/v1/users/refresh response must have a new '%s' cookie", refreshCookieName)
+	}
+
+	if jwtCookie := findCookie(w.Result().Cookies(), jwtCookieName); jwtCookie == nil || jwtCookie.Value == "" {
+		t.Errorf("/v1/users/refresh response must have a '%s' cookie", jwtCookieName)
+	}
+
+	// Reuse the exchanged refresh token: should fail
+	req = newJsonRequest("POST", "/v1/users/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: loginCookie.Name, Value: loginCookie.Value})
+
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 401 {
+		t.Errorf("/v1/users/refresh got = '%v', want = '%v'", w.Code, 401)
+	}
+}
+
 func TestRepositoryLogout(t *testing.T) {
 	router, cfg, _ := setNewRouter()
 
@@ -395,6 +704,42 @@ func TestRepositoryCreateTask(t *testing.T) {
 	if response.Completed {
 		t.Error("/v1/tasks task should not be completed")
 	}
+
+	// The options and the access to the list are checked
+	dueAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	type testCase struct {
+		name     string
+		payload  map[string]any
+		wantCode int
+	}
+
+	tests := []testCase{
+		{name: "Unknown list", payload: map[string]any{"text": "task text", "list_id": uuid.NewString()}, wantCode: 403},
+		{name: "Unknown parent", payload: map[string]any{"text": "task text", "parent_id": uuid.NewString()}, wantCode: 400},
+		{name: "Invalid tag", payload: map[string]any{"text": "task text", "tags": []string{" "}}, wantCode: 400},
+		{name: "Reminder without due date", payload: map[string]any{"text": "task text", "reminder_offset": 60}, wantCode: 400},
+		{name: "Negative reminder", payload: map[string]any{"text": "task text", "due_at": dueAt, "reminder_offset": -60}, wantCode: 400},
+		{name: "Recurrence without due date", payload: map[string]any{"text": "task text", "recurrence": "FREQ=DAILY"}, wantCode: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, _ := json.Marshal(tt.payload)
+
+			req := httptest.NewRequest("POST", "/v1/tasks", bytes.NewBuffer(payload))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("/v1/tasks got = '%v', want = '%v'", w.Code, tt.wantCode)
+			}
+		})
+	}
 }
 
 func TestRepositoryUpdateTask(t *testing.T) {
@@ -435,6 +780,7 @@ func TestRepositoryUpdateTask(t *testing.T) {
 		"text": "task text v2",
 	}
 	req := newJsonRequest("PUT", "/v1/tasks/"+ti.ID.String(), payload)
+	req.Header.Set("If-Match", `"1"`)
 	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
 
 	w := httptest.NewRecorder()
@@ -632,6 +978,7 @@ func TestRepositoryDeleteTask(t *testing.T) {
 	jwtCookie := jwtService.AuthCookie(token)
 
 	req := newJsonRequest("DELETE", "/v1/tasks/"+ti.ID.String(), nil)
+	req.Header.Set("If-Match", `"1"`)
 	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
 
 	w := httptest.NewRecorder()
@@ -646,3 +993,2588 @@ func TestRepositoryDeleteTask(t *testing.T) {
 		t.Errorf("/v1/tasks/:id response body should be empty, got = '%s'", w.Body.String())
 	}
 }
+
+func TestRepositoryListTasks(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("test1@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the tasks to the tasks collection
+	for _, text := range []string{"task text 1", "task text 2", "task text 3"} {
+		ti, err := task.NewTask(text, u.ID)
+		if err != nil {
+			t.Errorf("/v1/tasks failed to create a new task: err = '%v'", err)
+		}
+
+		mongoTask := taskConverter.ToRepoFromTask(ti)
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), mongoTask)
+		if err != nil {
+			t.Errorf("/v1/tasks failed to save a new task: err = '%v'", err)
+		}
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	req := newJsonRequest("GET", "/v1/tasks?sort=text&order=desc&limit=2", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response []model.Task
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/tasks error = '%v'", err)
+	}
+
+	if len(response) != 2 {
+		t.Fatalf("/v1/tasks got = '%v', want = '%v'", len(response), 2)
+	}
+
+	if response[0].Text != "task text 3" {
+		t.Errorf("/v1/tasks got = '%v', want = '%v'", response[0].Text, "task text 3")
+	}
+
+	if w.Header().Get("X-Next-Cursor") == "" {
+		t.Error("/v1/tasks response must have a 'X-Next-Cursor' header")
+	}
+
+	req = newJsonRequest("GET", "/v1/tasks?limit=1000", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Errorf("/v1/tasks got = '%v', want = '%v'", w.Code, 400)
+	}
+
+	req = newJsonRequest("GET", "/v1/tasks?cursor=invalid", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Errorf("/v1/tasks with an invalid cursor got = '%v', want = '%v'", w.Code, 400)
+	}
+
+	// The tasks of a list the user is not a member of are forbidden
+	req = newJsonRequest("GET", "/v1/tasks?list_id="+uuid.NewString(), nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 403 {
+		t.Errorf("/v1/tasks of another list got = '%v', want = '%v'", w.Code, 403)
+	}
+}
+
+func TestRepositoryListTasksDefaultLimit(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("test1@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks failed to save a new user: err = '%v'", err)
+	}
+
+	// Add one task more than a page to the tasks collection
+	for i := 0; i <= task.DefaultQueryLimit; i++ {
+		ti, err := task.NewTask(fmt.Sprintf("task text %d", i), u.ID)
+		if err != nil {
+			t.Errorf("/v1/tasks failed to create a new task: err = '%v'", err)
+		}
+
+		mongoTask := taskConverter.ToRepoFromTask(ti)
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), mongoTask)
+		if err != nil {
+			t.Errorf("/v1/tasks failed to save a new task: err = '%v'", err)
+		}
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	req := newJsonRequest("GET", "/v1/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response []model.Task
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/tasks error = '%v'", err)
+	}
+
+	if len(response) != task.DefaultQueryLimit {
+		t.Errorf("/v1/tasks got = '%v', want = '%v'", len(response), task.DefaultQueryLimit)
+	}
+
+	if w.Header().Get("X-Next-Cursor") == "" {
+		t.Error("/v1/tasks response must have a 'X-Next-Cursor' header")
+	}
+}
+
+func TestRepositoryOverdueTasks(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("test1@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/overdue failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/overdue failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the task to the tasks collection
+	ti, err := task.NewTask("task text v1", u.ID, task.WithDueDate(time.Now().Add(-time.Hour)))
+	if err != nil {
+		t.Errorf("/v1/tasks/overdue failed to create a new task: err = '%v'", err)
+	}
+
+	mongoTask := taskConverter.ToRepoFromTask(ti)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), mongoTask)
+	if err != nil {
+		t.Errorf("/v1/tasks/overdue failed to save a new task: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	req := newJsonRequest("GET", "/v1/tasks/overdue", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks/overdue got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response []model.Task
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/tasks/overdue error = '%v'", err)
+	}
+
+	if len(response) != 1 || response[0].ID != ti.ID.String() {
+		t.Errorf("/v1/tasks/overdue got = '%v', want = '%v'", response, ti.ID)
+	}
+
+	if len(response) == 1 && response[0].DueAt == nil {
+		t.Error("/v1/tasks/overdue task must have a due date")
+	}
+}
+
+func TestRepositoryTaskDueDate(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("due-date@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/due-date failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/due-date failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the task to the tasks collection
+	ti, err := task.NewTask("task text v1", u.ID)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/due-date failed to create a new task: err = '%v'", err)
+	}
+
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/due-date failed to save a new task: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	type testCase struct {
+		name     string
+		payload  map[string]any
+		wantCode int
+	}
+
+	dueAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	tests := []testCase{
+		{name: "Zero due date", payload: map[string]any{"due_at": time.Time{}.Format(time.RFC3339)}, wantCode: 400},
+		{name: "Negative reminder", payload: map[string]any{"due_at": dueAt, "reminder_offset": -60}, wantCode: 400},
+		{name: "Reminder without due date", payload: map[string]any{"reminder_offset": 60}, wantCode: 400},
+		{name: "Success", payload: map[string]any{"due_at": dueAt, "reminder_offset": 60}, wantCode: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, _ := json.Marshal(tt.payload)
+
+			req := httptest.NewRequest("PUT", "/v1/tasks/"+ti.ID.String()+"/due-date", bytes.NewBuffer(payload))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("/v1/tasks/:id/due-date got = '%v', want = '%v'", w.Code, tt.wantCode)
+			}
+		})
+	}
+
+	// The due window is checked
+	for within, wantCode := range map[string]int{"-1h": 400, "2h": 200} {
+		req := newJsonRequest("GET", "/v1/tasks/due-soon?within="+within, nil)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != wantCode {
+			t.Errorf("/v1/tasks/due-soon?within=%s got = '%v', want = '%v'", within, w.Code, wantCode)
+		}
+	}
+}
+
+func TestRepositoryTaskProgress(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("test1@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/progress failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/progress failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the parent task and two subtasks to the tasks collection
+	parent, err := task.NewTask("parent", u.ID)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/progress failed to create a new task: err = '%v'", err)
+	}
+
+	done, err := task.NewTask("done", u.ID, task.WithParent(parent.ID))
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/progress failed to create a new task: err = '%v'", err)
+	}
+	done.MarkCompleted()
+
+	todo, err := task.NewTask("todo", u.ID, task.WithParent(parent.ID))
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/progress failed to create a new task: err = '%v'", err)
+	}
+
+	for _, ti := range []task.Task{parent, done, todo} {
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+		if err != nil {
+			t.Errorf("/v1/tasks/:id/progress failed to save a new task: err = '%v'", err)
+		}
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	req := newJsonRequest("GET", "/v1/tasks/"+parent.ID.String()+"/progress", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks/:id/progress got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response model.Progress
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/progress error = '%v'", err)
+	}
+
+	if response.Completed != 1 || response.Total != 2 {
+		t.Errorf("/v1/tasks/:id/progress got = '%v', want = '%v'", response, model.Progress{Completed: 1, Total: 2})
+	}
+
+	// A missing task and a task of another user
+	foreign, err := task.NewTask("foreign", uuid.New())
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/progress failed to create a new task: err = '%v'", err)
+	}
+
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(foreign))
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/progress failed to save a new task: err = '%v'", err)
+	}
+
+	for id, wantCode := range map[string]int{uuid.NewString(): 404, foreign.ID.String(): 403} {
+		req = newJsonRequest("GET", "/v1/tasks/"+id+"/progress", nil)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w = httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != wantCode {
+			t.Errorf("/v1/tasks/:id/progress got = '%v', want = '%v'", w.Code, wantCode)
+		}
+	}
+}
+
+func TestRepositorySetTaskParent(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("parent@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/parent failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/parent failed to save a new user: err = '%v'", err)
+	}
+
+	// Add a chain of task.MaxDepth tasks and a task on its own to the tasks collection
+	var chain []task.Task
+	for i := 0; i < task.MaxDepth; i++ {
+		var opts []task.Option
+		if i > 0 {
+			opts = append(opts, task.WithParent(chain[i-1].ID))
+		}
+
+		ti, err := task.NewTask(fmt.Sprintf("level %d", i+1), u.ID, opts...)
+		if err != nil {
+			t.Errorf("/v1/tasks/:id/parent failed to create a new task: err = '%v'", err)
+		}
+		chain = append(chain, ti)
+	}
+
+	single, err := task.NewTask("single", u.ID)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/parent failed to create a new task: err = '%v'", err)
+	}
+
+	for _, ti := range append(chain, single) {
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+		if err != nil {
+			t.Errorf("/v1/tasks/:id/parent failed to save a new task: err = '%v'", err)
+		}
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	type testCase struct {
+		name     string
+		id       uuid.UUID
+		parentId uuid.UUID
+		wantCode int
+	}
+
+	tests := []testCase{
+		{name: "Own parent", id: single.ID, parentId: single.ID, wantCode: 400},
+		{name: "Unknown parent", id: single.ID, parentId: uuid.New(), wantCode: 400},
+		{name: "Cycle", id: chain[0].ID, parentId: chain[2].ID, wantCode: 409},
+		{name: "Too deep", id: single.ID, parentId: chain[task.MaxDepth-1].ID, wantCode: 409},
+		{name: "Success", id: single.ID, parentId: chain[0].ID, wantCode: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newJsonRequest("PUT", "/v1/tasks/"+tt.id.String()+"/parent", map[string]string{"parent_id": tt.parentId.String()})
+			req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("/v1/tasks/:id/parent got = '%v', want = '%v'", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRepositoryShareList(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the owner and the invitee to the users collection
+	owner, err := user.NewUser("list-owner@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/lists failed to create a new user: err = '%v'", err)
+	}
+
+	invitee, err := user.NewUser("list-invitee@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/lists failed to create a new user: err = '%v'", err)
+	}
+
+	for _, u := range []user.User{owner, invitee} {
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), userConverter.ToRepoFromUser(u))
+		if err != nil {
+			t.Errorf("/v1/lists failed to save a new user: err = '%v'", err)
+		}
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(owner.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	// Create a list
+	req := newJsonRequest("POST", "/v1/lists", map[string]string{"name": "Team"})
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 201 {
+		t.Errorf("/v1/lists got = '%v', want = '%v'", w.Code, 201)
+	}
+
+	var response model.List
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/lists error = '%v'", err)
+	}
+
+	// Invite a user by email
+	req = newJsonRequest("POST", "/v1/lists/"+response.ID+"/members", map[string]string{
+		"email": invitee.Email,
+		"role":  "viewer",
+	})
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/lists/:id/members got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/lists/:id/members error = '%v'", err)
+	}
+
+	if len(response.Members) != 2 || response.Members[1].UserID != invitee.ID.String() || response.Members[1].Role != "viewer" {
+		t.Errorf("/v1/lists/:id/members got = '%v', want = '%v'", response.Members, invitee.ID)
+	}
+
+	// The viewer cannot add tasks to the list
+	token, err = jwtService.CreateJWTTokenForUser(invitee.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie = jwtService.AuthCookie(token)
+
+	req = newJsonRequest("POST", "/v1/tasks", map[string]string{"text": "task text v1", "list_id": response.ID})
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 403 {
+		t.Errorf("/v1/tasks got = '%v', want = '%v'", w.Code, 403)
+	}
+}
+
+func TestRepositoryTags(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("test1@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tags failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tags failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the tasks to the tasks collection
+	var tagged task.Task
+	for _, tags := range [][]string{{"home", "work"}, {"work"}} {
+		ti, err := task.NewTask("task text v1", u.ID, task.WithTags(tags...))
+		if err != nil {
+			t.Errorf("/v1/tags failed to create a new task: err = '%v'", err)
+		}
+		tagged = ti
+
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+		if err != nil {
+			t.Errorf("/v1/tags failed to save a new task: err = '%v'", err)
+		}
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	req := newJsonRequest("GET", "/v1/tags", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tags got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response []model.Tag
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/tags error = '%v'", err)
+	}
+
+	want := []model.Tag{{Tag: "work", Count: 2}, {Tag: "home", Count: 1}}
+	if len(response) != len(want) || response[0] != want[0] || response[1] != want[1] {
+		t.Errorf("/v1/tags got = '%v', want = '%v'", response, want)
+	}
+
+	// Invalid tags are the client's fault
+	tooMany := make([]string, 0, task.MaxTags+1)
+	for i := 0; i <= task.MaxTags; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("tag%d", i))
+	}
+
+	for _, tags := range [][]string{{" "}, tooMany} {
+		payload, _ := json.Marshal(map[string][]string{"tags": tags})
+
+		req = httptest.NewRequest("PUT", "/v1/tasks/"+tagged.ID.String()+"/tags", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w = httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != 400 {
+			t.Errorf("/v1/tasks/:id/tags got = '%v', want = '%v' for %v tags", w.Code, 400, len(tags))
+		}
+	}
+}
+
+func TestRepositorySearchTasks(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	err := taskRepository.NewRepository(cfg).EnsureIndexes(context.Background())
+	if err != nil {
+		t.Errorf("/v1/tasks/search failed to create the indexes: err = '%v'", err)
+	}
+
+	// Add the user to the users collection
+	u, err := user.NewUser("test1@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/search failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/search failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the task to the tasks collection
+	ti, err := task.NewTask("Buy milk", u.ID)
+	if err != nil {
+		t.Errorf("/v1/tasks/search failed to create a new task: err = '%v'", err)
+	}
+
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+	if err != nil {
+		t.Errorf("/v1/tasks/search failed to save a new task: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	req := newJsonRequest("GET", "/v1/tasks/search?q=milk", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks/search got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response []model.SearchResult
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/tasks/search error = '%v'", err)
+	}
+
+	if len(response) != 1 || response[0].ID != ti.ID.String() {
+		t.Fatalf("/v1/tasks/search got = '%v', want = '%v'", response, ti.ID)
+	}
+
+	if len(response[0].Highlights) != 2 || !response[0].Highlights[1].Match {
+		t.Errorf("/v1/tasks/search got = '%v', want the term highlighted", response[0].Highlights)
+	}
+
+	// Invalid queries are the client's fault
+	for _, query := range []string{"q=", "q=milk&limit=0"} {
+		req = newJsonRequest("GET", "/v1/tasks/search?"+query, nil)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w = httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != 400 {
+			t.Errorf("/v1/tasks/search?%s got = '%v', want = '%v'", query, w.Code, 400)
+		}
+	}
+}
+
+func TestRepositoryTrashTask(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("trash@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/trash failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/trash failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the task to the tasks collection
+	ti, err := task.NewTask("task text", u.ID)
+	if err != nil {
+		t.Errorf("/v1/tasks/trash failed to create a new task: err = '%v'", err)
+	}
+
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+	if err != nil {
+		t.Errorf("/v1/tasks/trash failed to save a new task: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	// Move the task to the trash
+	req := newJsonRequest("DELETE", "/v1/tasks/"+ti.ID.String(), nil)
+	req.Header.Set("If-Match", `"1"`)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("DELETE /v1/tasks/:id got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	// The trash contains the task
+	req = newJsonRequest("GET", "/v1/tasks/trash", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks/trash got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var trash []model.Task
+
+	err = json.Unmarshal(w.Body.Bytes(), &trash)
+	if err != nil {
+		t.Errorf("/v1/tasks/trash error = '%v'", err)
+	}
+
+	if len(trash) != 1 || trash[0].ID != ti.ID.String() || trash[0].DeletedAt == nil {
+		t.Errorf("/v1/tasks/trash got = '%v', want the deleted task", trash)
+	}
+
+	// Restore the task
+	req = newJsonRequest("POST", "/v1/tasks/"+ti.ID.String()+"/restore", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks/:id/restore got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var restored model.Task
+
+	err = json.Unmarshal(w.Body.Bytes(), &restored)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/restore error = '%v'", err)
+	}
+
+	if restored.ID != ti.ID.String() || restored.DeletedAt != nil {
+		t.Errorf("/v1/tasks/:id/restore got = '%v', want the restored task", restored)
+	}
+	// A task not in the trash cannot be restored
+	req = newJsonRequest("POST", "/v1/tasks/"+ti.ID.String()+"/restore", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 409 {
+		t.Errorf("/v1/tasks/:id/restore got = '%v', want = '%v'", w.Code, 409)
+	}
+}
+
+func TestRepositoryTaskOccurrences(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("occurrences@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/occurrences failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/occurrences failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the recurring task to the tasks collection
+	dueAt := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+	ti, err := task.NewTask("task text", u.ID, task.WithDueDate(dueAt), task.WithRecurrence(task.MustParseRecurrence("FREQ=DAILY;INTERVAL=2")))
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/occurrences failed to create a new task: err = '%v'", err)
+	}
+
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/occurrences failed to save a new task: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	req := newJsonRequest("GET", "/v1/tasks/"+ti.ID.String()+"/occurrences?n=2", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks/:id/occurrences got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response model.Occurrences
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/occurrences error = '%v'", err)
+	}
+
+	want := []time.Time{dueAt.AddDate(0, 0, 2), dueAt.AddDate(0, 0, 4)}
+	if len(response.DueAt) != len(want) || !response.DueAt[0].Equal(want[0]) || !response.DueAt[1].Equal(want[1]) {
+		t.Errorf("/v1/tasks/:id/occurrences got = '%v', want = '%v'", response.DueAt, want)
+	}
+
+	// A task without a due date and a task of another user
+	plain, err := task.NewTask("plain", u.ID)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/occurrences failed to create a new task: err = '%v'", err)
+	}
+
+	foreign, err := task.NewTask("foreign", uuid.New(), task.WithDueDate(dueAt), task.WithRecurrence(task.MustParseRecurrence("FREQ=DAILY")))
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/occurrences failed to create a new task: err = '%v'", err)
+	}
+
+	for _, ti := range []task.Task{plain, foreign} {
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+		if err != nil {
+			t.Errorf("/v1/tasks/:id/occurrences failed to save a new task: err = '%v'", err)
+		}
+	}
+
+	type testCase struct {
+		name     string
+		url      string
+		wantCode int
+	}
+
+	tests := []testCase{
+		{name: "Invalid n", url: "/v1/tasks/" + ti.ID.String() + "/occurrences?n=0", wantCode: 400},
+		{name: "Unknown task", url: "/v1/tasks/" + uuid.NewString() + "/occurrences", wantCode: 404},
+		{name: "Task of another user", url: "/v1/tasks/" + foreign.ID.String() + "/occurrences", wantCode: 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newJsonRequest("GET", tt.url, nil)
+			req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("%s got = '%v', want = '%v'", tt.url, w.Code, tt.wantCode)
+			}
+		})
+	}
+
+	// A recurrence requires a due date
+	req = newJsonRequest("PUT", "/v1/tasks/"+plain.ID.String()+"/recurrence", map[string]string{"recurrence": "FREQ=DAILY"})
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Errorf("/v1/tasks/:id/recurrence got = '%v', want = '%v'", w.Code, 400)
+	}
+}
+
+func TestRepositoryTaskHistory(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("history@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/history failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/history failed to save a new user: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	// Create the task and change its text
+	req := newJsonRequest("POST", "/v1/tasks", map[string]string{"text": "first"})
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var created model.Task
+
+	err = json.Unmarshal(w.Body.Bytes(), &created)
+	if err != nil {
+		t.Errorf("/v1/tasks error = '%v'", err)
+	}
+
+	req = newJsonRequest("PUT", "/v1/tasks/"+created.ID, map[string]string{"text": "second"})
+	req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, created.Version))
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("PUT /v1/tasks/:id got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	req = newJsonRequest("GET", "/v1/tasks/"+created.ID+"/history", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks/:id/history got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response []model.Event
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/history error = '%v'", err)
+	}
+
+	if len(response) != 2 {
+		t.Fatalf("/v1/tasks/:id/history got = '%v', want = '%v'", len(response), 2)
+	}
+
+	last := response[1]
+	if last.Operation != "update_text" || last.ActorID != u.ID.String() || last.Before.Text != "first" || last.After.Text != "second" {
+		t.Errorf("/v1/tasks/:id/history got = '%v', want the text update", last)
+	}
+
+	// A missing task and a task of another user
+	foreign, err := task.NewTask("foreign", uuid.New())
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/history failed to create a new task: err = '%v'", err)
+	}
+
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(foreign))
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/history failed to save a new task: err = '%v'", err)
+	}
+
+	for id, wantCode := range map[string]int{uuid.NewString(): 404, foreign.ID.String(): 403} {
+		req = newJsonRequest("GET", "/v1/tasks/"+id+"/history", nil)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != wantCode {
+			t.Errorf("/v1/tasks/:id/history got = '%v', want = '%v'", w.Code, wantCode)
+		}
+	}
+}
+
+func TestRepositoryTaskConcurrency(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("concurrency@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/:id failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the task to the tasks collection
+	ti, err := task.NewTask("task text", u.ID)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id failed to create a new task: err = '%v'", err)
+	}
+
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+	if err != nil {
+		t.Errorf("/v1/tasks/:id failed to save a new task: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	type testCase struct {
+		name     string
+		method   string
+		ifMatch  string
+		wantCode int
+		wantETag string
+	}
+
+	// The cases run in order, each one sees the changes of the previous ones
+	tests := []testCase{
+		{name: "Read", method: "GET", ifMatch: "", wantCode: 200, wantETag: `"1"`},
+		{name: "Update without If-Match", method: "PUT", ifMatch: "", wantCode: 428, wantETag: ""},
+		{name: "Update", method: "PUT", ifMatch: `"1"`, wantCode: 200, wantETag: `"2"`},
+		{name: "Stale update", method: "PUT", ifMatch: `"1"`, wantCode: 412, wantETag: ""},
+		{name: "Stale delete", method: "DELETE", ifMatch: `"1"`, wantCode: 412, wantETag: ""},
+		{name: "Delete", method: "DELETE", ifMatch: `"2"`, wantCode: 200, wantETag: ""},
+	}
+
+	for _, tt := range tests {
+		req := newJsonRequest(tt.method, "/v1/tasks/"+ti.ID.String(), map[string]string{"text": "task text v2"})
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: /v1/tasks/:id got = '%v', want = '%v'", tt.name, w.Code, tt.wantCode)
+		}
+
+		if etag := w.Header().Get("ETag"); etag != tt.wantETag {
+			t.Errorf("%s: /v1/tasks/:id ETag got = '%v', want = '%v'", tt.name, etag, tt.wantETag)
+		}
+	}
+}
+
+func TestRepositoryBatchTasks(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("batch@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/batch failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/batch failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the task to the tasks collection
+	ti, err := task.NewTask("task text", u.ID)
+	if err != nil {
+		t.Errorf("/v1/tasks/batch failed to create a new task: err = '%v'", err)
+	}
+
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+	if err != nil {
+		t.Errorf("/v1/tasks/batch failed to save a new task: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"atomic": false,
+		"operations": []map[string]interface{}{
+			{"op": "create", "text": "new task"},
+			{"op": "complete", "id": ti.ID.String()},
+			{"op": "update_text", "id": ti.ID.String(), "text": "task text v2", "version": 1},
+			{"op": "delete", "id": uuid.NewString()},
+		},
+	})
+
+	req := httptest.NewRequest("POST", "/v1/tasks/batch", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks/batch got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response []model.BatchResult
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/tasks/batch failed to parse the response: err = '%v'", err)
+	}
+
+	wantStatuses := []int{200, 200, 412, 404}
+	if len(response) != len(wantStatuses) {
+		t.Fatalf("/v1/tasks/batch len got = '%v', want = '%v'", len(response), len(wantStatuses))
+	}
+
+	for i, want := range wantStatuses {
+		if response[i].Status != want {
+			t.Errorf("/v1/tasks/batch [%d] status got = '%v', want = '%v'", i, response[i].Status, want)
+		}
+	}
+
+	if response[0].Task == nil || response[0].Task.Text != "new task" {
+		t.Errorf("/v1/tasks/batch [0] task got = '%v', want text = '%v'", response[0].Task, "new task")
+	}
+
+	// An empty batch is the client's fault
+	payload, _ = json.Marshal(map[string]interface{}{"operations": []map[string]interface{}{}})
+
+	req = httptest.NewRequest("POST", "/v1/tasks/batch", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Errorf("/v1/tasks/batch got = '%v', want = '%v'", w.Code, 400)
+	}
+}
+
+func TestRepositoryMoveTask(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("move@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/move failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/:id/move failed to save a new user: err = '%v'", err)
+	}
+
+	// Add the tasks to the tasks collection
+	var ids []string
+	for i := 0; i < 3; i++ {
+		ti, err := task.NewTask(fmt.Sprintf("task %d", i), u.ID)
+		if err != nil {
+			t.Errorf("/v1/tasks/:id/move failed to create a new task: err = '%v'", err)
+		}
+		ti.MoveTo(float64(i+1) * task.PositionGap)
+
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+		if err != nil {
+			t.Errorf("/v1/tasks/:id/move failed to save a new task: err = '%v'", err)
+		}
+
+		ids = append(ids, ti.ID.String())
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	type testCase struct {
+		name      string
+		id        string
+		payload   map[string]string
+		wantCode  int
+		wantOrder []string
+	}
+
+	// The cases run in order, each one sees the changes of the previous ones
+	tests := []testCase{
+		{name: "No neighbour", id: ids[2], payload: map[string]string{}, wantCode: 400, wantOrder: []string{ids[0], ids[1], ids[2]}},
+		{name: "Before", id: ids[2], payload: map[string]string{"before": ids[0]}, wantCode: 200, wantOrder: []string{ids[2], ids[0], ids[1]}},
+		{name: "After", id: ids[0], payload: map[string]string{"after": ids[1]}, wantCode: 200, wantOrder: []string{ids[2], ids[1], ids[0]}},
+	}
+
+	for _, tt := range tests {
+		req := newJsonRequest("PUT", "/v1/tasks/"+tt.id+"/move", tt.payload)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: /v1/tasks/:id/move got = '%v', want = '%v'", tt.name, w.Code, tt.wantCode)
+		}
+
+		req = httptest.NewRequest("GET", "/v1/tasks?sort=position", nil)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response []model.Task
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("%s: /v1/tasks failed to parse the response: err = '%v'", tt.name, err)
+		}
+
+		var order []string
+		for _, ti := range response {
+			order = append(order, ti.ID)
+		}
+
+		if !reflect.DeepEqual(order, tt.wantOrder) {
+			t.Errorf("%s: /v1/tasks got = '%v', want = '%v'", tt.name, order, tt.wantOrder)
+		}
+	}
+}
+
+func TestRepositoryEisenhowerMatrix(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("matrix@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/matrix failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/matrix failed to save a new user: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	type testCase struct {
+		name     string
+		payload  map[string]string
+		wantCode int
+	}
+
+	soon := time.Now().Add(time.Hour).Format(time.RFC3339)
+	tests := []testCase{
+		{name: "Do first", payload: map[string]string{"text": "do first", "priority": "high", "due_at": soon}, wantCode: 200},
+		{name: "Schedule", payload: map[string]string{"text": "schedule", "priority": "high"}, wantCode: 200},
+		{name: "Eliminate", payload: map[string]string{"text": "eliminate", "priority": "low"}, wantCode: 200},
+		{name: "Invalid priority", payload: map[string]string{"text": "invalid", "priority": "critical"}, wantCode: 400},
+	}
+
+	for _, tt := range tests {
+		req := newJsonRequest("POST", "/v1/tasks", tt.payload)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: /v1/tasks got = '%v', want = '%v'", tt.name, w.Code, tt.wantCode)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/v1/tasks/matrix", nil)
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks/matrix got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response model.Matrix
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/tasks/matrix failed to parse the response: err = '%v'", err)
+	}
+
+	if len(response.DoFirst) != 1 || len(response.Schedule) != 1 || len(response.Delegate) != 0 || len(response.Eliminate) != 1 {
+		t.Errorf("/v1/tasks/matrix got = '%v'", response)
+	}
+
+	if len(response.DoFirst) == 1 && response.DoFirst[0].Priority != "high" {
+		t.Errorf("/v1/tasks/matrix priority got = '%v', want = '%v'", response.DoFirst[0].Priority, "high")
+	}
+}
+
+func TestRepositoryTaskStream(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("stream@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/stream failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/stream failed to save a new user: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	req := newJsonRequest("POST", "/v1/tasks", map[string]string{"text": "streamed"})
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	type testCase struct {
+		name        string
+		lastEventID string
+		wantCode    int
+		wantEvent   bool
+	}
+
+	tests := []testCase{
+		{name: "Resume", lastEventID: "0", wantCode: 200, wantEvent: true},
+		{name: "No resume", lastEventID: "", wantCode: 200, wantEvent: false},
+		{name: "Invalid Last-Event-ID", lastEventID: "abc", wantCode: 400, wantEvent: false},
+	}
+
+	for _, tt := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+
+		req := httptest.NewRequest("GET", "/v1/tasks/stream", nil).WithContext(ctx)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+		if tt.lastEventID != "" {
+			req.Header.Set("Last-Event-ID", tt.lastEventID)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		cancel()
+
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: /v1/tasks/stream got = '%v', want = '%v'", tt.name, w.Code, tt.wantCode)
+		}
+
+		gotEvent := bytes.Contains(w.Body.Bytes(), []byte("event: task.created\n"))
+		if gotEvent != tt.wantEvent {
+			t.Errorf("%s: /v1/tasks/stream got = '%s'", tt.name, w.Body.String())
+		}
+	}
+}
+
+// receiveChannelMessage returns the next message of the given type the channel sends.
+func receiveChannelMessage(t *testing.T, ws *websocket.Conn, messageType string) model.ChannelMessage {
+	t.Helper()
+
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var m model.ChannelMessage
+		if err := websocket.JSON.Receive(ws, &m); err != nil {
+			t.Fatalf("/v1/lists/:id/channel failed to receive a %s message: err = '%v'", messageType, err)
+		}
+
+		if m.Type == messageType {
+			return m
+		}
+	}
+}
+
+func TestRepositoryListChannel(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// Add the owner and the viewer to the users collection
+	owner, err := user.NewUser("channel-owner@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/lists/:id/channel failed to create a new user: err = '%v'", err)
+	}
+
+	viewer, err := user.NewUser("channel-viewer@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/lists/:id/channel failed to create a new user: err = '%v'", err)
+	}
+
+	cookies := map[uuid.UUID]http.Cookie{}
+	for _, u := range []user.User{owner, viewer} {
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), userConverter.ToRepoFromUser(u))
+		if err != nil {
+			t.Errorf("/v1/lists/:id/channel failed to save a new user: err = '%v'", err)
+		}
+
+		token, err := jwtService.CreateJWTTokenForUser(u.ID)
+		if err != nil {
+			return
+		}
+		cookies[u.ID] = jwtService.AuthCookie(token)
+	}
+
+	// Create a list shared with the viewer
+	req := newJsonRequest("POST", "/v1/lists", map[string]string{"name": "Channel"})
+	req.AddCookie(&http.Cookie{Name: cookies[owner.ID].Name, Value: cookies[owner.ID].Value})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var l model.List
+	err = json.Unmarshal(w.Body.Bytes(), &l)
+	if err != nil {
+		t.Fatalf("/v1/lists error = '%v'", err)
+	}
+
+	req = newJsonRequest("POST", "/v1/lists/"+l.ID+"/members", map[string]string{"email": viewer.Email, "role": "viewer"})
+	req.AddCookie(&http.Cookie{Name: cookies[owner.ID].Name, Value: cookies[owner.ID].Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("/v1/lists/:id/members got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	dial := func(userId uuid.UUID, origin string) (*websocket.Conn, error) {
+		wsConfig, err := websocket.NewConfig("ws"+server.URL[len("http"):]+"/v1/lists/"+l.ID+"/channel", origin)
+		if err != nil {
+			return nil, err
+		}
+		wsConfig.Header.Add("Cookie", (&http.Cookie{Name: cookies[userId].Name, Value: cookies[userId].Value}).String())
+
+		return websocket.DialConfig(wsConfig)
+	}
+
+	// Other sites cannot open the channel with the cookie of the user
+	if ws, err := dial(owner.ID, "http://evil.example.com"); err == nil {
+		ws.Close()
+		t.Errorf("/v1/lists/:id/channel accepted a foreign origin")
+	}
+
+	ownerWs, err := dial(owner.ID, cfg.AllowedOrigin)
+	if err != nil {
+		t.Fatalf("/v1/lists/:id/channel failed to connect: err = '%v'", err)
+	}
+	defer ownerWs.Close()
+
+	ownerWelcome := receiveChannelMessage(t, ownerWs, "welcome")
+
+	viewerWs, err := dial(viewer.ID, cfg.AllowedOrigin)
+	if err != nil {
+		t.Fatalf("/v1/lists/:id/channel failed to connect: err = '%v'", err)
+	}
+	defer viewerWs.Close()
+
+	receiveChannelMessage(t, viewerWs, "welcome")
+
+	// The owner sees the viewer join
+	for {
+		m := receiveChannelMessage(t, ownerWs, "presence")
+		if len(m.Members) == 2 {
+			break
+		}
+	}
+
+	// The owner creates a task and the viewer gets the change
+	err = websocket.JSON.Send(ownerWs, map[string]string{"type": "create", "ref": "1", "text": "shared task"})
+	if err != nil {
+		t.Fatalf("/v1/lists/:id/channel failed to send: err = '%v'", err)
+	}
+
+	ack := receiveChannelMessage(t, ownerWs, "ack")
+	if ack.Ref != "1" || ack.Task == nil || ack.Task.ListID == nil || *ack.Task.ListID != l.ID {
+		t.Fatalf("/v1/lists/:id/channel ack got = '%v'", ack)
+	}
+
+	event := receiveChannelMessage(t, viewerWs, "event")
+	if event.Event != string(task.EventCreated) || event.Task == nil || event.Task.ID != ack.Task.ID || event.ActorID != owner.ID.String() {
+		t.Errorf("/v1/lists/:id/channel event got = '%v'", event)
+	}
+
+	// The viewer can say what they are viewing but cannot change the tasks
+	err = websocket.JSON.Send(viewerWs, map[string]string{"type": "presence", "task_id": ack.Task.ID, "state": "viewing"})
+	if err != nil {
+		t.Fatalf("/v1/lists/:id/channel failed to send: err = '%v'", err)
+	}
+
+	for {
+		m := receiveChannelMessage(t, ownerWs, "presence")
+		found := false
+		for _, p := range m.Members {
+			if p.UserID == viewer.ID.String() && p.TaskID != nil && *p.TaskID == ack.Task.ID {
+				found = true
+			}
+		}
+		if found {
+			break
+		}
+	}
+
+	err = websocket.JSON.Send(viewerWs, map[string]string{"type": "complete", "ref": "2", "id": ack.Task.ID})
+	if err != nil {
+		t.Fatalf("/v1/lists/:id/channel failed to send: err = '%v'", err)
+	}
+
+	denied := receiveChannelMessage(t, viewerWs, "error")
+	if denied.Ref != "2" || denied.Status != 403 {
+		t.Errorf("/v1/lists/:id/channel error got = '%v', want = '%v'", denied.Status, 403)
+	}
+
+	// The tasks of the other lists cannot be changed through the channel
+	req = newJsonRequest("POST", "/v1/tasks", map[string]string{"text": "personal task"})
+	req.AddCookie(&http.Cookie{Name: cookies[owner.ID].Name, Value: cookies[owner.ID].Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var personal model.Task
+	err = json.Unmarshal(w.Body.Bytes(), &personal)
+	if err != nil {
+		t.Fatalf("/v1/tasks error = '%v'", err)
+	}
+
+	for i, operation := range []string{"update_text", "complete", "move", "delete"} {
+		ref := fmt.Sprint(3 + i)
+		err = websocket.JSON.Send(ownerWs, map[string]string{"type": operation, "ref": ref, "id": personal.ID, "text": "changed"})
+		if err != nil {
+			t.Fatalf("/v1/lists/:id/channel failed to send: err = '%v'", err)
+		}
+
+		rejected := receiveChannelMessage(t, ownerWs, "error")
+		if rejected.Ref != ref || rejected.Status != 404 {
+			t.Errorf("/v1/lists/:id/channel %s of a personal task got = '%v', want = '%v'", operation, rejected.Status, 404)
+		}
+	}
+
+	req = newJsonRequest("GET", "/v1/tasks/"+personal.ID, nil)
+	req.AddCookie(&http.Cookie{Name: cookies[owner.ID].Name, Value: cookies[owner.ID].Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var unchanged model.Task
+	err = json.Unmarshal(w.Body.Bytes(), &unchanged)
+	if err != nil || unchanged.Text != "personal task" || unchanged.Completed {
+		t.Errorf("/v1/tasks/:id got = '%v', '%v', want the personal task unchanged", unchanged, err)
+	}
+
+	// The owner sees the viewer leave
+	viewerWs.Close()
+	for {
+		m := receiveChannelMessage(t, ownerWs, "presence")
+		if len(m.Members) == 1 && m.Members[0].SessionID == ownerWelcome.SessionID {
+			break
+		}
+	}
+}
+
+func TestRepositoryWebhooks(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("webhooks@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/webhooks failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/webhooks failed to save a new user: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	newWebhookRequest := func(method, url string, payload map[string]any) *http.Request {
+		jsonPayload, _ := json.Marshal(payload)
+
+		req := httptest.NewRequest(method, url, bytes.NewBuffer(jsonPayload))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		return req
+	}
+
+	type testCase struct {
+		name     string
+		payload  map[string]any
+		wantCode int
+	}
+
+	tests := []testCase{
+		{name: "Invalid URL", payload: map[string]any{"url": "not a url", "events": []string{"task.created"}}, wantCode: 400},
+		{name: "Unknown event", payload: map[string]any{"url": "https://example.com/hook", "events": []string{"task.archived"}}, wantCode: 400},
+		{name: "Success", payload: map[string]any{"url": "https://example.com/hook", "events": []string{"task.created"}}, wantCode: 201},
+	}
+
+	var created model.Webhook
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newWebhookRequest("POST", "/v1/webhooks", tt.payload))
+
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: /v1/webhooks got = '%v', want = '%v'", tt.name, w.Code, tt.wantCode)
+		}
+
+		if w.Code == 201 {
+			err = json.Unmarshal(w.Body.Bytes(), &created)
+			if err != nil {
+				t.Errorf("/v1/webhooks failed to parse the response: err = '%v'", err)
+			}
+		}
+	}
+
+	if created.Secret == "" {
+		t.Errorf("/v1/webhooks secret got = '%v', want a secret", created.Secret)
+	}
+
+	// The secret is not shown again
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newWebhookRequest("GET", "/v1/webhooks", nil))
+
+	var webhooks []model.Webhook
+	err = json.Unmarshal(w.Body.Bytes(), &webhooks)
+	if err != nil {
+		t.Errorf("/v1/webhooks failed to parse the response: err = '%v'", err)
+	}
+
+	if len(webhooks) != 1 || webhooks[0].ID != created.ID || webhooks[0].Secret != "" {
+		t.Errorf("/v1/webhooks got = '%v'", webhooks)
+	}
+
+	// Creating a task queues a delivery
+	req := newJsonRequest("POST", "/v1/tasks", map[string]string{"text": "hooked"})
+	req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/tasks got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newWebhookRequest("GET", "/v1/webhooks/"+created.ID+"/deliveries", nil))
+
+	if w.Code != 200 {
+		t.Errorf("/v1/webhooks/:id/deliveries got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var deliveries []model.Delivery
+	err = json.Unmarshal(w.Body.Bytes(), &deliveries)
+	if err != nil {
+		t.Errorf("/v1/webhooks/:id/deliveries failed to parse the response: err = '%v'", err)
+	}
+
+	if len(deliveries) != 1 || deliveries[0].Event != "task.created" || deliveries[0].Status != "pending" || deliveries[0].NextAttemptAt == nil {
+		t.Errorf("/v1/webhooks/:id/deliveries got = '%v'", deliveries)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newWebhookRequest("PUT", "/v1/webhooks/"+created.ID, map[string]any{"url": "https://example.com/updated", "events": []string{"task.deleted"}}))
+
+	if w.Code != 200 {
+		t.Errorf("PUT /v1/webhooks/:id got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newWebhookRequest("DELETE", "/v1/webhooks/"+created.ID, nil))
+
+	if w.Code != 200 {
+		t.Errorf("DELETE /v1/webhooks/:id got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newWebhookRequest("GET", "/v1/webhooks/"+created.ID, nil))
+
+	if w.Code != 404 {
+		t.Errorf("GET /v1/webhooks/:id got = '%v', want = '%v'", w.Code, 404)
+	}
+}
+
+func TestRepositoryExportImportTasks(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("transfer@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/tasks/export failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/tasks/export failed to save a new user: err = '%v'", err)
+	}
+
+	// Add a task and its completed subtask to the tasks collection
+	parent, err := task.NewTask("parent", u.ID, task.WithTags("work"))
+	if err != nil {
+		t.Errorf("/v1/tasks/export failed to create a new task: err = '%v'", err)
+	}
+
+	subtask, err := task.NewTask("subtask", u.ID, task.WithParent(parent.ID))
+	if err != nil {
+		t.Errorf("/v1/tasks/export failed to create a new task: err = '%v'", err)
+	}
+	subtask.MarkCompleted()
+
+	for _, ti := range []task.Task{parent, subtask} {
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+		if err != nil {
+			t.Errorf("/v1/tasks/export failed to save a new task: err = '%v'", err)
+		}
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	export := func(format string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/tasks/export?format="+format, nil)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != 200 {
+			t.Errorf("/v1/tasks/export?format=%s got = '%v', want = '%v'", format, w.Code, 200)
+		}
+
+		return w
+	}
+
+	importFile := func(format string, duplicates string, body string) model.ImportReport {
+		req := httptest.NewRequest("POST", "/v1/tasks/import?format="+format+"&duplicates="+duplicates, bytes.NewBufferString(body))
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != 200 {
+			t.Errorf("/v1/tasks/import?format=%s got = '%v', want = '%v'", format, w.Code, 200)
+		}
+
+		var report model.ImportReport
+		err := json.Unmarshal(w.Body.Bytes(), &report)
+		if err != nil {
+			t.Errorf("/v1/tasks/import failed to parse the response: err = '%v'", err)
+		}
+
+		return report
+	}
+
+	// Markdown is a checklist with the subtask under its parent
+	wantChecklist := "- [ ] parent\n  - [x] subtask\n"
+	if got := export("md").Body.String(); got != wantChecklist {
+		t.Errorf("/v1/tasks/export?format=md got = '%v', want = '%v'", got, wantChecklist)
+	}
+
+	// CSV has a header row and a row per task
+	w := export("csv")
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="tasks.csv"` {
+		t.Errorf("/v1/tasks/export?format=csv Content-Disposition got = '%v'", got)
+	}
+	if got := bytes.Count(w.Body.Bytes(), []byte("\n")); got != 3 {
+		t.Errorf("/v1/tasks/export?format=csv lines got = '%v', want = '%v'", got, 3)
+	}
+
+	// JSON round-trips, the changed task is updated and the other one left alone
+	var exported []model.Task
+	err = json.Unmarshal(export("json").Body.Bytes(), &exported)
+	if err != nil || len(exported) != 2 {
+		t.Fatalf("/v1/tasks/export?format=json got = '%v', err = '%v'", exported, err)
+	}
+	for i := range exported {
+		if exported[i].ID == parent.ID.String() {
+			exported[i].Text = "parent v2"
+		}
+	}
+
+	payload, _ := json.Marshal(exported)
+	report := importFile("json", "update", string(payload))
+	if report.Updated != 1 || report.Unchanged != 1 {
+		t.Errorf("/v1/tasks/import?format=json got = '%+v', want 1 updated and 1 unchanged", report)
+	}
+
+	// CSV rows are reported on one by one
+	report = importFile("csv", "skip", "text,due_at,tags\nnew task,,home\nbroken,tomorrow,\n")
+	if report.Created != 1 || report.Failed != 1 || len(report.Rows) != 2 {
+		t.Fatalf("/v1/tasks/import?format=csv got = '%+v', want 1 created and 1 failed", report)
+	}
+	if report.Rows[1].Row != 2 || report.Rows[1].Error == "" {
+		t.Errorf("/v1/tasks/import?format=csv [1] got = '%+v', want an error for row 2", report.Rows[1])
+	}
+
+	// Markdown subtasks are imported under their parents
+	report = importFile("md", "skip", "# Groceries\n- [ ] milk\n  - [x] oat milk\n")
+	if report.Created != 2 || report.Rows[1].Task == nil || report.Rows[1].Task.ParentID == nil ||
+		*report.Rows[1].Task.ParentID != report.Rows[0].Task.ID {
+		t.Errorf("/v1/tasks/import?format=md got = '%+v', want the second item under the first", report)
+	}
+}
+
+func TestRepositoryTaskFeed(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the user to the users collection
+	u, err := user.NewUser("feed@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/users/current/feed failed to create a new user: err = '%v'", err)
+	}
+
+	mongoUser := userConverter.ToRepoFromUser(u)
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), mongoUser)
+	if err != nil {
+		t.Errorf("/v1/users/current/feed failed to save a new user: err = '%v'", err)
+	}
+
+	// Add a completed task with a due date and a task without one to the tasks collection
+	dueAt := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+	due, err := task.NewTask("file taxes, finally", u.ID, task.WithDueDate(dueAt))
+	if err != nil {
+		t.Errorf("/v1/users/current/feed failed to create a new task: err = '%v'", err)
+	}
+	due.MarkCompleted()
+	// Stored before the tasks were versioned
+	due.Version = 0
+
+	undated, err := task.NewTask("someday", u.ID)
+	if err != nil {
+		t.Errorf("/v1/users/current/feed failed to create a new task: err = '%v'", err)
+	}
+
+	for _, ti := range []task.Task{due, undated} {
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+		if err != nil {
+			t.Errorf("/v1/users/current/feed failed to save a new task: err = '%v'", err)
+		}
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	authorized := func(method string, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	// Create the feed
+	w := authorized("POST", "/v1/users/current/feed")
+	if w.Code != 201 {
+		t.Fatalf("POST /v1/users/current/feed got = '%v', want = '%v'", w.Code, 201)
+	}
+
+	var created model.Feed
+	err = json.Unmarshal(w.Body.Bytes(), &created)
+	if err != nil || created.URL == "" {
+		t.Fatalf("POST /v1/users/current/feed got = '%v', err = '%v'", w.Body.String(), err)
+	}
+
+	// Fetch the feed without the cookie
+	req := httptest.NewRequest("GET", created.URL, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("GET %s got = '%v', want = '%v'", created.URL, w.Code, 200)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:" + due.ID.String() + "\r\n",
+		"SUMMARY:file taxes\\, finally\r\n",
+		"STATUS:COMPLETED\r\n",
+		"DUE:20300102T150405Z\r\n",
+		"SEQUENCE:0\r\n",
+	} {
+		if !bytes.Contains([]byte(body), []byte(want)) {
+			t.Errorf("GET %s got = '%v', want it to contain '%v'", created.URL, body, want)
+		}
+	}
+	if bytes.Contains([]byte(body), []byte(undated.ID.String())) {
+		t.Errorf("GET %s got = '%v', want no task without a due date", created.URL, body)
+	}
+
+	etag := w.Header().Get("ETag")
+	lastModified := w.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Errorf("GET %s ETag = '%v', Last-Modified = '%v', want both", created.URL, etag, lastModified)
+	}
+
+	// Conditional requests of an unchanged feed
+	for header, value := range map[string]string{"If-None-Match": etag, "If-Modified-Since": lastModified} {
+		req = httptest.NewRequest("GET", created.URL, nil)
+		req.Header.Set(header, value)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != 304 {
+			t.Errorf("GET %s with %s got = '%v', want = '%v'", created.URL, header, w.Code, 304)
+		}
+	}
+
+	// Revoke the feed
+	w = authorized("DELETE", "/v1/users/current/feed")
+	if w.Code != 204 {
+		t.Errorf("DELETE /v1/users/current/feed got = '%v', want = '%v'", w.Code, 204)
+	}
+
+	req = httptest.NewRequest("GET", created.URL, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 404 {
+		t.Errorf("GET %s after revoking got = '%v', want = '%v'", created.URL, w.Code, 404)
+	}
+
+	w = authorized("GET", "/v1/users/current/feed")
+	if w.Code != 404 {
+		t.Errorf("GET /v1/users/current/feed after revoking got = '%v', want = '%v'", w.Code, 404)
+	}
+}
+
+func TestRepositoryUserProfile(t *testing.T) {
+	router, cfg, jwtService := setNewRouter()
+
+	// Add the users to the users collection
+	u, err := user.NewUser("profile@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/users/current failed to create a new user: err = '%v'", err)
+	}
+
+	other, err := user.NewUser("taken@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/users/current failed to create a new user: err = '%v'", err)
+	}
+
+	for _, ui := range []user.User{u, other} {
+		_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), userConverter.ToRepoFromUser(ui))
+		if err != nil {
+			t.Errorf("/v1/users/current failed to save a new user: err = '%v'", err)
+		}
+	}
+
+	// Add a personal task to the tasks collection
+	ti, err := task.NewTask("task text", u.ID)
+	if err != nil {
+		t.Errorf("/v1/users/current failed to create a new task: err = '%v'", err)
+	}
+
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("tasks").InsertOne(context.Background(), taskConverter.ToRepoFromTask(ti))
+	if err != nil {
+		t.Errorf("/v1/users/current failed to save a new task: err = '%v'", err)
+	}
+
+	token, err := jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		return
+	}
+
+	jwtCookie := jwtService.AuthCookie(token)
+
+	authorized := func(method string, target string, payload map[string]string) *httptest.ResponseRecorder {
+		req := newJsonRequest(method, target, payload)
+		req.AddCookie(&http.Cookie{Name: jwtCookie.Name, Value: jwtCookie.Value})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	// Sensitive changes require the current password
+	w := authorized("PUT", "/v1/users/current/email", map[string]string{"password": "WrongPassword", "email": "changed@example.com"})
+	if w.Code != 403 {
+		t.Errorf("PUT /v1/users/current/email got = '%v', want = '%v'", w.Code, 403)
+	}
+
+	w = authorized("PUT", "/v1/users/current/email", map[string]string{"password": "Password123", "email": other.Email})
+	if w.Code != 409 {
+		t.Errorf("PUT /v1/users/current/email got = '%v', want = '%v'", w.Code, 409)
+	}
+
+	w = authorized("PUT", "/v1/users/current/email", map[string]string{"password": "Password123", "email": "changed@example.com"})
+	if w.Code != 200 {
+		t.Fatalf("PUT /v1/users/current/email got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	var response model.User
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil || response.Email != "changed@example.com" {
+		t.Errorf("PUT /v1/users/current/email got = '%v', err = '%v'", w.Body.String(), err)
+	}
+
+	w = authorized("PUT", "/v1/users/current/password", map[string]string{"password": "Password123", "new_password": "Password456"})
+	if w.Code != 200 {
+		t.Fatalf("PUT /v1/users/current/password got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	// Log in with the new credentials
+	req := newJsonRequest("POST", "/v1/users/login", map[string]string{"email": "changed@example.com", "password": "Password456"})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/users/login got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	// Delete the account
+	w = authorized("DELETE", "/v1/users/current", map[string]string{"password": "Password123"})
+	if w.Code != 403 {
+		t.Errorf("DELETE /v1/users/current got = '%v', want = '%v'", w.Code, 403)
+	}
+
+	w = authorized("DELETE", "/v1/users/current", map[string]string{"password": "Password456"})
+	if w.Code != 204 {
+		t.Fatalf("DELETE /v1/users/current got = '%v', want = '%v'", w.Code, 204)
+	}
+
+	w = authorized("GET", "/v1/users/current", nil)
+	if w.Code != 401 {
+		t.Errorf("GET /v1/users/current after deleting got = '%v', want = '%v'", w.Code, 401)
+	}
+
+	count, err := mongodb.NewOrGetSingleton(cfg).Collection("tasks").CountDocuments(context.Background(), bson.M{"_id": ti.ID.String()})
+	if err != nil || count != 0 {
+		t.Errorf("DELETE /v1/users/current left the task of the user, count = '%v', err = '%v'", count, err)
+	}
+}
+
+func TestRepositoryPasswordReset(t *testing.T) {
+	router, cfg, _ := setNewRouter()
+
+	u, err := user.NewUser("reset@example.com", "Password123")
+	if err != nil {
+		t.Errorf("/v1/users/password failed to create a new user: err = '%v'", err)
+	}
+
+	// Add the user to the users collection
+	_, err = mongodb.NewOrGetSingleton(cfg).Collection("users").InsertOne(context.Background(), userConverter.ToRepoFromUser(u))
+	if err != nil {
+		t.Errorf("/v1/users/password failed to save a new user: err = '%v'", err)
+	}
+
+	// Log in to get a refresh token
+	req := newJsonRequest("POST", "/v1/users/login", map[string]string{"email": u.Email, "password": "Password123"})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	loginCookie := findCookie(w.Result().Cookies(), refreshCookieName)
+	if loginCookie == nil || loginCookie.Value == "" {
+		t.Fatalf("/v1/users/login response must have a '%s' cookie", refreshCookieName)
+	}
+
+	// Request a reset for an unknown and a registered email: the responses are the same
+	for _, email := range []string{"unknown@example.com", u.Email} {
+		req = newJsonRequest("POST", "/v1/users/password/forgot", map[string]string{"email": email})
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != 202 {
+			t.Errorf("/v1/users/password/forgot got = '%v', want = '%v'", w.Code, 202)
+		}
+	}
+
+	// Read the token from the mailed link
+	b, err := waitForFile(cfg.MailFile)
+	if err != nil {
+		t.Fatalf("/v1/users/password/forgot mail not written: err = '%v'", err)
+	}
+
+	msg, err := netmail.ReadMessage(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("/v1/users/password/forgot mail is invalid: err = '%v'", err)
+	}
+	if msg.Header.Get("To") != u.Email {
+		t.Errorf("/v1/users/password/forgot mail To = '%v', want = '%v'", msg.Header.Get("To"), u.Email)
+	}
+
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatalf("/v1/users/password/forgot mail body is invalid: err = '%v'", err)
+	}
+
+	match := regexp.MustCompile(`/reset-password\?token=([A-Za-z0-9_-]+)`).FindSubmatch(body)
+	if match == nil {
+		t.Fatalf("/v1/users/password/forgot mail body = '%s', want a reset link", body)
+	}
+	token := string(match[1])
+
+	// Reset the password
+	req = newJsonRequest("POST", "/v1/users/password/reset", map[string]string{"token": token, "password": "Password456"})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("/v1/users/password/reset got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	// The token is single-use
+	req = newJsonRequest("POST", "/v1/users/password/reset", map[string]string{"token": token, "password": "Password789"})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Errorf("/v1/users/password/reset with a used token got = '%v', want = '%v'", w.Code, 400)
+	}
+
+	// The existing sessions are ended
+	req = newJsonRequest("POST", "/v1/users/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: loginCookie.Name, Value: loginCookie.Value})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 401 {
+		t.Errorf("/v1/users/refresh after the reset got = '%v', want = '%v'", w.Code, 401)
+	}
+
+	// Log in with the new password
+	req = newJsonRequest("POST", "/v1/users/login", map[string]string{"email": u.Email, "password": "Password456"})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("/v1/users/login with the new password got = '%v', want = '%v'", w.Code, 200)
+	}
+}
+
+func TestRepositoryPasswordResetThrottled(t *testing.T) {
+	router, _, _ := setNewRouterWithConfig(func(cfg *config.Config) {
+		cfg.PasswordResetAccountRequests = 2
+		cfg.PasswordResetRequestWindow = 60
+	})
+
+	email := uuid.NewString() + "@example.com"
+
+	// The requests beyond the limit for the email are refused, whether a user is registered with it or not
+	for i, want := range []int{202, 202, 429} {
+		req := newJsonRequest("POST", "/v1/users/password/forgot", map[string]string{"email": email})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Errorf("/v1/users/password/forgot request %d got = '%v', want = '%v'", i, w.Code, want)
+		}
+		if want == 429 && w.Header().Get("Retry-After") == "" {
+			t.Error("/v1/users/password/forgot response must have a Retry-After header")
+		}
+	}
+}
+
+func TestRepositoryEmailVerification(t *testing.T) {
+	router, cfg, _ := setNewRouterWithAccess(usecase.UnverifiedAccessLimited)
+
+	credentials := map[string]string{"email": "verify@example.com", "password": "Password123"}
+
+	// Register: the user is unverified and gets a verification link
+	req := newJsonRequest("POST", "/v1/users", credentials)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 201 {
+		t.Fatalf("/v1/users got = '%v', want = '%v'", w.Code, 201)
+	}
+
+	var response model.User
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/users error = '%v'", err)
+	}
+	if response.Verified {
+		t.Error("/v1/users User.Verified = true for a new user")
+	}
+
+	b, err := os.ReadFile(cfg.MailFile)
+	if err != nil {
+		t.Fatalf("/v1/users verification mail not written: err = '%v'", err)
+	}
+
+	msg, err := netmail.ReadMessage(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("/v1/users verification mail is invalid: err = '%v'", err)
+	}
+	if msg.Header.Get("To") != credentials["email"] {
+		t.Errorf("/v1/users verification mail To = '%v', want = '%v'", msg.Header.Get("To"), credentials["email"])
+	}
+
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatalf("/v1/users verification mail body is invalid: err = '%v'", err)
+	}
+
+	match := regexp.MustCompile(`/verify-email\?token=([A-Za-z0-9_.-]+)`).FindSubmatch(body)
+	if match == nil {
+		t.Fatalf("/v1/users verification mail body = '%s', want a verification link", body)
+	}
+	token := string(match[1])
+
+	// Unverified users may log in under the limited access
+	req = newJsonRequest("POST", "/v1/users/login", credentials)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("/v1/users/login got = '%v', want = '%v'", w.Code, 200)
+	}
+	authCookie := findCookie(w.Result().Cookies(), jwtCookieName)
+	if authCookie == nil {
+		t.Fatalf("/v1/users/login response must have a '%s' cookie", jwtCookieName)
+	}
+
+	// They can view their tasks but not change them
+	req = newJsonRequest("GET", "/v1/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: authCookie.Name, Value: authCookie.Value})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("GET /v1/tasks unverified got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	req = newJsonRequest("POST", "/v1/tasks", map[string]string{"text": "Verify the email"})
+	req.AddCookie(&http.Cookie{Name: authCookie.Name, Value: authCookie.Value})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 403 {
+		t.Errorf("POST /v1/tasks unverified got = '%v', want = '%v'", w.Code, 403)
+	}
+
+	// Nor publish them through a webhook or a feed
+	req = newJsonRequest("POST", "/v1/webhooks", map[string]string{"url": "https://example.com/hook"})
+	req.AddCookie(&http.Cookie{Name: authCookie.Name, Value: authCookie.Value})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 403 {
+		t.Errorf("POST /v1/webhooks unverified got = '%v', want = '%v'", w.Code, 403)
+	}
+
+	req = newJsonRequest("POST", "/v1/users/current/feed", nil)
+	req.AddCookie(&http.Cookie{Name: authCookie.Name, Value: authCookie.Value})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 403 {
+		t.Errorf("POST /v1/users/current/feed unverified got = '%v', want = '%v'", w.Code, 403)
+	}
+
+	// Another link is not sent right after the first one
+	req = newJsonRequest("POST", "/v1/users/verification/resend", map[string]string{"email": credentials["email"]})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 429 {
+		t.Errorf("/v1/users/verification/resend got = '%v', want = '%v'", w.Code, 429)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("/v1/users/verification/resend response must have a Retry-After header")
+	}
+
+	// Unknown emails are not told apart
+	req = newJsonRequest("POST", "/v1/users/verification/resend", map[string]string{"email": "unknown@example.com"})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 202 {
+		t.Errorf("/v1/users/verification/resend for an unknown email got = '%v', want = '%v'", w.Code, 202)
+	}
+
+	// Verify the email
+	req = newJsonRequest("POST", "/v1/users/verification", map[string]string{"token": token + "x"})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Errorf("/v1/users/verification with an invalid token got = '%v', want = '%v'", w.Code, 400)
+	}
+
+	req = newJsonRequest("POST", "/v1/users/verification", map[string]string{"token": token})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("/v1/users/verification got = '%v', want = '%v'", w.Code, 200)
+	}
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("/v1/users/verification error = '%v'", err)
+	}
+	if !response.Verified {
+		t.Error("/v1/users/verification User.Verified = false")
+	}
+
+	// Verified users have full access
+	req = newJsonRequest("POST", "/v1/tasks", map[string]string{"text": "Verify the email"})
+	req.AddCookie(&http.Cookie{Name: authCookie.Name, Value: authCookie.Value})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("POST /v1/tasks verified got = '%v', want = '%v'", w.Code, 200)
+	}
+}
+
+func TestRepositoryEmailVerificationLogin(t *testing.T) {
+	router, _, _ := setNewRouterWithAccess(usecase.UnverifiedAccessNone)
+
+	credentials := map[string]string{"email": "verify-login@example.com", "password": "Password123"}
+
+	req := newJsonRequest("POST", "/v1/users", credentials)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 201 {
+		t.Fatalf("/v1/users got = '%v', want = '%v'", w.Code, 201)
+	}
+
+	// Unverified users may not log in
+	req = newJsonRequest("POST", "/v1/users/login", credentials)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 403 {
+		t.Errorf("/v1/users/login unverified got = '%v', want = '%v'", w.Code, 403)
+	}
+	if findCookie(w.Result().Cookies(), jwtCookieName) != nil {
+		t.Errorf("/v1/users/login unverified response must not have a '%s' cookie", jwtCookieName)
+	}
+
+	// A wrong password is reported as usual
+	req = newJsonRequest("POST", "/v1/users/login", map[string]string{"email": credentials["email"], "password": "Password456"})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 401 {
+		t.Errorf("/v1/users/login with a wrong password got = '%v', want = '%v'", w.Code, 401)
+	}
+
+	// Nor refresh a session started while they could
+	fullAccessRouter, _, _ := setNewRouter()
+
+	req = newJsonRequest("POST", "/v1/users/login", credentials)
+	w = httptest.NewRecorder()
+	fullAccessRouter.ServeHTTP(w, req)
+
+	refreshCookie := findCookie(w.Result().Cookies(), refreshCookieName)
+	if refreshCookie == nil {
+		t.Fatalf("/v1/users/login response must have a '%s' cookie", refreshCookieName)
+	}
+
+	req = newJsonRequest("POST", "/v1/users/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: refreshCookie.Name, Value: refreshCookie.Value})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 403 {
+		t.Errorf("/v1/users/refresh unverified got = '%v', want = '%v'", w.Code, 403)
+	}
+	if c := findCookie(w.Result().Cookies(), jwtCookieName); c != nil && c.Value != "" {
+		t.Errorf("/v1/users/refresh unverified response must not have a '%s' cookie", jwtCookieName)
+	}
+}
+
+func TestRepositoryLoginLockout(t *testing.T) {
+	router, _, _ := setNewRouterWithConfig(func(cfg *config.Config) {
+		cfg.LoginAccountLockoutThreshold = 3
+		cfg.LoginIPLockoutThreshold = 5
+		cfg.LoginLockoutDuration = 15
+		cfg.LoginAttemptWindow = 60
+	})
+
+	tryLogin := func(email, password, ip string) *httptest.ResponseRecorder {
+		req := newJsonRequest("POST", "/v1/users/login", map[string]string{"email": email, "password": password})
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	credentials := map[string]string{"email": "lockout@example.com", "password": "Password123"}
+
+	req := newJsonRequest("POST", "/v1/users", credentials)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 201 {
+		t.Fatalf("/v1/users got = '%v', want = '%v'", w.Code, 201)
+	}
+
+	// The failed attempts are counted alike whether the email is registered or not
+	for i := 0; i < 3; i++ {
+		if w := tryLogin(credentials["email"], "Password456", "198.51.100.1"); w.Code != 401 {
+			t.Fatalf("/v1/users/login with a wrong password got = '%v', want = '%v'", w.Code, 401)
+		}
+		if w := tryLogin("lockout-unknown@example.com", "Password456", "198.51.100.2"); w.Code != 401 {
+			t.Fatalf("/v1/users/login with an unknown email got = '%v', want = '%v'", w.Code, 401)
+		}
+	}
+
+	// The accounts are locked out from any IP address, even with the right password
+	known := tryLogin(credentials["email"], credentials["password"], "198.51.100.3")
+	unknown := tryLogin("lockout-unknown@example.com", credentials["password"], "198.51.100.3")
+
+	if known.Code != 429 {
+		t.Errorf("/v1/users/login locked out got = '%v', want = '%v'", known.Code, 429)
+	}
+	if known.Header().Get("Retry-After") == "" {
+		t.Error("/v1/users/login locked out response must have a Retry-After header")
+	}
+	if findCookie(known.Result().Cookies(), jwtCookieName) != nil {
+		t.Errorf("/v1/users/login locked out response must not have a '%s' cookie", jwtCookieName)
+	}
+	if unknown.Code != known.Code || unknown.Body.String() != known.Body.String() {
+		t.Errorf("/v1/users/login locked out unknown email got = '%v' %s, want = '%v' %s", unknown.Code, unknown.Body, known.Code, known.Body)
+	}
+
+	// An IP address is locked out whatever the emails tried from it
+	for i := 0; i < 5; i++ {
+		if w := tryLogin(fmt.Sprintf("lockout-%d@example.com", i), "Password456", "198.51.100.4"); w.Code != 401 {
+			t.Fatalf("/v1/users/login with an unknown email got = '%v', want = '%v'", w.Code, 401)
+		}
+	}
+
+	if w := tryLogin("lockout-other@example.com", "Password456", "198.51.100.4"); w.Code != 429 {
+		t.Errorf("/v1/users/login from a locked out IP address got = '%v', want = '%v'", w.Code, 429)
+	}
+	if w := tryLogin("lockout-other@example.com", "Password456", "198.51.100.5"); w.Code != 401 {
+		t.Errorf("/v1/users/login from another IP address got = '%v', want = '%v'", w.Code, 401)
+	}
+
+	// The current password of a logged in user is guarded alike
+	credentials = map[string]string{"email": "lockout-change@example.com", "password": "Password123"}
+
+	req = newJsonRequest("POST", "/v1/users", credentials)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 201 {
+		t.Fatalf("/v1/users got = '%v', want = '%v'", w.Code, 201)
+	}
+
+	authCookie := findCookie(tryLogin(credentials["email"], credentials["password"], "198.51.100.6").Result().Cookies(), jwtCookieName)
+	if authCookie == nil {
+		t.Fatalf("/v1/users/login response must have a '%s' cookie", jwtCookieName)
+	}
+
+	changePassword := func(password string) *httptest.ResponseRecorder {
+		req := newJsonRequest("PUT", "/v1/users/current/password", map[string]string{"password": password, "new_password": "Password789"})
+		req.RemoteAddr = "198.51.100.6:1234"
+		req.AddCookie(&http.Cookie{Name: authCookie.Name, Value: authCookie.Value})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	for i := 0; i < 3; i++ {
+		if w := changePassword("Password456"); w.Code != 403 {
+			t.Fatalf("PUT /v1/users/current/password with a wrong password got = '%v', want = '%v'", w.Code, 403)
+		}
+	}
+
+	if w := changePassword(credentials["password"]); w.Code != 429 {
+		t.Errorf("PUT /v1/users/current/password locked out got = '%v', want = '%v'", w.Code, 429)
+	}
+	if w := tryLogin(credentials["email"], credentials["password"], "198.51.100.7"); w.Code != 429 {
+		t.Errorf("/v1/users/login locked out by password changes got = '%v', want = '%v'", w.Code, 429)
+	}
+
+	// The lockouts are counted in the metrics
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	for _, metric := range []string{
+		`tododdd_login_lockouts_total{scope="account"}`,
+		`tododdd_login_lockouts_total{scope="ip"}`,
+		`tododdd_login_throttled_total`,
+	} {
+		if !bytes.Contains(w.Body.Bytes(), []byte(metric)) {
+			t.Errorf("/metrics must have '%s'", metric)
+		}
+	}
+}
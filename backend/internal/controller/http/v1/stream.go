@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/middleware"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/stream"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+	"github.com/ozaitsev92/tododdd/pkg/pubsub"
+)
+
+const (
+	lastEventIDHeader      = "Last-Event-ID"
+	defaultStreamHeartbeat = 15 * time.Second
+)
+
+type streamRoutes struct {
+	l          logger.Interface
+	jwtService *jwt.JWTService
+	u          *usecase.UserUseCase
+	hub        *stream.TaskHub
+	heartbeat  time.Duration
+}
+
+func newStreamRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase, hub *stream.TaskHub, heartbeat time.Duration) {
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+	r := &streamRoutes{l, jwtService, u, hub, heartbeat}
+
+	h := handler.Group("/tasks")
+	h.Use(middleware.JwtMiddleware(u, jwtService), middleware.VerifiedMiddleware(u))
+	{
+		h.GET("/stream", r.taskStream)
+	}
+}
+
+// taskStream streams the changes of the user's tasks as Server-Sent Events. A client reconnecting with
+// the Last-Event-ID header first gets the kept events it has missed.
+func (r *streamRoutes) taskStream(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	var lastEventID uint64
+	v := c.Request.Header.Values(lastEventIDHeader)
+	resume := len(v) > 0
+	if resume && v[0] != "" {
+		id, err := strconv.ParseUint(v[0], 10, 64)
+		if err != nil {
+			r.l.Error(err, "http - v1 - taskStream")
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid Last-Event-ID header"})
+
+			return
+		}
+		lastEventID = id
+	}
+
+	sub, missed, err := r.hub.Subscribe(uuid.MustParse(userID), lastEventID, resume)
+	if err != nil {
+		r.l.Error(err, "http - v1 - taskStream")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"Error": err.Error()})
+
+		return
+	}
+	defer sub.Close()
+
+	// The stream outlives the write timeout of the server.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, m := range missed {
+		if err := writeTaskEvent(c.Writer, m); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(r.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case m, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind or shutting down, the client reconnects with Last-Event-ID.
+				return
+			}
+			if err := writeTaskEvent(c.Writer, m); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeTaskEvent(w gin.ResponseWriter, m pubsub.Message[task.Event]) error {
+	data, err := json.Marshal(model.ToResponseFromTask(m.Data.Task))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", m.ID, m.Data.Type, data)
+
+	return err
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/middleware"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+)
+
+type tagRoutes struct {
+	l          logger.Interface
+	jwtService *jwt.JWTService
+	u          *usecase.UserUseCase
+	t          *usecase.TaskUseCase
+}
+
+func newTagRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase, t *usecase.TaskUseCase) {
+	r := &tagRoutes{l, jwtService, u, t}
+
+	h := handler.Group("/tags")
+	h.Use(middleware.JwtMiddleware(u, jwtService), middleware.VerifiedMiddleware(u))
+	{
+		h.GET("", r.index)
+		h.PUT("/:tag", r.renameTag)
+	}
+}
+
+func (r *tagRoutes) index(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	tags, err := r.t.GetTagsForUser(c.Request.Context(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - index")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromTagCollection(tags))
+}
+
+// renameTag renames the tag on all the user's personal tasks, merging it into the new one if it is already used.
+func (r *tagRoutes) renameTag(c *gin.Context) {
+	type renameTagRequest struct {
+		Tag string `json:"tag" binding:"required"`
+	}
+	var request renameTagRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - renameTag")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	n, err := r.t.RenameTag(c.Request.Context(), c.Param("tag"), request.Tag, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - renameTag")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": n})
+}
@@ -1,40 +1,161 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/middleware"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
 	"github.com/ozaitsev92/tododdd/internal/usecase"
 	"github.com/ozaitsev92/tododdd/pkg/logger"
 )
 
+const (
+	nextCursorHeader     = "X-Next-Cursor"
+	etagHeader           = "ETag"
+	ifMatchHeader        = "If-Match"
+	defaultDueSoonWindow = 24 * time.Hour
+	// defaultOccurrencesPreview is the number of occurrences previewed when n is not given.
+	defaultOccurrencesPreview = 5
+)
+
 type taskRoutes struct {
 	l          logger.Interface
 	jwtService *jwt.JWTService
 	u          *usecase.UserUseCase
 	t          *usecase.TaskUseCase
+	b          *usecase.TaskBatchUseCase
 }
 
 // todo: refactor. too many params
-func newTaskRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase, t *usecase.TaskUseCase) {
-	r := &taskRoutes{l, jwtService, u, t}
+func newTaskRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase, t *usecase.TaskUseCase, b *usecase.TaskBatchUseCase) {
+	r := &taskRoutes{l, jwtService, u, t, b}
 
 	h := handler.Group("/tasks")
-	h.Use(middleware.JwtMiddleware(u, jwtService))
+	h.Use(middleware.JwtMiddleware(u, jwtService), middleware.VerifiedMiddleware(u))
 	{
 		h.GET("", r.index)
+		h.GET("/search", r.searchTasks)
+		h.GET("/overdue", r.overdueTasks)
+		h.GET("/matrix", r.eisenhowerMatrix)
+		h.GET("/due-soon", r.dueSoonTasks)
+		h.GET("/trash", r.trashTasks)
 		h.POST("", r.createTask)
+		h.POST("/batch", r.batchTasks)
+		h.GET("/:id", r.getTask)
 		h.PUT("/:id", r.updateTask)
 		h.DELETE("/:id", r.deleteTask)
+		h.POST("/:id/restore", r.restoreTask)
 		h.PUT("/:id/mark-completed", r.markTaskCompleted)
 		h.PUT("/:id/mark-not-completed", r.markTaskNotCompleted)
+		h.PUT("/:id/due-date", r.setTaskDueDate)
+		h.PUT("/:id/recurrence", r.setTaskRecurrence)
+		h.GET("/:id/occurrences", r.taskOccurrences)
+		h.PUT("/:id/parent", r.setTaskParent)
+		h.PUT("/:id/move", r.moveTask)
+		h.PUT("/:id/tags", r.setTaskTags)
+		h.GET("/:id/progress", r.taskProgress)
+		h.GET("/:id/history", r.taskHistory)
+	}
+}
+
+// taskErrorStatus maps the errors of the task use case to HTTP status codes.
+func taskErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, task.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, task.ErrTaskNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrUnauthorizedAction):
+		return http.StatusForbidden
+	case errors.Is(err, task.ErrInvalidText), errors.Is(err, task.ErrInvalidMove), errors.Is(err, task.ErrInvalidPriority),
+		errors.Is(err, task.ErrInvalidParent), errors.Is(err, task.ErrInvalidTag), errors.Is(err, task.ErrTooManyTags),
+		errors.Is(err, task.ErrInvalidDueDate), errors.Is(err, task.ErrInvalidReminderOffset), errors.Is(err, task.ErrReminderWithoutDueDate),
+		errors.Is(err, task.ErrInvalidRecurrence), errors.Is(err, task.ErrRecurrenceWithoutDueDate), errors.Is(err, task.ErrInvalidListID),
+		errors.Is(err, usecase.ErrInvalidOccurrences), errors.Is(err, usecase.ErrInvalidBatchSize), errors.Is(err, usecase.ErrInvalidBatchOperation):
+		return http.StatusBadRequest
+	case errors.Is(err, task.ErrParentCycle), errors.Is(err, task.ErrMaxDepthExceeded), errors.Is(err, task.ErrTaskNotInTrash):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrBatchRolledBack):
+		return http.StatusFailedDependency
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// taskQueryErrorStatus maps the errors of finding the tasks to HTTP status codes.
+// Only an invalid query is the client's fault.
+func taskQueryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrUnauthorizedAction):
+		return http.StatusForbidden
+	case errors.Is(err, task.ErrInvalidSortField), errors.Is(err, task.ErrInvalidSortDirection),
+		errors.Is(err, task.ErrInvalidLimit), errors.Is(err, task.ErrInvalidCursor), errors.Is(err, task.ErrInvalidDateRange),
+		errors.Is(err, task.ErrInvalidTag), errors.Is(err, task.ErrInvalidTagMatch), errors.Is(err, task.ErrInvalidSearchText),
+		errors.Is(err, usecase.ErrInvalidDueWindow):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
 	}
 }
 
+// taskETag returns the entity tag of the task, its quoted version.
+func taskETag(t task.Task) string {
+	return strconv.Quote(strconv.FormatInt(t.Version, 10))
+}
+
+// ifMatchVersion returns the task version the If-Match header requires, task.AnyVersion for "*".
+// It reports false when the header is missing or is not an entity tag issued by taskETag.
+func ifMatchVersion(c *gin.Context) (int64, bool) {
+	header := strings.TrimSpace(c.GetHeader(ifMatchHeader))
+	if header == "*" {
+		return task.AnyVersion, true
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 0 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// priorityOptions returns the options setting the priority and the Eisenhower classification
+// given in a request. The importance and the urgency can only be given together.
+func priorityOptions(priority *string, important *bool, urgent *bool) ([]task.Option, error) {
+	var opts []task.Option
+	if priority != nil {
+		p, err := task.ParsePriority(*priority)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, task.WithPriority(p))
+	}
+
+	if (important == nil) != (urgent == nil) {
+		return nil, errors.New("important and urgent must be given together")
+	}
+
+	if important != nil {
+		opts = append(opts, task.WithEisenhower(task.Eisenhower{Important: *important, Urgent: *urgent}))
+	}
+
+	return opts, nil
+}
+
 func (r *taskRoutes) index(c *gin.Context) {
 	userID := c.GetString("userID")
 	if userID == "" {
@@ -43,9 +164,214 @@ func (r *taskRoutes) index(c *gin.Context) {
 		return
 	}
 
-	tasks, err := r.t.GetAllTasksForUser(c.Request.Context(), uuid.MustParse(userID))
+	q, err := parseTaskQuery(c)
 	if err != nil {
 		r.l.Error(err, "http - v1 - index")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	page, err := r.t.FindTasksForUser(c.Request.Context(), q, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - index")
+		c.AbortWithStatusJSON(taskQueryErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	if page.NextCursor != "" {
+		c.Header(nextCursorHeader, page.NextCursor)
+	}
+
+	if nested, _ := strconv.ParseBool(c.Query("nested")); nested {
+		c.JSON(http.StatusOK, model.ToResponseFromTaskTree(page.Tasks))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromTaskCollection(page.Tasks))
+}
+
+// parseTaskQuery builds a task query from the request query string:
+// list_id, completed, created_from, created_to, updated_from, updated_to (RFC 3339),
+// q, tags (comma separated), tags_match (any or all), sort, order, limit and cursor. The nested parameter is handled by index.
+func parseTaskQuery(c *gin.Context) (task.Query, error) {
+	q := task.NewQuery(uuid.Nil)
+
+	if v := c.Query("list_id"); v != "" {
+		listId, err := uuid.Parse(v)
+		if err != nil {
+			return task.Query{}, fmt.Errorf("list_id: %w", err)
+		}
+		q.ListID = &listId
+	}
+
+	if v := c.Query("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return task.Query{}, fmt.Errorf("completed: %w", err)
+		}
+		q.Completed = &completed
+	}
+
+	dates := map[string]*time.Time{
+		"created_from": &q.CreatedFrom,
+		"created_to":   &q.CreatedTo,
+		"updated_from": &q.UpdatedFrom,
+		"updated_to":   &q.UpdatedTo,
+	}
+	for name, dst := range dates {
+		if v := c.Query(name); v != "" {
+			d, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return task.Query{}, fmt.Errorf("%s: %w", name, err)
+			}
+			*dst = d
+		}
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return task.Query{}, fmt.Errorf("limit: %w", err)
+		}
+		q.Limit = limit
+	}
+
+	if v := c.Query("sort"); v != "" {
+		q.SortBy = task.SortField(v)
+	}
+
+	if v := c.Query("order"); v != "" {
+		q.SortDirection = task.SortDirection(v)
+	}
+
+	if v := c.Query("tags"); v != "" {
+		q.Tags = strings.Split(v, ",")
+	}
+
+	if v := c.Query("tags_match"); v != "" {
+		q.TagMatch = task.TagMatch(v)
+	}
+
+	q.Text = c.Query("q")
+	q.Cursor = c.Query("cursor")
+
+	return q, nil
+}
+
+func (r *taskRoutes) searchTasks(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	q := task.NewSearchQuery(uuid.Nil, c.Query("q"))
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			r.l.Error(err, "http - v1 - searchTasks")
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid limit parameter"})
+
+			return
+		}
+		q.Limit = limit
+	}
+
+	results, err := r.t.SearchTasksForUser(c.Request.Context(), q, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - searchTasks")
+		c.AbortWithStatusJSON(taskQueryErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromSearchResults(results))
+}
+
+func (r *taskRoutes) overdueTasks(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	tasks, err := r.t.GetOverdueTasksForUser(c.Request.Context(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - overdueTasks")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromTaskCollection(tasks))
+}
+
+func (r *taskRoutes) eisenhowerMatrix(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	groups, err := r.t.GetEisenhowerMatrixForUser(c.Request.Context(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - eisenhowerMatrix")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromMatrix(groups))
+}
+
+func (r *taskRoutes) dueSoonTasks(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	within := defaultDueSoonWindow
+	if v := c.Query("within"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			r.l.Error(err, "http - v1 - dueSoonTasks")
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid within parameter"})
+
+			return
+		}
+		within = d
+	}
+
+	tasks, err := r.t.GetDueSoonTasksForUser(c.Request.Context(), uuid.MustParse(userID), within)
+	if err != nil {
+		r.l.Error(err, "http - v1 - dueSoonTasks")
+		c.AbortWithStatusJSON(taskQueryErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromTaskCollection(tasks))
+}
+
+func (r *taskRoutes) trashTasks(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	tasks, err := r.t.GetTrashForUser(c.Request.Context(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - trashTasks")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
 
 		return
@@ -56,7 +382,16 @@ func (r *taskRoutes) index(c *gin.Context) {
 
 func (r *taskRoutes) createTask(c *gin.Context) {
 	type createTaskRequest struct {
-		Text string `json:"text" binding:"required"`
+		Text           string     `json:"text" binding:"required"`
+		ListID         *uuid.UUID `json:"list_id"`
+		ParentID       *uuid.UUID `json:"parent_id"`
+		DueAt          *time.Time `json:"due_at"`
+		ReminderOffset *int64     `json:"reminder_offset"`
+		Recurrence     *string    `json:"recurrence"`
+		Tags           []string   `json:"tags"`
+		Priority       *string    `json:"priority"`
+		Important      *bool      `json:"important"`
+		Urgent         *bool      `json:"urgent"`
 	}
 	var request createTaskRequest
 
@@ -74,20 +409,165 @@ func (r *taskRoutes) createTask(c *gin.Context) {
 		return
 	}
 
-	task, err := r.t.CreateTask(c.Request.Context(), request.Text, uuid.MustParse(userID))
+	var opts []task.Option
+	if request.ListID != nil {
+		opts = append(opts, task.WithList(*request.ListID))
+	}
+	if request.ParentID != nil {
+		opts = append(opts, task.WithParent(*request.ParentID))
+	}
+	if request.DueAt != nil {
+		opts = append(opts, task.WithDueDate(*request.DueAt))
+	}
+	if request.ReminderOffset != nil {
+		opts = append(opts, task.WithReminder(time.Duration(*request.ReminderOffset)*time.Second))
+	}
+	if request.Recurrence != nil {
+		recurrence, err := task.ParseRecurrence(*request.Recurrence)
+		if err != nil {
+			r.l.Error(err, "http - v1 - createTask")
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid recurrence"})
+
+			return
+		}
+		opts = append(opts, task.WithRecurrence(recurrence))
+	}
+	if len(request.Tags) > 0 {
+		opts = append(opts, task.WithTags(request.Tags...))
+	}
+
+	priorityOpts, err := priorityOptions(request.Priority, request.Important, request.Urgent)
+	if err != nil {
+		r.l.Error(err, "http - v1 - createTask")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+
+		return
+	}
+	opts = append(opts, priorityOpts...)
+
+	task, err := r.t.CreateTask(c.Request.Context(), request.Text, uuid.MustParse(userID), opts...)
 	if err != nil {
 		r.l.Error(err, "http - v1 - createTask")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
 
 		return
 	}
 
+	c.Header(etagHeader, taskETag(task))
 	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
 }
 
+func (r *taskRoutes) getTask(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	task, err := r.t.GetTask(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - getTask")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.Header(etagHeader, taskETag(task))
+	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
+}
+
+func (r *taskRoutes) batchTasks(c *gin.Context) {
+	type batchOperationRequest struct {
+		Op       string     `json:"op" binding:"required"`
+		ID       *uuid.UUID `json:"id"`
+		Text     string     `json:"text"`
+		Version  *int64     `json:"version"`
+		ListID   *uuid.UUID `json:"list_id"`
+		ParentID *uuid.UUID `json:"parent_id"`
+	}
+	type batchTasksRequest struct {
+		Atomic     bool                    `json:"atomic"`
+		Operations []batchOperationRequest `json:"operations" binding:"required,dive"`
+	}
+	var request batchTasksRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - batchTasks")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	items := make([]usecase.BatchItem, 0, len(request.Operations))
+	for _, op := range request.Operations {
+		item := usecase.BatchItem{
+			Operation: usecase.BatchOperation(op.Op),
+			Text:      op.Text,
+			Version:   task.AnyVersion,
+		}
+
+		if item.Operation == usecase.BatchCreate {
+			if op.ListID != nil {
+				item.Options = append(item.Options, task.WithList(*op.ListID))
+			}
+			if op.ParentID != nil {
+				item.Options = append(item.Options, task.WithParent(*op.ParentID))
+			}
+		} else if op.ID == nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Operation id is required"})
+
+			return
+		} else {
+			item.TaskID = *op.ID
+		}
+
+		if op.Version != nil {
+			item.Version = *op.Version
+		}
+
+		items = append(items, item)
+	}
+
+	results, err := r.b.ApplyBatch(c.Request.Context(), items, request.Atomic, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - batchTasks")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	response := make([]model.BatchResult, 0, len(results))
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			response = append(response, model.BatchResult{Status: taskErrorStatus(result.Err), Error: result.Err.Error()})
+		case result.Task == nil:
+			response = append(response, model.BatchResult{Status: http.StatusOK})
+		default:
+			t := model.ToResponseFromTask(*result.Task)
+			response = append(response, model.BatchResult{Status: http.StatusOK, Task: &t})
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func (r *taskRoutes) updateTask(c *gin.Context) {
 	type updateTaskRequest struct {
-		Text string `json:"text" binding:"required"`
+		Text      string  `json:"text" binding:"required"`
+		Priority  *string `json:"priority"`
+		Important *bool   `json:"important"`
+		Urgent    *bool   `json:"urgent"`
 	}
 	var request updateTaskRequest
 
@@ -98,6 +578,13 @@ func (r *taskRoutes) updateTask(c *gin.Context) {
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"Error": "If-Match header is required"})
+
+		return
+	}
+
 	if err := c.ShouldBindJSON(&request); err != nil {
 		r.l.Error(err, "http - v1 - updateTask")
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
@@ -105,16 +592,25 @@ func (r *taskRoutes) updateTask(c *gin.Context) {
 		return
 	}
 
+	opts, err := priorityOptions(request.Priority, request.Important, request.Urgent)
+	if err != nil {
+		r.l.Error(err, "http - v1 - updateTask")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+
+		return
+	}
+
 	id := c.Param("id")
 
-	task, err := r.t.UpdateTask(c.Request.Context(), uuid.MustParse(id), request.Text, uuid.MustParse(userID))
+	task, err := r.t.UpdateTask(c.Request.Context(), uuid.MustParse(id), version, request.Text, uuid.MustParse(userID), opts...)
 	if err != nil {
 		r.l.Error(err, "http - v1 - updateTask")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
 
 		return
 	}
 
+	c.Header(etagHeader, taskETag(task))
 	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
 }
 
@@ -126,12 +622,30 @@ func (r *taskRoutes) deleteTask(c *gin.Context) {
 		return
 	}
 
+	policy := usecase.DeleteSubtasks
+	switch c.DefaultQuery("subtasks", "delete") {
+	case "delete":
+	case "reparent":
+		policy = usecase.ReparentSubtasks
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid subtasks parameter"})
+
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"Error": "If-Match header is required"})
+
+		return
+	}
+
 	id := c.Param("id")
 
-	err := r.t.DeleteTask(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(userID))
+	err := r.t.DeleteTask(c.Request.Context(), uuid.MustParse(id), version, uuid.MustParse(userID), policy)
 	if err != nil {
 		r.l.Error(err, "http - v1 - deleteTask")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
 
 		return
 	}
@@ -139,6 +653,28 @@ func (r *taskRoutes) deleteTask(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{})
 }
 
+func (r *taskRoutes) restoreTask(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	task, err := r.t.RestoreTask(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - restoreTask")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.Header(etagHeader, taskETag(task))
+	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
+}
+
 func (r *taskRoutes) markTaskCompleted(c *gin.Context) {
 	userID := c.GetString("userID")
 	if userID == "" {
@@ -152,11 +688,12 @@ func (r *taskRoutes) markTaskCompleted(c *gin.Context) {
 	task, err := r.t.MarkTaskCompleted(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(userID))
 	if err != nil {
 		r.l.Error(err, "http - v1 - markTaskCompleted")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
 
 		return
 	}
 
+	c.Header(etagHeader, taskETag(task))
 	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
 }
 
@@ -173,10 +710,274 @@ func (r *taskRoutes) markTaskNotCompleted(c *gin.Context) {
 	task, err := r.t.MarkTaskNotCompleted(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(userID))
 	if err != nil {
 		r.l.Error(err, "http - v1 - markTaskNotCompleted")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.Header(etagHeader, taskETag(task))
+	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
+}
+
+func (r *taskRoutes) setTaskDueDate(c *gin.Context) {
+	type setTaskDueDateRequest struct {
+		DueAt          *time.Time `json:"due_at"`
+		ReminderOffset *int64     `json:"reminder_offset"`
+	}
+	var request setTaskDueDateRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - setTaskDueDate")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	var reminderOffset *time.Duration
+	if request.ReminderOffset != nil {
+		d := time.Duration(*request.ReminderOffset) * time.Second
+		reminderOffset = &d
+	}
+
+	id := c.Param("id")
+
+	task, err := r.t.SetTaskDueDate(c.Request.Context(), uuid.MustParse(id), request.DueAt, reminderOffset, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - setTaskDueDate")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
 
 		return
 	}
 
+	c.Header(etagHeader, taskETag(task))
 	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
 }
+
+func (r *taskRoutes) setTaskRecurrence(c *gin.Context) {
+	type setTaskRecurrenceRequest struct {
+		Recurrence *string `json:"recurrence"`
+	}
+	var request setTaskRecurrenceRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - setTaskRecurrence")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	var recurrence *task.Recurrence
+	if request.Recurrence != nil {
+		rule, err := task.ParseRecurrence(*request.Recurrence)
+		if err != nil {
+			r.l.Error(err, "http - v1 - setTaskRecurrence")
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid recurrence"})
+
+			return
+		}
+		recurrence = &rule
+	}
+
+	id := c.Param("id")
+
+	task, err := r.t.SetTaskRecurrence(c.Request.Context(), uuid.MustParse(id), recurrence, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - setTaskRecurrence")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.Header(etagHeader, taskETag(task))
+	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
+}
+
+func (r *taskRoutes) taskOccurrences(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	n, err := strconv.Atoi(c.DefaultQuery("n", strconv.Itoa(defaultOccurrencesPreview)))
+	if err != nil {
+		r.l.Error(err, "http - v1 - taskOccurrences")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid n parameter"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	occurrences, err := r.t.PreviewTaskOccurrences(c.Request.Context(), uuid.MustParse(id), n, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - taskOccurrences")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.Occurrences{DueAt: occurrences})
+}
+
+func (r *taskRoutes) setTaskParent(c *gin.Context) {
+	type setTaskParentRequest struct {
+		ParentID *uuid.UUID `json:"parent_id"`
+	}
+	var request setTaskParentRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - setTaskParent")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	task, err := r.t.SetTaskParent(c.Request.Context(), uuid.MustParse(id), request.ParentID, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - setTaskParent")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.Header(etagHeader, taskETag(task))
+	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
+}
+
+// moveTask moves the task right after the "after" task or right before the "before" task
+// of the same listing in the manual order.
+func (r *taskRoutes) moveTask(c *gin.Context) {
+	type moveTaskRequest struct {
+		After  *uuid.UUID `json:"after"`
+		Before *uuid.UUID `json:"before"`
+	}
+	var request moveTaskRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - moveTask")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	task, err := r.t.MoveTask(c.Request.Context(), uuid.MustParse(id), request.After, request.Before, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - moveTask")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.Header(etagHeader, taskETag(task))
+	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
+}
+
+func (r *taskRoutes) setTaskTags(c *gin.Context) {
+	type setTaskTagsRequest struct {
+		Tags []string `json:"tags"`
+	}
+	var request setTaskTagsRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - setTaskTags")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	task, err := r.t.SetTaskTags(c.Request.Context(), uuid.MustParse(id), request.Tags, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - setTaskTags")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.Header(etagHeader, taskETag(task))
+	c.JSON(http.StatusOK, model.ToResponseFromTask(task))
+}
+
+func (r *taskRoutes) taskProgress(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	progress, err := r.t.GetTaskProgress(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - taskProgress")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromProgress(progress))
+}
+
+func (r *taskRoutes) taskHistory(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id := c.Param("id")
+
+	events, err := r.t.GetTaskHistory(c.Request.Context(), uuid.MustParse(id), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - taskHistory")
+		c.AbortWithStatusJSON(taskErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromHistory(events))
+}
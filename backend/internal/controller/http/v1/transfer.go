@@ -0,0 +1,568 @@
+package v1
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/middleware"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+)
+
+const (
+	formatJSON     = "json"
+	formatCSV      = "csv"
+	formatMarkdown = "md"
+	// maxImportBodySize is the largest imported file in bytes.
+	maxImportBodySize = 4 << 20
+)
+
+// transferFormats are the content types of the formats the tasks are exported and imported in.
+var transferFormats = map[string]string{
+	formatJSON:     "application/json; charset=utf-8",
+	formatCSV:      "text/csv; charset=utf-8",
+	formatMarkdown: "text/markdown; charset=utf-8",
+}
+
+// csvColumns are the columns of a CSV export. An import reads the columns named in its header row,
+// only text is required.
+var csvColumns = []string{
+	"id", "text", "completed", "user_id", "list_id", "parent_id", "due_at", "reminder_offset", "recurrence",
+	"tags", "priority", "important", "urgent", "position", "version", "created_at", "updated_at",
+}
+
+// checklistItem matches an item of a Markdown checklist, capturing its indentation, its mark and its text.
+var checklistItem = regexp.MustCompile(`^(\s*)[-*+] \[([ xX])\] (.*)$`)
+
+type transferRoutes struct {
+	l          logger.Interface
+	jwtService *jwt.JWTService
+	u          *usecase.UserUseCase
+	t          *usecase.TaskUseCase
+	im         *usecase.TaskImportUseCase
+}
+
+func newTransferRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase, t *usecase.TaskUseCase, im *usecase.TaskImportUseCase) {
+	r := &transferRoutes{l, jwtService, u, t, im}
+
+	h := handler.Group("/tasks")
+	h.Use(middleware.JwtMiddleware(u, jwtService), middleware.VerifiedMiddleware(u))
+	{
+		h.GET("/export", r.exportTasks)
+		h.POST("/import", r.importTasks)
+	}
+}
+
+// importErrorStatus maps the errors of the import use case to HTTP status codes.
+func importErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidImportSize), errors.Is(err, usecase.ErrInvalidDuplicatePolicy):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// exportTasks streams all tasks of the user in the format given by the format parameter, json by default.
+// JSON and CSV carry every attribute of the tasks, Markdown is a checklist with the subtasks indented under their parents.
+func (r *transferRoutes) exportTasks(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	format := c.DefaultQuery("format", formatJSON)
+	contentType, ok := transferFormats[format]
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid format parameter"})
+
+		return
+	}
+
+	tasks, err := r.t.GetAllTasksForUser(c.Request.Context(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - exportTasks")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="tasks.%s"`, format))
+	c.Status(http.StatusOK)
+
+	switch format {
+	case formatJSON:
+		err = writeJSONTasks(c.Writer, tasks)
+	case formatCSV:
+		err = writeCSVTasks(c.Writer, tasks)
+	case formatMarkdown:
+		err = writeChecklist(c.Writer, model.ToResponseFromTaskTree(tasks), 0)
+	}
+	if err != nil {
+		// The response has been started, the client is left with a truncated file.
+		r.l.Error(err, "http - v1 - exportTasks")
+	}
+}
+
+// importTasks imports the tasks of the file in the request body, in the format given by the format parameter,
+// json by default. The duplicates parameter, skip by default or update, tells what to do with a row having
+// the id of an existing task. The file is imported row by row and every row is reported on.
+func (r *transferRoutes) importTasks(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	format := c.DefaultQuery("format", formatJSON)
+	if _, ok := transferFormats[format]; !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid format parameter"})
+
+		return
+	}
+
+	duplicates := usecase.DuplicatePolicy(c.DefaultQuery("duplicates", string(usecase.SkipDuplicates)))
+
+	var (
+		records []importRecord
+		err     error
+	)
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodySize)
+	switch format {
+	case formatJSON:
+		records, err = readJSONRecords(body)
+	case formatCSV:
+		records, err = readCSVRecords(body)
+	case formatMarkdown:
+		records, err = readChecklistRecords(body)
+	}
+	if err != nil {
+		r.l.Error(err, "http - v1 - importTasks")
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"Error": err.Error()})
+
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid file: " + err.Error()})
+
+		return
+	}
+
+	rows := make([]usecase.ImportRow, 0, len(records))
+	for _, record := range records {
+		row, err := toImportRow(record.task)
+		if record.err != nil {
+			err = record.err
+		}
+		row.Err = err
+
+		rows = append(rows, row)
+	}
+
+	results, err := r.im.ImportTasks(c.Request.Context(), rows, duplicates, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - importTasks")
+		c.AbortWithStatusJSON(importErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	report := model.ImportReport{Rows: make([]model.ImportResult, 0, len(results))}
+	for i, result := range results {
+		item := model.ImportResult{Row: i + 1, Status: string(result.Status)}
+		if result.Task != nil {
+			t := model.ToResponseFromTask(*result.Task)
+			item.Task = &t
+		}
+		if result.Err != nil {
+			item.Error = result.Err.Error()
+		}
+
+		switch result.Status {
+		case usecase.ImportCreated:
+			report.Created++
+		case usecase.ImportUpdated:
+			report.Updated++
+		case usecase.ImportUnchanged:
+			report.Unchanged++
+		case usecase.ImportSkipped:
+			report.Skipped++
+		case usecase.ImportFailed:
+			report.Failed++
+		}
+
+		report.Rows = append(report.Rows, item)
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// writeJSONTasks writes the tasks as a JSON array, one task at a time.
+func writeJSONTasks(w io.Writer, tasks []task.Task) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for i, t := range tasks {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+
+		if err := enc.Encode(model.ToResponseFromTask(t)); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "]\n")
+
+	return err
+}
+
+// writeCSVTasks writes the tasks as CSV with a header row naming the csvColumns.
+func writeCSVTasks(w io.Writer, tasks []task.Task) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		if err := cw.Write(toCSVRecord(model.ToResponseFromTask(t))); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// writeChecklist writes the tasks as a Markdown checklist, indenting the subtasks by two spaces per level.
+func writeChecklist(w io.Writer, nodes []model.TaskNode, depth int) error {
+	for _, n := range nodes {
+		mark := " "
+		if n.Completed {
+			mark = "x"
+		}
+
+		// An item is a single line.
+		text := strings.Join(strings.Fields(n.Text), " ")
+		if _, err := fmt.Fprintf(w, "%s- [%s] %s\n", strings.Repeat("  ", depth), mark, text); err != nil {
+			return err
+		}
+
+		if err := writeChecklist(w, n.Subtasks, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toCSVRecord(t model.Task) []string {
+	values := map[string]string{
+		"id":              t.ID,
+		"text":            t.Text,
+		"completed":       strconv.FormatBool(t.Completed),
+		"user_id":         t.UserID,
+		"list_id":         formatOptional(t.ListID, func(v string) string { return v }),
+		"parent_id":       formatOptional(t.ParentID, func(v string) string { return v }),
+		"due_at":          formatOptional(t.DueAt, formatTime),
+		"reminder_offset": formatOptional(t.ReminderOffset, func(v int64) string { return strconv.FormatInt(v, 10) }),
+		"recurrence":      formatOptional(t.Recurrence, func(v string) string { return v }),
+		"tags":            strings.Join(t.Tags, " "),
+		"priority":        t.Priority,
+		"important":       formatOptional(t.Important, strconv.FormatBool),
+		"urgent":          formatOptional(t.Urgent, strconv.FormatBool),
+		"position":        strconv.FormatFloat(t.Position, 'g', -1, 64),
+		"version":         strconv.FormatInt(t.Version, 10),
+		"created_at":      formatTime(t.CreatedAt),
+		"updated_at":      formatTime(t.UpdatedAt),
+	}
+
+	record := make([]string, 0, len(csvColumns))
+	for _, column := range csvColumns {
+		record = append(record, values[column])
+	}
+
+	return record
+}
+
+// formatOptional formats the value, an empty string stands for nil.
+func formatOptional[T any](v *T, format func(T) string) string {
+	if v == nil {
+		return ""
+	}
+
+	return format(*v)
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// importRecord is a task read from an imported file, err is set when its row cannot be read.
+type importRecord struct {
+	task model.Task
+	err  error
+}
+
+// readJSONRecords reads a JSON array of tasks as they are exported.
+func readJSONRecords(r io.Reader) ([]importRecord, error) {
+	var tasks []model.Task
+	if err := json.NewDecoder(r).Decode(&tasks); err != nil {
+		return nil, err
+	}
+
+	records := make([]importRecord, 0, len(tasks))
+	for _, t := range tasks {
+		records = append(records, importRecord{task: t})
+	}
+
+	return records, nil
+}
+
+// readCSVRecords reads CSV with a header row naming the columns, see csvColumns.
+func readCSVRecords(r io.Reader) ([]importRecord, error) {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	if _, ok := columns["text"]; !ok {
+		return nil, errors.New("the text column is missing")
+	}
+
+	var records []importRecord
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, err
+		}
+		if err != nil {
+			records = append(records, importRecord{err: err})
+			continue
+		}
+
+		t, err := fromCSVRecord(columns, record)
+		records = append(records, importRecord{task: t, err: err})
+	}
+
+	return records, nil
+}
+
+func fromCSVRecord(columns map[string]int, record []string) (model.Task, error) {
+	value := func(column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	t := model.Task{
+		ID:       value("id"),
+		Text:     value("text"),
+		Priority: value("priority"),
+		Tags:     strings.Fields(value("tags")),
+	}
+
+	if v := value("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return model.Task{}, fmt.Errorf("completed: %w", err)
+		}
+		t.Completed = completed
+	}
+
+	if v := value("list_id"); v != "" {
+		t.ListID = &v
+	}
+
+	if v := value("parent_id"); v != "" {
+		t.ParentID = &v
+	}
+
+	if v := value("due_at"); v != "" {
+		dueAt, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return model.Task{}, fmt.Errorf("due_at: %w", err)
+		}
+		t.DueAt = &dueAt
+	}
+
+	if v := value("reminder_offset"); v != "" {
+		offset, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return model.Task{}, fmt.Errorf("reminder_offset: %w", err)
+		}
+		t.ReminderOffset = &offset
+	}
+
+	if v := value("recurrence"); v != "" {
+		t.Recurrence = &v
+	}
+
+	flags := map[string]**bool{
+		"important": &t.Important,
+		"urgent":    &t.Urgent,
+	}
+	for name, dst := range flags {
+		if v := value(name); v != "" {
+			flag, err := strconv.ParseBool(v)
+			if err != nil {
+				return model.Task{}, fmt.Errorf("%s: %w", name, err)
+			}
+			*dst = &flag
+		}
+	}
+
+	return t, nil
+}
+
+// readChecklistRecords reads the items of a Markdown checklist, other lines are ignored.
+// An item indented deeper than the one before it becomes its subtask.
+func readChecklistRecords(r io.Reader) ([]importRecord, error) {
+	type level struct {
+		indent int
+		id     string
+	}
+	var parents []level
+
+	var records []importRecord
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m := checklistItem.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+
+		indent := len(strings.ReplaceAll(m[1], "\t", "    "))
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+
+		// The items have no ids, new ones link the subtasks to their parents.
+		t := model.Task{
+			ID:        uuid.NewString(),
+			Text:      strings.TrimSpace(m[3]),
+			Completed: m[2] != " ",
+		}
+		if len(parents) > 0 {
+			parentId := parents[len(parents)-1].id
+			t.ParentID = &parentId
+		}
+
+		parents = append(parents, level{indent: indent, id: t.ID})
+		records = append(records, importRecord{task: t})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// toImportRow reads the attributes of the task, a failure is reported for its row only.
+func toImportRow(t model.Task) (usecase.ImportRow, error) {
+	row := usecase.ImportRow{
+		Text:      t.Text,
+		Completed: t.Completed,
+	}
+
+	if t.ID != "" {
+		id, err := uuid.Parse(t.ID)
+		if err != nil {
+			return usecase.ImportRow{}, fmt.Errorf("id: %w", err)
+		}
+		row.ID = id
+	}
+
+	if t.ListID != nil {
+		listId, err := uuid.Parse(*t.ListID)
+		if err != nil {
+			return usecase.ImportRow{}, fmt.Errorf("list_id: %w", err)
+		}
+		row.Options = append(row.Options, task.WithList(listId))
+	}
+
+	if t.ParentID != nil {
+		parentId, err := uuid.Parse(*t.ParentID)
+		if err != nil {
+			return usecase.ImportRow{}, fmt.Errorf("parent_id: %w", err)
+		}
+		row.Options = append(row.Options, task.WithParent(parentId))
+	}
+
+	if t.DueAt != nil {
+		row.Options = append(row.Options, task.WithDueDate(*t.DueAt))
+	}
+
+	if t.ReminderOffset != nil {
+		row.Options = append(row.Options, task.WithReminder(time.Duration(*t.ReminderOffset)*time.Second))
+	}
+
+	if t.Recurrence != nil {
+		recurrence, err := task.ParseRecurrence(*t.Recurrence)
+		if err != nil {
+			return usecase.ImportRow{}, fmt.Errorf("recurrence: %w", err)
+		}
+		row.Options = append(row.Options, task.WithRecurrence(recurrence))
+	}
+
+	if len(t.Tags) > 0 {
+		row.Options = append(row.Options, task.WithTags(t.Tags...))
+	}
+
+	var priority *string
+	if t.Priority != "" {
+		priority = &t.Priority
+	}
+
+	priorityOpts, err := priorityOptions(priority, t.Important, t.Urgent)
+	if err != nil {
+		return usecase.ImportRow{}, err
+	}
+	row.Options = append(row.Options, priorityOpts...)
+
+	return row, nil
+}
@@ -1,13 +1,19 @@
 package v1
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/middleware"
 	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+	"github.com/ozaitsev92/tododdd/internal/domain/user"
 	"github.com/ozaitsev92/tododdd/internal/usecase"
 	"github.com/ozaitsev92/tododdd/pkg/logger"
 )
@@ -16,11 +22,14 @@ type userRoutes struct {
 	l          logger.Interface
 	jwtService *jwt.JWTService
 	u          *usecase.UserUseCase
+	s          *usecase.SessionUseCase
+	v          *usecase.EmailVerificationUseCase
+	g          *usecase.LoginGuardUseCase
 }
 
 // todo: refactor. too many params
-func newUserRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase) {
-	r := &userRoutes{l, jwtService, u}
+func newUserRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase, s *usecase.SessionUseCase, v *usecase.EmailVerificationUseCase, g *usecase.LoginGuardUseCase) {
+	r := &userRoutes{l, jwtService, u, s, v, g}
 
 	h := handler.Group("/users")
 	{
@@ -28,11 +37,33 @@ func newUserRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt
 
 		h.POST("", r.createUser)
 		h.POST("/login", r.loginUser)
+		h.POST("/refresh", r.refreshUser)
 		h.POST("/logout", r.logoutUser)
 		h.GET("/current", jwtMiddleware, r.currentUser)
+		h.PUT("/current/email", jwtMiddleware, r.changeEmail)
+		h.PUT("/current/password", jwtMiddleware, r.changePassword)
+		h.DELETE("/current", jwtMiddleware, r.deleteUser)
 	}
 }
 
+// userErrorStatus returns the status code of the response to a failed change of the current user.
+func userErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, user.ErrUserNotFound):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrWrongPassword):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrEmailAlreadyInUse):
+		return http.StatusConflict
+	case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, user.ErrInvalidPassword):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// createUser registers a new user and mails them a link to verify their email. The user is registered
+// even if the mail fails, they can ask for another one.
 func (r *userRoutes) createUser(c *gin.Context) {
 	type createUserRequest struct {
 		Email    string `json:"email" binding:"required"`
@@ -55,6 +86,11 @@ func (r *userRoutes) createUser(c *gin.Context) {
 		return
 	}
 
+	err = r.v.SendVerification(c.Request.Context(), u)
+	if err != nil {
+		r.l.Error(err, "http - v1 - createUser")
+	}
+
 	c.JSON(http.StatusCreated, model.ToResponseFromUser(u))
 }
 
@@ -72,46 +108,230 @@ func (r *userRoutes) loginUser(c *gin.Context) {
 		return
 	}
 
-	u, err := r.u.GetUserByEmail(c.Request.Context(), request.Email)
+	ip := c.ClientIP()
+
+	// The attempt is counted as failed before the password is checked
+	attempt, wait, err := r.g.StartLogin(c.Request.Context(), request.Email, ip)
 	if err != nil {
 		r.l.Error(err, "http - v1 - loginUser")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Invalid login and/or password"})
+		if errors.Is(err, usecase.ErrLoginThrottled) {
+			loginThrottled.Inc()
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"Error": err.Error()})
+
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": "Internal server error"})
 
 		return
 	}
 
-	if !u.ComparePassword(request.Password) {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Invalid login and/or password"})
+	u, err := r.u.Authenticate(c.Request.Context(), request.Email, request.Password)
+	if err != nil {
+		r.l.Error(err, "http - v1 - loginUser")
+		if errors.Is(err, usecase.ErrInvalidCredentials) {
+			loginFailures.Inc()
+			r.loginFailed(attempt, ip, "http - v1 - loginUser")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Invalid login and/or password"})
+
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": "Internal server error"})
 
 		return
 	}
 
-	token, err := r.jwtService.CreateJWTTokenForUser(u.ID)
+	err = r.g.LoginSucceeded(c.Request.Context(), attempt)
+	if err != nil {
+		r.l.Error(err, "http - v1 - loginUser")
+	}
+
+	err = r.u.AuthorizeLogin(u)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	err = r.setSessionCookies(c, u.ID)
 	if err != nil {
 		r.l.Error(err, "http - v1 - loginUser")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": "Internal server error"})
 
 		return
 	}
-	// todo: add a refresh token
-	// https://medium.com/novai-go-programming-101/building-a-jwt-authentication-system-with-refresh-tokens-in-go-adce3b30c1ac
-	cookie := r.jwtService.AuthCookie(token)
 
-	c.SetCookie(
-		cookie.Name,
-		cookie.Value,
-		cookie.MaxAge,
-		cookie.Path,
-		cookie.Domain,
-		cookie.Secure,
-		cookie.HttpOnly,
-	)
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// loginFailed reports the lockouts the failed login attempt has started.
+func (r *userRoutes) loginFailed(attempt usecase.LoginAttempt, ip string, op string) {
+	for _, lockout := range r.g.LoginFailed(attempt) {
+		loginLockouts.WithLabelValues(string(lockout.Scope)).Inc()
+		r.l.Warn(
+			op+" - %s locked out after %d failed login attempts until %s, ip %s",
+			lockout.Scope, lockout.Failures, lockout.Until.Format(time.RFC3339), ip,
+		)
+	}
+}
+
+// guardPassword makes the change of the current user which requires their password, counted by the LoginGuard
+// as an attempt to log in with their email like loginUser, so the password can not be guessed through it.
+// It responds and returns false if the change has failed or the attempt is throttled.
+func (r *userRoutes) guardPassword(c *gin.Context, op string, change func() error) bool {
+	current, ok := c.Get("user")
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return false
+	}
+
+	ip := c.ClientIP()
+
+	// The attempt is counted as failed before the password is checked
+	attempt, wait, err := r.g.StartLogin(c.Request.Context(), current.(user.User).Email, ip)
+	if err != nil {
+		r.l.Error(err, op)
+		if errors.Is(err, usecase.ErrLoginThrottled) {
+			loginThrottled.Inc()
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"Error": err.Error()})
+
+			return false
+		}
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": "Internal server error"})
+
+		return false
+	}
+
+	err = change()
+	switch {
+	case errors.Is(err, usecase.ErrWrongPassword):
+		loginFailures.Inc()
+		r.loginFailed(attempt, ip, op)
+	case passwordChecked(err):
+		if err := r.g.LoginSucceeded(c.Request.Context(), attempt); err != nil {
+			r.l.Error(err, op)
+		}
+	}
+
+	if err != nil {
+		r.l.Error(err, op)
+		c.AbortWithStatusJSON(userErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return false
+	}
+
+	return true
+}
+
+// passwordChecked returns true if the change of the current user has succeeded, or has failed with the error,
+// once their password has been found right.
+func passwordChecked(err error) bool {
+	return err == nil ||
+		errors.Is(err, user.ErrInvalidEmail) ||
+		errors.Is(err, user.ErrInvalidPassword) ||
+		errors.Is(err, usecase.ErrEmailAlreadyInUse)
+}
+
+func (r *userRoutes) refreshUser(c *gin.Context) {
+	refreshToken, err := r.jwtService.GetRefreshTokenFromRequest(c.Request)
+	if err != nil {
+		r.clearSessionCookies(c)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	refreshToken, t, err := r.s.RefreshSession(c.Request.Context(), refreshToken)
+	if err != nil {
+		if errors.Is(err, session.ErrRefreshTokenReused) {
+			r.l.Warn("http - v1 - refreshUser - refresh token reuse detected, session revoked")
+		} else {
+			r.l.Error(err, "http - v1 - refreshUser")
+		}
+		r.clearSessionCookies(c)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	// The user may no longer be allowed to log in, the session is ended then like a new one would be refused
+	u, err := r.u.GetUserByID(c.Request.Context(), t.UserID)
+	if err == nil {
+		err = r.u.AuthorizeLogin(u)
+	}
+	if err != nil {
+		r.l.Error(err, "http - v1 - refreshUser")
+		if err := r.s.EndSession(c.Request.Context(), refreshToken); err != nil {
+			r.l.Error(err, "http - v1 - refreshUser")
+		}
+		r.clearSessionCookies(c)
+
+		switch {
+		case errors.Is(err, usecase.ErrEmailNotVerified):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"Error": err.Error()})
+		case errors.Is(err, user.ErrUserNotFound):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": "Internal server error"})
+		}
+
+		return
+	}
+
+	token, err := r.jwtService.CreateJWTTokenForUser(u.ID)
+	if err != nil {
+		r.l.Error(err, "http - v1 - refreshUser")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": "Internal server error"})
+
+		return
+	}
+
+	setCookie(c, r.jwtService.AuthCookie(token))
+	setCookie(c, r.jwtService.RefreshCookie(refreshToken, t.ExpiresAt))
 	c.JSON(http.StatusOK, gin.H{})
 }
 
 func (r *userRoutes) logoutUser(c *gin.Context) {
-	cookie := r.jwtService.ExpiredAuthCookie()
+	if refreshToken, err := r.jwtService.GetRefreshTokenFromRequest(c.Request); err == nil {
+		err = r.s.EndSession(c.Request.Context(), refreshToken)
+		if err != nil {
+			r.l.Error(err, "http - v1 - logoutUser")
+		}
+	}
+
+	r.clearSessionCookies(c)
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// setSessionCookies starts a new session for the user and sets the access and refresh token cookies.
+func (r *userRoutes) setSessionCookies(c *gin.Context, userId uuid.UUID) error {
+	token, err := r.jwtService.CreateJWTTokenForUser(userId)
+	if err != nil {
+		return err
+	}
+
+	refreshToken, t, err := r.s.StartSession(c.Request.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	setCookie(c, r.jwtService.AuthCookie(token))
+	setCookie(c, r.jwtService.RefreshCookie(refreshToken, t.ExpiresAt))
 
+	return nil
+}
+
+func (r *userRoutes) clearSessionCookies(c *gin.Context) {
+	setCookie(c, r.jwtService.ExpiredAuthCookie())
+	setCookie(c, r.jwtService.ExpiredRefreshCookie())
+}
+
+func setCookie(c *gin.Context, cookie http.Cookie) {
 	c.SetCookie(
 		cookie.Name,
 		cookie.Value,
@@ -121,7 +341,6 @@ func (r *userRoutes) logoutUser(c *gin.Context) {
 		cookie.Secure,
 		cookie.HttpOnly,
 	)
-	c.JSON(http.StatusOK, gin.H{})
 }
 
 func (r *userRoutes) currentUser(c *gin.Context) {
@@ -141,3 +360,116 @@ func (r *userRoutes) currentUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, model.ToResponseFromUser(u))
 }
+
+// changeEmail changes the email of the current user and mails a link to verify the new one.
+func (r *userRoutes) changeEmail(c *gin.Context) {
+	id := c.GetString("userID")
+	if id == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	type changeEmailRequest struct {
+		Password string `json:"password" binding:"required"`
+		Email    string `json:"email" binding:"required"`
+	}
+	var request changeEmailRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - changeEmail")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	var u user.User
+	changed := r.guardPassword(c, "http - v1 - changeEmail", func() (err error) {
+		u, err = r.u.ChangeEmail(c.Request.Context(), uuid.MustParse(id), request.Password, request.Email)
+
+		return err
+	})
+	if !changed {
+		return
+	}
+
+	err := r.v.SendVerification(c.Request.Context(), u)
+	if err != nil {
+		r.l.Error(err, "http - v1 - changeEmail")
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromUser(u))
+}
+
+func (r *userRoutes) changePassword(c *gin.Context) {
+	id := c.GetString("userID")
+	if id == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	type changePasswordRequest struct {
+		Password    string `json:"password" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+	var request changePasswordRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - changePassword")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	var u user.User
+	changed := r.guardPassword(c, "http - v1 - changePassword", func() (err error) {
+		u, err = r.u.ChangePassword(c.Request.Context(), uuid.MustParse(id), request.Password, request.NewPassword)
+
+		return err
+	})
+	if !changed {
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromUser(u))
+}
+
+// deleteUser deletes the account of the current user and ends their session.
+func (r *userRoutes) deleteUser(c *gin.Context) {
+	id := c.GetString("userID")
+	if id == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	type deleteUserRequest struct {
+		Password string `json:"password" binding:"required"`
+	}
+	var request deleteUserRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - deleteUser")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	deleted := r.guardPassword(c, "http - v1 - deleteUser", func() error {
+		return r.u.DeleteUser(c.Request.Context(), uuid.MustParse(id), request.Password)
+	})
+	if !deleted {
+		return
+	}
+
+	if refreshToken, err := r.jwtService.GetRefreshTokenFromRequest(c.Request); err == nil {
+		err = r.s.EndSession(c.Request.Context(), refreshToken)
+		if err != nil {
+			r.l.Error(err, "http - v1 - deleteUser")
+		}
+	}
+
+	r.clearSessionCookies(c)
+	c.Status(http.StatusNoContent)
+}
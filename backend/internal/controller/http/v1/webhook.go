@@ -0,0 +1,234 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/jwt"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/middleware"
+	"github.com/ozaitsev92/tododdd/internal/controller/http/v1/model"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+)
+
+type webhookRoutes struct {
+	l          logger.Interface
+	jwtService *jwt.JWTService
+	u          *usecase.UserUseCase
+	w          *usecase.WebhookUseCase
+}
+
+func newWebhookRoutes(handler *gin.RouterGroup, l logger.Interface, jwtService *jwt.JWTService, u *usecase.UserUseCase, w *usecase.WebhookUseCase) {
+	r := &webhookRoutes{l, jwtService, u, w}
+
+	h := handler.Group("/webhooks")
+	h.Use(middleware.JwtMiddleware(u, jwtService), middleware.VerifiedMiddleware(u))
+	{
+		h.GET("", r.index)
+		h.POST("", r.createWebhook)
+		h.GET("/:id", r.showWebhook)
+		h.PUT("/:id", r.updateWebhook)
+		h.DELETE("/:id", r.deleteWebhook)
+		h.GET("/:id/deliveries", r.webhookDeliveries)
+	}
+}
+
+// webhookErrorStatus maps an error of the WebhookUseCase to an HTTP status.
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrUnauthorizedAction):
+		return http.StatusForbidden
+	case errors.Is(err, webhook.ErrWebhookNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrTooManyWebhooks):
+		return http.StatusConflict
+	case errors.Is(err, webhook.ErrInvalidURL), errors.Is(err, webhook.ErrInvalidEvents):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// webhookRequest is the body of the requests registering or changing a webhook.
+type webhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+}
+
+func (request webhookRequest) eventTypes() []task.EventType {
+	events := make([]task.EventType, 0, len(request.Events))
+	for _, e := range request.Events {
+		events = append(events, task.EventType(e))
+	}
+
+	return events
+}
+
+func (r *webhookRoutes) index(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	webhooks, err := r.w.GetWebhooksForUser(c.Request.Context(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - index")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromWebhookCollection(webhooks))
+}
+
+// createWebhook registers a webhook. The response is the only one carrying the secret the deliveries are signed with.
+func (r *webhookRoutes) createWebhook(c *gin.Context) {
+	var request webhookRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - createWebhook")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	w, err := r.w.CreateWebhook(c.Request.Context(), request.URL, request.eventTypes(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - createWebhook")
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	response := model.ToResponseFromWebhook(w)
+	response.Secret = w.Secret
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (r *webhookRoutes) showWebhook(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid webhook id"})
+
+		return
+	}
+
+	w, err := r.w.GetWebhook(c.Request.Context(), id, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - showWebhook")
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromWebhook(w))
+}
+
+func (r *webhookRoutes) updateWebhook(c *gin.Context) {
+	var request webhookRequest
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid webhook id"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - updateWebhook")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid request body"})
+
+		return
+	}
+
+	w, err := r.w.UpdateWebhook(c.Request.Context(), id, request.URL, request.eventTypes(), uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - updateWebhook")
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromWebhook(w))
+}
+
+func (r *webhookRoutes) deleteWebhook(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid webhook id"})
+
+		return
+	}
+
+	err = r.w.DeleteWebhook(c.Request.Context(), id, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - deleteWebhook")
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// webhookDeliveries returns the latest deliveries of the webhook with their attempts, the latest first.
+func (r *webhookRoutes) webhookDeliveries(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": "Unauthorized"})
+
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"Error": "Invalid webhook id"})
+
+		return
+	}
+
+	deliveries, err := r.w.GetWebhookDeliveries(c.Request.Context(), id, uuid.MustParse(userID))
+	if err != nil {
+		r.l.Error(err, "http - v1 - webhookDeliveries")
+		c.AbortWithStatusJSON(webhookErrorStatus(err), gin.H{"Error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ToResponseFromDeliveryCollection(deliveries))
+}
@@ -0,0 +1,55 @@
+package feed
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	tokenLength = 32
+)
+
+var (
+	ErrInvalidUserID = errors.New("user id is invalid")
+)
+
+// Feed is a representation of the private calendar feed of a user's tasks. Calendar clients cannot log in,
+// they reach the feed by the raw token in its URL. A user has one feed at most, only the hash of its token is kept.
+type Feed struct {
+	UserID    uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+}
+
+// NewFeed creates and returns a new Feed together with its raw token.
+func NewFeed(userId uuid.UUID) (Feed, string, error) {
+	if userId == uuid.Nil {
+		return Feed{}, "", ErrInvalidUserID
+	}
+
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return Feed{}, "", err
+	}
+
+	raw := base64.RawURLEncoding.EncodeToString(b)
+
+	return Feed{
+		UserID:    userId,
+		TokenHash: HashToken(raw),
+		CreatedAt: time.Now(),
+	}, raw, nil
+}
+
+// HashToken returns the hash a raw token is stored and looked up by.
+func HashToken(raw string) string {
+	h := sha256.Sum256([]byte(raw))
+
+	return hex.EncodeToString(h[:])
+}
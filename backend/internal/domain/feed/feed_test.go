@@ -0,0 +1,73 @@
+package feed_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+)
+
+func TestFeedNewFeed(t *testing.T) {
+	type testCase struct {
+		name    string
+		userId  uuid.UUID
+		wantErr error
+	}
+
+	tests := []testCase{
+		{
+			name:    "Success",
+			userId:  uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			wantErr: nil,
+		},
+		{
+			name:    "Empty userId",
+			userId:  uuid.Nil,
+			wantErr: feed.ErrInvalidUserID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, raw, err := feed.NewFeed(tt.userId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewFeed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if raw == "" || got.TokenHash == raw {
+				t.Error("NewFeed() raw token must be returned and must not be stored")
+			}
+			if got.TokenHash != feed.HashToken(raw) {
+				t.Errorf("NewFeed() TokenHash = %v, want %v", got.TokenHash, feed.HashToken(raw))
+			}
+			if got.UserID != tt.userId {
+				t.Errorf("NewFeed() UserID = %v, want %v", got.UserID, tt.userId)
+			}
+			if got.CreatedAt.IsZero() {
+				t.Error("NewFeed() CreatedAt is zero")
+			}
+		})
+	}
+}
+
+func TestFeedNewFeedTokensDiffer(t *testing.T) {
+	userId := uuid.New()
+
+	_, first, err := feed.NewFeed(userId)
+	if err != nil {
+		t.Fatalf("NewFeed() error = %v", err)
+	}
+
+	_, second, err := feed.NewFeed(userId)
+	if err != nil {
+		t.Fatalf("NewFeed() error = %v", err)
+	}
+
+	if first == second {
+		t.Error("NewFeed() returned the same token twice")
+	}
+}
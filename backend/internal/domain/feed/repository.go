@@ -0,0 +1,23 @@
+package feed
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFeedNotFound       = errors.New("the feed was not found in the repository")
+	ErrFailedToSaveFeed   = errors.New("failed to save the feed")
+	ErrFailedToDeleteFeed = errors.New("failed to delete the feed")
+)
+
+type Repository interface {
+	GetByHash(context.Context, string) (Feed, error)
+	GetByUserID(context.Context, uuid.UUID) (Feed, error)
+	// Save saves the feed in place of the current feed of the user, if any.
+	Save(context.Context, Feed) error
+	// DeleteByUserID deletes the feed of the user, ErrFeedNotFound is returned when the user has none.
+	DeleteByUserID(context.Context, uuid.UUID) error
+}
@@ -0,0 +1,168 @@
+package history
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+// Operation is the kind of change an Event records.
+type Operation string
+
+const (
+	OpCreate        Operation = "create"
+	OpUpdate        Operation = "update"
+	OpUpdateText    Operation = "update_text"
+	OpSetTags       Operation = "set_tags"
+	OpRenameTag     Operation = "rename_tag"
+	OpSetDueDate    Operation = "set_due_date"
+	OpSetRecurrence Operation = "set_recurrence"
+	OpComplete      Operation = "complete"
+	OpUncomplete    Operation = "uncomplete"
+	OpSetParent     Operation = "set_parent"
+	OpMove          Operation = "move"
+	OpDelete        Operation = "delete"
+	OpRestore       Operation = "restore"
+)
+
+var (
+	ErrInvalidTaskID    = errors.New("task id is invalid")
+	ErrInvalidActorID   = errors.New("actor id is invalid")
+	ErrInvalidOperation = errors.New("operation is invalid")
+)
+
+// State is a snapshot of the task fields a change can touch.
+type State struct {
+	Text           string
+	Completed      bool
+	ListID         *uuid.UUID
+	ParentID       *uuid.UUID
+	DueAt          *time.Time
+	ReminderOffset *time.Duration
+	// Recurrence is the recurrence rule, empty for a one-off task.
+	Recurrence string
+	Tags       []string
+	DeletedAt  *time.Time
+	Position   float64
+	Priority   task.Priority
+	Eisenhower *task.Eisenhower
+}
+
+// StateOf returns a snapshot of the task that later changes of the task do not affect.
+func StateOf(t task.Task) State {
+	var recurrence string
+	if t.Recurrence != nil {
+		recurrence = t.Recurrence.String()
+	}
+
+	return State{
+		Text:           t.Text,
+		Completed:      t.Completed,
+		ListID:         clonePtr(t.ListID),
+		ParentID:       clonePtr(t.ParentID),
+		DueAt:          clonePtr(t.DueAt),
+		ReminderOffset: clonePtr(t.ReminderOffset),
+		Recurrence:     recurrence,
+		Tags:           slices.Clone(t.Tags),
+		DeletedAt:      clonePtr(t.DeletedAt),
+		Position:       t.Position,
+		Priority:       t.Priority,
+		Eisenhower:     clonePtr(t.Eisenhower),
+	}
+}
+
+// Event is an immutable record of a change made to a task.
+type Event struct {
+	ID      uuid.UUID
+	TaskID  uuid.UUID
+	ActorID uuid.UUID
+	// Operation is the kind of change.
+	Operation Operation
+	// Before is nil for a task creation.
+	Before *State
+	After  *State
+	// OccurredAt is when the change was made.
+	OccurredAt time.Time
+}
+
+// NewEvent creates and returns a new Event.
+func NewEvent(taskId uuid.UUID, actorId uuid.UUID, op Operation, before *State, after *State) (Event, error) {
+	if taskId == uuid.Nil {
+		return Event{}, ErrInvalidTaskID
+	}
+
+	if actorId == uuid.Nil {
+		return Event{}, ErrInvalidActorID
+	}
+
+	if op == "" || after == nil {
+		return Event{}, ErrInvalidOperation
+	}
+
+	return Event{
+		ID:         uuid.New(),
+		TaskID:     taskId,
+		ActorID:    actorId,
+		Operation:  op,
+		Before:     before,
+		After:      after,
+		OccurredAt: time.Now(),
+	}, nil
+}
+
+// ChangedFields returns the names of the fields whose values differ before and after the change.
+// Every field with a value is reported for a task creation.
+func (e Event) ChangedFields() []string {
+	before := State{}
+	if e.Before != nil {
+		before = *e.Before
+	}
+
+	after := State{}
+	if e.After != nil {
+		after = *e.After
+	}
+
+	var fields []string
+	add := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+
+	add("text", before.Text != after.Text)
+	add("completed", before.Completed != after.Completed)
+	add("list_id", !equalPtr(before.ListID, after.ListID, func(a, b uuid.UUID) bool { return a == b }))
+	add("parent_id", !equalPtr(before.ParentID, after.ParentID, func(a, b uuid.UUID) bool { return a == b }))
+	add("due_at", !equalPtr(before.DueAt, after.DueAt, time.Time.Equal))
+	add("reminder_offset", !equalPtr(before.ReminderOffset, after.ReminderOffset, func(a, b time.Duration) bool { return a == b }))
+	add("recurrence", before.Recurrence != after.Recurrence)
+	add("tags", !slices.Equal(before.Tags, after.Tags))
+	add("deleted_at", !equalPtr(before.DeletedAt, after.DeletedAt, time.Time.Equal))
+	add("position", before.Position != after.Position)
+	add("priority", before.Priority != after.Priority)
+	add("eisenhower", !equalPtr(before.Eisenhower, after.Eisenhower, func(a, b task.Eisenhower) bool { return a == b }))
+
+	return fields
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+
+	v := *p
+
+	return &v
+}
+
+func equalPtr[T any](a, b *T, eq func(T, T) bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return eq(*a, *b)
+}
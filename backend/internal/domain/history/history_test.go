@@ -0,0 +1,83 @@
+package history_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/history"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+func TestNewEvent(t *testing.T) {
+	state := history.State{Text: "test"}
+
+	type testCase struct {
+		name    string
+		taskId  uuid.UUID
+		actorId uuid.UUID
+		op      history.Operation
+		after   *history.State
+		wantErr error
+	}
+
+	tests := []testCase{
+		{name: "Valid", taskId: uuid.New(), actorId: uuid.New(), op: history.OpCreate, after: &state, wantErr: nil},
+		{name: "No task", taskId: uuid.Nil, actorId: uuid.New(), op: history.OpCreate, after: &state, wantErr: history.ErrInvalidTaskID},
+		{name: "No actor", taskId: uuid.New(), actorId: uuid.Nil, op: history.OpCreate, after: &state, wantErr: history.ErrInvalidActorID},
+		{name: "No operation", taskId: uuid.New(), actorId: uuid.New(), op: "", after: &state, wantErr: history.ErrInvalidOperation},
+		{name: "No state", taskId: uuid.New(), actorId: uuid.New(), op: history.OpCreate, after: nil, wantErr: history.ErrInvalidOperation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e, err := history.NewEvent(tt.taskId, tt.actorId, tt.op, nil, tt.after)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (e.TaskID != tt.taskId || e.ActorID != tt.actorId || e.Operation != tt.op) {
+				t.Errorf("NewEvent() got = %v", e)
+			}
+		})
+	}
+}
+
+func TestEventChangedFields(t *testing.T) {
+	ti, err := task.NewTask("test", uuid.New(), task.WithDueDate(time.Now()), task.WithTags("home"))
+	if err != nil {
+		t.Fatalf("NewTask() error = %v", err)
+	}
+
+	before := history.StateOf(ti)
+
+	// Changing the task does not change the snapshot
+	ti.RenameTag("home", "work")
+	ti.MarkCompleted()
+
+	after := history.StateOf(ti)
+
+	if want := []string{"home"}; !reflect.DeepEqual(before.Tags, want) {
+		t.Errorf("StateOf() tags = %v, want %v", before.Tags, want)
+	}
+
+	e, err := history.NewEvent(ti.ID, ti.UserID, history.OpComplete, &before, &after)
+	if err != nil {
+		t.Fatalf("NewEvent() error = %v", err)
+	}
+
+	if got, want := e.ChangedFields(), []string{"completed", "tags"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedFields() got = %v, want %v", got, want)
+	}
+
+	created, err := history.NewEvent(ti.ID, ti.UserID, history.OpCreate, nil, &before)
+	if err != nil {
+		t.Fatalf("NewEvent() error = %v", err)
+	}
+
+	if got, want := created.ChangedFields(), []string{"text", "due_at", "tags", "position"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedFields() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,19 @@
+package history
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFailedToSaveEvent = errors.New("failed to save the history event")
+)
+
+// Repository is an append-only store of task history events.
+type Repository interface {
+	Append(context.Context, Event) error
+	// GetByTaskID returns the history of the task, the oldest event first.
+	GetByTaskID(context.Context, uuid.UUID) ([]Event, error)
+}
@@ -0,0 +1,195 @@
+package list
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is the access level of a list member.
+type Role string
+
+const (
+	// RoleOwner can read and change the tasks and manage the list and its members.
+	RoleOwner Role = "owner"
+	// RoleEditor can read and change the tasks of the list.
+	RoleEditor Role = "editor"
+	// RoleViewer can only read the tasks of the list.
+	RoleViewer Role = "viewer"
+)
+
+var (
+	ErrInvalidName         = errors.New("name is invalid")
+	ErrInvalidUserID       = errors.New("user id is invalid")
+	ErrInvalidRole         = errors.New("role is invalid")
+	ErrMemberAlreadyExists = errors.New("the user is already a member of the list")
+	ErrMemberNotFound      = errors.New("the user is not a member of the list")
+	ErrLastOwner           = errors.New("the list must have at least one owner")
+)
+
+// Member is a user who has access to a list.
+type Member struct {
+	UserID  uuid.UUID
+	Role    Role
+	AddedAt time.Time
+}
+
+// List is a representation of a task list shared between its members.
+type List struct {
+	ID        uuid.UUID
+	Name      string
+	Members   []Member
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewList creates and returns a new List owned by the user.
+func NewList(name string, ownerId uuid.UUID) (List, error) {
+	if name == "" {
+		return List{}, ErrInvalidName
+	}
+
+	if ownerId == uuid.Nil {
+		return List{}, ErrInvalidUserID
+	}
+
+	currentTime := time.Now()
+
+	return List{
+		ID:        uuid.New(),
+		Name:      name,
+		Members:   []Member{{UserID: ownerId, Role: RoleOwner, AddedAt: currentTime}},
+		CreatedAt: currentTime,
+		UpdatedAt: currentTime,
+	}, nil
+}
+
+// IsValid reports whether the role is one of the known roles.
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleOwner, RoleEditor, RoleViewer:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanView reports whether the role allows reading the tasks of the list.
+func (r Role) CanView() bool {
+	return r.IsValid()
+}
+
+// CanEdit reports whether the role allows changing the tasks of the list.
+func (r Role) CanEdit() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+// CanManage reports whether the role allows changing the list itself and its members.
+func (r Role) CanManage() bool {
+	return r == RoleOwner
+}
+
+// SetName sets the Name field.
+func (l *List) SetName(name string) error {
+	if name == "" {
+		return ErrInvalidName
+	}
+
+	l.Name = name
+	l.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// RoleOf returns the role of the user in the list. The second value is false if the user is not a member.
+func (l List) RoleOf(userId uuid.UUID) (Role, bool) {
+	for _, m := range l.Members {
+		if m.UserID == userId {
+			return m.Role, true
+		}
+	}
+
+	return "", false
+}
+
+// AddMember gives the user access to the list with the role.
+func (l *List) AddMember(userId uuid.UUID, role Role) error {
+	if userId == uuid.Nil {
+		return ErrInvalidUserID
+	}
+
+	if !role.IsValid() {
+		return ErrInvalidRole
+	}
+
+	if _, ok := l.RoleOf(userId); ok {
+		return ErrMemberAlreadyExists
+	}
+
+	currentTime := time.Now()
+
+	l.Members = append(l.Members, Member{UserID: userId, Role: role, AddedAt: currentTime})
+	l.UpdatedAt = currentTime
+
+	return nil
+}
+
+// SetMemberRole changes the role of a member. The last owner cannot be demoted.
+func (l *List) SetMemberRole(userId uuid.UUID, role Role) error {
+	if !role.IsValid() {
+		return ErrInvalidRole
+	}
+
+	i := l.memberIndex(userId)
+	if i < 0 {
+		return ErrMemberNotFound
+	}
+
+	if l.Members[i].Role == RoleOwner && role != RoleOwner && l.owners() == 1 {
+		return ErrLastOwner
+	}
+
+	l.Members[i].Role = role
+	l.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// RemoveMember takes the access to the list away from a member. The last owner cannot be removed.
+func (l *List) RemoveMember(userId uuid.UUID) error {
+	i := l.memberIndex(userId)
+	if i < 0 {
+		return ErrMemberNotFound
+	}
+
+	if l.Members[i].Role == RoleOwner && l.owners() == 1 {
+		return ErrLastOwner
+	}
+
+	l.Members = append(l.Members[:i:i], l.Members[i+1:]...)
+	l.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func (l List) memberIndex(userId uuid.UUID) int {
+	for i, m := range l.Members {
+		if m.UserID == userId {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (l List) owners() int {
+	n := 0
+	for _, m := range l.Members {
+		if m.Role == RoleOwner {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,145 @@
+package list_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+)
+
+func TestListNewList(t *testing.T) {
+	type args struct {
+		name    string
+		ownerId uuid.UUID
+	}
+
+	type testCase struct {
+		name    string
+		args    args
+		wantErr error
+	}
+
+	tests := []testCase{
+		{
+			name: "Success",
+			args: args{
+				name:    "Groceries",
+				ownerId: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Empty name",
+			args: args{
+				name:    "",
+				ownerId: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			},
+			wantErr: list.ErrInvalidName,
+		},
+		{
+			name: "Empty ownerId",
+			args: args{
+				name:    "Groceries",
+				ownerId: uuid.Nil,
+			},
+			wantErr: list.ErrInvalidUserID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := list.NewList(tt.args.name, tt.args.ownerId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.Name != tt.args.name {
+				t.Errorf("NewList() Name = %v, want %v", got.Name, tt.args.name)
+			}
+			if role, ok := got.RoleOf(tt.args.ownerId); !ok || role != list.RoleOwner {
+				t.Errorf("NewList() RoleOf() = %v, want %v", role, list.RoleOwner)
+			}
+		})
+	}
+}
+
+func TestListMembers(t *testing.T) {
+	ownerId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+	editorId := uuid.MustParse("842efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	l, err := list.NewList("Groceries", ownerId)
+	if err != nil {
+		t.Fatalf("NewList() error = %v", err)
+	}
+
+	if err := l.AddMember(editorId, "admin"); !errors.Is(err, list.ErrInvalidRole) {
+		t.Errorf("AddMember() error = %v, wantErr %v", err, list.ErrInvalidRole)
+	}
+
+	if err := l.AddMember(editorId, list.RoleEditor); err != nil {
+		t.Errorf("AddMember() error = %v", err)
+	}
+
+	if err := l.AddMember(editorId, list.RoleViewer); !errors.Is(err, list.ErrMemberAlreadyExists) {
+		t.Errorf("AddMember() error = %v, wantErr %v", err, list.ErrMemberAlreadyExists)
+	}
+
+	if err := l.SetMemberRole(ownerId, list.RoleEditor); !errors.Is(err, list.ErrLastOwner) {
+		t.Errorf("SetMemberRole() error = %v, wantErr %v", err, list.ErrLastOwner)
+	}
+
+	if err := l.RemoveMember(ownerId); !errors.Is(err, list.ErrLastOwner) {
+		t.Errorf("RemoveMember() error = %v, wantErr %v", err, list.ErrLastOwner)
+	}
+
+	if err := l.SetMemberRole(editorId, list.RoleOwner); err != nil {
+		t.Errorf("SetMemberRole() error = %v", err)
+	}
+
+	if err := l.RemoveMember(ownerId); err != nil {
+		t.Errorf("RemoveMember() error = %v", err)
+	}
+
+	if _, ok := l.RoleOf(ownerId); ok {
+		t.Error("RemoveMember() the user is still a member")
+	}
+
+	if err := l.RemoveMember(ownerId); !errors.Is(err, list.ErrMemberNotFound) {
+		t.Errorf("RemoveMember() error = %v, wantErr %v", err, list.ErrMemberNotFound)
+	}
+}
+
+func TestListRole(t *testing.T) {
+	type testCase struct {
+		role       list.Role
+		wantView   bool
+		wantEdit   bool
+		wantManage bool
+	}
+
+	tests := []testCase{
+		{role: list.RoleOwner, wantView: true, wantEdit: true, wantManage: true},
+		{role: list.RoleEditor, wantView: true, wantEdit: true, wantManage: false},
+		{role: list.RoleViewer, wantView: true, wantEdit: false, wantManage: false},
+		{role: "admin", wantView: false, wantEdit: false, wantManage: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			t.Parallel()
+			if got := tt.role.CanView(); got != tt.wantView {
+				t.Errorf("CanView() got = %v, want %v", got, tt.wantView)
+			}
+			if got := tt.role.CanEdit(); got != tt.wantEdit {
+				t.Errorf("CanEdit() got = %v, want %v", got, tt.wantEdit)
+			}
+			if got := tt.role.CanManage(); got != tt.wantManage {
+				t.Errorf("CanManage() got = %v, want %v", got, tt.wantManage)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package list
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrListNotFound     = errors.New("the list was not found in the repository")
+	ErrFailedToSaveList = errors.New("failed to save the list")
+	ErrFailedUpdateList = errors.New("failed to update the list")
+	ErrFailedDeleteList = errors.New("failed to delete the list")
+)
+
+type Repository interface {
+	GetByID(context.Context, uuid.UUID) (List, error)
+	// GetAllByMemberID returns the lists the user is a member of with any role.
+	GetAllByMemberID(context.Context, uuid.UUID) ([]List, error)
+	Save(context.Context, List) error
+	Update(context.Context, List) error
+	Delete(context.Context, uuid.UUID) error
+}
@@ -0,0 +1,107 @@
+package login
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidKey = errors.New("login attempts key is invalid")
+)
+
+// Scope is what the failed login attempts, or the password reset requests, are counted for.
+type Scope string
+
+const (
+	// ScopeAccount counts the attempts to log in with an email, whether a user is registered with it or not.
+	ScopeAccount Scope = "account"
+	// ScopeIP counts the attempts to log in from an IP address, whatever the email.
+	ScopeIP Scope = "ip"
+	// ScopeResetAccount counts the password reset requests for an email, whether a user is registered with it or not.
+	ScopeResetAccount Scope = "reset_account"
+	// ScopeResetIP counts the password reset requests from an IP address, whatever the email.
+	ScopeResetIP Scope = "reset_ip"
+)
+
+// NewKey returns the key of the attempts of the scope with the email or the IP address. The value is hashed,
+// so the emails tried are not kept.
+func NewKey(scope Scope, value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return "", ErrInvalidKey
+	}
+
+	sum := sha256.Sum256([]byte(value))
+
+	return string(scope) + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// Attempts are the recent consecutive failed login attempts, or password reset requests, of a key.
+type Attempts struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+}
+
+// Policy decides how long the next login attempt has to wait after failed ones. A number of failures
+// is free, every next one doubles the wait from Backoff up to MaxBackoff, and from LockoutThreshold
+// failures on every failure locks out for LockoutDuration. The failures are forgotten after Window
+// without any. The zero values turn the backoff and the lockout off.
+type Policy struct {
+	FreeAttempts     int
+	Backoff          time.Duration
+	MaxBackoff       time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	Window           time.Duration
+}
+
+// Delay returns how long after the last of the failures the next attempt is allowed.
+func (p Policy) Delay(failures int) time.Duration {
+	var delay time.Duration
+
+	if p.Backoff > 0 && failures > p.FreeAttempts {
+		delay = p.Backoff
+		for i := p.FreeAttempts + 1; i < failures && (p.MaxBackoff <= 0 || delay < p.MaxBackoff) && delay <= math.MaxInt64/2; i++ {
+			delay *= 2
+		}
+
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			delay = p.MaxBackoff
+		}
+	}
+
+	if p.IsLockout(failures) && p.LockoutDuration > delay {
+		delay = p.LockoutDuration
+	}
+
+	return delay
+}
+
+// IsLockout returns true if the failures lock the key out.
+func (p Policy) IsLockout(failures int) bool {
+	return p.LockoutThreshold > 0 && failures >= p.LockoutThreshold
+}
+
+// Wait returns how long the next attempt of the key has to wait at the time, zero if it is allowed.
+func (p Policy) Wait(a Attempts, now time.Time) time.Duration {
+	if a.Failures == 0 || p.IsExpired(a, now) {
+		return 0
+	}
+
+	wait := a.LastFailureAt.Add(p.Delay(a.Failures)).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
+}
+
+// IsExpired returns true if the failures of the key are old enough to be forgotten at the time.
+func (p Policy) IsExpired(a Attempts, now time.Time) bool {
+	return p.Window > 0 && now.Sub(a.LastFailureAt) > p.Window
+}
@@ -0,0 +1,120 @@
+package login_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
+)
+
+func TestNewKey(t *testing.T) {
+	key, err := login.NewKey(login.ScopeAccount, " Test@Example.com ")
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+	if !strings.HasPrefix(key, "account:") || strings.Contains(key, "example") {
+		t.Errorf("NewKey() = %v, want the hashed email in the account scope", key)
+	}
+
+	same, _ := login.NewKey(login.ScopeAccount, "test@example.com")
+	if same != key {
+		t.Errorf("NewKey() = %v, want %v whatever the case and the spaces", same, key)
+	}
+
+	ip, _ := login.NewKey(login.ScopeIP, "test@example.com")
+	if ip == key {
+		t.Error("NewKey() is the same in another scope")
+	}
+
+	if _, err := login.NewKey(login.ScopeIP, " "); !errors.Is(err, login.ErrInvalidKey) {
+		t.Errorf("NewKey() error = %v, wantErr %v", err, login.ErrInvalidKey)
+	}
+}
+
+func TestPolicyDelay(t *testing.T) {
+	p := login.Policy{
+		FreeAttempts:     2,
+		Backoff:          time.Second,
+		MaxBackoff:       5 * time.Second,
+		LockoutThreshold: 8,
+		LockoutDuration:  time.Minute,
+	}
+
+	want := []time.Duration{
+		0,               // no failures
+		0,               // free
+		0,               // free
+		time.Second,     // backoff
+		2 * time.Second, // doubled
+		4 * time.Second,
+		5 * time.Second, // capped
+		5 * time.Second,
+		time.Minute, // locked out
+		time.Minute,
+	}
+
+	for failures, delay := range want {
+		if got := p.Delay(failures); got != delay {
+			t.Errorf("Delay(%d) = %v, want %v", failures, got, delay)
+		}
+	}
+
+	if p.IsLockout(7) || !p.IsLockout(8) {
+		t.Error("IsLockout() is not from the threshold on")
+	}
+
+	if got := (login.Policy{Backoff: time.Second}).Delay(1000); got <= 0 {
+		t.Errorf("Delay() = %v with no max backoff, want it not to overflow", got)
+	}
+
+	if got := (login.Policy{}).Delay(100); got != 0 {
+		t.Errorf("Delay() = %v for the zero policy, want 0", got)
+	}
+}
+
+func TestPolicyWait(t *testing.T) {
+	p := login.Policy{
+		Backoff: 10 * time.Second,
+		Window:  time.Hour,
+	}
+	now := time.Now()
+
+	type testCase struct {
+		name     string
+		attempts login.Attempts
+		want     time.Duration
+	}
+
+	tests := []testCase{
+		{
+			name:     "No failures",
+			attempts: login.Attempts{},
+			want:     0,
+		},
+		{
+			name:     "Waiting",
+			attempts: login.Attempts{Failures: 2, LastFailureAt: now.Add(-5 * time.Second)},
+			want:     15 * time.Second,
+		},
+		{
+			name:     "Waited",
+			attempts: login.Attempts{Failures: 1, LastFailureAt: now.Add(-time.Minute)},
+			want:     0,
+		},
+		{
+			name:     "Expired",
+			attempts: login.Attempts{Failures: 100, LastFailureAt: now.Add(-2 * time.Hour)},
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Wait(tt.attempts, now); got != tt.want {
+				t.Errorf("Wait() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrFailedToSaveAttempts = errors.New("failed to save the login attempts")
+	ErrAttemptsChanged      = errors.New("the login attempts have changed since they were read")
+)
+
+type Repository interface {
+	// Get returns the attempts of the key, the Attempts with no failures if there are none.
+	Get(ctx context.Context, key string) (Attempts, error)
+	// AddFailure counts a failed attempt of the key of the seen attempts at the time and returns the attempts.
+	// The failures are counted anew if the last one is older than the window. The attempts can be dropped
+	// after the window. ErrAttemptsChanged is returned if the stored attempts are no longer the seen ones,
+	// as another attempt has been counted or the attempts have been reset since they were read.
+	AddFailure(ctx context.Context, seen Attempts, at time.Time, window time.Duration) (Attempts, error)
+	// RemoveFailure takes back a failed attempt of the key. The time of the last failure is kept.
+	RemoveFailure(ctx context.Context, key string) error
+	// Reset forgets the failed attempts of the key.
+	Reset(ctx context.Context, key string) error
+}
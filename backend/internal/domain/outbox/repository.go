@@ -0,0 +1,23 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+var (
+	ErrFailedToAddEvent = errors.New("failed to add the event to the outbox")
+)
+
+// Repository is the outbox of the task events. An event is added in the transaction saving the change
+// it reports, so it is stored if and only if the change is, and is kept until it has been dispatched.
+type Repository interface {
+	Add(context.Context, task.Event) error
+	// GetPending returns at most limit events not dispatched yet, the oldest first.
+	GetPending(ctx context.Context, limit int) ([]task.Event, error)
+	// MarkDispatched takes the events out of the pending ones.
+	MarkDispatched(ctx context.Context, ids []uuid.UUID) error
+}
@@ -0,0 +1,27 @@
+package reset
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTokenNotFound     = errors.New("the password reset token was not found in the repository")
+	ErrTokenUsed         = errors.New("the password reset token has already been used")
+	ErrFailedToSaveToken = errors.New("failed to save the password reset token")
+)
+
+type Repository interface {
+	GetByHash(context.Context, string) (Token, error)
+	Save(context.Context, Token) error
+	// Use saves the token marked as used unless the stored one has been used already,
+	// in which case ErrTokenUsed is returned.
+	Use(context.Context, Token) error
+	// GetLatestByUserID returns the token issued to the user last, ErrTokenNotFound if there are none.
+	GetLatestByUserID(ctx context.Context, userId uuid.UUID) (Token, error)
+	// UseAllByUserID marks all the tokens of the user as used.
+	UseAllByUserID(ctx context.Context, userId uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,84 @@
+package reset
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	tokenLength = 32
+)
+
+var (
+	ErrInvalidUserID = errors.New("user id is invalid")
+	ErrInvalidTTL    = errors.New("token lifetime is invalid")
+)
+
+// Token is a representation of a password reset token entity. The raw token is mailed to the user
+// and can be used once before it expires to set a new password. Only its hash is kept.
+type Token struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	Used      bool
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewToken creates and returns a new Token together with its raw value.
+func NewToken(userId uuid.UUID, ttl time.Duration) (Token, string, error) {
+	if userId == uuid.Nil {
+		return Token{}, "", ErrInvalidUserID
+	}
+
+	if ttl <= 0 {
+		return Token{}, "", ErrInvalidTTL
+	}
+
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return Token{}, "", err
+	}
+
+	raw := base64.RawURLEncoding.EncodeToString(b)
+	currentTime := time.Now()
+
+	return Token{
+		ID:        uuid.New(),
+		UserID:    userId,
+		TokenHash: HashToken(raw),
+		ExpiresAt: currentTime.Add(ttl),
+		CreatedAt: currentTime,
+		UpdatedAt: currentTime,
+	}, raw, nil
+}
+
+// HashToken returns the hash a raw token is stored and looked up by.
+func HashToken(raw string) string {
+	h := sha256.Sum256([]byte(raw))
+
+	return hex.EncodeToString(h[:])
+}
+
+// IsExpired reports whether the token lifetime is over.
+func (t *Token) IsExpired() bool {
+	return !time.Now().Before(t.ExpiresAt)
+}
+
+// IsUsable reports whether the token can still be used to reset the password.
+func (t *Token) IsUsable() bool {
+	return !t.Used && !t.IsExpired()
+}
+
+// MarkUsed marks the token as used.
+func (t *Token) MarkUsed() {
+	t.Used = true
+	t.UpdatedAt = time.Now()
+}
@@ -0,0 +1,96 @@
+package reset_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/reset"
+)
+
+func TestResetNewToken(t *testing.T) {
+	type args struct {
+		userId uuid.UUID
+		ttl    time.Duration
+	}
+
+	type testCase struct {
+		name    string
+		args    args
+		wantErr error
+	}
+
+	tests := []testCase{
+		{
+			name: "Success",
+			args: args{
+				userId: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				ttl:    time.Hour,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Empty userId",
+			args: args{
+				userId: uuid.Nil,
+				ttl:    time.Hour,
+			},
+			wantErr: reset.ErrInvalidUserID,
+		},
+		{
+			name: "Invalid ttl",
+			args: args{
+				userId: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				ttl:    0,
+			},
+			wantErr: reset.ErrInvalidTTL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, raw, err := reset.NewToken(tt.args.userId, tt.args.ttl)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if raw == "" || got.TokenHash == raw {
+				t.Error("NewToken() raw token must be returned and must not be stored")
+			}
+			if got.TokenHash != reset.HashToken(raw) {
+				t.Errorf("NewToken() TokenHash = %v, want %v", got.TokenHash, reset.HashToken(raw))
+			}
+			if got.UserID != tt.args.userId {
+				t.Errorf("NewToken() UserID = %v, want %v", got.UserID, tt.args.userId)
+			}
+			if got.ID == uuid.Nil {
+				t.Error("NewToken() ID is nil")
+			}
+			if !got.IsUsable() {
+				t.Error("NewToken() token must be usable")
+			}
+		})
+	}
+}
+
+func TestResetIsUsable(t *testing.T) {
+	rt, _, err := reset.NewToken(uuid.New(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewToken() error = %v", err)
+	}
+
+	rt.MarkUsed()
+	if !rt.Used || rt.IsUsable() {
+		t.Error("MarkUsed() the token must not be usable")
+	}
+
+	rt.Used = false
+	rt.ExpiresAt = time.Now().Add(-time.Second)
+	if !rt.IsExpired() || rt.IsUsable() {
+		t.Error("IsUsable() an expired token must not be usable")
+	}
+}
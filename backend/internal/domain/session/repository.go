@@ -0,0 +1,26 @@
+package session
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRefreshTokenNotFound      = errors.New("the refresh token was not found in the repository")
+	ErrRefreshTokenReused        = errors.New("the refresh token has already been used")
+	ErrFailedToSaveRefreshToken  = errors.New("failed to save the refresh token")
+	ErrFailedRevokeRefreshTokens = errors.New("failed to revoke the refresh tokens")
+)
+
+type Repository interface {
+	GetByHash(context.Context, string) (RefreshToken, error)
+	Save(context.Context, RefreshToken) error
+	// Rotate marks the used token as used, unless it has been used or revoked already,
+	// and saves the token issued instead of it.
+	Rotate(ctx context.Context, used RefreshToken, next RefreshToken) error
+	RevokeFamily(context.Context, uuid.UUID) error
+	// RevokeAllByUserID revokes every token of the user, ending all their sessions.
+	RevokeAllByUserID(context.Context, uuid.UUID) error
+}
@@ -0,0 +1,93 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	tokenLength = 32
+)
+
+var (
+	ErrInvalidUserID = errors.New("user id is invalid")
+	ErrInvalidTTL    = errors.New("token lifetime is invalid")
+)
+
+// RefreshToken is a representation of a server-side refresh token entity.
+// Tokens issued one from another share a FamilyID so a whole login session can be revoked at once.
+type RefreshToken struct {
+	ID        uuid.UUID
+	FamilyID  uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	Used      bool
+	Revoked   bool
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewRefreshToken creates and returns a new RefreshToken together with its raw value.
+// Only the hash of the raw value is kept in the entity. A nil familyId starts a new family.
+func NewRefreshToken(userId, familyId uuid.UUID, ttl time.Duration) (RefreshToken, string, error) {
+	if userId == uuid.Nil {
+		return RefreshToken{}, "", ErrInvalidUserID
+	}
+
+	if ttl <= 0 {
+		return RefreshToken{}, "", ErrInvalidTTL
+	}
+
+	if familyId == uuid.Nil {
+		familyId = uuid.New()
+	}
+
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return RefreshToken{}, "", err
+	}
+
+	raw := base64.RawURLEncoding.EncodeToString(b)
+	currentTime := time.Now()
+
+	return RefreshToken{
+		ID:        uuid.New(),
+		FamilyID:  familyId,
+		UserID:    userId,
+		TokenHash: HashToken(raw),
+		ExpiresAt: currentTime.Add(ttl),
+		CreatedAt: currentTime,
+		UpdatedAt: currentTime,
+	}, raw, nil
+}
+
+// HashToken returns the hash a raw token is stored and looked up by.
+func HashToken(raw string) string {
+	h := sha256.Sum256([]byte(raw))
+
+	return hex.EncodeToString(h[:])
+}
+
+// IsExpired reports whether the token lifetime is over.
+func (t *RefreshToken) IsExpired() bool {
+	return !time.Now().Before(t.ExpiresAt)
+}
+
+// MarkUsed marks the token as exchanged for a new one.
+func (t *RefreshToken) MarkUsed() {
+	t.Used = true
+	t.UpdatedAt = time.Now()
+}
+
+// Revoke marks the token as revoked.
+func (t *RefreshToken) Revoke() {
+	t.Revoked = true
+	t.UpdatedAt = time.Now()
+}
@@ -0,0 +1,113 @@
+package session_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+)
+
+func TestSessionNewRefreshToken(t *testing.T) {
+	type args struct {
+		userId   uuid.UUID
+		familyId uuid.UUID
+		ttl      time.Duration
+	}
+
+	type testCase struct {
+		name    string
+		args    args
+		wantErr error
+	}
+
+	tests := []testCase{
+		{
+			name: "Success",
+			args: args{
+				userId: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				ttl:    time.Hour,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Success with family",
+			args: args{
+				userId:   uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				familyId: uuid.MustParse("842efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				ttl:      time.Hour,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Empty userId",
+			args: args{
+				userId: uuid.Nil,
+				ttl:    time.Hour,
+			},
+			wantErr: session.ErrInvalidUserID,
+		},
+		{
+			name: "Invalid ttl",
+			args: args{
+				userId: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				ttl:    0,
+			},
+			wantErr: session.ErrInvalidTTL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, raw, err := session.NewRefreshToken(tt.args.userId, tt.args.familyId, tt.args.ttl)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewRefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if raw == "" || got.TokenHash == raw {
+				t.Error("NewRefreshToken() raw token must be returned and must not be stored")
+			}
+			if got.TokenHash != session.HashToken(raw) {
+				t.Errorf("NewRefreshToken() TokenHash = %v, want %v", got.TokenHash, session.HashToken(raw))
+			}
+			if got.UserID != tt.args.userId {
+				t.Errorf("NewRefreshToken() UserID = %v, want %v", got.UserID, tt.args.userId)
+			}
+			if tt.args.familyId != uuid.Nil && got.FamilyID != tt.args.familyId {
+				t.Errorf("NewRefreshToken() FamilyID = %v, want %v", got.FamilyID, tt.args.familyId)
+			}
+			if got.FamilyID == uuid.Nil || got.ID == uuid.Nil {
+				t.Error("NewRefreshToken() ID or FamilyID is nil")
+			}
+			if got.IsExpired() {
+				t.Error("NewRefreshToken() token must not be expired")
+			}
+		})
+	}
+}
+
+func TestSessionMarkUsedAndRevoke(t *testing.T) {
+	rt, _, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRefreshToken() error = %v", err)
+	}
+
+	rt.MarkUsed()
+	if !rt.Used {
+		t.Error("MarkUsed() Used = false, want true")
+	}
+
+	rt.Revoke()
+	if !rt.Revoked {
+		t.Error("Revoke() Revoked = false, want true")
+	}
+
+	rt.ExpiresAt = time.Now().Add(-time.Second)
+	if !rt.IsExpired() {
+		t.Error("IsExpired() = false, want true")
+	}
+}
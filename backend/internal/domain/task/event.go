@@ -0,0 +1,81 @@
+package task
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType is the kind of change an Event reports.
+type EventType string
+
+const (
+	EventCreated   EventType = "task.created"
+	EventUpdated   EventType = "task.updated"
+	EventCompleted EventType = "task.completed"
+	EventDeleted   EventType = "task.deleted"
+)
+
+// EventTypes are all the event types, in the order of the lifecycle of a task.
+var EventTypes = []EventType{EventCreated, EventUpdated, EventCompleted, EventDeleted}
+
+// Event reports a change of a task to whoever follows the task.
+type Event struct {
+	ID   uuid.UUID
+	Type EventType
+	// Task is the task after the change.
+	Task    Task
+	ActorID uuid.UUID
+	// OccurredAt is when the change was made.
+	OccurredAt time.Time
+}
+
+// NewEvent creates and returns a new Event.
+func NewEvent(eventType EventType, t Task, actorId uuid.UUID) Event {
+	return Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		Task:       t,
+		ActorID:    actorId,
+		OccurredAt: time.Now(),
+	}
+}
+
+// PullEvent returns the event reporting the change made to the task since it was created or read
+// and forgets the change. It reports false when the task has not been changed. Several changes made
+// to the task before it is saved are reported by one event of the most significant of them.
+// It is meant to be called once the change is saved, so the event carries the task as stored.
+func (t *Task) PullEvent(actorId uuid.UUID) (Event, bool) {
+	if t.raised == "" {
+		return Event{}, false
+	}
+
+	eventType := t.raised
+	t.raised = ""
+
+	return NewEvent(eventType, *t, actorId), true
+}
+
+// raise records a change of the task, see PullEvent.
+func (t *Task) raise(eventType EventType) {
+	if eventRank(eventType) > eventRank(t.raised) {
+		t.raised = eventType
+	}
+}
+
+// eventRank orders the event types by significance: a created task is reported as created
+// whatever else is done to it before it is saved, a deleted one as deleted and so on.
+func eventRank(eventType EventType) int {
+	switch eventType {
+	case EventCreated:
+		return 4
+	case EventDeleted:
+		return 3
+	case EventCompleted:
+		return 2
+	case EventUpdated:
+		return 1
+	default:
+		return 0
+	}
+}
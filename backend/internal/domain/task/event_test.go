@@ -0,0 +1,110 @@
+package task_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+func TestTaskPullEvent(t *testing.T) {
+	userId := uuid.New()
+	actorId := uuid.New()
+
+	type testCase struct {
+		name     string
+		change   func(ti *task.Task)
+		wantType task.EventType
+		wantOk   bool
+	}
+
+	tests := []testCase{
+		{
+			name:   "Unchanged",
+			change: func(ti *task.Task) {},
+			wantOk: false,
+		},
+		{
+			name: "Updated",
+			change: func(ti *task.Task) {
+				_ = ti.SetText("changed")
+				_ = ti.SetPriority(task.PriorityHigh)
+			},
+			wantType: task.EventUpdated,
+			wantOk:   true,
+		},
+		{
+			name: "Completed",
+			change: func(ti *task.Task) {
+				ti.ClearRecurrence()
+				ti.MarkCompleted()
+			},
+			wantType: task.EventCompleted,
+			wantOk:   true,
+		},
+		{
+			name: "Deleted",
+			change: func(ti *task.Task) {
+				ti.MarkCompleted()
+				_ = ti.MoveToTrash(time.Now())
+			},
+			wantType: task.EventDeleted,
+			wantOk:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ti, err := task.NewTask("test", userId)
+			if err != nil {
+				t.Fatalf("NewTask() error = %v", err)
+			}
+			// The task is read back, the creation is reported already
+			if _, ok := ti.PullEvent(actorId); !ok {
+				t.Fatal("PullEvent() of a new task ok = false, want true")
+			}
+
+			tt.change(&ti)
+			ti.Version++
+
+			got, ok := ti.PullEvent(actorId)
+			if ok != tt.wantOk {
+				t.Fatalf("PullEvent() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if got.Type != tt.wantType {
+				t.Errorf("PullEvent() Type = %v, want %v", got.Type, tt.wantType)
+			}
+			if got.ActorID != actorId {
+				t.Errorf("PullEvent() ActorID = %v, want %v", got.ActorID, actorId)
+			}
+			if got.Task.Version != ti.Version {
+				t.Errorf("PullEvent() Task.Version = %v, want %v", got.Task.Version, ti.Version)
+			}
+			if _, ok := ti.PullEvent(actorId); ok {
+				t.Error("second PullEvent() ok = true, want false")
+			}
+		})
+	}
+}
+
+func TestTaskPullEventOfNewTask(t *testing.T) {
+	ti, err := task.NewTask("test", uuid.New(), task.WithDueDate(time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("NewTask() error = %v", err)
+	}
+
+	_ = ti.SetText("changed")
+
+	got, ok := ti.PullEvent(ti.UserID)
+	if !ok || got.Type != task.EventCreated {
+		t.Errorf("PullEvent() = %v, %v, want %v, true", got.Type, ok, task.EventCreated)
+	}
+	if got.Task.Text != "changed" {
+		t.Errorf("PullEvent() Task.Text = %v, want %v", got.Task.Text, "changed")
+	}
+}
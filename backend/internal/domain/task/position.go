@@ -0,0 +1,80 @@
+package task
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	// PositionGap is the distance between neighbouring tasks after a rebalance
+	// and between a task moved to an end of the order and its neighbour.
+	PositionGap float64 = 1024
+)
+
+var (
+	ErrInvalidMove = errors.New("move target is invalid")
+)
+
+// initialPosition ranks a new task by its creation time, so it goes to the end of the manual order
+// without the order being read.
+func initialPosition(createdAt time.Time) float64 {
+	return float64(createdAt.UnixMilli())
+}
+
+// MoveTo sets the Position field.
+func (t *Task) MoveTo(position float64) {
+	t.Position = position
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+}
+
+// PositionBetween returns a position between the neighbours a task is moved between,
+// nil standing for an end of the order. It reports false when there is no room left between them
+// and the order has to be rebalanced first.
+func PositionBetween(prev, next *Task) (float64, bool) {
+	switch {
+	case prev == nil && next == nil:
+		return PositionGap, true
+	case prev == nil:
+		return next.Position - PositionGap, true
+	case next == nil:
+		return prev.Position + PositionGap, true
+	}
+
+	position := prev.Position + (next.Position-prev.Position)/2
+
+	return position, prev.Position < position && position < next.Position
+}
+
+// Rebalance spreads the positions of the ordered tasks PositionGap apart and returns the tasks it moved.
+func Rebalance(tasks []Task) []Task {
+	var moved []Task
+	for i := range tasks {
+		position := float64(i+1) * PositionGap
+		if tasks[i].Position == position {
+			continue
+		}
+
+		tasks[i].MoveTo(position)
+		moved = append(moved, tasks[i])
+	}
+
+	return moved
+}
+
+// ComparePosition orders tasks by position, then by creation time and ID so the order is total.
+func ComparePosition(a, b Task) int {
+	switch {
+	case a.Position < b.Position:
+		return -1
+	case a.Position > b.Position:
+		return 1
+	}
+
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.ID.String(), b.ID.String())
+}
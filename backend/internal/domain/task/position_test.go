@@ -0,0 +1,59 @@
+package task_test
+
+import (
+	"testing"
+
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+func TestPositionBetween(t *testing.T) {
+	at := func(position float64) *task.Task {
+		return &task.Task{Position: position}
+	}
+
+	type testCase struct {
+		name   string
+		prev   *task.Task
+		next   *task.Task
+		want   float64
+		wantOk bool
+	}
+
+	tests := []testCase{
+		{name: "Empty order", prev: nil, next: nil, want: task.PositionGap, wantOk: true},
+		{name: "First", prev: nil, next: at(3000), want: 3000 - task.PositionGap, wantOk: true},
+		{name: "Last", prev: at(3000), next: nil, want: 3000 + task.PositionGap, wantOk: true},
+		{name: "Between", prev: at(1000), next: at(2000), want: 1500, wantOk: true},
+		{name: "No room", prev: at(1), next: at(1), want: 1, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := task.PositionBetween(tt.prev, tt.next)
+			if ok != tt.wantOk {
+				t.Fatalf("PositionBetween() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && got != tt.want {
+				t.Errorf("PositionBetween() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRebalance(t *testing.T) {
+	tasks := []task.Task{{Position: task.PositionGap}, {Position: 1500}, {Position: 1500}}
+
+	moved := task.Rebalance(tasks)
+
+	if len(moved) != 2 {
+		t.Errorf("Rebalance() moved = %v, want %v", len(moved), 2)
+	}
+
+	for i, ti := range tasks {
+		if want := float64(i+1) * task.PositionGap; ti.Position != want {
+			t.Errorf("Rebalance() [%d] Position = %v, want %v", i, ti.Position, want)
+		}
+	}
+}
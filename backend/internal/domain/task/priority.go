@@ -0,0 +1,169 @@
+package task
+
+import (
+	"errors"
+	"time"
+)
+
+// Priority is how soon the user wants to get to a task, higher first.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+// Quadrant is a cell of the Eisenhower matrix.
+type Quadrant string
+
+const (
+	// QuadrantDoFirst holds the important and urgent tasks.
+	QuadrantDoFirst Quadrant = "do_first"
+	// QuadrantSchedule holds the important tasks that are not urgent.
+	QuadrantSchedule Quadrant = "schedule"
+	// QuadrantDelegate holds the urgent tasks that are not important.
+	QuadrantDelegate Quadrant = "delegate"
+	// QuadrantEliminate holds the tasks that are neither important nor urgent.
+	QuadrantEliminate Quadrant = "eliminate"
+
+	// UrgencyWindow is how close the due date of an unclassified task has to be for the task to be urgent.
+	UrgencyWindow = 48 * time.Hour
+)
+
+var (
+	ErrInvalidPriority = errors.New("priority is invalid")
+)
+
+var priorityNames = map[Priority]string{
+	PriorityNone:   "none",
+	PriorityLow:    "low",
+	PriorityMedium: "medium",
+	PriorityHigh:   "high",
+}
+
+// Quadrants are the cells of the Eisenhower matrix in the order they are worked through.
+var Quadrants = []Quadrant{QuadrantDoFirst, QuadrantSchedule, QuadrantDelegate, QuadrantEliminate}
+
+// ParsePriority parses the name of a priority like "high".
+func ParsePriority(name string) (Priority, error) {
+	for p, n := range priorityNames {
+		if n == name {
+			return p, nil
+		}
+	}
+
+	return PriorityNone, ErrInvalidPriority
+}
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	return priorityNames[p]
+}
+
+// Validate checks that the priority is one of the defined levels.
+func (p Priority) Validate() error {
+	if _, ok := priorityNames[p]; !ok {
+		return ErrInvalidPriority
+	}
+
+	return nil
+}
+
+// Eisenhower is the user's own classification of a task in the Eisenhower matrix.
+type Eisenhower struct {
+	Important bool
+	Urgent    bool
+}
+
+// WithPriority sets the priority of a new Task.
+func WithPriority(p Priority) Option {
+	return func(t *Task) error {
+		return t.SetPriority(p)
+	}
+}
+
+// WithEisenhower classifies a new Task in the Eisenhower matrix.
+func WithEisenhower(e Eisenhower) Option {
+	return func(t *Task) error {
+		t.SetEisenhower(e)
+
+		return nil
+	}
+}
+
+// SetPriority sets the Priority field.
+func (t *Task) SetPriority(p Priority) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	t.Priority = p
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+
+	return nil
+}
+
+// SetEisenhower sets the Eisenhower field.
+func (t *Task) SetEisenhower(e Eisenhower) {
+	t.Eisenhower = &e
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+}
+
+// ClearEisenhower leaves the quadrant of the task to be derived, see Quadrant.
+func (t *Task) ClearEisenhower() {
+	t.Eisenhower = nil
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+}
+
+// Quadrant returns the cell of the Eisenhower matrix the task falls in. Unless the user has classified
+// the task, it is important when its priority is high and urgent when it is due within UrgencyWindow.
+func (t *Task) Quadrant(now time.Time) Quadrant {
+	e := Eisenhower{
+		Important: t.Priority >= PriorityHigh,
+		Urgent:    t.DueAt != nil && t.DueAt.Before(now.Add(UrgencyWindow)),
+	}
+	if t.Eisenhower != nil {
+		e = *t.Eisenhower
+	}
+
+	switch {
+	case e.Important && e.Urgent:
+		return QuadrantDoFirst
+	case e.Important:
+		return QuadrantSchedule
+	case e.Urgent:
+		return QuadrantDelegate
+	default:
+		return QuadrantEliminate
+	}
+}
+
+// GroupByQuadrant buckets the tasks into the quadrants of the Eisenhower matrix.
+// Every quadrant is present and the order of the tasks is kept inside each of them.
+func GroupByQuadrant(tasks []Task, now time.Time) map[Quadrant][]Task {
+	groups := make(map[Quadrant][]Task, len(Quadrants))
+	for _, q := range Quadrants {
+		groups[q] = []Task{}
+	}
+
+	for _, t := range tasks {
+		q := t.Quadrant(now)
+		groups[q] = append(groups[q], t)
+	}
+
+	return groups
+}
+
+// ComparePriority orders tasks by priority, highest first, then in the manual order.
+func ComparePriority(a, b Task) int {
+	if a.Priority != b.Priority {
+		return int(b.Priority - a.Priority)
+	}
+
+	return ComparePosition(a, b)
+}
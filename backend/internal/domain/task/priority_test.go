@@ -0,0 +1,95 @@
+package task_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+func TestParsePriority(t *testing.T) {
+	for _, p := range []task.Priority{task.PriorityNone, task.PriorityLow, task.PriorityMedium, task.PriorityHigh} {
+		got, err := task.ParsePriority(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParsePriority(%q) got = %v, %v, want %v", p.String(), got, err, p)
+		}
+	}
+
+	if _, err := task.ParsePriority("critical"); !errors.Is(err, task.ErrInvalidPriority) {
+		t.Errorf("ParsePriority() error = %v, wantErr %v", err, task.ErrInvalidPriority)
+	}
+
+	if _, err := task.NewTask("test", uuid.New(), task.WithPriority(task.Priority(7))); !errors.Is(err, task.ErrInvalidPriority) {
+		t.Errorf("NewTask() error = %v, wantErr %v", err, task.ErrInvalidPriority)
+	}
+}
+
+func TestTaskQuadrant(t *testing.T) {
+	now := time.Now()
+	soon := now.Add(time.Hour)
+	later := now.Add(7 * 24 * time.Hour)
+
+	type testCase struct {
+		name string
+		opts []task.Option
+		want task.Quadrant
+	}
+
+	tests := []testCase{
+		{name: "Unclassified", opts: nil, want: task.QuadrantEliminate},
+		{name: "High priority", opts: []task.Option{task.WithPriority(task.PriorityHigh)}, want: task.QuadrantSchedule},
+		{name: "Due soon", opts: []task.Option{task.WithDueDate(soon)}, want: task.QuadrantDelegate},
+		{name: "High priority due soon", opts: []task.Option{task.WithPriority(task.PriorityHigh), task.WithDueDate(soon)}, want: task.QuadrantDoFirst},
+		{name: "Due later", opts: []task.Option{task.WithPriority(task.PriorityMedium), task.WithDueDate(later)}, want: task.QuadrantEliminate},
+		{
+			name: "Classified by the user",
+			opts: []task.Option{task.WithPriority(task.PriorityHigh), task.WithDueDate(soon), task.WithEisenhower(task.Eisenhower{Important: false, Urgent: false})},
+			want: task.QuadrantEliminate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ti, err := task.NewTask("test", uuid.New(), tt.opts...)
+			if err != nil {
+				t.Fatalf("NewTask() error = %v", err)
+			}
+
+			if got := ti.Quadrant(now); got != tt.want {
+				t.Errorf("Quadrant() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupByQuadrant(t *testing.T) {
+	important := task.WithEisenhower(task.Eisenhower{Important: true, Urgent: false})
+
+	var tasks []task.Task
+	for _, opts := range [][]task.Option{{important}, {}, {important}} {
+		ti, err := task.NewTask("test", uuid.New(), opts...)
+		if err != nil {
+			t.Fatalf("NewTask() error = %v", err)
+		}
+		tasks = append(tasks, ti)
+	}
+
+	groups := task.GroupByQuadrant(tasks, time.Now())
+
+	if len(groups) != len(task.Quadrants) {
+		t.Errorf("GroupByQuadrant() len = %v, want %v", len(groups), len(task.Quadrants))
+	}
+
+	schedule := groups[task.QuadrantSchedule]
+	if len(schedule) != 2 || schedule[0].ID != tasks[0].ID || schedule[1].ID != tasks[2].ID {
+		t.Errorf("GroupByQuadrant() schedule = %v, want tasks 0 and 2", schedule)
+	}
+
+	if len(groups[task.QuadrantEliminate]) != 1 || len(groups[task.QuadrantDoFirst]) != 0 {
+		t.Errorf("GroupByQuadrant() got = %v", groups)
+	}
+}
@@ -0,0 +1,293 @@
+package task
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SortField is a task field a listing can be ordered by.
+type SortField string
+
+// SortDirection is the order of a listing.
+type SortDirection string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+	SortByText      SortField = "text"
+	SortByPosition  SortField = "position"
+
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+
+	// DefaultQueryLimit is the page size of a Query when no limit is given.
+	DefaultQueryLimit = 50
+	// MaxQueryLimit is the largest page size a Query may request.
+	MaxQueryLimit = 100
+)
+
+var (
+	ErrInvalidSortField     = errors.New("sort field is invalid")
+	ErrInvalidSortDirection = errors.New("sort direction is invalid")
+	ErrInvalidLimit         = errors.New("limit is invalid")
+	ErrInvalidCursor        = errors.New("cursor is invalid")
+	ErrInvalidDateRange     = errors.New("date range is invalid")
+)
+
+// Query is a specification of the tasks a user wants to list.
+// Zero values of the optional fields mean "no restriction".
+type Query struct {
+	UserID uuid.UUID
+	// ListID selects the tasks of a shared list. When nil, the personal tasks of UserID are selected.
+	ListID      *uuid.UUID
+	Completed   *bool
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	Text        string
+	// Tags are normalized tags matched according to TagMatch.
+	Tags          []string
+	TagMatch      TagMatch
+	SortBy        SortField
+	SortDirection SortDirection
+	// Limit is the page size, from 1 to MaxQueryLimit.
+	Limit int
+	// Cursor is the opaque NextCursor of the previous Page.
+	Cursor string
+}
+
+// Page is a single page of a task listing.
+type Page struct {
+	Tasks []Task
+	// NextCursor is empty when there are no more tasks.
+	NextCursor string
+}
+
+// Cursor is a decoded position in a listing: the sort key and ID of the last returned task.
+type Cursor struct {
+	SortBy   SortField `json:"s"`
+	Time     time.Time `json:"t,omitempty"`
+	Text     string    `json:"x,omitempty"`
+	Position float64   `json:"p,omitempty"`
+	ID       uuid.UUID `json:"i"`
+}
+
+// NewQuery creates a Query for the tasks of a user ordered by creation time, DefaultQueryLimit per page.
+func NewQuery(userId uuid.UUID) Query {
+	return Query{
+		UserID:        userId,
+		TagMatch:      TagMatchAny,
+		SortBy:        SortByCreatedAt,
+		SortDirection: SortAsc,
+		Limit:         DefaultQueryLimit,
+	}
+}
+
+// Validate checks that the query is well formed.
+func (q Query) Validate() error {
+	if q.UserID == uuid.Nil {
+		return ErrInvalidUserID
+	}
+
+	switch q.SortBy {
+	case SortByCreatedAt, SortByUpdatedAt, SortByText, SortByPosition:
+	default:
+		return ErrInvalidSortField
+	}
+
+	switch q.SortDirection {
+	case SortAsc, SortDesc:
+	default:
+		return ErrInvalidSortDirection
+	}
+
+	switch q.TagMatch {
+	case TagMatchAny, TagMatchAll:
+	default:
+		return ErrInvalidTagMatch
+	}
+
+	for _, tag := range q.Tags {
+		if normalized, err := NormalizeTag(tag); err != nil || normalized != tag {
+			return ErrInvalidTag
+		}
+	}
+
+	if q.Limit <= 0 || q.Limit > MaxQueryLimit {
+		return ErrInvalidLimit
+	}
+
+	if !q.CreatedFrom.IsZero() && !q.CreatedTo.IsZero() && q.CreatedFrom.After(q.CreatedTo) {
+		return ErrInvalidDateRange
+	}
+
+	if !q.UpdatedFrom.IsZero() && !q.UpdatedTo.IsZero() && q.UpdatedFrom.After(q.UpdatedTo) {
+		return ErrInvalidDateRange
+	}
+
+	if _, err := q.DecodeCursor(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Matches reports whether the task satisfies the query filters. The cursor is not taken into account.
+// Tasks in the trash never match.
+func (q Query) Matches(t Task) bool {
+	if t.IsTrashed() {
+		return false
+	}
+
+	if q.ListID != nil {
+		if t.ListID == nil || *t.ListID != *q.ListID {
+			return false
+		}
+	} else if t.ListID != nil || t.UserID != q.UserID {
+		return false
+	}
+
+	if q.Completed != nil && t.Completed != *q.Completed {
+		return false
+	}
+
+	if !inRange(t.CreatedAt, q.CreatedFrom, q.CreatedTo) || !inRange(t.UpdatedAt, q.UpdatedFrom, q.UpdatedTo) {
+		return false
+	}
+
+	if q.Text != "" && !strings.Contains(strings.ToLower(t.Text), strings.ToLower(q.Text)) {
+		return false
+	}
+
+	if len(q.Tags) > 0 && !q.matchesTags(t) {
+		return false
+	}
+
+	return true
+}
+
+// Less reports whether task a goes before task b in the query order.
+// Ties on the sort field are broken by ID so the order is total.
+func (q Query) Less(a, b Task) bool {
+	c := q.compare(a, q.CursorAt(b))
+	if q.SortDirection == SortDesc {
+		return c > 0
+	}
+
+	return c < 0
+}
+
+// After reports whether the task goes after the cursor position in the query order.
+func (q Query) After(t Task, c Cursor) bool {
+	if q.SortDirection == SortDesc {
+		return q.compare(t, c) < 0
+	}
+
+	return q.compare(t, c) > 0
+}
+
+// CursorAt returns the position of the task in the query order.
+func (q Query) CursorAt(t Task) Cursor {
+	c := Cursor{SortBy: q.SortBy, ID: t.ID}
+
+	switch q.SortBy {
+	case SortByUpdatedAt:
+		c.Time = t.UpdatedAt
+	case SortByText:
+		c.Text = t.Text
+	case SortByPosition:
+		c.Position = t.Position
+	default:
+		c.Time = t.CreatedAt
+	}
+
+	return c
+}
+
+// EncodeCursor returns an opaque cursor pointing right after the task.
+func (q Query) EncodeCursor(t Task) string {
+	b, err := json.Marshal(q.CursorAt(t))
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor decodes the query cursor. It returns nil if the query has no cursor.
+func (q Query) DecodeCursor() (*Cursor, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if c.SortBy != q.SortBy || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+func (q Query) compare(t Task, c Cursor) int {
+	var r int
+
+	switch q.SortBy {
+	case SortByText:
+		r = strings.Compare(t.Text, c.Text)
+	case SortByUpdatedAt:
+		r = t.UpdatedAt.Compare(c.Time)
+	case SortByPosition:
+		r = cmp.Compare(t.Position, c.Position)
+	default:
+		r = t.CreatedAt.Compare(c.Time)
+	}
+
+	if r != 0 {
+		return r
+	}
+
+	return strings.Compare(t.ID.String(), c.ID.String())
+}
+
+func (q Query) matchesTags(t Task) bool {
+	for _, tag := range q.Tags {
+		has := t.HasTag(tag)
+		if q.TagMatch == TagMatchAll && !has {
+			return false
+		}
+
+		if q.TagMatch == TagMatchAny && has {
+			return true
+		}
+	}
+
+	return q.TagMatch == TagMatchAll
+}
+
+func inRange(v, from, to time.Time) bool {
+	if !from.IsZero() && v.Before(from) {
+		return false
+	}
+
+	if !to.IsZero() && v.After(to) {
+		return false
+	}
+
+	return true
+}
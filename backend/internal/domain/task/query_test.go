@@ -0,0 +1,254 @@
+package task_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+func TestQueryValidate(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+	now := time.Now()
+
+	type testCase struct {
+		name    string
+		query   func() task.Query
+		wantErr error
+	}
+
+	tests := []testCase{
+		{
+			name:    "Success",
+			query:   func() task.Query { return task.NewQuery(userId) },
+			wantErr: nil,
+		},
+		{
+			name:    "Empty userId",
+			query:   func() task.Query { return task.NewQuery(uuid.Nil) },
+			wantErr: task.ErrInvalidUserID,
+		},
+		{
+			name: "Invalid sort field",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.SortBy = "user_id"
+				return q
+			},
+			wantErr: task.ErrInvalidSortField,
+		},
+		{
+			name: "Invalid sort direction",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.SortDirection = "up"
+				return q
+			},
+			wantErr: task.ErrInvalidSortDirection,
+		},
+		{
+			name: "Zero limit",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.Limit = 0
+				return q
+			},
+			wantErr: task.ErrInvalidLimit,
+		},
+		{
+			name: "Limit too big",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.Limit = task.MaxQueryLimit + 1
+				return q
+			},
+			wantErr: task.ErrInvalidLimit,
+		},
+		{
+			name: "Inverted date range",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.CreatedFrom = now
+				q.CreatedTo = now.Add(-time.Hour)
+				return q
+			},
+			wantErr: task.ErrInvalidDateRange,
+		},
+		{
+			name: "Malformed cursor",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.Cursor = "not a cursor"
+				return q
+			},
+			wantErr: task.ErrInvalidCursor,
+		},
+		{
+			name: "Cursor for another sort field",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.SortBy = task.SortByText
+				q.Cursor = task.NewQuery(userId).EncodeCursor(task.Task{ID: uuid.New(), CreatedAt: now})
+				return q
+			},
+			wantErr: task.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.query().Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+	now := time.Now()
+	completed := true
+	listId := uuid.New()
+
+	ti := task.Task{
+		ID:        uuid.New(),
+		Text:      "Buy Milk",
+		Completed: true,
+		UserID:    userId,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	type testCase struct {
+		name  string
+		query func() task.Query
+		want  bool
+	}
+
+	tests := []testCase{
+		{
+			name:  "No filters",
+			query: func() task.Query { return task.NewQuery(userId) },
+			want:  true,
+		},
+		{
+			name:  "Another user",
+			query: func() task.Query { return task.NewQuery(uuid.New()) },
+			want:  false,
+		},
+		{
+			name: "Another list",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.ListID = &listId
+				return q
+			},
+			want: false,
+		},
+		{
+			name: "Completed",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.Completed = &completed
+				return q
+			},
+			want: true,
+		},
+		{
+			name: "Text is case insensitive",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.Text = "milk"
+				return q
+			},
+			want: true,
+		},
+		{
+			name: "Text does not match",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.Text = "bread"
+				return q
+			},
+			want: false,
+		},
+		{
+			name: "Created before range",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.CreatedFrom = now.Add(time.Hour)
+				return q
+			},
+			want: false,
+		},
+		{
+			name: "Updated within range",
+			query: func() task.Query {
+				q := task.NewQuery(userId)
+				q.UpdatedFrom = now.Add(-time.Hour)
+				q.UpdatedTo = now.Add(time.Hour)
+				return q
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.query().Matches(ti); got != tt.want {
+				t.Errorf("Matches() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryCursor(t *testing.T) {
+	q := task.NewQuery(uuid.New())
+	q.SortBy = task.SortByText
+	q.SortDirection = task.SortDesc
+
+	ti := task.Task{ID: uuid.New(), Text: "b"}
+	q.Cursor = q.EncodeCursor(ti)
+
+	c, err := q.DecodeCursor()
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+
+	if c.ID != ti.ID || c.Text != ti.Text {
+		t.Errorf("DecodeCursor() got = %v, want %v", c, q.CursorAt(ti))
+	}
+
+	if !q.After(task.Task{ID: uuid.New(), Text: "a"}, *c) {
+		t.Error("After() task 'a' must go after 'b' in descending order")
+	}
+
+	if q.After(task.Task{ID: uuid.New(), Text: "c"}, *c) {
+		t.Error("After() task 'c' must go before 'b' in descending order")
+	}
+}
+
+func TestQueryMatchesList(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+	listId := uuid.MustParse("842efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	ti, err := task.NewTask("shared", uuid.New(), task.WithList(listId))
+	if err != nil {
+		t.Fatalf("NewTask() error = %v", err)
+	}
+
+	q := task.NewQuery(userId)
+	if q.Matches(ti) {
+		t.Error("Matches() a task of a list must not be listed as personal")
+	}
+
+	q.ListID = &listId
+	if !q.Matches(ti) {
+		t.Error("Matches() a task of a list must be listed for the list")
+	}
+}
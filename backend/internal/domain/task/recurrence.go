@@ -0,0 +1,366 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the base period of a recurrence rule.
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+	FrequencyYearly  Frequency = "YEARLY"
+
+	// MaxRecurrenceInterval is the largest INTERVAL a recurrence rule can have.
+	MaxRecurrenceInterval = 1000
+
+	// maxRecurrencePeriods bounds the search for the next occurrence of rules
+	// whose BYDAY and BYMONTHDAY parts rarely or never match.
+	maxRecurrencePeriods = 1000
+
+	untilLayout     = "20060102T150405Z"
+	untilDateLayout = "20060102"
+)
+
+var (
+	ErrInvalidRecurrence        = errors.New("recurrence rule is invalid")
+	ErrRecurrenceWithoutDueDate = errors.New("recurrence requires a due date")
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence is a subset of an RFC 5545 RRULE: FREQ, INTERVAL, BYDAY (without ordinals),
+// BYMONTHDAY, COUNT and UNTIL. The due date of the task carrying the rule is its DTSTART.
+type Recurrence struct {
+	Frequency Frequency
+	// Interval is the number of periods between occurrences, at least 1.
+	Interval int
+	// ByDay are the week days the occurrences fall on, Monday first.
+	ByDay []time.Weekday
+	// ByMonthDay are the days of the month the occurrences fall on, negative values count from the end.
+	ByMonthDay []int
+	// Count is the number of occurrences left including the current one, zero for no limit.
+	Count int
+	// Until is the last moment an occurrence can fall on, nil for no limit.
+	Until *time.Time
+}
+
+// ParseRecurrence parses a rule like "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10".
+// A leading "RRULE:" is allowed.
+func ParseRecurrence(rule string) (Recurrence, error) {
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+
+	r := Recurrence{Interval: 1}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" || seen[name] {
+			return Recurrence{}, ErrInvalidRecurrence
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			r.Frequency = Frequency(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err == nil && r.Count < 1 {
+				err = ErrInvalidRecurrence
+			}
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		default:
+			err = ErrInvalidRecurrence
+		}
+
+		if err != nil {
+			return Recurrence{}, ErrInvalidRecurrence
+		}
+	}
+
+	if err := r.Validate(); err != nil {
+		return Recurrence{}, err
+	}
+
+	return r, nil
+}
+
+// MustParseRecurrence is like ParseRecurrence but panics if the rule cannot be parsed.
+func MustParseRecurrence(rule string) Recurrence {
+	r, err := ParseRecurrence(rule)
+	if err != nil {
+		panic(fmt.Sprintf("task: ParseRecurrence(%q): %v", rule, err))
+	}
+
+	return r
+}
+
+// Validate checks that the rule is complete and consistent.
+func (r Recurrence) Validate() error {
+	switch r.Frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+	case FrequencyYearly:
+		if len(r.ByDay) > 0 || len(r.ByMonthDay) > 0 {
+			return ErrInvalidRecurrence
+		}
+	default:
+		return ErrInvalidRecurrence
+	}
+
+	if r.Interval < 1 || r.Interval > MaxRecurrenceInterval {
+		return ErrInvalidRecurrence
+	}
+
+	if r.Count < 0 || (r.Count > 0 && r.Until != nil) {
+		return ErrInvalidRecurrence
+	}
+
+	for _, d := range r.ByMonthDay {
+		if d == 0 || d < -31 || d > 31 {
+			return ErrInvalidRecurrence
+		}
+	}
+
+	return nil
+}
+
+// String formats the rule in its canonical form.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			days = append(days, strings.ToUpper(d.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence after start, the current occurrence of the rule.
+// It reports false when the series is over.
+func (r Recurrence) Next(start time.Time) (time.Time, bool) {
+	occurrences := r.Occurrences(start, 1)
+	if len(occurrences) == 0 {
+		return time.Time{}, false
+	}
+
+	return occurrences[0], true
+}
+
+// Occurrences returns at most n occurrences following start, the current occurrence of the rule.
+// The occurrences keep the clock time and location of start.
+func (r Recurrence) Occurrences(start time.Time, n int) []time.Time {
+	limit := n
+	if r.Count > 0 && r.Count-1 < limit {
+		limit = r.Count - 1
+	}
+
+	var occurrences []time.Time
+	for period := 0; period < maxRecurrencePeriods && len(occurrences) < limit; period++ {
+		for _, candidate := range r.expand(start, period) {
+			if !candidate.After(start) {
+				continue
+			}
+
+			if r.Until != nil && candidate.After(*r.Until) {
+				return occurrences
+			}
+
+			occurrences = append(occurrences, candidate)
+			if len(occurrences) == limit {
+				break
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// following returns the rule of the occurrence after the current one.
+func (r Recurrence) following() Recurrence {
+	next := r
+	if next.Count > 0 {
+		next.Count--
+	}
+
+	return next
+}
+
+// expand returns the sorted candidate occurrences of the given period since start.
+func (r Recurrence) expand(start time.Time, period int) []time.Time {
+	y, m, d := start.Date()
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	}
+
+	var candidates []time.Time
+	switch r.Frequency {
+	case FrequencyDaily:
+		candidates = []time.Time{day(y, m, d+period*r.Interval)}
+	case FrequencyWeekly:
+		monday := d - mondayOffset(start.Weekday()) + period*r.Interval*7
+		if len(r.ByDay) == 0 {
+			candidates = []time.Time{day(y, m, monday+mondayOffset(start.Weekday()))}
+		}
+		for _, wd := range r.ByDay {
+			candidates = append(candidates, day(y, m, monday+mondayOffset(wd)))
+		}
+	case FrequencyMonthly:
+		first := time.Date(y, m+time.Month(period*r.Interval), 1, 0, 0, 0, 0, start.Location())
+		days := daysIn(first.Year(), first.Month())
+		switch {
+		case len(r.ByMonthDay) > 0:
+			for _, md := range r.ByMonthDay {
+				if md < 0 {
+					md = days + md + 1
+				}
+				if md >= 1 && md <= days {
+					candidates = append(candidates, day(first.Year(), first.Month(), md))
+				}
+			}
+		case len(r.ByDay) > 0:
+			for md := 1; md <= days; md++ {
+				candidates = append(candidates, day(first.Year(), first.Month(), md))
+			}
+		case d <= days:
+			candidates = []time.Time{day(first.Year(), first.Month(), d)}
+		}
+	case FrequencyYearly:
+		year := y + period*r.Interval
+		if d <= daysIn(year, m) {
+			candidates = []time.Time{day(year, m, d)}
+		}
+	}
+
+	candidates = slices.DeleteFunc(candidates, func(c time.Time) bool {
+		return !r.matches(c)
+	})
+	slices.SortFunc(candidates, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+
+	return slices.CompactFunc(candidates, time.Time.Equal)
+}
+
+// matches reports whether the day passes the BYDAY and BYMONTHDAY parts of the rule.
+func (r Recurrence) matches(t time.Time) bool {
+	if len(r.ByDay) > 0 && !slices.Contains(r.ByDay, t.Weekday()) {
+		return false
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := daysIn(t.Year(), t.Month())
+		return slices.ContainsFunc(r.ByMonthDay, func(md int) bool {
+			return md == t.Day() || md == t.Day()-days-1
+		})
+	}
+
+	return true
+}
+
+func parseByDay(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, code := range strings.Split(value, ",") {
+		d, ok := weekdayCodes[code]
+		if !ok {
+			return nil, ErrInvalidRecurrence
+		}
+
+		days = append(days, d)
+	}
+
+	slices.SortFunc(days, func(a, b time.Weekday) int {
+		return mondayOffset(a) - mondayOffset(b)
+	})
+
+	return slices.Compact(days), nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+	for _, s := range strings.Split(value, ",") {
+		d, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+
+		days = append(days, d)
+	}
+
+	slices.Sort(days)
+
+	return slices.Compact(days), nil
+}
+
+// parseUntil accepts a UTC date-time or a date, which includes the whole day.
+func parseUntil(value string) (*time.Time, error) {
+	until, err := time.Parse(untilLayout, value)
+	if err != nil {
+		until, err = time.Parse(untilDateLayout, value)
+		if err != nil {
+			return nil, err
+		}
+		until = until.Add(24*time.Hour - time.Second)
+	}
+
+	return &until, nil
+}
+
+// mondayOffset returns the number of days from Monday to the week day.
+func mondayOffset(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
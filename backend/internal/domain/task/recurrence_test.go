@@ -0,0 +1,191 @@
+package task_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	type testCase struct {
+		name    string
+		rule    string
+		want    string
+		wantErr error
+	}
+
+	tests := []testCase{
+		{name: "Daily", rule: "FREQ=DAILY", want: "FREQ=DAILY", wantErr: nil},
+		{name: "Prefix and case", rule: "RRULE:freq=weekly;byday=fr,mo,mo", want: "FREQ=WEEKLY;BYDAY=MO,FR", wantErr: nil},
+		{name: "All parts", rule: "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=15,-1;COUNT=6", want: "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=-1,15;COUNT=6", wantErr: nil},
+		{name: "Until date", rule: "FREQ=DAILY;UNTIL=20261231", want: "FREQ=DAILY;UNTIL=20261231T235959Z", wantErr: nil},
+		{name: "Empty", rule: "", want: "", wantErr: task.ErrInvalidRecurrence},
+		{name: "No frequency", rule: "INTERVAL=2", want: "", wantErr: task.ErrInvalidRecurrence},
+		{name: "Unknown frequency", rule: "FREQ=HOURLY", want: "", wantErr: task.ErrInvalidRecurrence},
+		{name: "Unknown part", rule: "FREQ=DAILY;BYHOUR=9", want: "", wantErr: task.ErrInvalidRecurrence},
+		{name: "Repeated part", rule: "FREQ=DAILY;FREQ=WEEKLY", want: "", wantErr: task.ErrInvalidRecurrence},
+		{name: "Zero interval", rule: "FREQ=DAILY;INTERVAL=0", want: "", wantErr: task.ErrInvalidRecurrence},
+		{name: "Ordinal day", rule: "FREQ=MONTHLY;BYDAY=1MO", want: "", wantErr: task.ErrInvalidRecurrence},
+		{name: "Zero month day", rule: "FREQ=MONTHLY;BYMONTHDAY=0", want: "", wantErr: task.ErrInvalidRecurrence},
+		{name: "Count and until", rule: "FREQ=DAILY;COUNT=2;UNTIL=20261231", want: "", wantErr: task.ErrInvalidRecurrence},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := task.ParseRecurrence(tt.rule)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseRecurrence() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseRecurrence() got = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceOccurrences(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 30, 0, 0, time.UTC)
+	}
+
+	type testCase struct {
+		name  string
+		rule  string
+		start time.Time
+		n     int
+		want  []time.Time
+	}
+
+	tests := []testCase{
+		{
+			name:  "Every other day",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: day(2026, time.January, 5),
+			n:     3,
+			want:  []time.Time{day(2026, time.January, 7), day(2026, time.January, 9), day(2026, time.January, 11)},
+		},
+		{
+			name:  "Weekdays",
+			rule:  "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			start: day(2026, time.January, 9),
+			n:     2,
+			want:  []time.Time{day(2026, time.January, 12), day(2026, time.January, 13)},
+		},
+		{
+			name:  "Several days a week",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			start: day(2026, time.January, 5),
+			n:     4,
+			want:  []time.Time{day(2026, time.January, 7), day(2026, time.January, 9), day(2026, time.January, 12), day(2026, time.January, 14)},
+		},
+		{
+			name:  "Every other week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			start: day(2026, time.January, 5),
+			n:     2,
+			want:  []time.Time{day(2026, time.January, 6), day(2026, time.January, 20)},
+		},
+		{
+			name:  "Last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: day(2026, time.January, 31),
+			n:     3,
+			want:  []time.Time{day(2026, time.February, 28), day(2026, time.March, 31), day(2026, time.April, 30)},
+		},
+		{
+			name:  "Months without the day are skipped",
+			rule:  "FREQ=MONTHLY",
+			start: day(2026, time.January, 31),
+			n:     2,
+			want:  []time.Time{day(2026, time.March, 31), day(2026, time.May, 31)},
+		},
+		{
+			name:  "Leap day",
+			rule:  "FREQ=YEARLY",
+			start: day(2024, time.February, 29),
+			n:     1,
+			want:  []time.Time{day(2028, time.February, 29)},
+		},
+		{
+			name:  "Count includes the current occurrence",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: day(2026, time.January, 5),
+			n:     5,
+			want:  []time.Time{day(2026, time.January, 6), day(2026, time.January, 7)},
+		},
+		{
+			name:  "Until",
+			rule:  "FREQ=DAILY;UNTIL=20260107",
+			start: day(2026, time.January, 5),
+			n:     5,
+			want:  []time.Time{day(2026, time.January, 6), day(2026, time.January, 7)},
+		},
+		{
+			name:  "Never matching",
+			rule:  "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=30",
+			start: day(2026, time.February, 1),
+			n:     1,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := task.MustParseRecurrence(tt.rule).Occurrences(tt.start, tt.n)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Occurrences() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskNextOccurrence(t *testing.T) {
+	dueAt := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+
+	if _, err := task.NewTask("test", uuid.New(), task.WithRecurrence(task.MustParseRecurrence("FREQ=DAILY"))); !errors.Is(err, task.ErrRecurrenceWithoutDueDate) {
+		t.Errorf("NewTask() error = %v, wantErr %v", err, task.ErrRecurrenceWithoutDueDate)
+	}
+
+	ti, err := task.NewTask(
+		"test",
+		uuid.New(),
+		task.WithDueDate(dueAt),
+		task.WithRecurrence(task.MustParseRecurrence("FREQ=WEEKLY;COUNT=2")),
+		task.WithTags("chores"),
+	)
+	if err != nil {
+		t.Fatalf("NewTask() error = %v", err)
+	}
+
+	next, ok := ti.NextOccurrence()
+	if !ok {
+		t.Fatal("NextOccurrence() reported no next occurrence")
+	}
+
+	if next.ID == ti.ID || next.Text != ti.Text || next.UserID != ti.UserID || !reflect.DeepEqual(next.Tags, ti.Tags) {
+		t.Errorf("NextOccurrence() got = %v, want a copy of %v", next, ti)
+	}
+
+	if want := dueAt.AddDate(0, 0, 7); next.DueAt == nil || !next.DueAt.Equal(want) {
+		t.Errorf("NextOccurrence() due at = %v, want %v", next.DueAt, want)
+	}
+
+	if next.Recurrence == nil || next.Recurrence.Count != 1 {
+		t.Errorf("NextOccurrence() recurrence = %v, want the last occurrence", next.Recurrence)
+	}
+
+	if _, ok := next.NextOccurrence(); ok {
+		t.Error("NextOccurrence() reported an occurrence past the count")
+	}
+
+	ti.ClearDueDate()
+	if ti.Recurrence != nil {
+		t.Errorf("ClearDueDate() recurrence = %v, want nil", ti.Recurrence)
+	}
+}
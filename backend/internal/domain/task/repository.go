@@ -3,6 +3,7 @@ package task
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,10 +15,45 @@ var (
 	ErrFailedDeleteTask = errors.New("failed to delete the task")
 )
 
+// Repository stores tasks. GetByID and GetChildren return tasks in the trash as well,
+// the other queries leave them out unless stated otherwise.
 type Repository interface {
 	GetByID(context.Context, uuid.UUID) (Task, error)
+	// GetAllByUserID returns the user's tasks in the manual order, see ComparePosition.
 	GetAllByUserID(context.Context, uuid.UUID) ([]Task, error)
+	// GetListing returns all the tasks of a listing in the manual order, see ComparePosition: the user's
+	// personal tasks when listId is nil, the tasks of the list otherwise. Unlike Find it is not paged.
+	GetListing(ctx context.Context, userId uuid.UUID, listId *uuid.UUID) ([]Task, error)
+	// GetOpenByUserID returns the user's not completed tasks, see ComparePriority for the order.
+	GetOpenByUserID(context.Context, uuid.UUID) ([]Task, error)
+	// GetTrashByUserID returns the user's tasks in the trash, the most recently deleted first.
+	GetTrashByUserID(context.Context, uuid.UUID) ([]Task, error)
+	// Find returns a page of the tasks of a listing matching the query.
+	Find(context.Context, Query) (Page, error)
+	// Search returns the user's tasks matching any of the query terms, most relevant first.
+	// The Highlights of the results are left empty.
+	Search(context.Context, SearchQuery) ([]SearchResult, error)
+	// GetChildren returns the direct subtasks of a task ordered by creation time, trashed ones included.
+	GetChildren(context.Context, uuid.UUID) ([]Task, error)
+	// GetAllDueByUserID returns the user's not completed tasks due in [from, to) ordered by due date.
+	// A zero from means no lower bound.
+	GetAllDueByUserID(ctx context.Context, userId uuid.UUID, from time.Time, to time.Time) ([]Task, error)
+	// GetTagCountsByUserID returns the tags of the user's personal tasks with the number of tasks per tag,
+	// most used first. The tasks of the lists are left out, the user may no longer have access to them.
+	GetTagCountsByUserID(context.Context, uuid.UUID) ([]TagCount, error)
+	// RenameTag replaces the tag with another one on all the user's personal tasks at once, trashed ones included,
+	// and returns the number of tasks changed. The versions of the changed tasks are incremented.
+	RenameTag(ctx context.Context, userId uuid.UUID, from string, to string) (int, error)
 	Save(context.Context, Task) error
+	// Update saves the task if the stored task is at the same version and increments the stored version.
+	// It returns ErrVersionMismatch if the task has been updated since it was read.
 	Update(context.Context, Task) error
+	// Delete removes the task permanently.
 	Delete(context.Context, uuid.UUID) error
+	// DeleteAllByListID permanently removes the tasks of the list, trashed ones included,
+	// and returns their number.
+	DeleteAllByListID(ctx context.Context, listId uuid.UUID) (int, error)
+	// PurgeTrash permanently removes the tasks moved to the trash before the given time
+	// and returns their number.
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
 }
@@ -0,0 +1,169 @@
+package task
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultSearchLimit is the number of results a search returns when no limit is given.
+	DefaultSearchLimit = 20
+)
+
+var (
+	ErrInvalidSearchText = errors.New("search text is invalid")
+)
+
+// SearchQuery is a full-text search over the tasks of a user. Tasks in the trash are not searched.
+type SearchQuery struct {
+	UserID uuid.UUID
+	Text   string
+	Limit  int
+}
+
+// SearchResult is a task found by a search with its relevance score.
+type SearchResult struct {
+	Task  Task
+	Score float64
+	// Highlights is the task text split into fragments, the ones matching a search term are marked.
+	Highlights []Fragment
+}
+
+// Fragment is a piece of a highlighted text.
+type Fragment struct {
+	Text  string
+	Match bool
+}
+
+// NewSearchQuery creates a SearchQuery returning DefaultSearchLimit results at most.
+func NewSearchQuery(userId uuid.UUID, text string) SearchQuery {
+	return SearchQuery{
+		UserID: userId,
+		Text:   text,
+		Limit:  DefaultSearchLimit,
+	}
+}
+
+// Validate checks that the query is well formed.
+func (q SearchQuery) Validate() error {
+	if q.UserID == uuid.Nil {
+		return ErrInvalidUserID
+	}
+
+	if len(q.Terms()) == 0 {
+		return ErrInvalidSearchText
+	}
+
+	if q.Limit <= 0 || q.Limit > MaxQueryLimit {
+		return ErrInvalidLimit
+	}
+
+	return nil
+}
+
+// Terms returns the distinct search terms of the query.
+func (q SearchQuery) Terms() []string {
+	var terms []string
+
+	seen := make(map[string]bool)
+	for _, token := range Tokenize(q.Text) {
+		if !seen[token] {
+			seen[token] = true
+			terms = append(terms, token)
+		}
+	}
+
+	return terms
+}
+
+// Score returns the relevance of the task for the query, zero if no term matches.
+// It follows the MongoDB text score for a single field: repeated occurrences of a term
+// count less and less, and a term weighs more in a shorter text.
+func (q SearchQuery) Score(t Task) float64 {
+	if t.IsTrashed() {
+		return 0
+	}
+
+	tokens := Tokenize(t.Text)
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int)
+	for _, token := range tokens {
+		counts[token]++
+	}
+
+	var score float64
+	for _, term := range q.Terms() {
+		n := counts[term]
+		if n == 0 {
+			continue
+		}
+
+		freq := 2 - math.Pow(0.5, float64(n-1))
+		score += freq * (0.5*float64(n)/float64(len(tokens)) + 0.5)
+	}
+
+	return score
+}
+
+// Tokenize splits the text into lower case words.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isSeparator)
+}
+
+// Highlight splits the text into fragments marking the words that are among the terms.
+func Highlight(text string, terms []string) []Fragment {
+	match := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		match[term] = true
+	}
+
+	var fragments []Fragment
+	add := func(s string, m bool) {
+		if s == "" {
+			return
+		}
+
+		if n := len(fragments); n > 0 && !m && !fragments[n-1].Match {
+			fragments[n-1].Text += s
+			return
+		}
+
+		fragments = append(fragments, Fragment{Text: s, Match: m})
+	}
+
+	start := 0
+	inWord := false
+	for i, r := range text {
+		if sep := isSeparator(r); sep == inWord {
+			if inWord {
+				word := text[start:i]
+				add(word, match[strings.ToLower(word)])
+			} else {
+				add(text[start:i], false)
+			}
+
+			start = i
+			inWord = !sep
+		}
+	}
+
+	if inWord {
+		word := text[start:]
+		add(word, match[strings.ToLower(word)])
+	} else {
+		add(text[start:], false)
+	}
+
+	return fragments
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
@@ -0,0 +1,73 @@
+package task_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+func TestSearchQueryValidate(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	type testCase struct {
+		name    string
+		query   task.SearchQuery
+		wantErr error
+	}
+
+	tests := []testCase{
+		{name: "Success", query: task.NewSearchQuery(userId, "milk"), wantErr: nil},
+		{name: "Empty userId", query: task.NewSearchQuery(uuid.Nil, "milk"), wantErr: task.ErrInvalidUserID},
+		{name: "No terms", query: task.NewSearchQuery(userId, " ,.! "), wantErr: task.ErrInvalidSearchText},
+		{name: "Zero limit", query: task.SearchQuery{UserID: userId, Text: "milk"}, wantErr: task.ErrInvalidLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.query.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSearchQueryScore(t *testing.T) {
+	q := task.NewSearchQuery(uuid.New(), "Buy MILK, milk and bread")
+
+	if want := []string{"buy", "milk", "and", "bread"}; !reflect.DeepEqual(q.Terms(), want) {
+		t.Errorf("Terms() got = %v, want %v", q.Terms(), want)
+	}
+
+	none := q.Score(task.Task{Text: "call mom"})
+	one := q.Score(task.Task{Text: "get some milk from the shop"})
+	short := q.Score(task.Task{Text: "milk"})
+	two := q.Score(task.Task{Text: "bread and milk"})
+
+	if none != 0 {
+		t.Errorf("Score() got = %v, want %v", none, 0)
+	}
+
+	if !(one > 0 && short > one && two > short) {
+		t.Errorf("Score() got = %v, %v, %v, want increasing", one, short, two)
+	}
+}
+
+func TestSearchHighlight(t *testing.T) {
+	got := task.Highlight("Buy milk, then MILK!", []string{"milk"})
+	want := []task.Fragment{
+		{Text: "Buy ", Match: false},
+		{Text: "milk", Match: true},
+		{Text: ", then ", Match: false},
+		{Text: "MILK", Match: true},
+		{Text: "!", Match: false},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Highlight() got = %v, want %v", got, want)
+	}
+}
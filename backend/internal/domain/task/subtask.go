@@ -0,0 +1,33 @@
+package task
+
+import (
+	"errors"
+)
+
+const (
+	// MaxDepth is the maximum number of levels in a task tree, top-level tasks included.
+	MaxDepth = 5
+)
+
+var (
+	ErrMaxDepthExceeded = errors.New("the task tree is too deep")
+	ErrParentCycle      = errors.New("a task cannot be a subtask of its own subtask")
+)
+
+// Progress is the completion roll-up of a task's subtasks.
+type Progress struct {
+	Completed int
+	Total     int
+}
+
+// NewProgress counts the completed tasks among the given subtasks.
+func NewProgress(subtasks []Task) Progress {
+	p := Progress{Total: len(subtasks)}
+	for _, t := range subtasks {
+		if t.Completed {
+			p.Completed++
+		}
+	}
+
+	return p
+}
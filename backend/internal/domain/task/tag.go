@@ -0,0 +1,119 @@
+package task
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TagMatch tells how a listing filtered by several tags matches them.
+type TagMatch string
+
+const (
+	// TagMatchAny selects the tasks having at least one of the tags.
+	TagMatchAny TagMatch = "any"
+	// TagMatchAll selects the tasks having every one of the tags.
+	TagMatchAll TagMatch = "all"
+
+	// MaxTags is the largest number of tags a task can have.
+	MaxTags = 10
+	// MaxTagLength is the longest tag in characters.
+	MaxTagLength = 32
+)
+
+var (
+	ErrInvalidTag      = errors.New("tag is invalid")
+	ErrTooManyTags     = errors.New("too many tags")
+	ErrInvalidTagMatch = errors.New("tag match is invalid")
+)
+
+// TagCount is a tag and the number of tasks it is attached to.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// NormalizeTag returns the canonical form of a tag: lower case,
+// without surrounding spaces and with inner whitespace replaced by single dashes.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+
+	if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+		return "", ErrInvalidTag
+	}
+
+	return tag, nil
+}
+
+// NormalizeTags normalizes the tags and returns them sorted and without duplicates.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		normalized = append(normalized, tag)
+	}
+
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized), nil
+}
+
+// WithTags attaches the tags to a new Task.
+func WithTags(tags ...string) Option {
+	return func(t *Task) error {
+		return t.SetTags(tags)
+	}
+}
+
+// SetTags replaces the tags of the task. The tags are normalized, at most MaxTags are allowed.
+func (t *Task) SetTags(tags []string) error {
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	if len(normalized) > MaxTags {
+		return ErrTooManyTags
+	}
+
+	if len(normalized) == 0 {
+		normalized = nil
+	}
+
+	t.Tags = normalized
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+
+	return nil
+}
+
+// RenameTag replaces the tag with another one, merging them if the task has both.
+// It reports whether the task had the tag.
+func (t *Task) RenameTag(from, to string) bool {
+	if !t.HasTag(from) {
+		return false
+	}
+
+	tags := slices.DeleteFunc(slices.Clone(t.Tags), func(tag string) bool { return tag == from })
+	tags = append(tags, to)
+	slices.Sort(tags)
+
+	t.Tags = slices.Compact(tags)
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+
+	return true
+}
+
+// HasTag reports whether the task has the normalized tag.
+func (t Task) HasTag(tag string) bool {
+	_, found := slices.BinarySearch(t.Tags, tag)
+
+	return found
+}
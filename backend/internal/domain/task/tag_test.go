@@ -0,0 +1,119 @@
+package task_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+func TestTaskNormalizeTag(t *testing.T) {
+	type testCase struct {
+		name    string
+		tag     string
+		want    string
+		wantErr error
+	}
+
+	tests := []testCase{
+		{name: "Lower case", tag: "Work", want: "work", wantErr: nil},
+		{name: "Spaces", tag: "  high   priority ", want: "high-priority", wantErr: nil},
+		{name: "Empty", tag: "   ", want: "", wantErr: task.ErrInvalidTag},
+		{name: "Too long", tag: strings.Repeat("a", task.MaxTagLength+1), want: "", wantErr: task.ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := task.NormalizeTag(tt.tag)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NormalizeTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeTag() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskSetTags(t *testing.T) {
+	ti, err := task.NewTask("test", uuid.New(), task.WithTags("Work", "home", "work "))
+	if err != nil {
+		t.Fatalf("NewTask() error = %v", err)
+	}
+
+	if want := []string{"home", "work"}; !reflect.DeepEqual(ti.Tags, want) {
+		t.Errorf("WithTags() got = %v, want %v", ti.Tags, want)
+	}
+
+	tooMany := make([]string, 0, task.MaxTags+1)
+	for i := 0; i <= task.MaxTags; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("tag%d", i))
+	}
+
+	if err := ti.SetTags(tooMany); !errors.Is(err, task.ErrTooManyTags) {
+		t.Errorf("SetTags() error = %v, wantErr %v", err, task.ErrTooManyTags)
+	}
+
+	if err := ti.SetTags(nil); err != nil || ti.Tags != nil {
+		t.Errorf("SetTags() got = %v, error = %v", ti.Tags, err)
+	}
+}
+
+func TestTaskRenameTag(t *testing.T) {
+	ti, err := task.NewTask("test", uuid.New(), task.WithTags("home", "job", "work"))
+	if err != nil {
+		t.Fatalf("NewTask() error = %v", err)
+	}
+
+	if ti.RenameTag("errands", "chores") {
+		t.Error("RenameTag() reported a tag the task does not have")
+	}
+
+	if !ti.RenameTag("job", "work") {
+		t.Error("RenameTag() did not find the tag")
+	}
+
+	if want := []string{"home", "work"}; !reflect.DeepEqual(ti.Tags, want) {
+		t.Errorf("RenameTag() got = %v, want %v", ti.Tags, want)
+	}
+}
+
+func TestQueryMatchesTags(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	ti, err := task.NewTask("test", userId, task.WithTags("home", "work"))
+	if err != nil {
+		t.Fatalf("NewTask() error = %v", err)
+	}
+
+	type testCase struct {
+		name  string
+		tags  []string
+		match task.TagMatch
+		want  bool
+	}
+
+	tests := []testCase{
+		{name: "Any of one", tags: []string{"work", "urgent"}, match: task.TagMatchAny, want: true},
+		{name: "Any of none", tags: []string{"urgent"}, match: task.TagMatchAny, want: false},
+		{name: "All present", tags: []string{"home", "work"}, match: task.TagMatchAll, want: true},
+		{name: "All but one", tags: []string{"work", "urgent"}, match: task.TagMatchAll, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			q := task.NewQuery(userId)
+			q.Tags = tt.tags
+			q.TagMatch = tt.match
+			if got := q.Matches(ti); got != tt.want {
+				t.Errorf("Matches() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
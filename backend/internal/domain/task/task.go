@@ -2,14 +2,32 @@ package task
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// MaxReminderOffset is the longest time before the due date a reminder can be set to.
+	MaxReminderOffset = 30 * 24 * time.Hour
+
+	// AnyVersion passed to CheckVersion matches every version of a task.
+	AnyVersion int64 = -1
+)
+
 var (
-	ErrInvalidText   = errors.New("text is invalid")
-	ErrInvalidUserID = errors.New("user id is invalid")
+	ErrInvalidID              = errors.New("id is invalid")
+	ErrInvalidText            = errors.New("text is invalid")
+	ErrInvalidUserID          = errors.New("user id is invalid")
+	ErrInvalidDueDate         = errors.New("due date is invalid")
+	ErrInvalidReminderOffset  = errors.New("reminder offset is invalid")
+	ErrReminderWithoutDueDate = errors.New("reminder requires a due date")
+	ErrInvalidParent          = errors.New("parent task is invalid")
+	ErrInvalidListID          = errors.New("list id is invalid")
+	ErrTaskInTrash            = errors.New("the task is in the trash")
+	ErrTaskNotInTrash         = errors.New("the task is not in the trash")
+	ErrVersionMismatch        = errors.New("the task has been changed since it was read")
 )
 
 // Task is a representation of a task entity.
@@ -18,12 +36,104 @@ type Task struct {
 	Text      string
 	Completed bool
 	UserID    uuid.UUID
+	// ListID is nil for a personal task of UserID.
+	ListID *uuid.UUID
+	// ParentID is nil for a top-level task.
+	ParentID *uuid.UUID
+	// DueAt is nil when the task has no deadline.
+	DueAt *time.Time
+	// ReminderOffset is how long before DueAt the user wants to be reminded, nil for no reminder.
+	ReminderOffset *time.Duration
+	// Recurrence is nil for a one-off task. DueAt is the current occurrence of the rule.
+	Recurrence *Recurrence
+	// Tags are normalized, sorted and unique.
+	Tags []string
+	// DeletedAt is set while the task is in the trash.
+	DeletedAt *time.Time
+	// Position is the rank of the task in the manual order, lower first.
+	Position float64
+	Priority Priority
+	// Eisenhower is nil when the user has not classified the task, see Quadrant.
+	Eisenhower *Eisenhower
+	// Version is incremented by the repository on every update.
+	Version   int64
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// raised is the change made to the task since it was created or read, see PullEvent.
+	raised EventType
+}
+
+// Option sets an optional attribute of a new Task.
+type Option func(*Task) error
+
+// WithID gives a new Task the id it had before, e.g. in an export, instead of a new one.
+func WithID(id uuid.UUID) Option {
+	return func(t *Task) error {
+		if id == uuid.Nil {
+			return ErrInvalidID
+		}
+
+		t.ID = id
+
+		return nil
+	}
+}
+
+// WithDueDate sets the due date of a new Task.
+func WithDueDate(dueAt time.Time) Option {
+	return func(t *Task) error {
+		return t.SetDueDate(dueAt)
+	}
+}
+
+// WithReminder sets the reminder offset of a new Task. It requires WithDueDate.
+func WithReminder(offset time.Duration) Option {
+	return func(t *Task) error {
+		if err := validateReminderOffset(offset); err != nil {
+			return err
+		}
+
+		t.ReminderOffset = &offset
+
+		return nil
+	}
+}
+
+// WithRecurrence makes a new Task repeat. It requires WithDueDate.
+func WithRecurrence(r Recurrence) Option {
+	return func(t *Task) error {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+
+		t.Recurrence = &r
+
+		return nil
+	}
+}
+
+// WithParent makes a new Task a subtask of the parent task.
+func WithParent(parentId uuid.UUID) Option {
+	return func(t *Task) error {
+		return t.SetParent(parentId)
+	}
+}
+
+// WithList puts a new Task into a shared list.
+func WithList(listId uuid.UUID) Option {
+	return func(t *Task) error {
+		if listId == uuid.Nil {
+			return ErrInvalidListID
+		}
+
+		t.ListID = &listId
+
+		return nil
+	}
 }
 
 // NewTask creates and returns a new Task.
-func NewTask(text string, userId uuid.UUID) (Task, error) {
+func NewTask(text string, userId uuid.UUID, opts ...Option) (Task, error) {
 	if text == "" {
 		return Task{}, ErrInvalidText
 	}
@@ -34,14 +144,35 @@ func NewTask(text string, userId uuid.UUID) (Task, error) {
 
 	currentTime := time.Now()
 
-	return Task{
+	t := Task{
 		ID:        uuid.New(),
 		Text:      text,
 		Completed: false,
 		UserID:    userId,
+		Position:  initialPosition(currentTime),
+		Version:   1,
 		CreatedAt: currentTime,
 		UpdatedAt: currentTime,
-	}, nil
+	}
+
+	for _, opt := range opts {
+		if err := opt(&t); err != nil {
+			return Task{}, err
+		}
+	}
+
+	if t.ReminderOffset != nil && t.DueAt == nil {
+		return Task{}, ErrReminderWithoutDueDate
+	}
+
+	if t.Recurrence != nil && t.DueAt == nil {
+		return Task{}, ErrRecurrenceWithoutDueDate
+	}
+
+	t.UpdatedAt = currentTime
+	t.raise(EventCreated)
+
+	return t, nil
 }
 
 // SetText sets the Text field.
@@ -52,6 +183,7 @@ func (t *Task) SetText(text string) error {
 
 	t.Text = text
 	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
 
 	return nil
 }
@@ -60,10 +192,211 @@ func (t *Task) SetText(text string) error {
 func (t *Task) MarkCompleted() {
 	t.Completed = true
 	t.UpdatedAt = time.Now()
+	t.raise(EventCompleted)
 }
 
 // MarkNotCompleted marks task a NOT completed.
 func (t *Task) MarkNotCompleted() {
 	t.Completed = false
 	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+}
+
+// SetParent sets the ParentID field. A task cannot be its own parent.
+func (t *Task) SetParent(parentId uuid.UUID) error {
+	if parentId == uuid.Nil || parentId == t.ID {
+		return ErrInvalidParent
+	}
+
+	t.ParentID = &parentId
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+
+	return nil
+}
+
+// ClearParent makes the task a top-level task.
+func (t *Task) ClearParent() {
+	t.ParentID = nil
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+}
+
+// SetDueDate sets the DueAt field.
+func (t *Task) SetDueDate(dueAt time.Time) error {
+	if dueAt.IsZero() {
+		return ErrInvalidDueDate
+	}
+
+	t.DueAt = &dueAt
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+
+	return nil
+}
+
+// ClearDueDate removes the due date together with the reminder and the recurrence.
+func (t *Task) ClearDueDate() {
+	t.DueAt = nil
+	t.ReminderOffset = nil
+	t.Recurrence = nil
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+}
+
+// SetReminder sets the ReminderOffset field. The task must have a due date.
+func (t *Task) SetReminder(offset time.Duration) error {
+	if t.DueAt == nil {
+		return ErrReminderWithoutDueDate
+	}
+
+	if err := validateReminderOffset(offset); err != nil {
+		return err
+	}
+
+	t.ReminderOffset = &offset
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+
+	return nil
+}
+
+// ClearReminder removes the reminder.
+func (t *Task) ClearReminder() {
+	t.ReminderOffset = nil
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+}
+
+// SetRecurrence sets the Recurrence field. The task must have a due date.
+func (t *Task) SetRecurrence(r Recurrence) error {
+	if t.DueAt == nil {
+		return ErrRecurrenceWithoutDueDate
+	}
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	t.Recurrence = &r
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+
+	return nil
+}
+
+// ClearRecurrence makes the task a one-off task.
+func (t *Task) ClearRecurrence() {
+	t.Recurrence = nil
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+}
+
+// NextOccurrences returns at most n due dates of the occurrences following the current one.
+func (t *Task) NextOccurrences(n int) []time.Time {
+	if t.Recurrence == nil || t.DueAt == nil {
+		return nil
+	}
+
+	return t.Recurrence.Occurrences(*t.DueAt, n)
+}
+
+// NextOccurrence returns a new task for the occurrence following the current one.
+// It reports false for a one-off task or when the series is over.
+func (t *Task) NextOccurrence() (Task, bool) {
+	if t.Recurrence == nil || t.DueAt == nil {
+		return Task{}, false
+	}
+
+	dueAt, ok := t.Recurrence.Next(*t.DueAt)
+	if !ok {
+		return Task{}, false
+	}
+
+	currentTime := time.Now()
+	recurrence := t.Recurrence.following()
+
+	next := Task{
+		ID:             uuid.New(),
+		Text:           t.Text,
+		Completed:      false,
+		UserID:         t.UserID,
+		ListID:         t.ListID,
+		ParentID:       t.ParentID,
+		DueAt:          &dueAt,
+		ReminderOffset: t.ReminderOffset,
+		Recurrence:     &recurrence,
+		Tags:           slices.Clone(t.Tags),
+		Position:       initialPosition(currentTime),
+		Priority:       t.Priority,
+		Eisenhower:     t.Eisenhower,
+		Version:        1,
+		CreatedAt:      currentTime,
+		UpdatedAt:      currentTime,
+	}
+	next.raise(EventCreated)
+
+	return next, true
+}
+
+// ReminderAt returns the time the user should be reminded at, if a reminder is set.
+func (t *Task) ReminderAt() (time.Time, bool) {
+	if t.DueAt == nil || t.ReminderOffset == nil {
+		return time.Time{}, false
+	}
+
+	return t.DueAt.Add(-*t.ReminderOffset), true
+}
+
+// IsOverdue reports whether the task is not completed and its due date has passed.
+func (t *Task) IsOverdue(now time.Time) bool {
+	return !t.Completed && t.DueAt != nil && t.DueAt.Before(now)
+}
+
+// MoveToTrash marks the task as deleted at the given time.
+func (t *Task) MoveToTrash(at time.Time) error {
+	if t.DeletedAt != nil {
+		return ErrTaskInTrash
+	}
+
+	t.DeletedAt = &at
+	t.UpdatedAt = at
+	t.raise(EventDeleted)
+
+	return nil
+}
+
+// Restore takes the task out of the trash.
+func (t *Task) Restore() error {
+	if t.DeletedAt == nil {
+		return ErrTaskNotInTrash
+	}
+
+	t.DeletedAt = nil
+	t.UpdatedAt = time.Now()
+	t.raise(EventUpdated)
+
+	return nil
+}
+
+// IsTrashed reports whether the task is in the trash.
+func (t *Task) IsTrashed() bool {
+	return t.DeletedAt != nil
+}
+
+// CheckVersion returns ErrVersionMismatch unless the task is at the expected version or AnyVersion is expected.
+func (t *Task) CheckVersion(expected int64) error {
+	if expected != AnyVersion && expected != t.Version {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+func validateReminderOffset(offset time.Duration) error {
+	if offset < 0 || offset > MaxReminderOffset {
+		return ErrInvalidReminderOffset
+	}
+
+	return nil
 }
@@ -3,6 +3,7 @@ package task_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/internal/domain/task"
@@ -194,3 +195,152 @@ func TestTaskMarkNotCompleted(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskNewTaskWithDueDate(t *testing.T) {
+	dueAt := time.Now().Add(24 * time.Hour)
+
+	type testCase struct {
+		name    string
+		opts    []task.Option
+		wantErr error
+	}
+
+	tests := []testCase{
+		{
+			name:    "Success",
+			opts:    []task.Option{task.WithDueDate(dueAt), task.WithReminder(time.Hour)},
+			wantErr: nil,
+		},
+		{
+			name:    "Zero due date",
+			opts:    []task.Option{task.WithDueDate(time.Time{})},
+			wantErr: task.ErrInvalidDueDate,
+		},
+		{
+			name:    "Reminder without due date",
+			opts:    []task.Option{task.WithReminder(time.Hour)},
+			wantErr: task.ErrReminderWithoutDueDate,
+		},
+		{
+			name:    "Negative reminder offset",
+			opts:    []task.Option{task.WithDueDate(dueAt), task.WithReminder(-time.Hour)},
+			wantErr: task.ErrInvalidReminderOffset,
+		},
+		{
+			name:    "Reminder offset too long",
+			opts:    []task.Option{task.WithDueDate(dueAt), task.WithReminder(task.MaxReminderOffset + time.Hour)},
+			wantErr: task.ErrInvalidReminderOffset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := task.NewTask("test", uuid.New(), tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (got.DueAt == nil || !got.DueAt.Equal(dueAt)) {
+				t.Errorf("NewTask() DueAt = %v, want %v", got.DueAt, dueAt)
+			}
+			if err == nil && !got.CreatedAt.Equal(got.UpdatedAt) {
+				t.Errorf("NewTask() UpdatedAt = %v, want %v", got.UpdatedAt, got.CreatedAt)
+			}
+			if at, ok := got.ReminderAt(); err == nil && (!ok || !at.Equal(dueAt.Add(-time.Hour))) {
+				t.Errorf("NewTask() ReminderAt = %v, want %v", at, dueAt.Add(-time.Hour))
+			}
+		})
+	}
+}
+
+func TestTaskSetDueDate(t *testing.T) {
+	ti := task.Task{}
+
+	if err := ti.SetReminder(time.Hour); !errors.Is(err, task.ErrReminderWithoutDueDate) {
+		t.Errorf("SetReminder() error = %v, wantErr %v", err, task.ErrReminderWithoutDueDate)
+	}
+
+	if err := ti.SetDueDate(time.Time{}); !errors.Is(err, task.ErrInvalidDueDate) {
+		t.Errorf("SetDueDate() error = %v, wantErr %v", err, task.ErrInvalidDueDate)
+	}
+
+	dueAt := time.Now().Add(-time.Hour)
+	if err := ti.SetDueDate(dueAt); err != nil {
+		t.Errorf("SetDueDate() error = %v", err)
+	}
+
+	if err := ti.SetReminder(time.Minute); err != nil {
+		t.Errorf("SetReminder() error = %v", err)
+	}
+
+	if !ti.IsOverdue(time.Now()) {
+		t.Error("IsOverdue() = false, want true")
+	}
+
+	ti.MarkCompleted()
+	if ti.IsOverdue(time.Now()) {
+		t.Error("IsOverdue() a completed task must not be overdue")
+	}
+
+	ti.ClearDueDate()
+	if ti.DueAt != nil || ti.ReminderOffset != nil {
+		t.Errorf("ClearDueDate() DueAt = %v, ReminderOffset = %v, want nil", ti.DueAt, ti.ReminderOffset)
+	}
+
+	if ti.UpdatedAt.IsZero() {
+		t.Error("ClearDueDate() UpdatedAt is zero")
+	}
+}
+
+func TestTaskSetParent(t *testing.T) {
+	ti := task.Task{ID: uuid.New()}
+
+	if err := ti.SetParent(ti.ID); !errors.Is(err, task.ErrInvalidParent) {
+		t.Errorf("SetParent() error = %v, wantErr %v", err, task.ErrInvalidParent)
+	}
+
+	if err := ti.SetParent(uuid.Nil); !errors.Is(err, task.ErrInvalidParent) {
+		t.Errorf("SetParent() error = %v, wantErr %v", err, task.ErrInvalidParent)
+	}
+
+	parentId := uuid.New()
+	if err := ti.SetParent(parentId); err != nil {
+		t.Errorf("SetParent() error = %v", err)
+	}
+
+	if ti.ParentID == nil || *ti.ParentID != parentId {
+		t.Errorf("SetParent() ParentID = %v, want %v", ti.ParentID, parentId)
+	}
+
+	ti.ClearParent()
+	if ti.ParentID != nil {
+		t.Errorf("ClearParent() ParentID = %v, want nil", ti.ParentID)
+	}
+}
+
+func TestTaskNewTaskWithID(t *testing.T) {
+	id := uuid.New()
+
+	ti, err := task.NewTask("test", uuid.New(), task.WithID(id))
+	if err != nil {
+		t.Fatalf("NewTask() error = %v", err)
+	}
+	if ti.ID != id {
+		t.Errorf("NewTask() ID = %v, want %v", ti.ID, id)
+	}
+
+	if _, err := task.NewTask("test", uuid.New(), task.WithID(id), task.WithParent(id)); !errors.Is(err, task.ErrInvalidParent) {
+		t.Errorf("NewTask() with itself as parent error = %v, wantErr %v", err, task.ErrInvalidParent)
+	}
+
+	if _, err := task.NewTask("test", uuid.New(), task.WithID(uuid.Nil)); !errors.Is(err, task.ErrInvalidID) {
+		t.Errorf("NewTask() error = %v, wantErr %v", err, task.ErrInvalidID)
+	}
+}
+
+func TestTaskNewProgress(t *testing.T) {
+	got := task.NewProgress([]task.Task{{Completed: true}, {Completed: false}, {Completed: true}})
+	if got.Completed != 2 || got.Total != 3 {
+		t.Errorf("NewProgress() got = %v, want %v", got, task.Progress{Completed: 2, Total: 3})
+	}
+}
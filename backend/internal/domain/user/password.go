@@ -0,0 +1,365 @@
+package user
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrPasswordTooShort      = fmt.Errorf("%w: it is too short", ErrInvalidPassword)
+	ErrPasswordTooCommon     = fmt.Errorf("%w: it is too common", ErrInvalidPassword)
+	ErrPasswordContainsEmail = fmt.Errorf("%w: it contains the email", ErrInvalidPassword)
+	ErrInvalidHasher         = errors.New("password hasher is invalid")
+)
+
+// HashAlgorithm is the algorithm the passwords are hashed with.
+type HashAlgorithm string
+
+const (
+	HashBcrypt   HashAlgorithm = "bcrypt"
+	HashArgon2id HashAlgorithm = "argon2id"
+)
+
+const (
+	// The argon2id parameters recommended by OWASP, used unless others are given.
+	defaultArgon2Time    = 2
+	defaultArgon2Memory  = 19 * 1024
+	defaultArgon2Threads = 1
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	argon2Prefix     = "$argon2id$"
+
+	// The ranges of the argon2id parameters. Beyond the maximums a hash takes too long or too much memory
+	// to compute, and argon2 needs at least 8 KiB of memory per thread.
+	maxArgon2Time         = 100
+	maxArgon2Memory       = 4 * 1024 * 1024
+	minArgon2MemoryThread = 8
+	maxArgon2Threads      = math.MaxUint8
+	minArgon2KeyLength    = 16
+	maxArgon2KeyLength    = 1024
+)
+
+// Passwords is how the passwords chosen by the users are checked and hashed.
+// The zero Passwords accepts any non-empty password and hashes it with bcrypt at bcrypt.MinCost.
+type Passwords struct {
+	Policy PasswordPolicy
+	Hasher PasswordHasher
+}
+
+// Option changes how a User checks and hashes the password given to it.
+type Option func(*Passwords)
+
+// WithPasswords checks the password against the policy of p and hashes it with the hasher of p.
+func WithPasswords(p Passwords) Option {
+	return func(dst *Passwords) {
+		*dst = p
+	}
+}
+
+// PasswordPolicy decides which passwords the users may choose. The zero PasswordPolicy accepts any non-empty password.
+type PasswordPolicy struct {
+	// MinLength is the least number of characters of a password.
+	MinLength int
+	// ForbidEmail rejects the passwords containing the email of the user or its local part.
+	ForbidEmail bool
+	// common are the lowercase passwords which are too common or have been breached.
+	common map[string]struct{}
+}
+
+// NewPasswordPolicy creates a PasswordPolicy rejecting the passwords shorter than minLength,
+// the common ones whatever their case and, if forbidEmail is set, the ones containing the email.
+func NewPasswordPolicy(minLength int, common []string, forbidEmail bool) PasswordPolicy {
+	p := PasswordPolicy{
+		MinLength:   minLength,
+		ForbidEmail: forbidEmail,
+		common:      make(map[string]struct{}, len(common)),
+	}
+
+	for _, password := range common {
+		p.common[strings.ToLower(password)] = struct{}{}
+	}
+
+	return p
+}
+
+// Validate returns an error wrapping ErrInvalidPassword if the user with the email may not choose the password.
+func (p PasswordPolicy) Validate(password, email string) error {
+	if password == "" {
+		return ErrInvalidPassword
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return ErrPasswordTooShort
+	}
+
+	lowerPassword := strings.ToLower(password)
+
+	if _, ok := p.common[lowerPassword]; ok {
+		return ErrPasswordTooCommon
+	}
+
+	if !p.ForbidEmail {
+		return nil
+	}
+
+	lowerEmail := strings.ToLower(email)
+	if lowerEmail != "" && strings.Contains(lowerPassword, lowerEmail) {
+		return ErrPasswordContainsEmail
+	}
+
+	// Short local parts would rule out too many passwords.
+	local, _, _ := strings.Cut(lowerEmail, "@")
+	if utf8.RuneCountInString(local) >= 3 && strings.Contains(lowerPassword, local) {
+		return ErrPasswordContainsEmail
+	}
+
+	return nil
+}
+
+// ReadCommonPasswords reads a list of common passwords, one per line. The empty lines and the lines
+// starting with # are skipped.
+func ReadCommonPasswords(r io.Reader) ([]string, error) {
+	var passwords []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		passwords = append(passwords, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return passwords, nil
+}
+
+// PasswordHasher hashes the passwords with the algorithm and its cost. The passwords hashed with
+// any supported algorithm or cost can be compared, the outdated hashes are to be replaced on login.
+// The zero PasswordHasher hashes with bcrypt at bcrypt.MinCost.
+type PasswordHasher struct {
+	// Algorithm is HashBcrypt if empty.
+	Algorithm HashAlgorithm
+	// BcryptCost is bcrypt.MinCost if zero.
+	BcryptCost int
+	// Argon2Time is the number of passes, Argon2Memory the memory in KiB and Argon2Threads the parallelism
+	// of argon2id. The recommended values are used for the zero ones.
+	Argon2Time    int
+	Argon2Memory  int
+	Argon2Threads int
+}
+
+// Validate returns ErrInvalidHasher if the algorithm is unknown or the cost is out of its range.
+func (h PasswordHasher) Validate() error {
+	switch h.algorithm() {
+	case HashBcrypt:
+		if cost := h.bcryptCost(); cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+			return fmt.Errorf("%w: bcrypt cost %d is out of range", ErrInvalidHasher, cost)
+		}
+	case HashArgon2id:
+		if h.Argon2Time < 0 || h.Argon2Memory < 0 || h.Argon2Threads < 0 {
+			return fmt.Errorf("%w: argon2id parameters are negative", ErrInvalidHasher)
+		}
+
+		time, memory, threads := h.argon2Settings()
+
+		return validateArgon2Params(time, memory, threads, argon2KeyLength)
+	default:
+		return fmt.Errorf("%w: unknown algorithm %q", ErrInvalidHasher, h.Algorithm)
+	}
+
+	return nil
+}
+
+// Hash returns the hash of the password, which includes the algorithm, the cost and the salt.
+func (h PasswordHasher) Hash(password string) (string, error) {
+	switch h.algorithm() {
+	case HashBcrypt:
+		b, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost())
+		if err != nil {
+			return "", err
+		}
+
+		return string(b), nil
+	case HashArgon2id:
+		if err := h.Validate(); err != nil {
+			return "", err
+		}
+
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+
+		time, memory, threads := h.argon2Params()
+		key := argon2.IDKey([]byte(password), salt, time, memory, threads, argon2KeyLength)
+
+		return fmt.Sprintf(
+			"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2Prefix,
+			argon2.Version,
+			memory,
+			time,
+			threads,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key),
+		), nil
+	default:
+		return "", ErrInvalidHasher
+	}
+}
+
+// Compare returns true if the hash is the hash of the password, whichever algorithm it has been hashed with.
+func (h PasswordHasher) Compare(hash, password string) bool {
+	if strings.HasPrefix(hash, argon2Prefix) {
+		a, err := parseArgon2Hash(hash)
+		if err != nil {
+			return false
+		}
+
+		key := argon2.IDKey([]byte(password), a.salt, a.time, a.memory, a.threads, uint32(len(a.key)))
+
+		return subtle.ConstantTimeCompare(key, a.key) == 1
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NeedsRehash returns true if the hash has been made with another algorithm or cost than the hasher uses.
+func (h PasswordHasher) NeedsRehash(hash string) bool {
+	switch h.algorithm() {
+	case HashBcrypt:
+		cost, err := bcrypt.Cost([]byte(hash))
+
+		return err != nil || cost != h.bcryptCost()
+	case HashArgon2id:
+		if !strings.HasPrefix(hash, argon2Prefix) {
+			return true
+		}
+
+		a, err := parseArgon2Hash(hash)
+		if err != nil {
+			return true
+		}
+
+		time, memory, threads := h.argon2Settings()
+
+		return int(a.time) != time || int(a.memory) != memory || int(a.threads) != threads || len(a.key) != argon2KeyLength
+	default:
+		return false
+	}
+}
+
+func (h PasswordHasher) algorithm() HashAlgorithm {
+	if h.Algorithm == "" {
+		return HashBcrypt
+	}
+
+	return h.Algorithm
+}
+
+func (h PasswordHasher) bcryptCost() int {
+	if h.BcryptCost == 0 {
+		return bcrypt.MinCost
+	}
+
+	return h.BcryptCost
+}
+
+// argon2Settings returns the argon2id parameters with the recommended values for the zero ones.
+// They are validated before being converted by argon2Params, the conversion would wrap the ones out of range.
+func (h PasswordHasher) argon2Settings() (time int, memory int, threads int) {
+	time, memory, threads = h.Argon2Time, h.Argon2Memory, h.Argon2Threads
+	if h.Argon2Time == 0 {
+		time = defaultArgon2Time
+	}
+	if h.Argon2Memory == 0 {
+		memory = defaultArgon2Memory
+	}
+	if h.Argon2Threads == 0 {
+		threads = defaultArgon2Threads
+	}
+
+	return time, memory, threads
+}
+
+// argon2Params returns the argon2id parameters for the argon2 package, the hasher must be valid.
+func (h PasswordHasher) argon2Params() (time uint32, memory uint32, threads uint8) {
+	t, m, p := h.argon2Settings()
+
+	return uint32(t), uint32(m), uint8(p)
+}
+
+// argon2Hash is a parsed argon2id hash.
+type argon2Hash struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+// parseArgon2Hash parses a hash in the PHC string format, $argon2id$v=19$m=19456,t=2,p=1$salt$key.
+func parseArgon2Hash(hash string) (argon2Hash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return argon2Hash{}, ErrInvalidHasher
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Hash{}, ErrInvalidHasher
+	}
+
+	var a argon2Hash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &a.memory, &a.time, &a.threads); err != nil {
+		return argon2Hash{}, ErrInvalidHasher
+	}
+
+	var err error
+	if a.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return argon2Hash{}, ErrInvalidHasher
+	}
+	if a.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(a.key) == 0 {
+		return argon2Hash{}, ErrInvalidHasher
+	}
+
+	// A stored hash must not make the comparison run too long or argon2 panic
+	if err = validateArgon2Params(int(a.time), int(a.memory), int(a.threads), len(a.key)); err != nil {
+		return argon2Hash{}, err
+	}
+
+	return a, nil
+}
+
+// validateArgon2Params returns ErrInvalidHasher if an argon2id parameter is out of its range.
+func validateArgon2Params(time, memory, threads, keyLength int) error {
+	switch {
+	case time < 1 || time > maxArgon2Time:
+		return fmt.Errorf("%w: argon2id time %d is out of range", ErrInvalidHasher, time)
+	case threads < 1 || threads > maxArgon2Threads:
+		return fmt.Errorf("%w: argon2id threads %d is out of range", ErrInvalidHasher, threads)
+	case memory < minArgon2MemoryThread*threads || memory > maxArgon2Memory:
+		return fmt.Errorf("%w: argon2id memory %d is out of range", ErrInvalidHasher, memory)
+	case keyLength < minArgon2KeyLength || keyLength > maxArgon2KeyLength:
+		return fmt.Errorf("%w: argon2id key length %d is out of range", ErrInvalidHasher, keyLength)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,198 @@
+package user_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ozaitsev92/tododdd/internal/domain/user"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := user.NewPasswordPolicy(10, []string{"Password123456"}, true)
+
+	type testCase struct {
+		name     string
+		password string
+		wantErr  error
+	}
+
+	tests := []testCase{
+		{
+			name:     "Success",
+			password: "correct horse battery",
+		},
+		{
+			name:     "Empty",
+			password: "",
+			wantErr:  user.ErrInvalidPassword,
+		},
+		{
+			name:     "Too short",
+			password: "horse",
+			wantErr:  user.ErrPasswordTooShort,
+		},
+		{
+			name:     "Too short in characters",
+			password: "пароль",
+			wantErr:  user.ErrPasswordTooShort,
+		},
+		{
+			name:     "Common whatever the case",
+			password: "PASSWORD123456",
+			wantErr:  user.ErrPasswordTooCommon,
+		},
+		{
+			name:     "Contains the email",
+			password: "xJohn.Smith@Example.comx",
+			wantErr:  user.ErrPasswordContainsEmail,
+		},
+		{
+			name:     "Contains the local part of the email",
+			password: "my name is john.smith",
+			wantErr:  user.ErrPasswordContainsEmail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := policy.Validate(tt.password, "john.smith@example.com")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, user.ErrInvalidPassword) {
+				t.Errorf("Validate() error = %v does not wrap %v", err, user.ErrInvalidPassword)
+			}
+		})
+	}
+
+	// The zero policy accepts any non-empty password
+	if err := (user.PasswordPolicy{}).Validate("john", "john@example.com"); err != nil {
+		t.Errorf("Validate() error = %v for the zero policy", err)
+	}
+
+	// Short local parts are allowed
+	if err := policy.Validate("joe is my name", "jo@example.com"); err != nil {
+		t.Errorf("Validate() error = %v for a short local part", err)
+	}
+}
+
+func TestReadCommonPasswords(t *testing.T) {
+	passwords, err := user.ReadCommonPasswords(strings.NewReader("# common passwords\n123456\n\n  qwerty  \r\npassword\n"))
+	if err != nil {
+		t.Fatalf("ReadCommonPasswords() error = %v", err)
+	}
+
+	want := []string{"123456", "qwerty", "password"}
+	if strings.Join(passwords, ",") != strings.Join(want, ",") {
+		t.Errorf("ReadCommonPasswords() = %v, want %v", passwords, want)
+	}
+}
+
+func TestPasswordHasher(t *testing.T) {
+	type testCase struct {
+		name   string
+		hasher user.PasswordHasher
+		prefix string
+	}
+
+	tests := []testCase{
+		{
+			name:   "Zero",
+			hasher: user.PasswordHasher{},
+			prefix: "$2a$04$",
+		},
+		{
+			name:   "Bcrypt",
+			hasher: user.PasswordHasher{Algorithm: user.HashBcrypt, BcryptCost: 5},
+			prefix: "$2a$05$",
+		},
+		{
+			name:   "Argon2id",
+			hasher: user.PasswordHasher{Algorithm: user.HashArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1},
+			prefix: "$argon2id$v=19$m=64,t=1,p=1$",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.hasher.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			hash, err := tt.hasher.Hash("secret")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if !strings.HasPrefix(hash, tt.prefix) {
+				t.Errorf("Hash() = %v, want the prefix %v", hash, tt.prefix)
+			}
+
+			if !tt.hasher.Compare(hash, "secret") {
+				t.Error("Compare() = false for the password")
+			}
+			if tt.hasher.Compare(hash, "Secret") {
+				t.Error("Compare() = true for another password")
+			}
+
+			// Any hasher compares any hash
+			if !(user.PasswordHasher{}).Compare(hash, "secret") {
+				t.Error("Compare() = false with another hasher")
+			}
+
+			if tt.hasher.NeedsRehash(hash) {
+				t.Error("NeedsRehash() = true for its own hash")
+			}
+		})
+	}
+
+	bcryptHash, _ := user.PasswordHasher{BcryptCost: 5}.Hash("secret")
+	argon2Hash, _ := user.PasswordHasher{Algorithm: user.HashArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1}.Hash("secret")
+
+	if !(user.PasswordHasher{BcryptCost: 6}).NeedsRehash(bcryptHash) {
+		t.Error("NeedsRehash() = false for another bcrypt cost")
+	}
+	if !(user.PasswordHasher{}).NeedsRehash(argon2Hash) {
+		t.Error("NeedsRehash() = false for an argon2id hash with bcrypt")
+	}
+	if !(user.PasswordHasher{Algorithm: user.HashArgon2id}).NeedsRehash(bcryptHash) {
+		t.Error("NeedsRehash() = false for a bcrypt hash with argon2id")
+	}
+	if !(user.PasswordHasher{Algorithm: user.HashArgon2id, Argon2Time: 2, Argon2Memory: 64, Argon2Threads: 1}).NeedsRehash(argon2Hash) {
+		t.Error("NeedsRehash() = false for another argon2id time")
+	}
+
+	invalid := []user.PasswordHasher{
+		{Algorithm: "md5"},
+		{BcryptCost: bcrypt.MaxCost + 1},
+		{Algorithm: user.HashArgon2id, Argon2Time: -1},
+		{Algorithm: user.HashArgon2id, Argon2Time: 1000},
+		{Algorithm: user.HashArgon2id, Argon2Memory: 1 << 32},
+		{Algorithm: user.HashArgon2id, Argon2Memory: 64, Argon2Threads: 16},
+		{Algorithm: user.HashArgon2id, Argon2Threads: 256},
+		{Algorithm: user.HashArgon2id, Argon2Threads: 257},
+		{Algorithm: user.HashArgon2id, Argon2Threads: -255},
+		{Algorithm: user.HashArgon2id, Argon2Memory: 1<<32 + 64},
+	}
+	for _, h := range invalid {
+		if err := h.Validate(); !errors.Is(err, user.ErrInvalidHasher) {
+			t.Errorf("Validate() error = %v for %+v, wantErr %v", err, h, user.ErrInvalidHasher)
+		}
+		if _, err := h.Hash("secret"); err == nil {
+			t.Errorf("Hash() error = %v for %+v, want an error", err, h)
+		}
+	}
+
+	// The stored hashes with parameters out of range are not compared
+	for _, params := range []string{"m=64,t=1,p=0", "m=64,t=0,p=1", "m=8,t=1,p=4"} {
+		hash := strings.Replace(argon2Hash, "m=64,t=1,p=1", params, 1)
+		if (user.PasswordHasher{}).Compare(hash, "secret") {
+			t.Errorf("Compare() = true for the parameters %v", params)
+		}
+	}
+}
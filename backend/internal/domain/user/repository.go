@@ -8,12 +8,17 @@ import (
 )
 
 var (
-	ErrUserNotFound      = errors.New("the user was not found in the repository")
-	ErrFailedToStoreUser = errors.New("failed to store the user")
+	ErrUserNotFound       = errors.New("the user was not found in the repository")
+	ErrFailedToStoreUser  = errors.New("failed to store the user")
+	ErrFailedToDeleteUser = errors.New("failed to delete the user")
 )
 
 type Repository interface {
 	GetByID(context.Context, uuid.UUID) (User, error)
 	GetByEmail(context.Context, string) (User, error)
 	Save(context.Context, User) error
+	// Update replaces the stored user, it returns ErrUserNotFound if there is none with the id.
+	Update(context.Context, User) error
+	// Delete removes the user permanently, it returns ErrUserNotFound if there is none with the id.
+	Delete(context.Context, uuid.UUID) error
 }
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -16,31 +15,26 @@ var (
 
 // User is a representation of a user entity.
 type User struct {
-	ID        uuid.UUID
-	Email     string
-	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       uuid.UUID
+	Email    string
+	Password string
+	// VerifiedAt is nil until the user verifies their email.
+	VerifiedAt *time.Time
+	// VerificationSentAt is when the last verification email has been sent, zero if none has been.
+	VerificationSentAt time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
 }
 
-// NewUser creates and returns a new User.
-func NewUser(email, password string) (User, error) {
-	if email == "" {
-		return User{}, ErrInvalidEmail
-	}
-
-	m, err := mail.ParseAddress(email)
+// NewUser creates and returns a new User. The password is checked and hashed as the zero Passwords does
+// unless WithPasswords is given.
+func NewUser(email, password string, opts ...Option) (User, error) {
+	parsedEmail, err := parseEmail(email)
 	if err != nil {
-		return User{}, ErrInvalidEmail
-	}
-
-	parsedEmail := m.Address
-
-	if password == "" {
-		return User{}, ErrInvalidPassword
+		return User{}, err
 	}
 
-	encryptedPassword, err := encryptString(password)
+	encryptedPassword, err := hashPassword(password, parsedEmail, opts)
 	if err != nil {
 		return User{}, err
 	}
@@ -58,16 +52,113 @@ func NewUser(email, password string) (User, error) {
 	return user, nil
 }
 
-// ComparePassword -.
+// ComparePassword returns true if the password is the password of the user.
 func (u *User) ComparePassword(password string) bool {
-	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) == nil
+	return PasswordHasher{}.Compare(u.Password, password)
+}
+
+// RehashPassword hashes the password of the user again with the hasher if it has been hashed with another
+// algorithm or cost, and returns true if it has. The password has to be compared with ComparePassword first.
+func (u *User) RehashPassword(password string, h PasswordHasher) (bool, error) {
+	if !h.NeedsRehash(u.Password) {
+		return false, nil
+	}
+
+	encryptedPassword, err := h.Hash(password)
+	if err != nil {
+		return false, err
+	}
+
+	u.Password = encryptedPassword
+
+	return true, nil
+}
+
+// SetEmail changes the email of the user.
+func (u *User) SetEmail(email string) error {
+	parsedEmail, err := parseEmail(email)
+	if err != nil {
+		return err
+	}
+
+	if parsedEmail != u.Email {
+		// A new email has to be verified again.
+		u.VerifiedAt = nil
+		u.VerificationSentAt = time.Time{}
+	}
+
+	u.Email = parsedEmail
+	u.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// SetPassword replaces the password of the user. The password is checked and hashed as the zero Passwords does
+// unless WithPasswords is given.
+func (u *User) SetPassword(password string, opts ...Option) error {
+	encryptedPassword, err := hashPassword(password, u.Email, opts)
+	if err != nil {
+		return err
+	}
+
+	u.Password = encryptedPassword
+	u.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// IsVerified returns true if the user has verified their email.
+func (u *User) IsVerified() bool {
+	return u.VerifiedAt != nil
+}
+
+// Verify marks the email of the user as verified.
+func (u *User) Verify() {
+	currentTime := time.Now()
+
+	u.VerifiedAt = &currentTime
+	u.UpdatedAt = currentTime
+}
+
+// MarkVerificationSent records that a verification email has just been sent to the user.
+func (u *User) MarkVerificationSent() {
+	u.VerificationSentAt = time.Now()
+}
+
+// VerificationResendWait returns how long the user has to wait before another verification email
+// can be sent to them, zero if it can be sent now.
+func (u *User) VerificationResendWait(interval time.Duration) time.Duration {
+	wait := time.Until(u.VerificationSentAt.Add(interval))
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
 }
 
-func encryptString(s string) (string, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(s), bcrypt.MinCost)
+func parseEmail(email string) (string, error) {
+	if email == "" {
+		return "", ErrInvalidEmail
+	}
+
+	m, err := mail.ParseAddress(email)
 	if err != nil {
+		return "", ErrInvalidEmail
+	}
+
+	return m.Address, nil
+}
+
+// hashPassword checks the password of the user with the email and hashes it.
+func hashPassword(password, email string, opts []Option) (string, error) {
+	var p Passwords
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	if err := p.Policy.Validate(password, email); err != nil {
 		return "", err
 	}
 
-	return string(b), nil
+	return p.Hasher.Hash(password)
 }
@@ -1,7 +1,10 @@
 package user_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/internal/domain/user"
@@ -137,3 +140,175 @@ func encryptString(s string) string {
 
 	return string(b)
 }
+
+func TestUserSetEmail(t *testing.T) {
+	type testCase struct {
+		name    string
+		email   string
+		want    string
+		wantErr error
+	}
+
+	tests := []testCase{
+		{
+			name:  "Success",
+			email: "New <new@example.com>",
+			want:  "new@example.com",
+		},
+		{
+			name:    "Empty email",
+			email:   "",
+			want:    "test@example.com",
+			wantErr: user.ErrInvalidEmail,
+		},
+		{
+			name:    "Invalid email",
+			email:   "invalid email",
+			want:    "test@example.com",
+			wantErr: user.ErrInvalidEmail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u, err := user.NewUser("test@example.com", "test")
+			if err != nil {
+				t.Fatalf("NewUser() error = %v", err)
+			}
+			updatedAt := u.UpdatedAt
+
+			err = u.SetEmail(tt.email)
+			if err != tt.wantErr {
+				t.Errorf("SetEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if u.Email != tt.want {
+				t.Errorf("SetEmail() Email = %v, want %v", u.Email, tt.want)
+			}
+			if tt.wantErr == nil && !u.UpdatedAt.After(updatedAt) {
+				t.Error("SetEmail() UpdatedAt has not changed")
+			}
+		})
+	}
+}
+
+func TestUserSetPassword(t *testing.T) {
+	u, err := user.NewUser("test@example.com", "test")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+
+	if err := u.SetPassword(""); err != user.ErrInvalidPassword {
+		t.Errorf("SetPassword() error = %v, wantErr %v", err, user.ErrInvalidPassword)
+	}
+	if !u.ComparePassword("test") {
+		t.Error("SetPassword() changed the password on error")
+	}
+
+	if err := u.SetPassword("changed"); err != nil {
+		t.Fatalf("SetPassword() error = %v", err)
+	}
+	if u.ComparePassword("test") || !u.ComparePassword("changed") {
+		t.Error("SetPassword() password is not replaced")
+	}
+}
+
+func TestUserVerify(t *testing.T) {
+	u, err := user.NewUser("test@example.com", "test")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if u.IsVerified() {
+		t.Error("NewUser() user is verified")
+	}
+
+	u.Verify()
+	if !u.IsVerified() {
+		t.Error("Verify() user is not verified")
+	}
+
+	if err := u.SetEmail("test@example.com"); err != nil {
+		t.Fatalf("SetEmail() error = %v", err)
+	}
+	if !u.IsVerified() {
+		t.Error("SetEmail() the same email is not verified anymore")
+	}
+
+	u.MarkVerificationSent()
+	if err := u.SetEmail("new@example.com"); err != nil {
+		t.Fatalf("SetEmail() error = %v", err)
+	}
+	if u.IsVerified() {
+		t.Error("SetEmail() a new email is verified")
+	}
+	if wait := u.VerificationResendWait(time.Hour); wait != 0 {
+		t.Errorf("VerificationResendWait() = %v for a new email, want 0", wait)
+	}
+}
+
+func TestUserVerificationResendWait(t *testing.T) {
+	u, err := user.NewUser("test@example.com", "test")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+
+	if wait := u.VerificationResendWait(time.Minute); wait != 0 {
+		t.Errorf("VerificationResendWait() = %v before any email, want 0", wait)
+	}
+
+	u.MarkVerificationSent()
+	if wait := u.VerificationResendWait(time.Minute); wait <= 0 || wait > time.Minute {
+		t.Errorf("VerificationResendWait() = %v right after an email, want up to %v", wait, time.Minute)
+	}
+
+	u.VerificationSentAt = time.Now().Add(-2 * time.Minute)
+	if wait := u.VerificationResendWait(time.Minute); wait != 0 {
+		t.Errorf("VerificationResendWait() = %v after the interval, want 0", wait)
+	}
+}
+
+func TestUserPasswords(t *testing.T) {
+	passwords := user.Passwords{
+		Policy: user.NewPasswordPolicy(8, nil, true),
+		Hasher: user.PasswordHasher{Algorithm: user.HashArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1},
+	}
+
+	if _, err := user.NewUser("test@example.com", "short", user.WithPasswords(passwords)); !errors.Is(err, user.ErrPasswordTooShort) {
+		t.Errorf("NewUser() error = %v, wantErr %v", err, user.ErrPasswordTooShort)
+	}
+
+	u, err := user.NewUser("test@example.com", "long enough", user.WithPasswords(passwords))
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if !strings.HasPrefix(u.Password, "$argon2id$") {
+		t.Errorf("NewUser() password = %v, want an argon2id hash", u.Password)
+	}
+
+	if err := u.SetPassword("my test password", user.WithPasswords(passwords)); !errors.Is(err, user.ErrPasswordContainsEmail) {
+		t.Errorf("SetPassword() error = %v, wantErr %v", err, user.ErrPasswordContainsEmail)
+	}
+	if !u.ComparePassword("long enough") {
+		t.Error("SetPassword() changed the password on error")
+	}
+}
+
+func TestUserRehashPassword(t *testing.T) {
+	u, err := user.NewUser("test@example.com", "test")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+
+	rehashed, err := u.RehashPassword("test", user.PasswordHasher{})
+	if err != nil || rehashed {
+		t.Errorf("RehashPassword() = %v, %v with the same hasher, want false, nil", rehashed, err)
+	}
+
+	rehashed, err = u.RehashPassword("test", user.PasswordHasher{Algorithm: user.HashArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1})
+	if err != nil || !rehashed {
+		t.Fatalf("RehashPassword() = %v, %v with another hasher, want true, nil", rehashed, err)
+	}
+	if !strings.HasPrefix(u.Password, "$argon2id$") || !u.ComparePassword("test") {
+		t.Errorf("RehashPassword() password = %v, want an argon2id hash of the password", u.Password)
+	}
+}
@@ -0,0 +1,221 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+// Status is the state of a delivery.
+type Status string
+
+const (
+	// StatusPending deliveries are attempted at NextAttemptAt.
+	StatusPending Status = "pending"
+	// StatusDelivered deliveries were accepted by the receiver.
+	StatusDelivered Status = "delivered"
+	// StatusFailed deliveries ran out of attempts.
+	StatusFailed Status = "failed"
+)
+
+var (
+	ErrDeliveryNotPending = errors.New("the delivery is not pending")
+	ErrInvalidBackoff     = errors.New("backoff is invalid")
+)
+
+// Attempt is one try to deliver an event.
+type Attempt struct {
+	At time.Time
+	// StatusCode is the status of the response, 0 when there was none.
+	StatusCode int
+	// Error tells why there was no response or why it was refused.
+	Error    string
+	Duration time.Duration
+}
+
+// Succeeded reports whether the receiver accepted the delivery with a 2xx response.
+func (a Attempt) Succeeded() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// Backoff is how a failed delivery is retried: the n-th retry waits Base * 2^(n-1), at most Max,
+// and the delivery fails after MaxAttempts attempts.
+type Backoff struct {
+	Base        time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+// Validate returns ErrInvalidBackoff unless the retries wait and a delivery is attempted at least once.
+func (b Backoff) Validate() error {
+	switch {
+	case b.Base <= 0:
+		return fmt.Errorf("%w: the base %v is not positive", ErrInvalidBackoff, b.Base)
+	case b.Max < b.Base:
+		return fmt.Errorf("%w: the max %v is below the base %v", ErrInvalidBackoff, b.Max, b.Base)
+	case b.MaxAttempts < 1:
+		return fmt.Errorf("%w: the max attempts %d is not positive", ErrInvalidBackoff, b.MaxAttempts)
+	default:
+		return nil
+	}
+}
+
+// Delay returns how long to wait after the given number of failed attempts.
+func (b Backoff) Delay(failed int) time.Duration {
+	delay := b.Base
+	for i := 1; i < failed; i++ {
+		delay *= 2
+		if delay >= b.Max {
+			return b.Max
+		}
+	}
+
+	return min(delay, b.Max)
+}
+
+// Delivery is an event to be sent to a webhook, with the attempts made so far.
+type Delivery struct {
+	ID        uuid.UUID
+	WebhookID uuid.UUID
+	UserID    uuid.UUID
+	EventID   uuid.UUID
+	EventType task.EventType
+	// Payload is the body sent to the receiver.
+	Payload       []byte
+	Status        Status
+	Attempts      []Attempt
+	NextAttemptAt time.Time
+	// LeasedUntil is until when the delivery is being attempted by a worker, zero if it is not.
+	// Another worker may attempt it after that, as the first one is assumed gone.
+	LeasedUntil time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewDelivery creates and returns a new pending Delivery of the event to the webhook, due now.
+func NewDelivery(w Webhook, e task.Event) (Delivery, error) {
+	payload, err := json.Marshal(newPayload(e))
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	currentTime := time.Now()
+
+	return Delivery{
+		ID:            uuid.New(),
+		WebhookID:     w.ID,
+		UserID:        w.UserID,
+		EventID:       e.ID,
+		EventType:     e.Type,
+		Payload:       payload,
+		Status:        StatusPending,
+		Attempts:      []Attempt{},
+		NextAttemptAt: currentTime,
+		CreatedAt:     currentTime,
+		UpdatedAt:     currentTime,
+	}, nil
+}
+
+// IsDue reports whether the delivery is to be attempted at the time and no worker is attempting it.
+func (d Delivery) IsDue(now time.Time) bool {
+	return d.Status == StatusPending && !d.NextAttemptAt.After(now) && !d.LeasedUntil.After(now)
+}
+
+// RecordAttempt adds the attempt and marks the delivery delivered, failed or due again after the backoff.
+// The lease of the delivery ends.
+func (d *Delivery) RecordAttempt(a Attempt, b Backoff) error {
+	if d.Status != StatusPending {
+		return ErrDeliveryNotPending
+	}
+
+	d.Attempts = append(d.Attempts, a)
+	d.LeasedUntil = time.Time{}
+	d.UpdatedAt = time.Now()
+
+	switch {
+	case a.Succeeded():
+		d.Status = StatusDelivered
+	case len(d.Attempts) >= b.MaxAttempts:
+		d.Status = StatusFailed
+	default:
+		d.NextAttemptAt = a.At.Add(b.Delay(len(d.Attempts)))
+	}
+
+	return nil
+}
+
+// Fail gives up on the delivery, e.g. when its webhook is gone.
+func (d *Delivery) Fail(reason string) {
+	d.Attempts = append(d.Attempts, Attempt{At: time.Now(), Error: reason})
+	d.LeasedUntil = time.Time{}
+	d.Status = StatusFailed
+	d.UpdatedAt = time.Now()
+}
+
+// payload is the body of a delivery.
+type payload struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	ActorID    string      `json:"actor_id"`
+	Task       payloadTask `json:"task"`
+}
+
+type payloadTask struct {
+	ID        string     `json:"id"`
+	Text      string     `json:"text"`
+	Completed bool       `json:"completed"`
+	UserID    string     `json:"user_id"`
+	ListID    *string    `json:"list_id"`
+	ParentID  *string    `json:"parent_id"`
+	DueAt     *time.Time `json:"due_at"`
+	Tags      []string   `json:"tags"`
+	Priority  string     `json:"priority"`
+	Version   int64      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func newPayload(e task.Event) payload {
+	t := e.Task
+
+	tags := t.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return payload{
+		ID:         e.ID.String(),
+		Type:       string(e.Type),
+		OccurredAt: e.OccurredAt,
+		ActorID:    e.ActorID.String(),
+		Task: payloadTask{
+			ID:        t.ID.String(),
+			Text:      t.Text,
+			Completed: t.Completed,
+			UserID:    t.UserID.String(),
+			ListID:    uuidToStringPtr(t.ListID),
+			ParentID:  uuidToStringPtr(t.ParentID),
+			DueAt:     t.DueAt,
+			Tags:      tags,
+			Priority:  t.Priority.String(),
+			Version:   t.Version,
+			CreatedAt: t.CreatedAt,
+			UpdatedAt: t.UpdatedAt,
+		},
+	}
+}
+
+func uuidToStringPtr(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+
+	s := id.String()
+
+	return &s
+}
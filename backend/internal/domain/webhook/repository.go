@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWebhookNotFound      = errors.New("the webhook was not found in the repository")
+	ErrFailedToSaveWebhook  = errors.New("failed to save the webhook")
+	ErrFailedUpdateWebhook  = errors.New("failed to update the webhook")
+	ErrFailedDeleteWebhook  = errors.New("failed to delete the webhook")
+	ErrDeliveryNotFound     = errors.New("the delivery was not found in the repository")
+	ErrFailedToSaveDelivery = errors.New("failed to save the delivery")
+	ErrFailedUpdateDelivery = errors.New("failed to update the delivery")
+	ErrDeliveryClaimed      = errors.New("the delivery is not due or is claimed by another worker")
+)
+
+type Repository interface {
+	GetByID(context.Context, uuid.UUID) (Webhook, error)
+	// GetAllByUserID returns the webhooks of the user, the oldest first.
+	GetAllByUserID(context.Context, uuid.UUID) ([]Webhook, error)
+	Save(context.Context, Webhook) error
+	Update(context.Context, Webhook) error
+	Delete(context.Context, uuid.UUID) error
+}
+
+type DeliveryRepository interface {
+	Save(context.Context, Delivery) error
+	Update(context.Context, Delivery) error
+	// GetDue returns at most limit deliveries due at the given time, see Delivery.IsDue, the longest waiting first.
+	GetDue(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+	// Claim leases the delivery to the worker until the given time if it is still due at now, and returns it.
+	// ErrDeliveryClaimed is returned otherwise, so only one of the workers claiming it at once attempts it.
+	Claim(ctx context.Context, id uuid.UUID, now time.Time, until time.Time) (Delivery, error)
+	// GetAllByWebhookID returns at most limit deliveries of the webhook, the latest first.
+	GetAllByWebhookID(ctx context.Context, webhookId uuid.UUID, limit int) ([]Delivery, error)
+	DeleteByWebhookID(context.Context, uuid.UUID) error
+}
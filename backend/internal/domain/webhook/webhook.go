@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+const (
+	secretLength = 32
+
+	// SignatureHeader carries the signature of a delivery, see Signature.
+	SignatureHeader = "X-Webhook-Signature-256"
+	// EventHeader carries the event type of a delivery.
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader carries the ID of a delivery. It is the same for every attempt.
+	DeliveryHeader = "X-Webhook-Delivery"
+)
+
+var (
+	ErrInvalidURL    = errors.New("webhook url is invalid")
+	ErrInvalidEvents = errors.New("webhook events are invalid")
+	ErrInvalidUserID = errors.New("user id is invalid")
+)
+
+// Webhook is a URL the user wants to be told about the changes of their tasks at.
+type Webhook struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	URL    string
+	// Events are the types of the events delivered to the URL.
+	Events []task.EventType
+	// Secret is the key the deliveries are signed with. It is shared with the receiver.
+	Secret    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewWebhook creates and returns a new Webhook of the user with a random secret.
+func NewWebhook(rawURL string, events []task.EventType, userId uuid.UUID) (Webhook, error) {
+	if userId == uuid.Nil {
+		return Webhook{}, ErrInvalidUserID
+	}
+
+	b := make([]byte, secretLength)
+	if _, err := rand.Read(b); err != nil {
+		return Webhook{}, err
+	}
+
+	currentTime := time.Now()
+
+	w := Webhook{
+		ID:        uuid.New(),
+		UserID:    userId,
+		Secret:    hex.EncodeToString(b),
+		CreatedAt: currentTime,
+		UpdatedAt: currentTime,
+	}
+
+	if err := w.Update(rawURL, events); err != nil {
+		return Webhook{}, err
+	}
+
+	return w, nil
+}
+
+// Update changes the URL and the events of the webhook.
+// The URL must be an absolute http or https URL and the events must be known, duplicates are dropped.
+func (w *Webhook) Update(rawURL string, events []task.EventType) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+
+	if len(events) == 0 {
+		return ErrInvalidEvents
+	}
+
+	unique := make([]task.EventType, 0, len(events))
+	for _, e := range events {
+		if !slices.Contains(task.EventTypes, e) {
+			return ErrInvalidEvents
+		}
+		if !slices.Contains(unique, e) {
+			unique = append(unique, e)
+		}
+	}
+
+	w.URL = u.String()
+	w.Events = unique
+	w.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// Subscribes reports whether the events of the type are delivered to the webhook.
+func (w *Webhook) Subscribes(eventType task.EventType) bool {
+	return slices.Contains(w.Events, eventType)
+}
+
+// Sign returns the signature of the payload with the secret of the webhook.
+func (w *Webhook) Sign(payload []byte) string {
+	return Signature(w.Secret, payload)
+}
+
+// Signature returns "sha256=" followed by the hex encoded HMAC-SHA256 of the payload keyed with the secret.
+// Receivers compute it over the raw request body and compare it with SignatureHeader in constant time.
+func Signature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
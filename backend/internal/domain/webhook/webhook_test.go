@@ -0,0 +1,211 @@
+package webhook_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+)
+
+func TestNewWebhook(t *testing.T) {
+	type args struct {
+		url    string
+		events []task.EventType
+		userId uuid.UUID
+	}
+
+	type testCase struct {
+		name       string
+		args       args
+		wantEvents []task.EventType
+		wantErr    error
+	}
+
+	userId := uuid.New()
+
+	tests := []testCase{
+		{
+			name:       "Success",
+			args:       args{url: "https://example.com/hook", events: []task.EventType{task.EventCreated, task.EventCompleted, task.EventCreated}, userId: userId},
+			wantEvents: []task.EventType{task.EventCreated, task.EventCompleted},
+			wantErr:    nil,
+		},
+		{
+			name:    "Relative URL",
+			args:    args{url: "/hook", events: []task.EventType{task.EventCreated}, userId: userId},
+			wantErr: webhook.ErrInvalidURL,
+		},
+		{
+			name:    "Unsupported scheme",
+			args:    args{url: "ftp://example.com/hook", events: []task.EventType{task.EventCreated}, userId: userId},
+			wantErr: webhook.ErrInvalidURL,
+		},
+		{
+			name:    "No events",
+			args:    args{url: "https://example.com/hook", events: nil, userId: userId},
+			wantErr: webhook.ErrInvalidEvents,
+		},
+		{
+			name:    "Unknown event",
+			args:    args{url: "https://example.com/hook", events: []task.EventType{"task.archived"}, userId: userId},
+			wantErr: webhook.ErrInvalidEvents,
+		},
+		{
+			name:    "No user",
+			args:    args{url: "https://example.com/hook", events: []task.EventType{task.EventCreated}, userId: uuid.Nil},
+			wantErr: webhook.ErrInvalidUserID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w, err := webhook.NewWebhook(tt.args.url, tt.args.events, tt.args.userId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(w.Events, tt.wantEvents) {
+				t.Errorf("NewWebhook() Events = %v, want %v", w.Events, tt.wantEvents)
+			}
+
+			if len(w.Secret) != 64 {
+				t.Errorf("NewWebhook() Secret = %v, want 32 random bytes", w.Secret)
+			}
+
+			if !w.Subscribes(task.EventCompleted) || w.Subscribes(task.EventDeleted) {
+				t.Errorf("Subscribes() got = %v", w.Events)
+			}
+		})
+	}
+}
+
+func TestWebhookSign(t *testing.T) {
+	w, err := webhook.NewWebhook("https://example.com/hook", []task.EventType{task.EventCreated}, uuid.New())
+	if err != nil {
+		t.Fatalf("NewWebhook() error = %v", err)
+	}
+
+	payload := []byte(`{"id":"1"}`)
+
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(payload)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := w.Sign(payload); got != want {
+		t.Errorf("Sign() got = %v, want %v", got, want)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := webhook.Backoff{Base: time.Second, Max: 10 * time.Second, MaxAttempts: 5}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		if got := b.Delay(i + 1); got != w {
+			t.Errorf("Delay(%d) got = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestBackoffValidate(t *testing.T) {
+	if err := (webhook.Backoff{Base: time.Second, Max: time.Second, MaxAttempts: 1}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	invalid := []webhook.Backoff{
+		{Base: 0, Max: time.Second, MaxAttempts: 3},
+		{Base: time.Second, Max: 0, MaxAttempts: 3},
+		{Base: time.Minute, Max: time.Second, MaxAttempts: 3},
+		{Base: time.Second, Max: time.Minute, MaxAttempts: 0},
+	}
+	for _, b := range invalid {
+		if err := b.Validate(); !errors.Is(err, webhook.ErrInvalidBackoff) {
+			t.Errorf("Validate() error = %v for %+v, wantErr %v", err, b, webhook.ErrInvalidBackoff)
+		}
+	}
+}
+
+func TestDeliveryRecordAttempt(t *testing.T) {
+	userId := uuid.New()
+
+	w, err := webhook.NewWebhook("https://example.com/hook", []task.EventType{task.EventCreated}, userId)
+	if err != nil {
+		t.Fatalf("NewWebhook() error = %v", err)
+	}
+
+	ti, err := task.NewTask("test", userId)
+	if err != nil {
+		t.Fatalf("NewTask() error = %v", err)
+	}
+
+	e := task.NewEvent(task.EventCreated, ti, userId)
+
+	d, err := webhook.NewDelivery(w, e)
+	if err != nil {
+		t.Fatalf("NewDelivery() error = %v", err)
+	}
+
+	var payload struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Task struct {
+			ID string `json:"id"`
+		} `json:"task"`
+	}
+	if err := json.Unmarshal(d.Payload, &payload); err != nil {
+		t.Fatalf("NewDelivery() Payload error = %v", err)
+	}
+	if payload.ID != e.ID.String() || payload.Type != string(task.EventCreated) || payload.Task.ID != ti.ID.String() {
+		t.Errorf("NewDelivery() Payload = %s", d.Payload)
+	}
+
+	b := webhook.Backoff{Base: time.Minute, Max: time.Hour, MaxAttempts: 3}
+	at := time.Now()
+
+	// A leased delivery is not due until the lease ends
+	d.LeasedUntil = at.Add(time.Minute)
+	if d.IsDue(at) || !d.IsDue(at.Add(time.Minute)) {
+		t.Errorf("IsDue() got = %v %v, want the delivery due once the lease ends", d.IsDue(at), d.IsDue(at.Add(time.Minute)))
+	}
+
+	// A failed attempt schedules a retry after the backoff and ends the lease
+	if err := d.RecordAttempt(webhook.Attempt{At: at, StatusCode: 500}, b); err != nil {
+		t.Fatalf("RecordAttempt() error = %v", err)
+	}
+	if d.Status != webhook.StatusPending || !d.NextAttemptAt.Equal(at.Add(time.Minute)) || !d.LeasedUntil.IsZero() {
+		t.Errorf("RecordAttempt() got = %v %v %v, want %v %v", d.Status, d.NextAttemptAt, d.LeasedUntil, webhook.StatusPending, at.Add(time.Minute))
+	}
+
+	if err := d.RecordAttempt(webhook.Attempt{At: at, Error: "connection refused"}, b); err != nil {
+		t.Fatalf("RecordAttempt() error = %v", err)
+	}
+	if d.Status != webhook.StatusPending || !d.NextAttemptAt.Equal(at.Add(2*time.Minute)) {
+		t.Errorf("RecordAttempt() got = %v %v, want %v %v", d.Status, d.NextAttemptAt, webhook.StatusPending, at.Add(2*time.Minute))
+	}
+
+	// The last attempt fails the delivery
+	if err := d.RecordAttempt(webhook.Attempt{At: at, StatusCode: 404}, b); err != nil {
+		t.Fatalf("RecordAttempt() error = %v", err)
+	}
+	if d.Status != webhook.StatusFailed || len(d.Attempts) != 3 {
+		t.Errorf("RecordAttempt() got = %v %v, want %v %v", d.Status, len(d.Attempts), webhook.StatusFailed, 3)
+	}
+
+	if err := d.RecordAttempt(webhook.Attempt{At: at, StatusCode: 200}, b); !errors.Is(err, webhook.ErrDeliveryNotPending) {
+		t.Errorf("RecordAttempt() error = %v, wantErr %v", err, webhook.ErrDeliveryNotPending)
+	}
+}
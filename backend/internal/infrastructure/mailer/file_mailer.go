@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+var _ usecase.Mailer = (*FileMailer)(nil)
+
+// FileMailer appends the mails to a file instead of sending them, for local development and tests.
+type FileMailer struct {
+	path string
+	from string
+	mu   sync.Mutex
+}
+
+func NewFileMailer(cfg config.Config) *FileMailer {
+	return &FileMailer{
+		path: cfg.MailFile,
+		from: cfg.MailFrom,
+	}
+}
+
+func (s *FileMailer) Send(_ context.Context, m usecase.Mail) error {
+	msg, err := message(s.from, m, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(msg, "\r\n"...))
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,61 @@
+package mailer_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/mailer"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+func TestFileMailerSend(t *testing.T) {
+	cfg := config.Config{
+		MailFrom: "Todo <noreply@example.com>",
+		MailFile: filepath.Join(t.TempDir(), "mails.eml"),
+	}
+
+	m := mailer.NewFileMailer(cfg)
+
+	err := m.Send(context.Background(), usecase.Mail{
+		To:      "test@example.com",
+		Subject: "Réinitialiser",
+		Body:    "Open the link:\nhttp://localhost/reset?token=abc",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	err = m.Send(context.Background(), usecase.Mail{
+		To:      "test@example.com",
+		Subject: "Hello\r\nBcc: victim@example.com",
+	})
+	if !errors.Is(err, mailer.ErrInvalidMail) {
+		t.Errorf("Send() error = %v, wantErr %v", err, mailer.ErrInvalidMail)
+	}
+
+	b, err := os.ReadFile(cfg.MailFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	got := string(b)
+	for _, want := range []string{
+		"From: Todo <noreply@example.com>\r\n",
+		"To: test@example.com\r\n",
+		"Subject: =?utf-8?q?R=C3=A9initialiser?=\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"Open the link:\r\nhttp://localhost/reset?token=3Dabc",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Send() wrote %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "victim@example.com") {
+		t.Errorf("Send() wrote %q, want no injected header", got)
+	}
+}
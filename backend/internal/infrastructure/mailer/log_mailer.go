@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+)
+
+var _ usecase.Mailer = (*LogMailer)(nil)
+
+// LogMailer logs the mails instead of sending them, for local development.
+// The bodies are logged as they are, links with tokens included, so it must not be used in production.
+type LogMailer struct {
+	l logger.Interface
+}
+
+func NewLogMailer(l logger.Interface) *LogMailer {
+	return &LogMailer{
+		l: l,
+	}
+}
+
+func (s *LogMailer) Send(_ context.Context, m usecase.Mail) error {
+	if err := validate(m); err != nil {
+		return err
+	}
+
+	s.l.Info("mailer - LogMailer - Send: to %q, subject %q:\n%s", m.To, m.Subject, m.Body)
+
+	return nil
+}
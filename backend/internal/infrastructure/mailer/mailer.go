@@ -0,0 +1,29 @@
+package mailer
+
+import (
+	"fmt"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/logger"
+)
+
+const (
+	SMTP = "smtp"
+	File = "file"
+	Log  = "log"
+)
+
+// New returns the mailer chosen by the config, the LogMailer when none is.
+func New(cfg config.Config, l logger.Interface) (usecase.Mailer, error) {
+	switch cfg.Mailer {
+	case SMTP:
+		return NewSMTPMailer(cfg), nil
+	case File:
+		return NewFileMailer(cfg), nil
+	case Log, "":
+		return NewLogMailer(l), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", cfg.Mailer)
+	}
+}
@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+var (
+	ErrInvalidMail = errors.New("mail is invalid")
+)
+
+// validate checks that the mail has a valid recipient. The recipient and the subject must be single lines
+// so no header can be injected through them.
+func validate(m usecase.Mail) error {
+	if _, err := mail.ParseAddress(m.To); err != nil {
+		return fmt.Errorf("%w: recipient: %v", ErrInvalidMail, err)
+	}
+
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return fmt.Errorf("%w: line break in a header", ErrInvalidMail)
+	}
+
+	return nil
+}
+
+// message returns the mail formatted as an RFC 5322 message with a quoted-printable UTF-8 body.
+func message(from string, m usecase.Mail, date time.Time) ([]byte, error) {
+	if err := validate(m); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	b.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&b)
+	if _, err := w.Write([]byte(strings.ReplaceAll(m.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	b.WriteString("\r\n")
+
+	return b.Bytes(), nil
+}
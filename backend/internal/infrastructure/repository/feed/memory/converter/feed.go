@@ -0,0 +1,23 @@
+package converter
+
+import (
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/memory/model"
+)
+
+func ToFeedFromRepo(f repoModel.Feed) feed.Feed {
+	return feed.Feed{
+		UserID:    uuid.MustParse(f.UserID),
+		TokenHash: f.TokenHash,
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+func ToRepoFromFeed(f feed.Feed) repoModel.Feed {
+	return repoModel.Feed{
+		UserID:    f.UserID.String(),
+		TokenHash: f.TokenHash,
+		CreatedAt: f.CreatedAt,
+	}
+}
@@ -0,0 +1,11 @@
+package model
+
+import (
+	"time"
+)
+
+type Feed struct {
+	UserID    string
+	TokenHash string
+	CreatedAt time.Time
+}
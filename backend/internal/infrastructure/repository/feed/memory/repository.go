@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/memory/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/memory/model"
+)
+
+var _ feed.Repository = (*Repository)(nil)
+
+type Repository struct {
+	feeds map[uuid.UUID]repoModel.Feed
+	mu    sync.RWMutex
+}
+
+func NewRepository(_ config.Config) *Repository {
+	return &Repository{
+		feeds: make(map[uuid.UUID]repoModel.Feed),
+	}
+}
+
+func (r *Repository) GetByHash(_ context.Context, hash string) (feed.Feed, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, f := range r.feeds {
+		if f.TokenHash == hash {
+			return converter.ToFeedFromRepo(f), nil
+		}
+	}
+
+	return feed.Feed{}, feed.ErrFeedNotFound
+}
+
+func (r *Repository) GetByUserID(_ context.Context, userId uuid.UUID) (feed.Feed, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f, ok := r.feeds[userId]
+	if !ok {
+		return feed.Feed{}, feed.ErrFeedNotFound
+	}
+
+	return converter.ToFeedFromRepo(f), nil
+}
+
+func (r *Repository) Save(_ context.Context, f feed.Feed) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.feeds == nil {
+		r.feeds = make(map[uuid.UUID]repoModel.Feed)
+	}
+
+	r.feeds[f.UserID] = converter.ToRepoFromFeed(f)
+
+	return nil
+}
+
+func (r *Repository) DeleteByUserID(_ context.Context, userId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.feeds[userId]; !ok {
+		return feed.ErrFeedNotFound
+	}
+
+	delete(r.feeds, userId)
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/memory"
+)
+
+func TestRepositorySave(t *testing.T) {
+	r := repository.NewRepository(config.Config{})
+
+	f, raw, err := feed.NewFeed(uuid.New())
+	if err != nil {
+		t.Errorf("Save() failed to create a new feed: err = '%v'", err)
+	}
+
+	// Check if the user has a feed: should fail
+	_, err = r.GetByUserID(context.Background(), f.UserID)
+	if !errors.Is(err, feed.ErrFeedNotFound) {
+		t.Errorf("GetByUserID() got = '%v', want = '%v'", err, feed.ErrFeedNotFound)
+	}
+
+	// Save the feed: should be found by the hash of its token
+	err = r.Save(context.Background(), f)
+	if err != nil {
+		t.Errorf("Save() err = '%v'", err)
+	}
+
+	found, err := r.GetByHash(context.Background(), feed.HashToken(raw))
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if found.UserID != f.UserID {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", found.UserID, f.UserID)
+	}
+
+	// Save a new feed of the user: should replace the old one
+	next, nextRaw, err := feed.NewFeed(f.UserID)
+	if err != nil {
+		t.Errorf("Save() failed to create a new feed: err = '%v'", err)
+	}
+
+	err = r.Save(context.Background(), next)
+	if err != nil {
+		t.Errorf("Save() err = '%v'", err)
+	}
+
+	_, err = r.GetByHash(context.Background(), feed.HashToken(raw))
+	if !errors.Is(err, feed.ErrFeedNotFound) {
+		t.Errorf("GetByHash() of the replaced token got = '%v', want = '%v'", err, feed.ErrFeedNotFound)
+	}
+
+	found, err = r.GetByUserID(context.Background(), f.UserID)
+	if err != nil {
+		t.Errorf("GetByUserID() err = '%v'", err)
+	}
+
+	if found.TokenHash != feed.HashToken(nextRaw) {
+		t.Errorf("GetByUserID() got = '%v', want = '%v'", found.TokenHash, feed.HashToken(nextRaw))
+	}
+
+	// Delete the feed: should succeed once
+	err = r.DeleteByUserID(context.Background(), f.UserID)
+	if err != nil {
+		t.Errorf("DeleteByUserID() err = '%v'", err)
+	}
+
+	err = r.DeleteByUserID(context.Background(), f.UserID)
+	if !errors.Is(err, feed.ErrFeedNotFound) {
+		t.Errorf("DeleteByUserID() got = '%v', want = '%v'", err, feed.ErrFeedNotFound)
+	}
+}
@@ -0,0 +1,11 @@
+package model
+
+import (
+	"time"
+)
+
+type Feed struct {
+	UserID    string    `bson:"_id"`
+	TokenHash string    `bson:"token_hash"`
+	CreatedAt time.Time `bson:"created_at"`
+}
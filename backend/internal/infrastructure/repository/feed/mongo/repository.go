@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/mongo/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/mongo/model"
+	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ feed.Repository = (*Repository)(nil)
+
+type Repository struct {
+	collection *mongo.Collection
+}
+
+func NewRepository(cfg config.Config) *Repository {
+	collection := mongodb.NewOrGetSingleton(cfg).Collection("feeds")
+
+	return &Repository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "token_hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
+
+func (r *Repository) GetByHash(ctx context.Context, hash string) (feed.Feed, error) {
+	return r.findOne(ctx, bson.M{"token_hash": hash})
+}
+
+func (r *Repository) GetByUserID(ctx context.Context, userId uuid.UUID) (feed.Feed, error) {
+	return r.findOne(ctx, bson.M{"_id": userId.String()})
+}
+
+func (r *Repository) Save(ctx context.Context, f feed.Feed) error {
+	_, err := r.collection.ReplaceOne(
+		ctx,
+		bson.M{"_id": f.UserID.String()},
+		converter.ToRepoFromFeed(f),
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return feed.ErrFailedToSaveFeed
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteByUserID(ctx context.Context, userId uuid.UUID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": userId.String()})
+	if err != nil {
+		return feed.ErrFailedToDeleteFeed
+	}
+
+	if result.DeletedCount == 0 {
+		return feed.ErrFeedNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) findOne(ctx context.Context, filter bson.M) (feed.Feed, error) {
+	var f repoModel.Feed
+
+	err := r.collection.FindOne(ctx, filter).Decode(&f)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return feed.Feed{}, feed.ErrFeedNotFound
+		}
+
+		return feed.Feed{}, err
+	}
+
+	return converter.ToFeedFromRepo(f), nil
+}
@@ -0,0 +1,125 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/mongo"
+)
+
+var (
+	MONGODB_PORT = ""
+)
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	resource, err := pool.Run("mongo", "latest", []string{})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+
+	err = pool.Retry(func() error {
+		MONGODB_PORT = resource.GetPort("27017/tcp")
+		_, err := net.Dial("tcp", net.JoinHostPort("localhost", MONGODB_PORT))
+		return err
+	})
+
+	if err != nil {
+		log.Fatalf("Could not connect to database: %s", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+func TestRepositorySave(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	r := repository.NewRepository(cfg)
+	err := r.EnsureIndexes(context.Background())
+	if err != nil {
+		t.Errorf("EnsureIndexes() err = '%v'", err)
+	}
+
+	f, raw, err := feed.NewFeed(uuid.New())
+	if err != nil {
+		t.Errorf("Save() failed to create a new feed: err = '%v'", err)
+	}
+
+	// Check if the user has a feed: should fail
+	_, err = r.GetByUserID(context.Background(), f.UserID)
+	if !errors.Is(err, feed.ErrFeedNotFound) {
+		t.Errorf("GetByUserID() got = '%v', want = '%v'", err, feed.ErrFeedNotFound)
+	}
+
+	// Save the feed: should be found by the hash of its token
+	err = r.Save(context.Background(), f)
+	if err != nil {
+		t.Errorf("Save() err = '%v'", err)
+	}
+
+	found, err := r.GetByHash(context.Background(), feed.HashToken(raw))
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if found.UserID != f.UserID {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", found.UserID, f.UserID)
+	}
+
+	// Save a new feed of the user: should replace the old one
+	next, nextRaw, err := feed.NewFeed(f.UserID)
+	if err != nil {
+		t.Errorf("Save() failed to create a new feed: err = '%v'", err)
+	}
+
+	err = r.Save(context.Background(), next)
+	if err != nil {
+		t.Errorf("Save() err = '%v'", err)
+	}
+
+	_, err = r.GetByHash(context.Background(), feed.HashToken(raw))
+	if !errors.Is(err, feed.ErrFeedNotFound) {
+		t.Errorf("GetByHash() of the replaced token got = '%v', want = '%v'", err, feed.ErrFeedNotFound)
+	}
+
+	found, err = r.GetByUserID(context.Background(), f.UserID)
+	if err != nil {
+		t.Errorf("GetByUserID() err = '%v'", err)
+	}
+
+	if found.TokenHash != feed.HashToken(nextRaw) {
+		t.Errorf("GetByUserID() got = '%v', want = '%v'", found.TokenHash, feed.HashToken(nextRaw))
+	}
+
+	// Delete the feed: should succeed once
+	err = r.DeleteByUserID(context.Background(), f.UserID)
+	if err != nil {
+		t.Errorf("DeleteByUserID() err = '%v'", err)
+	}
+
+	err = r.DeleteByUserID(context.Background(), f.UserID)
+	if !errors.Is(err, feed.ErrFeedNotFound) {
+		t.Errorf("DeleteByUserID() got = '%v', want = '%v'", err, feed.ErrFeedNotFound)
+	}
+}
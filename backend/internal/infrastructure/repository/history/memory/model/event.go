@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+type State struct {
+	Text           string
+	Completed      bool
+	ListID         *string
+	ParentID       *string
+	DueAt          *time.Time
+	ReminderOffset *time.Duration
+	Recurrence     string
+	Tags           []string
+	DeletedAt      *time.Time
+	Position       float64
+	Priority       int
+	Important      *bool
+	Urgent         *bool
+}
+
+type Event struct {
+	ID         string
+	TaskID     string
+	ActorID    string
+	Operation  string
+	Before     *State
+	After      *State
+	OccurredAt time.Time
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/history"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/history/memory/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/history/memory/model"
+)
+
+var _ history.Repository = (*Repository)(nil)
+
+type Repository struct {
+	// events are kept per task in the order they were appended.
+	events map[uuid.UUID][]repoModel.Event
+	mu     sync.RWMutex
+}
+
+func NewRepository(_ config.Config) *Repository {
+	return &Repository{
+		events: make(map[uuid.UUID][]repoModel.Event),
+	}
+}
+
+// Snapshot saves the events and returns a function bringing them back.
+func (r *Repository) Snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make(map[uuid.UUID][]repoModel.Event, len(r.events))
+	for id, e := range r.events {
+		events[id] = e[:len(e):len(e)]
+	}
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.events = events
+	}
+}
+
+func (r *Repository) Append(_ context.Context, e history.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.events == nil {
+		r.events = make(map[uuid.UUID][]repoModel.Event)
+	}
+
+	r.events[e.TaskID] = append(r.events[e.TaskID], converter.ToRepoFromEvent(e))
+
+	return nil
+}
+
+func (r *Repository) GetByTaskID(_ context.Context, taskId uuid.UUID) ([]history.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]history.Event, 0, len(r.events[taskId]))
+	for _, e := range r.events[taskId] {
+		events = append(events, converter.ToEventFromRepo(e))
+	}
+
+	return events, nil
+}
@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/history"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/history/memory"
+)
+
+func TestRepositoryGetByTaskID(t *testing.T) {
+	cfg := config.Config{}
+
+	taskId := uuid.New()
+	actorId := uuid.New()
+
+	created, err := history.NewEvent(taskId, actorId, history.OpCreate, nil, &history.State{Text: "before"})
+	if err != nil {
+		t.Errorf("GetByTaskID() failed to create a new event: err = '%v'", err)
+	}
+
+	updated, err := history.NewEvent(taskId, actorId, history.OpUpdateText, created.After, &history.State{Text: "after"})
+	if err != nil {
+		t.Errorf("GetByTaskID() failed to create a new event: err = '%v'", err)
+	}
+	updated.OccurredAt = created.OccurredAt.Add(time.Second)
+
+	other, err := history.NewEvent(uuid.New(), actorId, history.OpCreate, nil, &history.State{Text: "other"})
+	if err != nil {
+		t.Errorf("GetByTaskID() failed to create a new event: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	for _, e := range []history.Event{created, updated, other} {
+		err = r.Append(context.Background(), e)
+		if err != nil {
+			t.Errorf("Append() err = '%v'", err)
+		}
+	}
+
+	events, err := r.GetByTaskID(context.Background(), taskId)
+	if err != nil {
+		t.Errorf("GetByTaskID() err = '%v'", err)
+	}
+
+	if len(events) != 2 || events[0].ID != created.ID || events[1].ID != updated.ID {
+		t.Fatalf("GetByTaskID() got = '%v', want = '%v'", events, []history.Event{created, updated})
+	}
+
+	if events[0].Before != nil || events[1].Before == nil || events[1].Before.Text != "before" || events[1].After.Text != "after" {
+		t.Errorf("GetByTaskID() got = '%v', want = '%v'", events, []history.Event{created, updated})
+	}
+}
@@ -0,0 +1,109 @@
+package converter
+
+import (
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/history"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/history/mongo/model"
+)
+
+func ToEventFromRepo(e repoModel.Event) history.Event {
+	return history.Event{
+		ID:         uuid.MustParse(e.ID),
+		TaskID:     uuid.MustParse(e.TaskID),
+		ActorID:    uuid.MustParse(e.ActorID),
+		Operation:  history.Operation(e.Operation),
+		Before:     toStateFromRepo(e.Before),
+		After:      toStateFromRepo(e.After),
+		OccurredAt: e.OccurredAt,
+	}
+}
+
+func ToRepoFromEvent(e history.Event) repoModel.Event {
+	return repoModel.Event{
+		ID:         e.ID.String(),
+		TaskID:     e.TaskID.String(),
+		ActorID:    e.ActorID.String(),
+		Operation:  string(e.Operation),
+		Before:     toRepoFromState(e.Before),
+		After:      toRepoFromState(e.After),
+		OccurredAt: e.OccurredAt,
+	}
+}
+
+func toStateFromRepo(s *repoModel.State) *history.State {
+	if s == nil {
+		return nil
+	}
+
+	return &history.State{
+		Text:           s.Text,
+		Completed:      s.Completed,
+		ListID:         toUUIDPtr(s.ListID),
+		ParentID:       toUUIDPtr(s.ParentID),
+		DueAt:          s.DueAt,
+		ReminderOffset: s.ReminderOffset,
+		Recurrence:     s.Recurrence,
+		Tags:           s.Tags,
+		DeletedAt:      s.DeletedAt,
+		Position:       s.Position,
+		Priority:       task.Priority(s.Priority),
+		Eisenhower:     toEisenhowerPtr(s.Important, s.Urgent),
+	}
+}
+
+func toRepoFromState(s *history.State) *repoModel.State {
+	if s == nil {
+		return nil
+	}
+
+	var important, urgent *bool
+	if s.Eisenhower != nil {
+		i, u := s.Eisenhower.Important, s.Eisenhower.Urgent
+		important, urgent = &i, &u
+	}
+
+	return &repoModel.State{
+		Text:           s.Text,
+		Completed:      s.Completed,
+		ListID:         toStringPtr(s.ListID),
+		ParentID:       toStringPtr(s.ParentID),
+		DueAt:          s.DueAt,
+		ReminderOffset: s.ReminderOffset,
+		Recurrence:     s.Recurrence,
+		Tags:           s.Tags,
+		DeletedAt:      s.DeletedAt,
+		Position:       s.Position,
+		Priority:       int(s.Priority),
+		Important:      important,
+		Urgent:         urgent,
+	}
+}
+
+func toUUIDPtr(s *string) *uuid.UUID {
+	if s == nil {
+		return nil
+	}
+
+	id := uuid.MustParse(*s)
+
+	return &id
+}
+
+func toStringPtr(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+
+	s := id.String()
+
+	return &s
+}
+
+func toEisenhowerPtr(important, urgent *bool) *task.Eisenhower {
+	if important == nil || urgent == nil {
+		return nil
+	}
+
+	return &task.Eisenhower{Important: *important, Urgent: *urgent}
+}
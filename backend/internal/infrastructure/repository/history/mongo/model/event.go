@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+type State struct {
+	Text           string         `bson:"text"`
+	Completed      bool           `bson:"completed"`
+	ListID         *string        `bson:"list_id,omitempty"`
+	ParentID       *string        `bson:"parent_id,omitempty"`
+	DueAt          *time.Time     `bson:"due_at,omitempty"`
+	ReminderOffset *time.Duration `bson:"reminder_offset,omitempty"`
+	Recurrence     string         `bson:"recurrence,omitempty"`
+	Tags           []string       `bson:"tags,omitempty"`
+	DeletedAt      *time.Time     `bson:"deleted_at,omitempty"`
+	Position       float64        `bson:"position"`
+	Priority       int            `bson:"priority"`
+	Important      *bool          `bson:"important,omitempty"`
+	Urgent         *bool          `bson:"urgent,omitempty"`
+}
+
+type Event struct {
+	ID         string    `bson:"_id"`
+	TaskID     string    `bson:"task_id"`
+	ActorID    string    `bson:"actor_id"`
+	Operation  string    `bson:"operation"`
+	Before     *State    `bson:"before,omitempty"`
+	After      *State    `bson:"after"`
+	OccurredAt time.Time `bson:"occurred_at"`
+}
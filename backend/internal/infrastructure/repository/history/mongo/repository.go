@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/history"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/history/mongo/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/history/mongo/model"
+	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ history.Repository = (*Repository)(nil)
+
+type Repository struct {
+	collection *mongo.Collection
+}
+
+func NewRepository(cfg config.Config) *Repository {
+	collection := mongodb.NewOrGetSingleton(cfg).Collection("task_history")
+
+	return &Repository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "occurred_at", Value: 1}},
+	})
+
+	return err
+}
+
+func (r *Repository) Append(ctx context.Context, e history.Event) error {
+	_, err := r.collection.InsertOne(ctx, converter.ToRepoFromEvent(e))
+	if err != nil {
+		return history.ErrFailedToSaveEvent
+	}
+
+	return nil
+}
+
+func (r *Repository) GetByTaskID(ctx context.Context, taskId uuid.UUID) ([]history.Event, error) {
+	filter := bson.M{"task_id": taskId.String()}
+	sort := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, sort)
+	if err != nil {
+		return []history.Event{}, err
+	}
+
+	var mongoEvents []repoModel.Event
+
+	err = cursor.All(ctx, &mongoEvents)
+	if err != nil {
+		return []history.Event{}, err
+	}
+
+	events := make([]history.Event, 0, len(mongoEvents))
+	for _, mongoEvent := range mongoEvents {
+		events = append(events, converter.ToEventFromRepo(mongoEvent))
+	}
+
+	return events, nil
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+type Member struct {
+	UserID  string
+	Role    string
+	AddedAt time.Time
+}
+
+type List struct {
+	ID        string
+	Name      string
+	Members   []Member
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/memory/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/memory/model"
+)
+
+var _ list.Repository = (*Repository)(nil)
+
+type Repository struct {
+	lists map[uuid.UUID]repoModel.List
+	mu    sync.RWMutex
+}
+
+func NewRepository(_ config.Config) *Repository {
+	return &Repository{
+		lists: make(map[uuid.UUID]repoModel.List),
+	}
+}
+
+// Snapshot saves the lists and returns a function bringing them back.
+func (r *Repository) Snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lists := make(map[uuid.UUID]repoModel.List, len(r.lists))
+	for id, l := range r.lists {
+		lists[id] = l
+	}
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.lists = lists
+	}
+}
+
+func (r *Repository) GetByID(_ context.Context, id uuid.UUID) (list.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lists == nil {
+		r.lists = make(map[uuid.UUID]repoModel.List)
+	}
+
+	if l, ok := r.lists[id]; ok {
+		return converter.ToListFromRepo(l), nil
+	}
+
+	return list.List{}, list.ErrListNotFound
+}
+
+func (r *Repository) GetAllByMemberID(_ context.Context, userId uuid.UUID) ([]list.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lists == nil {
+		r.lists = make(map[uuid.UUID]repoModel.List)
+	}
+
+	lists := []list.List{}
+	for _, l := range r.lists {
+		for _, m := range l.Members {
+			if m.UserID == userId.String() {
+				lists = append(lists, converter.ToListFromRepo(l))
+				break
+			}
+		}
+	}
+
+	sort.Slice(lists, func(i, j int) bool { return lists[i].CreatedAt.UnixNano() < lists[j].CreatedAt.UnixNano() })
+
+	return lists, nil
+}
+
+func (r *Repository) Save(_ context.Context, l list.List) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lists == nil {
+		r.lists = make(map[uuid.UUID]repoModel.List)
+	}
+
+	if _, ok := r.lists[l.ID]; ok {
+		return fmt.Errorf("list already exists: %w", list.ErrFailedToSaveList)
+	}
+
+	r.lists[l.ID] = converter.ToRepoFromList(l)
+
+	return nil
+}
+
+func (r *Repository) Update(_ context.Context, l list.List) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lists == nil {
+		r.lists = make(map[uuid.UUID]repoModel.List)
+	}
+
+	if _, ok := r.lists[l.ID]; !ok {
+		return list.ErrListNotFound
+	}
+
+	r.lists[l.ID] = converter.ToRepoFromList(l)
+
+	return nil
+}
+
+func (r *Repository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lists == nil {
+		r.lists = make(map[uuid.UUID]repoModel.List)
+	}
+
+	if _, ok := r.lists[id]; !ok {
+		return list.ErrListNotFound
+	}
+
+	delete(r.lists, id)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package converter
+
+import (
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/mongo/model"
+)
+
+func ToListFromRepo(l repoModel.List) list.List {
+	members := make([]list.Member, 0, len(l.Members))
+	for _, m := range l.Members {
+		members = append(members, list.Member{
+			UserID:  uuid.MustParse(m.UserID),
+			Role:    list.Role(m.Role),
+			AddedAt: m.AddedAt,
+		})
+	}
+
+	return list.List{
+		ID:        uuid.MustParse(l.ID),
+		Name:      l.Name,
+		Members:   members,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
+
+func ToRepoFromList(l list.List) repoModel.List {
+	members := make([]repoModel.Member, 0, len(l.Members))
+	for _, m := range l.Members {
+		members = append(members, repoModel.Member{
+			UserID:  m.UserID.String(),
+			Role:    string(m.Role),
+			AddedAt: m.AddedAt,
+		})
+	}
+
+	return repoModel.List{
+		ID:        l.ID.String(),
+		Name:      l.Name,
+		Members:   members,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
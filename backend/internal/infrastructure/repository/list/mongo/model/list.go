@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+type Member struct {
+	UserID  string    `bson:"user_id"`
+	Role    string    `bson:"role"`
+	AddedAt time.Time `bson:"added_at"`
+}
+
+type List struct {
+	ID        string    `bson:"_id"`
+	Name      string    `bson:"name"`
+	Members   []Member  `bson:"members"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
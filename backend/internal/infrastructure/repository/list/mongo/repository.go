@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/mongo/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/mongo/model"
+	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ list.Repository = (*Repository)(nil)
+
+type Repository struct {
+	collection *mongo.Collection
+}
+
+func NewRepository(cfg config.Config) *Repository {
+	collection := mongodb.NewOrGetSingleton(cfg).Collection("lists")
+
+	return &Repository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "members.user_id", Value: 1}},
+	})
+
+	return err
+}
+
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (list.List, error) {
+	var mongoList repoModel.List
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&mongoList)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return list.List{}, list.ErrListNotFound
+		}
+
+		return list.List{}, err
+	}
+
+	return converter.ToListFromRepo(mongoList), nil
+}
+
+func (r *Repository) GetAllByMemberID(ctx context.Context, userId uuid.UUID) ([]list.List, error) {
+	filter := bson.M{"members.user_id": userId.String()}
+	sort := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, sort)
+	if err != nil {
+		return []list.List{}, err
+	}
+
+	var mongoLists []repoModel.List
+
+	err = cursor.All(ctx, &mongoLists)
+	if err != nil {
+		return []list.List{}, err
+	}
+
+	lists := make([]list.List, 0, len(mongoLists))
+	for _, mongoList := range mongoLists {
+		lists = append(lists, converter.ToListFromRepo(mongoList))
+	}
+
+	return lists, nil
+}
+
+func (r *Repository) Save(ctx context.Context, l list.List) error {
+	_, err := r.collection.InsertOne(ctx, converter.ToRepoFromList(l))
+	if err != nil {
+		return list.ErrFailedToSaveList
+	}
+
+	return nil
+}
+
+func (r *Repository) Update(ctx context.Context, l list.List) error {
+	mongoList := converter.ToRepoFromList(l)
+	filter := bson.M{"_id": mongoList.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"name":       mongoList.Name,
+			"members":    mongoList.Members,
+			"updated_at": mongoList.UpdatedAt,
+		},
+	}
+
+	result := r.collection.FindOneAndUpdate(ctx, filter, update)
+	if result.Err() != nil {
+		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+			return list.ErrListNotFound
+		}
+
+		return list.ErrFailedUpdateList
+	}
+
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.collection.FindOneAndDelete(ctx, bson.M{"_id": id.String()})
+	if result.Err() != nil {
+		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+			return list.ErrListNotFound
+		}
+
+		return list.ErrFailedDeleteList
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/mongo"
+)
+
+var (
+	MONGODB_PORT = ""
+)
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	resource, err := pool.Run("mongo", "latest", []string{})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+
+	err = pool.Retry(func() error {
+		MONGODB_PORT = resource.GetPort("27017/tcp")
+		_, err := net.Dial("tcp", net.JoinHostPort("localhost", MONGODB_PORT))
+		return err
+	})
+
+	if err != nil {
+		log.Fatalf("Could not connect to database: %s", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+func TestRepositoryGetByID(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	l, err := list.NewList("Groceries", uuid.New())
+	if err != nil {
+		t.Errorf("GetByID() failed to create a new list: err = '%v'", err)
+	}
+
+	// Check if a list exists in the DB: should fail
+	r := repository.NewRepository(cfg)
+	_, err = r.GetByID(context.Background(), l.ID)
+	if !errors.Is(err, list.ErrListNotFound) {
+		t.Errorf("GetByID() got = '%v', want = '%v'", err, list.ErrListNotFound)
+	}
+
+	// Save the list into the DB
+	err = r.Save(context.Background(), l)
+	if err != nil {
+		t.Errorf("GetByID() failed to save a new list: err = '%v'", err)
+	}
+
+	// Check if a list exists in the DB: should succeed
+	found, err := r.GetByID(context.Background(), l.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v', want = '%v'", err, nil)
+	}
+
+	if found.ID != l.ID || found.Name != l.Name {
+		t.Errorf("GetByID() got = '%v', want = '%v'", found, l)
+	}
+
+	if len(found.Members) != 1 || found.Members[0].Role != list.RoleOwner {
+		t.Errorf("GetByID() got = '%v', want = '%v'", found.Members, l.Members)
+	}
+}
+
+func TestRepositoryGetAllByMemberID(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	ownerId := uuid.New()
+	memberId := uuid.New()
+
+	shared, err := list.NewList("Shared", ownerId)
+	if err != nil {
+		t.Errorf("GetAllByMemberID() failed to create a new list: err = '%v'", err)
+	}
+
+	err = shared.AddMember(memberId, list.RoleViewer)
+	if err != nil {
+		t.Errorf("GetAllByMemberID() failed to add a member: err = '%v'", err)
+	}
+
+	private, err := list.NewList("Private", ownerId)
+	if err != nil {
+		t.Errorf("GetAllByMemberID() failed to create a new list: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	for _, l := range []list.List{shared, private} {
+		err = r.Save(context.Background(), l)
+		if err != nil {
+			t.Errorf("GetAllByMemberID() failed to save a new list: err = '%v'", err)
+		}
+	}
+
+	lists, err := r.GetAllByMemberID(context.Background(), ownerId)
+	if err != nil {
+		t.Errorf("GetAllByMemberID() err = '%v'", err)
+	}
+
+	if len(lists) != 2 {
+		t.Errorf("GetAllByMemberID() got = '%v', want = '%v'", len(lists), 2)
+	}
+
+	lists, err = r.GetAllByMemberID(context.Background(), memberId)
+	if err != nil {
+		t.Errorf("GetAllByMemberID() err = '%v'", err)
+	}
+
+	if len(lists) != 1 || lists[0].ID != shared.ID {
+		t.Errorf("GetAllByMemberID() got = '%v', want = '%v'", lists, shared.ID)
+	}
+}
+
+func TestRepositoryUpdate(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	l, err := list.NewList("Groceries", uuid.New())
+	if err != nil {
+		t.Errorf("Update() failed to create a new list: err = '%v'", err)
+	}
+
+	// Update a list that does not exist: should fail
+	r := repository.NewRepository(cfg)
+	err = r.Update(context.Background(), l)
+	if !errors.Is(err, list.ErrListNotFound) {
+		t.Errorf("Update() got = '%v', want = '%v'", err, list.ErrListNotFound)
+	}
+
+	err = r.Save(context.Background(), l)
+	if err != nil {
+		t.Errorf("Update() failed to save a new list: err = '%v'", err)
+	}
+
+	memberId := uuid.New()
+	_ = l.SetName("Shopping")
+	_ = l.AddMember(memberId, list.RoleEditor)
+
+	err = r.Update(context.Background(), l)
+	if err != nil {
+		t.Errorf("Update() err = '%v'", err)
+	}
+
+	found, err := r.GetByID(context.Background(), l.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v'", err)
+	}
+
+	if found.Name != "Shopping" {
+		t.Errorf("Update() got = '%v', want = '%v'", found.Name, "Shopping")
+	}
+
+	if role, ok := found.RoleOf(memberId); !ok || role != list.RoleEditor {
+		t.Errorf("Update() got = '%v', want = '%v'", role, list.RoleEditor)
+	}
+}
+
+func TestRepositoryDelete(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	l, err := list.NewList("Groceries", uuid.New())
+	if err != nil {
+		t.Errorf("Delete() failed to create a new list: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	err = r.Save(context.Background(), l)
+	if err != nil {
+		t.Errorf("Delete() failed to save a new list: err = '%v'", err)
+	}
+
+	err = r.Delete(context.Background(), l.ID)
+	if err != nil {
+		t.Errorf("Delete() err = '%v'", err)
+	}
+
+	_, err = r.GetByID(context.Background(), l.ID)
+	if !errors.Is(err, list.ErrListNotFound) {
+		t.Errorf("Delete() got = '%v', want = '%v'", err, list.ErrListNotFound)
+	}
+}
@@ -0,0 +1,13 @@
+package model
+
+import (
+	"time"
+)
+
+type Attempts struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	// ExpiresAt is when the attempts can be dropped, zero if never.
+	ExpiresAt time.Time
+}
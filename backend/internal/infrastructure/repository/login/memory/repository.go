@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/memory/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/memory/model"
+)
+
+var _ login.Repository = (*Repository)(nil)
+
+// pruneInterval is how often the expired attempts are dropped.
+const pruneInterval = time.Minute
+
+// Repository keeps the attempts of a single instance of the app.
+type Repository struct {
+	attempts map[string]repoModel.Attempts
+	prunedAt time.Time
+	mu       sync.RWMutex
+}
+
+func NewRepository(_ config.Config) *Repository {
+	return &Repository{
+		attempts: make(map[string]repoModel.Attempts),
+	}
+}
+
+func (r *Repository) Get(_ context.Context, key string) (login.Attempts, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.attempts[key]
+	if !ok || isExpired(a, time.Now()) {
+		return login.Attempts{Key: key}, nil
+	}
+
+	return converter.ToAttemptsFromRepo(a), nil
+}
+
+func (r *Repository) AddFailure(_ context.Context, seen login.Attempts, at time.Time, window time.Duration) (login.Attempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.attempts == nil {
+		r.attempts = make(map[string]repoModel.Attempts)
+	}
+
+	r.prune(at)
+
+	a, ok := r.attempts[seen.Key]
+	if !ok || isExpired(a, at) {
+		a = repoModel.Attempts{Key: seen.Key}
+	}
+
+	if a.Failures != seen.Failures || !a.LastFailureAt.Equal(seen.LastFailureAt) {
+		return login.Attempts{}, login.ErrAttemptsChanged
+	}
+
+	if window > 0 && at.Sub(a.LastFailureAt) > window {
+		a = repoModel.Attempts{Key: seen.Key}
+	}
+
+	a.Failures++
+	a.LastFailureAt = at
+	a.ExpiresAt = time.Time{}
+	if window > 0 {
+		a.ExpiresAt = at.Add(window)
+	}
+
+	r.attempts[seen.Key] = a
+
+	return converter.ToAttemptsFromRepo(a), nil
+}
+
+func (r *Repository) RemoveFailure(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if a, ok := r.attempts[key]; ok && a.Failures > 0 {
+		a.Failures--
+		r.attempts[key] = a
+	}
+
+	return nil
+}
+
+func (r *Repository) Reset(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+
+	return nil
+}
+
+// prune drops the expired attempts, at most once in the pruneInterval.
+func (r *Repository) prune(now time.Time) {
+	if now.Sub(r.prunedAt) < pruneInterval {
+		return
+	}
+
+	for key, a := range r.attempts {
+		if isExpired(a, now) {
+			delete(r.attempts, key)
+		}
+	}
+
+	r.prunedAt = now
+}
+
+func isExpired(a repoModel.Attempts, now time.Time) bool {
+	return !a.ExpiresAt.IsZero() && now.After(a.ExpiresAt)
+}
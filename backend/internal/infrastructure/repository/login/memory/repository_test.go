@@ -0,0 +1,135 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/memory"
+)
+
+func TestRepositoryAddFailure(t *testing.T) {
+	cfg := config.Config{}
+
+	r := repository.NewRepository(cfg)
+	key, err := login.NewKey(login.ScopeAccount, uuid.NewString()+"@example.com")
+	if err != nil {
+		t.Fatalf("AddFailure() failed to create a key: err = '%v'", err)
+	}
+
+	// Get the attempts of a key without failures: should be empty
+	a, err := r.Get(context.Background(), key)
+	if err != nil {
+		t.Errorf("Get() err = '%v', want = '%v'", err, nil)
+	}
+	if a.Key != key || a.Failures != 0 {
+		t.Errorf("Get() got = '%v', want no failures of '%v'", a, key)
+	}
+
+	// Count the failures
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		a, err = r.AddFailure(context.Background(), a, now, time.Hour)
+		if err != nil {
+			t.Errorf("AddFailure() err = '%v', want = '%v'", err, nil)
+		}
+		if a.Failures != i {
+			t.Errorf("AddFailure() got = '%v', want = '%v'", a.Failures, i)
+		}
+	}
+
+	found, err := r.Get(context.Background(), key)
+	if err != nil {
+		t.Errorf("Get() err = '%v', want = '%v'", err, nil)
+	}
+	if found.Failures != 3 || found.LastFailureAt.Sub(now).Abs() > time.Millisecond {
+		t.Errorf("Get() got = '%v', want 3 failures at '%v'", found, now)
+	}
+
+	// A failure on attempts no longer stored: should fail
+	_, err = r.AddFailure(context.Background(), login.Attempts{Key: key}, now, time.Hour)
+	if !errors.Is(err, login.ErrAttemptsChanged) {
+		t.Errorf("AddFailure() err = '%v', want = '%v'", err, login.ErrAttemptsChanged)
+	}
+
+	// Take a failure back: should keep the time of the last one
+	err = r.RemoveFailure(context.Background(), key)
+	if err != nil {
+		t.Errorf("RemoveFailure() err = '%v', want = '%v'", err, nil)
+	}
+
+	found, err = r.Get(context.Background(), key)
+	if err != nil {
+		t.Errorf("Get() err = '%v', want = '%v'", err, nil)
+	}
+	if found.Failures != 2 || !found.LastFailureAt.Equal(a.LastFailureAt) {
+		t.Errorf("RemoveFailure() got = '%v', want 2 failures at '%v'", found, a.LastFailureAt)
+	}
+
+	// A failure after the window, when the attempts are read as none: should count anew
+	a, err = r.AddFailure(context.Background(), login.Attempts{Key: key}, now.Add(2*time.Hour), time.Hour)
+	if err != nil {
+		t.Errorf("AddFailure() err = '%v', want = '%v'", err, nil)
+	}
+	if a.Failures != 1 {
+		t.Errorf("AddFailure() after the window got = '%v', want = '%v'", a.Failures, 1)
+	}
+}
+
+func TestRepositoryReset(t *testing.T) {
+	cfg := config.Config{}
+
+	r := repository.NewRepository(cfg)
+	key, err := login.NewKey(login.ScopeIP, "192.0.2."+strconv.Itoa(rand.Intn(256)))
+	if err != nil {
+		t.Fatalf("Reset() failed to create a key: err = '%v'", err)
+	}
+
+	_, err = r.AddFailure(context.Background(), login.Attempts{Key: key}, time.Now(), time.Hour)
+	if err != nil {
+		t.Errorf("Reset() failed to add a failure: err = '%v'", err)
+	}
+
+	err = r.Reset(context.Background(), key)
+	if err != nil {
+		t.Errorf("Reset() err = '%v', want = '%v'", err, nil)
+	}
+
+	a, err := r.Get(context.Background(), key)
+	if err != nil {
+		t.Errorf("Get() err = '%v', want = '%v'", err, nil)
+	}
+	if a.Failures != 0 {
+		t.Errorf("Reset() got = '%v', want = '%v'", a.Failures, 0)
+	}
+
+	// Reset a key without failures: should succeed
+	err = r.Reset(context.Background(), key)
+	if err != nil {
+		t.Errorf("Reset() err = '%v', want = '%v'", err, nil)
+	}
+}
+
+func TestRepositoryExpiredAttempts(t *testing.T) {
+	r := repository.NewRepository(config.Config{})
+	key, _ := login.NewKey(login.ScopeIP, "192.0.2.1")
+
+	_, err := r.AddFailure(context.Background(), login.Attempts{Key: key}, time.Now().Add(-2*time.Hour), time.Hour)
+	if err != nil {
+		t.Errorf("AddFailure() err = '%v', want = '%v'", err, nil)
+	}
+
+	a, err := r.Get(context.Background(), key)
+	if err != nil {
+		t.Errorf("Get() err = '%v', want = '%v'", err, nil)
+	}
+	if a.Failures != 0 {
+		t.Errorf("Get() expired got = '%v', want = '%v'", a.Failures, 0)
+	}
+}
@@ -0,0 +1,14 @@
+package converter
+
+import (
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/mongo/model"
+)
+
+func ToAttemptsFromRepo(a repoModel.Attempts) login.Attempts {
+	return login.Attempts{
+		Key:           a.Key,
+		Failures:      a.Failures,
+		LastFailureAt: a.LastFailureAt,
+	}
+}
@@ -0,0 +1,12 @@
+package model
+
+import (
+	"time"
+)
+
+type Attempts struct {
+	Key           string     `bson:"_id"`
+	Failures      int        `bson:"failures"`
+	LastFailureAt time.Time  `bson:"last_failure_at"`
+	ExpiresAt     *time.Time `bson:"expires_at,omitempty"`
+}
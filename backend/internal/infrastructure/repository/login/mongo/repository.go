@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/mongo/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/mongo/model"
+	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ login.Repository = (*Repository)(nil)
+
+// Repository keeps the attempts shared by all the instances of the app.
+type Repository struct {
+	collection *mongo.Collection
+}
+
+func NewRepository(cfg config.Config) *Repository {
+	collection := mongodb.NewOrGetSingleton(cfg).Collection("login_attempts")
+
+	return &Repository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+
+	return err
+}
+
+func (r *Repository) Get(ctx context.Context, key string) (login.Attempts, error) {
+	var a repoModel.Attempts
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&a)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return login.Attempts{Key: key}, nil
+		}
+
+		return login.Attempts{}, err
+	}
+
+	// The TTL monitor drops the expired attempts only once a minute.
+	if a.ExpiresAt != nil && time.Now().After(*a.ExpiresAt) {
+		return login.Attempts{Key: key}, nil
+	}
+
+	return converter.ToAttemptsFromRepo(a), nil
+}
+
+// AddFailure counts the failure with a single update of the seen attempts, so the failures of the instances
+// are not lost and none of them is counted on attempts it has not seen.
+func (r *Repository) AddFailure(ctx context.Context, seen login.Attempts, at time.Time, window time.Duration) (login.Attempts, error) {
+	failures := bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failures", 0}}, 1}}
+	set := bson.M{"last_failure_at": at}
+
+	if window > 0 {
+		lastFailureAt := bson.M{"$ifNull": bson.A{"$last_failure_at", time.Time{}}}
+		failures = bson.M{"$cond": bson.A{bson.M{"$lt": bson.A{lastFailureAt, at.Add(-window)}}, 1, failures}}
+		set["expires_at"] = at.Add(window)
+	}
+
+	set["failures"] = failures
+
+	filter := bson.M{"_id": seen.Key, "failures": seen.Failures, "last_failure_at": seen.LastFailureAt}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	// No attempts have been seen if there are none stored or they have expired. The upsert fails
+	// on the duplicate id if others are stored.
+	if seen.LastFailureAt.IsZero() {
+		filter = bson.M{"_id": seen.Key, "expires_at": bson.M{"$lt": at}}
+		opts.SetUpsert(true)
+	}
+
+	var a repoModel.Attempts
+
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.A{bson.M{"$set": set}}, opts).Decode(&a)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) || mongo.IsDuplicateKeyError(err) {
+			return login.Attempts{}, login.ErrAttemptsChanged
+		}
+
+		return login.Attempts{}, login.ErrFailedToSaveAttempts
+	}
+
+	return converter.ToAttemptsFromRepo(a), nil
+}
+
+func (r *Repository) RemoveFailure(ctx context.Context, key string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key, "failures": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"failures": -1}})
+	if err != nil {
+		return login.ErrFailedToSaveAttempts
+	}
+
+	return nil
+}
+
+func (r *Repository) Reset(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	if err != nil {
+		return login.ErrFailedToSaveAttempts
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	taskModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/memory/model"
+)
+
+type Event struct {
+	ID         string
+	Type       string
+	Task       taskModel.Task
+	ActorID    string
+	OccurredAt time.Time
+}
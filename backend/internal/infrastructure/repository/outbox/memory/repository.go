@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/outbox"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/memory/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/memory/model"
+)
+
+var _ outbox.Repository = (*Repository)(nil)
+
+type Repository struct {
+	// events are the pending events in the order they were added, dispatched events are dropped.
+	events []repoModel.Event
+	mu     sync.RWMutex
+}
+
+func NewRepository(_ config.Config) *Repository {
+	return &Repository{}
+}
+
+// Snapshot saves the events and returns a function bringing them back.
+func (r *Repository) Snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := slices.Clone(r.events)
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.events = events
+	}
+}
+
+func (r *Repository) Add(_ context.Context, e task.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, converter.ToRepoFromEvent(e))
+
+	return nil
+}
+
+func (r *Repository) GetPending(_ context.Context, limit int) ([]task.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]task.Event, 0, min(limit, len(r.events)))
+	for _, e := range r.events[:min(limit, len(r.events))] {
+		events = append(events, converter.ToEventFromRepo(e))
+	}
+
+	return events, nil
+}
+
+func (r *Repository) MarkDispatched(_ context.Context, ids []uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dispatched := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		dispatched[id.String()] = true
+	}
+
+	r.events = slices.DeleteFunc(slices.Clone(r.events), func(e repoModel.Event) bool {
+		return dispatched[e.ID]
+	})
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/memory"
+)
+
+func TestRepositoryGetPending(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+	actorId := uuid.New()
+
+	ti, err := task.NewTask("test", userId, task.WithTags("work"))
+	if err != nil {
+		t.Fatalf("GetPending() failed to create a new task: err = '%v'", err)
+	}
+
+	now := time.Now().Truncate(time.Millisecond)
+
+	var events []task.Event
+	for i, eventType := range []task.EventType{task.EventCreated, task.EventUpdated, task.EventCompleted} {
+		e := task.NewEvent(eventType, ti, actorId)
+		e.OccurredAt = now.Add(time.Duration(i) * time.Second)
+		events = append(events, e)
+	}
+
+	r := repository.NewRepository(cfg)
+	for _, e := range events {
+		err = r.Add(context.Background(), e)
+		if err != nil {
+			t.Fatalf("Add() err = '%v'", err)
+		}
+	}
+
+	got, err := r.GetPending(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetPending() err = '%v'", err)
+	}
+	if len(got) != 2 || got[0].ID != events[0].ID || got[1].ID != events[1].ID {
+		t.Fatalf("GetPending() got = '%v', want the first two events", got)
+	}
+	if got[0].Type != task.EventCreated || got[0].ActorID != actorId || !got[0].OccurredAt.Equal(now) {
+		t.Errorf("GetPending() got = '%v', want = '%v'", got[0], events[0])
+	}
+	if got[0].Task.ID != ti.ID || got[0].Task.Text != ti.Text || !got[0].Task.HasTag("work") {
+		t.Errorf("GetPending() Task = '%v', want = '%v'", got[0].Task, ti)
+	}
+
+	err = r.MarkDispatched(context.Background(), []uuid.UUID{events[0].ID, events[1].ID})
+	if err != nil {
+		t.Fatalf("MarkDispatched() err = '%v'", err)
+	}
+
+	got, err = r.GetPending(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("GetPending() err = '%v'", err)
+	}
+	if len(got) != 1 || got[0].ID != events[2].ID {
+		t.Errorf("GetPending() after MarkDispatched() got = '%v', want the last event", got)
+	}
+}
+
+func TestRepositorySnapshot(t *testing.T) {
+	r := repository.NewRepository(config.Config{})
+
+	ti, err := task.NewTask("test", uuid.New())
+	if err != nil {
+		t.Fatalf("Snapshot() failed to create a new task: err = '%v'", err)
+	}
+
+	kept := task.NewEvent(task.EventCreated, ti, ti.UserID)
+	err = r.Add(context.Background(), kept)
+	if err != nil {
+		t.Fatalf("Add() err = '%v'", err)
+	}
+
+	restore := r.Snapshot()
+
+	err = r.Add(context.Background(), task.NewEvent(task.EventUpdated, ti, ti.UserID))
+	if err != nil {
+		t.Fatalf("Add() err = '%v'", err)
+	}
+	err = r.MarkDispatched(context.Background(), []uuid.UUID{kept.ID})
+	if err != nil {
+		t.Fatalf("MarkDispatched() err = '%v'", err)
+	}
+
+	restore()
+
+	got, err := r.GetPending(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("GetPending() err = '%v'", err)
+	}
+	if len(got) != 1 || got[0].ID != kept.ID {
+		t.Errorf("GetPending() after restore got = '%v', want the event added before the snapshot", got)
+	}
+}
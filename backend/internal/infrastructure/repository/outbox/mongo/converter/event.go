@@ -0,0 +1,28 @@
+package converter
+
+import (
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/mongo/model"
+	taskConverter "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/mongo/converter"
+)
+
+func ToEventFromRepo(e repoModel.Event) task.Event {
+	return task.Event{
+		ID:         uuid.MustParse(e.ID),
+		Type:       task.EventType(e.Type),
+		Task:       taskConverter.ToTaskFromRepo(e.Task),
+		ActorID:    uuid.MustParse(e.ActorID),
+		OccurredAt: e.OccurredAt,
+	}
+}
+
+func ToRepoFromEvent(e task.Event) repoModel.Event {
+	return repoModel.Event{
+		ID:         e.ID.String(),
+		Type:       string(e.Type),
+		Task:       taskConverter.ToRepoFromTask(e.Task),
+		ActorID:    e.ActorID.String(),
+		OccurredAt: e.OccurredAt,
+	}
+}
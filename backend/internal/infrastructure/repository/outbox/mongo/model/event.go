@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	taskModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/mongo/model"
+)
+
+type Event struct {
+	ID           string         `bson:"_id"`
+	Type         string         `bson:"type"`
+	Task         taskModel.Task `bson:"task"`
+	ActorID      string         `bson:"actor_id"`
+	OccurredAt   time.Time      `bson:"occurred_at"`
+	DispatchedAt *time.Time     `bson:"dispatched_at,omitempty"`
+}
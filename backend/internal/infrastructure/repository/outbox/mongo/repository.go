@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/outbox"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/mongo/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/mongo/model"
+	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// dispatchedRetention is how long the dispatched events are kept for inspection.
+	dispatchedRetention = 24 * time.Hour
+)
+
+var _ outbox.Repository = (*Repository)(nil)
+
+type Repository struct {
+	collection *mongo.Collection
+}
+
+func NewRepository(cfg config.Config) *Repository {
+	collection := mongodb.NewOrGetSingleton(cfg).Collection("outbox")
+
+	return &Repository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on. The dispatched events expire.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "dispatched_at", Value: 1}, {Key: "occurred_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "dispatched_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(dispatchedRetention.Seconds())),
+		},
+	})
+
+	return err
+}
+
+func (r *Repository) Add(ctx context.Context, e task.Event) error {
+	_, err := r.collection.InsertOne(ctx, converter.ToRepoFromEvent(e))
+	if err != nil {
+		return outbox.ErrFailedToAddEvent
+	}
+
+	return nil
+}
+
+func (r *Repository) GetPending(ctx context.Context, limit int) ([]task.Event, error) {
+	filter := bson.M{"dispatched_at": nil}
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: 1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return []task.Event{}, err
+	}
+
+	var mongoEvents []repoModel.Event
+
+	err = cursor.All(ctx, &mongoEvents)
+	if err != nil {
+		return []task.Event{}, err
+	}
+
+	events := make([]task.Event, 0, len(mongoEvents))
+	for _, mongoEvent := range mongoEvents {
+		events = append(events, converter.ToEventFromRepo(mongoEvent))
+	}
+
+	return events, nil
+}
+
+func (r *Repository) MarkDispatched(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	mongoIds := make([]string, 0, len(ids))
+	for _, id := range ids {
+		mongoIds = append(mongoIds, id.String())
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": mongoIds}}
+	update := bson.M{"$set": bson.M{"dispatched_at": time.Now()}}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+
+	return err
+}
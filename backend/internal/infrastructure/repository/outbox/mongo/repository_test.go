@@ -0,0 +1,114 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/mongo"
+)
+
+var (
+	MONGODB_PORT = ""
+)
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	resource, err := pool.Run("mongo", "latest", []string{})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+
+	err = pool.Retry(func() error {
+		MONGODB_PORT = resource.GetPort("27017/tcp")
+		_, err := net.Dial("tcp", net.JoinHostPort("localhost", MONGODB_PORT))
+		return err
+	})
+
+	if err != nil {
+		log.Fatalf("Could not connect to database: %s", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+func TestRepositoryGetPending(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	userId := uuid.New()
+	actorId := uuid.New()
+
+	ti, err := task.NewTask("test", userId, task.WithTags("work"))
+	if err != nil {
+		t.Fatalf("GetPending() failed to create a new task: err = '%v'", err)
+	}
+
+	now := time.Now().Truncate(time.Millisecond)
+
+	var events []task.Event
+	for i, eventType := range []task.EventType{task.EventCreated, task.EventUpdated, task.EventCompleted} {
+		e := task.NewEvent(eventType, ti, actorId)
+		e.OccurredAt = now.Add(time.Duration(i) * time.Second)
+		events = append(events, e)
+	}
+
+	r := repository.NewRepository(cfg)
+	err = r.EnsureIndexes(context.Background())
+	if err != nil {
+		t.Fatalf("EnsureIndexes() err = '%v'", err)
+	}
+
+	for _, e := range events {
+		err = r.Add(context.Background(), e)
+		if err != nil {
+			t.Fatalf("Add() err = '%v'", err)
+		}
+	}
+
+	got, err := r.GetPending(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetPending() err = '%v'", err)
+	}
+	if len(got) != 2 || got[0].ID != events[0].ID || got[1].ID != events[1].ID {
+		t.Fatalf("GetPending() got = '%v', want the first two events", got)
+	}
+	if got[0].Type != task.EventCreated || got[0].ActorID != actorId || !got[0].OccurredAt.Equal(now) {
+		t.Errorf("GetPending() got = '%v', want = '%v'", got[0], events[0])
+	}
+	if got[0].Task.ID != ti.ID || got[0].Task.Text != ti.Text || !got[0].Task.HasTag("work") {
+		t.Errorf("GetPending() Task = '%v', want = '%v'", got[0].Task, ti)
+	}
+
+	err = r.MarkDispatched(context.Background(), []uuid.UUID{events[0].ID, events[1].ID})
+	if err != nil {
+		t.Fatalf("MarkDispatched() err = '%v'", err)
+	}
+
+	got, err = r.GetPending(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("GetPending() err = '%v'", err)
+	}
+	if len(got) != 1 || got[0].ID != events[2].ID {
+		t.Errorf("GetPending() after MarkDispatched() got = '%v', want the last event", got)
+	}
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+type Token struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	Used      bool
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
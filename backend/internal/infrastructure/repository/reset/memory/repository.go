@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/reset"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/memory/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/memory/model"
+)
+
+var _ reset.Repository = (*Repository)(nil)
+
+type Repository struct {
+	tokens map[uuid.UUID]repoModel.Token
+	mu     sync.RWMutex
+}
+
+func NewRepository(_ config.Config) *Repository {
+	return &Repository{
+		tokens: make(map[uuid.UUID]repoModel.Token),
+	}
+}
+
+// Snapshot saves the tokens and returns a function bringing them back.
+func (r *Repository) Snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tokens := make(map[uuid.UUID]repoModel.Token, len(r.tokens))
+	for id, t := range r.tokens {
+		tokens[id] = t
+	}
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.tokens = tokens
+	}
+}
+
+func (r *Repository) GetByHash(_ context.Context, hash string) (reset.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.tokens {
+		if t.TokenHash == hash {
+			return converter.ToTokenFromRepo(t), nil
+		}
+	}
+
+	return reset.Token{}, reset.ErrTokenNotFound
+}
+
+func (r *Repository) Save(_ context.Context, t reset.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tokens == nil {
+		r.tokens = make(map[uuid.UUID]repoModel.Token)
+	}
+
+	if _, ok := r.tokens[t.ID]; ok {
+		return fmt.Errorf("password reset token already exists: %w", reset.ErrFailedToSaveToken)
+	}
+
+	r.tokens[t.ID] = converter.ToRepoFromToken(t)
+
+	return nil
+}
+
+func (r *Repository) Use(_ context.Context, t reset.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.tokens[t.ID]
+	if !ok {
+		return reset.ErrTokenNotFound
+	}
+
+	if current.Used {
+		return reset.ErrTokenUsed
+	}
+
+	current.Used = true
+	current.UpdatedAt = t.UpdatedAt
+	r.tokens[t.ID] = current
+
+	return nil
+}
+
+func (r *Repository) GetLatestByUserID(_ context.Context, userId uuid.UUID) (reset.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *repoModel.Token
+	for _, t := range r.tokens {
+		if t.UserID == userId.String() && (latest == nil || t.CreatedAt.After(latest.CreatedAt)) {
+			latest = &t
+		}
+	}
+
+	if latest == nil {
+		return reset.Token{}, reset.ErrTokenNotFound
+	}
+
+	return converter.ToTokenFromRepo(*latest), nil
+}
+
+func (r *Repository) UseAllByUserID(_ context.Context, userId uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, t := range r.tokens {
+		if t.UserID == userId.String() && !t.Used {
+			t.Used = true
+			t.UpdatedAt = at
+			r.tokens[id] = t
+		}
+	}
+
+	return nil
+}
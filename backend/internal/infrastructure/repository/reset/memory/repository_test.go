@@ -0,0 +1,146 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/reset"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/memory"
+)
+
+func TestRepositoryGetByHash(t *testing.T) {
+	cfg := config.Config{}
+
+	rt, raw, err := reset.NewToken(uuid.New(), time.Hour)
+	if err != nil {
+		t.Errorf("GetByHash() failed to create a new token: err = '%v'", err)
+	}
+
+	// Check if a token exists in the DB: should fail
+	r := repository.NewRepository(cfg)
+	_, err = r.GetByHash(context.Background(), reset.HashToken(raw))
+	if !errors.Is(err, reset.ErrTokenNotFound) {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", err, reset.ErrTokenNotFound)
+	}
+
+	// Save the token into the DB
+	err = r.Save(context.Background(), rt)
+	if err != nil {
+		t.Errorf("GetByHash() failed to save a new token: err = '%v'", err)
+	}
+
+	// Check if a token exists in the DB: should succeed
+	found, err := r.GetByHash(context.Background(), reset.HashToken(raw))
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v', want = '%v'", err, nil)
+	}
+
+	if found.ID != rt.ID {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", found.ID, rt.ID)
+	}
+
+	if found.UserID != rt.UserID {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", found.UserID, rt.UserID)
+	}
+}
+
+func TestRepositoryUse(t *testing.T) {
+	cfg := config.Config{}
+
+	rt, _, err := reset.NewToken(uuid.New(), time.Hour)
+	if err != nil {
+		t.Errorf("Use() failed to create a new token: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	err = r.Save(context.Background(), rt)
+	if err != nil {
+		t.Errorf("Use() failed to save a new token: err = '%v'", err)
+	}
+
+	rt.MarkUsed()
+
+	// Use the token: should succeed
+	err = r.Use(context.Background(), rt)
+	if err != nil {
+		t.Errorf("Use() err = '%v'", err)
+	}
+
+	found, err := r.GetByHash(context.Background(), rt.TokenHash)
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if !found.Used {
+		t.Error("Use() the token must be used")
+	}
+
+	// Use the token again: should fail
+	err = r.Use(context.Background(), rt)
+	if !errors.Is(err, reset.ErrTokenUsed) {
+		t.Errorf("Use() got = '%v', want = '%v'", err, reset.ErrTokenUsed)
+	}
+}
+
+func TestRepositoryByUserID(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+	r := repository.NewRepository(cfg)
+
+	_, err := r.GetLatestByUserID(context.Background(), userId)
+	if !errors.Is(err, reset.ErrTokenNotFound) {
+		t.Errorf("GetLatestByUserID() got = '%v', want = '%v'", err, reset.ErrTokenNotFound)
+	}
+
+	var tokens []reset.Token
+	for i := 0; i < 2; i++ {
+		rt, _, err := reset.NewToken(userId, time.Hour)
+		if err != nil {
+			t.Fatalf("NewToken() err = '%v'", err)
+		}
+		rt.CreatedAt = rt.CreatedAt.Add(time.Duration(i) * time.Minute).Truncate(time.Millisecond)
+
+		err = r.Save(context.Background(), rt)
+		if err != nil {
+			t.Fatalf("Save() err = '%v'", err)
+		}
+		tokens = append(tokens, rt)
+	}
+
+	// A token of another user
+	other, _, err := reset.NewToken(uuid.New(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewToken() err = '%v'", err)
+	}
+	err = r.Save(context.Background(), other)
+	if err != nil {
+		t.Fatalf("Save() err = '%v'", err)
+	}
+
+	latest, err := r.GetLatestByUserID(context.Background(), userId)
+	if err != nil || latest.ID != tokens[1].ID {
+		t.Errorf("GetLatestByUserID() got = '%v', '%v', want = '%v'", latest.ID, err, tokens[1].ID)
+	}
+
+	err = r.UseAllByUserID(context.Background(), userId, time.Now())
+	if err != nil {
+		t.Errorf("UseAllByUserID() err = '%v'", err)
+	}
+
+	for _, rt := range tokens {
+		found, err := r.GetByHash(context.Background(), rt.TokenHash)
+		if err != nil || !found.Used {
+			t.Errorf("UseAllByUserID() token got = '%v', '%v', want it used", found.Used, err)
+		}
+	}
+
+	found, err := r.GetByHash(context.Background(), other.TokenHash)
+	if err != nil || found.Used {
+		t.Errorf("UseAllByUserID() token of another user got = '%v', '%v', want it not used", found.Used, err)
+	}
+}
@@ -0,0 +1,31 @@
+package converter
+
+import (
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/reset"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/mongo/model"
+)
+
+func ToTokenFromRepo(t repoModel.Token) reset.Token {
+	return reset.Token{
+		ID:        uuid.MustParse(t.ID),
+		UserID:    uuid.MustParse(t.UserID),
+		TokenHash: t.TokenHash,
+		Used:      t.Used,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func ToRepoFromToken(t reset.Token) repoModel.Token {
+	return repoModel.Token{
+		ID:        t.ID.String(),
+		UserID:    t.UserID.String(),
+		TokenHash: t.TokenHash,
+		Used:      t.Used,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
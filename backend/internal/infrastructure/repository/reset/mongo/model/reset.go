@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+type Token struct {
+	ID        string    `bson:"_id"`
+	UserID    string    `bson:"user_id"`
+	TokenHash string    `bson:"token_hash"`
+	Used      bool      `bson:"used"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/reset"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/mongo/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/mongo/model"
+	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ reset.Repository = (*Repository)(nil)
+
+type Repository struct {
+	collection *mongo.Collection
+}
+
+func NewRepository(cfg config.Config) *Repository {
+	collection := mongodb.NewOrGetSingleton(cfg).Collection("password_reset_tokens")
+
+	return &Repository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on. Expired tokens are removed by MongoDB.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+
+	return err
+}
+
+func (r *Repository) GetByHash(ctx context.Context, hash string) (reset.Token, error) {
+	var t repoModel.Token
+
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&t)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return reset.Token{}, reset.ErrTokenNotFound
+		}
+
+		return reset.Token{}, err
+	}
+
+	return converter.ToTokenFromRepo(t), nil
+}
+
+func (r *Repository) Save(ctx context.Context, t reset.Token) error {
+	_, err := r.collection.InsertOne(ctx, converter.ToRepoFromToken(t))
+	if err != nil {
+		return reset.ErrFailedToSaveToken
+	}
+
+	return nil
+}
+
+func (r *Repository) Use(ctx context.Context, t reset.Token) error {
+	filter := bson.M{"_id": t.ID.String(), "used": false}
+	update := bson.M{
+		"$set": bson.M{
+			"used":       true,
+			"updated_at": t.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return reset.ErrFailedToSaveToken
+	}
+
+	if result.MatchedCount == 0 {
+		return reset.ErrTokenUsed
+	}
+
+	return nil
+}
+
+func (r *Repository) GetLatestByUserID(ctx context.Context, userId uuid.UUID) (reset.Token, error) {
+	var t repoModel.Token
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userId.String()}, opts).Decode(&t)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return reset.Token{}, reset.ErrTokenNotFound
+		}
+
+		return reset.Token{}, err
+	}
+
+	return converter.ToTokenFromRepo(t), nil
+}
+
+func (r *Repository) UseAllByUserID(ctx context.Context, userId uuid.UUID, at time.Time) error {
+	filter := bson.M{"user_id": userId.String(), "used": false}
+	update := bson.M{
+		"$set": bson.M{
+			"used":       true,
+			"updated_at": at,
+		},
+	}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return reset.ErrFailedToSaveToken
+	}
+
+	return nil
+}
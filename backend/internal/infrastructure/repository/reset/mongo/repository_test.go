@@ -0,0 +1,191 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/reset"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/mongo"
+)
+
+var (
+	MONGODB_PORT = ""
+)
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	resource, err := pool.Run("mongo", "latest", []string{})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+
+	err = pool.Retry(func() error {
+		MONGODB_PORT = resource.GetPort("27017/tcp")
+		_, err := net.Dial("tcp", net.JoinHostPort("localhost", MONGODB_PORT))
+		return err
+	})
+
+	if err != nil {
+		log.Fatalf("Could not connect to database: %s", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+func TestRepositoryGetByHash(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	rt, raw, err := reset.NewToken(uuid.New(), time.Hour)
+	if err != nil {
+		t.Errorf("GetByHash() failed to create a new token: err = '%v'", err)
+	}
+
+	// Check if a token exists in the DB: should fail
+	r := repository.NewRepository(cfg)
+	_, err = r.GetByHash(context.Background(), reset.HashToken(raw))
+	if !errors.Is(err, reset.ErrTokenNotFound) {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", err, reset.ErrTokenNotFound)
+	}
+
+	// Save the token into the DB
+	err = r.Save(context.Background(), rt)
+	if err != nil {
+		t.Errorf("GetByHash() failed to save a new token: err = '%v'", err)
+	}
+
+	// Check if a token exists in the DB: should succeed
+	found, err := r.GetByHash(context.Background(), reset.HashToken(raw))
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v', want = '%v'", err, nil)
+	}
+
+	if found.ID != rt.ID {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", found.ID, rt.ID)
+	}
+
+	if found.UserID != rt.UserID {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", found.UserID, rt.UserID)
+	}
+}
+
+func TestRepositoryUse(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	rt, _, err := reset.NewToken(uuid.New(), time.Hour)
+	if err != nil {
+		t.Errorf("Use() failed to create a new token: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	err = r.Save(context.Background(), rt)
+	if err != nil {
+		t.Errorf("Use() failed to save a new token: err = '%v'", err)
+	}
+
+	rt.MarkUsed()
+
+	// Use the token: should succeed
+	err = r.Use(context.Background(), rt)
+	if err != nil {
+		t.Errorf("Use() err = '%v'", err)
+	}
+
+	found, err := r.GetByHash(context.Background(), rt.TokenHash)
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if !found.Used {
+		t.Error("Use() the token must be used")
+	}
+
+	// Use the token again: should fail
+	err = r.Use(context.Background(), rt)
+	if !errors.Is(err, reset.ErrTokenUsed) {
+		t.Errorf("Use() got = '%v', want = '%v'", err, reset.ErrTokenUsed)
+	}
+}
+
+func TestRepositoryByUserID(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	userId := uuid.New()
+	r := repository.NewRepository(cfg)
+
+	_, err := r.GetLatestByUserID(context.Background(), userId)
+	if !errors.Is(err, reset.ErrTokenNotFound) {
+		t.Errorf("GetLatestByUserID() got = '%v', want = '%v'", err, reset.ErrTokenNotFound)
+	}
+
+	var tokens []reset.Token
+	for i := 0; i < 2; i++ {
+		rt, _, err := reset.NewToken(userId, time.Hour)
+		if err != nil {
+			t.Fatalf("NewToken() err = '%v'", err)
+		}
+		rt.CreatedAt = rt.CreatedAt.Add(time.Duration(i) * time.Minute).Truncate(time.Millisecond)
+
+		err = r.Save(context.Background(), rt)
+		if err != nil {
+			t.Fatalf("Save() err = '%v'", err)
+		}
+		tokens = append(tokens, rt)
+	}
+
+	// A token of another user
+	other, _, err := reset.NewToken(uuid.New(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewToken() err = '%v'", err)
+	}
+	err = r.Save(context.Background(), other)
+	if err != nil {
+		t.Fatalf("Save() err = '%v'", err)
+	}
+
+	latest, err := r.GetLatestByUserID(context.Background(), userId)
+	if err != nil || latest.ID != tokens[1].ID {
+		t.Errorf("GetLatestByUserID() got = '%v', '%v', want = '%v'", latest.ID, err, tokens[1].ID)
+	}
+
+	err = r.UseAllByUserID(context.Background(), userId, time.Now())
+	if err != nil {
+		t.Errorf("UseAllByUserID() err = '%v'", err)
+	}
+
+	for _, rt := range tokens {
+		found, err := r.GetByHash(context.Background(), rt.TokenHash)
+		if err != nil || !found.Used {
+			t.Errorf("UseAllByUserID() token got = '%v', '%v', want it used", found.Used, err)
+		}
+	}
+
+	found, err := r.GetByHash(context.Background(), other.TokenHash)
+	if err != nil || found.Used {
+		t.Errorf("UseAllByUserID() token of another user got = '%v', '%v', want it not used", found.Used, err)
+	}
+}
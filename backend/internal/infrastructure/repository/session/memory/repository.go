@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/memory/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/memory/model"
+)
+
+var _ session.Repository = (*Repository)(nil)
+
+type Repository struct {
+	tokens map[uuid.UUID]repoModel.RefreshToken
+	mu     sync.RWMutex
+}
+
+func NewRepository(_ config.Config) *Repository {
+	return &Repository{
+		tokens: make(map[uuid.UUID]repoModel.RefreshToken),
+	}
+}
+
+// Snapshot saves the refresh tokens and returns a function bringing them back.
+func (r *Repository) Snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tokens := make(map[uuid.UUID]repoModel.RefreshToken, len(r.tokens))
+	for id, t := range r.tokens {
+		tokens[id] = t
+	}
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.tokens = tokens
+	}
+}
+
+func (r *Repository) GetByHash(_ context.Context, hash string) (session.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tokens == nil {
+		r.tokens = make(map[uuid.UUID]repoModel.RefreshToken)
+	}
+
+	for _, t := range r.tokens {
+		if t.TokenHash == hash {
+			return converter.ToRefreshTokenFromRepo(t), nil
+		}
+	}
+
+	return session.RefreshToken{}, session.ErrRefreshTokenNotFound
+}
+
+func (r *Repository) Save(_ context.Context, t session.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tokens == nil {
+		r.tokens = make(map[uuid.UUID]repoModel.RefreshToken)
+	}
+
+	if _, ok := r.tokens[t.ID]; ok {
+		return fmt.Errorf("refresh token already exists: %w", session.ErrFailedToSaveRefreshToken)
+	}
+
+	r.tokens[t.ID] = converter.ToRepoFromRefreshToken(t)
+
+	return nil
+}
+
+func (r *Repository) Rotate(_ context.Context, used session.RefreshToken, next session.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tokens == nil {
+		r.tokens = make(map[uuid.UUID]repoModel.RefreshToken)
+	}
+
+	current, ok := r.tokens[used.ID]
+	if !ok {
+		return session.ErrRefreshTokenNotFound
+	}
+
+	if current.Used || current.Revoked {
+		return session.ErrRefreshTokenReused
+	}
+
+	if _, ok := r.tokens[next.ID]; ok {
+		return fmt.Errorf("refresh token already exists: %w", session.ErrFailedToSaveRefreshToken)
+	}
+
+	current.Used = true
+	current.UpdatedAt = used.UpdatedAt
+	r.tokens[used.ID] = current
+	r.tokens[next.ID] = converter.ToRepoFromRefreshToken(next)
+
+	return nil
+}
+
+func (r *Repository) RevokeFamily(_ context.Context, familyId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tokens == nil {
+		r.tokens = make(map[uuid.UUID]repoModel.RefreshToken)
+	}
+
+	now := time.Now()
+	for id, t := range r.tokens {
+		if t.FamilyID == familyId.String() && !t.Revoked {
+			t.Revoked = true
+			t.UpdatedAt = now
+			r.tokens[id] = t
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) RevokeAllByUserID(_ context.Context, userId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tokens == nil {
+		r.tokens = make(map[uuid.UUID]repoModel.RefreshToken)
+	}
+
+	now := time.Now()
+	for id, t := range r.tokens {
+		if t.UserID == userId.String() && !t.Revoked {
+			t.Revoked = true
+			t.UpdatedAt = now
+			r.tokens[id] = t
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/memory"
+)
+
+func TestRepositoryGetByHash(t *testing.T) {
+	cfg := config.Config{}
+
+	rt, raw, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("GetByHash() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	// Check if a token exists in the DB: should fail
+	r := repository.NewRepository(cfg)
+	_, err = r.GetByHash(context.Background(), session.HashToken(raw))
+	if !errors.Is(err, session.ErrRefreshTokenNotFound) {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", err, session.ErrRefreshTokenNotFound)
+	}
+
+	// Save the token into the DB
+	err = r.Save(context.Background(), rt)
+	if err != nil {
+		t.Errorf("GetByHash() failed to save a new refresh token: err = '%v'", err)
+	}
+
+	// Check if a token exists in the DB: should succeed
+	found, err := r.GetByHash(context.Background(), session.HashToken(raw))
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v', want = '%v'", err, nil)
+	}
+
+	if found.ID != rt.ID {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", found.ID, rt.ID)
+	}
+
+	if found.FamilyID != rt.FamilyID {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", found.FamilyID, rt.FamilyID)
+	}
+}
+
+func TestRepositoryRotate(t *testing.T) {
+	cfg := config.Config{}
+
+	rt, _, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("Rotate() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	next, raw, err := session.NewRefreshToken(rt.UserID, rt.FamilyID, time.Hour)
+	if err != nil {
+		t.Errorf("Rotate() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	err = r.Save(context.Background(), rt)
+	if err != nil {
+		t.Errorf("Rotate() failed to save a new refresh token: err = '%v'", err)
+	}
+
+	// Rotate the token: should succeed
+	rt.MarkUsed()
+	err = r.Rotate(context.Background(), rt, next)
+	if err != nil {
+		t.Errorf("Rotate() err = '%v'", err)
+	}
+
+	found, err := r.GetByHash(context.Background(), rt.TokenHash)
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if !found.Used {
+		t.Error("Rotate() the rotated token must be marked as used")
+	}
+
+	_, err = r.GetByHash(context.Background(), session.HashToken(raw))
+	if err != nil {
+		t.Errorf("Rotate() the next token must be saved: err = '%v'", err)
+	}
+
+	// Rotate the token again: should fail
+	another, _, err := session.NewRefreshToken(rt.UserID, rt.FamilyID, time.Hour)
+	if err != nil {
+		t.Errorf("Rotate() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	err = r.Rotate(context.Background(), rt, another)
+	if !errors.Is(err, session.ErrRefreshTokenReused) {
+		t.Errorf("Rotate() got = '%v', want = '%v'", err, session.ErrRefreshTokenReused)
+	}
+}
+
+func TestRepositoryRevokeFamily(t *testing.T) {
+	cfg := config.Config{}
+
+	rt, _, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("RevokeFamily() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	other, _, err := session.NewRefreshToken(rt.UserID, uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("RevokeFamily() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	for _, ti := range []session.RefreshToken{rt, other} {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("RevokeFamily() failed to save a new refresh token: err = '%v'", err)
+		}
+	}
+
+	err = r.RevokeFamily(context.Background(), rt.FamilyID)
+	if err != nil {
+		t.Errorf("RevokeFamily() err = '%v'", err)
+	}
+
+	found, err := r.GetByHash(context.Background(), rt.TokenHash)
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if !found.Revoked {
+		t.Error("RevokeFamily() the token must be revoked")
+	}
+
+	found, err = r.GetByHash(context.Background(), other.TokenHash)
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if found.Revoked {
+		t.Error("RevokeFamily() a token of another family must not be revoked")
+	}
+}
+
+func TestRepositoryRevokeAllByUserID(t *testing.T) {
+	cfg := config.Config{}
+
+	rt, _, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("RevokeAllByUserID() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	sameUser, _, err := session.NewRefreshToken(rt.UserID, uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("RevokeAllByUserID() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	otherUser, _, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("RevokeAllByUserID() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	for _, ti := range []session.RefreshToken{rt, sameUser, otherUser} {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("RevokeAllByUserID() failed to save a new refresh token: err = '%v'", err)
+		}
+	}
+
+	err = r.RevokeAllByUserID(context.Background(), rt.UserID)
+	if err != nil {
+		t.Errorf("RevokeAllByUserID() err = '%v'", err)
+	}
+
+	for _, ti := range []session.RefreshToken{rt, sameUser} {
+		found, err := r.GetByHash(context.Background(), ti.TokenHash)
+		if err != nil {
+			t.Errorf("GetByHash() err = '%v'", err)
+		}
+
+		if !found.Revoked {
+			t.Error("RevokeAllByUserID() the tokens of the user must be revoked")
+		}
+	}
+
+	found, err := r.GetByHash(context.Background(), otherUser.TokenHash)
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if found.Revoked {
+		t.Error("RevokeAllByUserID() a token of another user must not be revoked")
+	}
+}
@@ -0,0 +1,35 @@
+package converter
+
+import (
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/mongo/model"
+)
+
+func ToRefreshTokenFromRepo(t repoModel.RefreshToken) session.RefreshToken {
+	return session.RefreshToken{
+		ID:        uuid.MustParse(t.ID),
+		FamilyID:  uuid.MustParse(t.FamilyID),
+		UserID:    uuid.MustParse(t.UserID),
+		TokenHash: t.TokenHash,
+		Used:      t.Used,
+		Revoked:   t.Revoked,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func ToRepoFromRefreshToken(t session.RefreshToken) repoModel.RefreshToken {
+	return repoModel.RefreshToken{
+		ID:        t.ID.String(),
+		FamilyID:  t.FamilyID.String(),
+		UserID:    t.UserID.String(),
+		TokenHash: t.TokenHash,
+		Used:      t.Used,
+		Revoked:   t.Revoked,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+type RefreshToken struct {
+	ID        string    `bson:"_id"`
+	FamilyID  string    `bson:"family_id"`
+	UserID    string    `bson:"user_id"`
+	TokenHash string    `bson:"token_hash"`
+	Used      bool      `bson:"used"`
+	Revoked   bool      `bson:"revoked"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/mongo/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/mongo/model"
+	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ session.Repository = (*Repository)(nil)
+
+type Repository struct {
+	collection *mongo.Collection
+}
+
+func NewRepository(cfg config.Config) *Repository {
+	collection := mongodb.NewOrGetSingleton(cfg).Collection("refresh_tokens")
+
+	return &Repository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "family_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+
+	return err
+}
+
+func (r *Repository) GetByHash(ctx context.Context, hash string) (session.RefreshToken, error) {
+	var t repoModel.RefreshToken
+
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&t)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return session.RefreshToken{}, session.ErrRefreshTokenNotFound
+		}
+
+		return session.RefreshToken{}, err
+	}
+
+	return converter.ToRefreshTokenFromRepo(t), nil
+}
+
+func (r *Repository) Save(ctx context.Context, t session.RefreshToken) error {
+	_, err := r.collection.InsertOne(ctx, converter.ToRepoFromRefreshToken(t))
+	if err != nil {
+		return session.ErrFailedToSaveRefreshToken
+	}
+
+	return nil
+}
+
+func (r *Repository) Rotate(ctx context.Context, used session.RefreshToken, next session.RefreshToken) error {
+	filter := bson.M{"_id": used.ID.String(), "used": false, "revoked": false}
+	update := bson.M{
+		"$set": bson.M{
+			"used":       true,
+			"updated_at": used.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return session.ErrFailedToSaveRefreshToken
+	}
+
+	if result.MatchedCount == 0 {
+		return session.ErrRefreshTokenReused
+	}
+
+	return r.Save(ctx, next)
+}
+
+func (r *Repository) RevokeFamily(ctx context.Context, familyId uuid.UUID) error {
+	filter := bson.M{"family_id": familyId.String(), "revoked": false}
+	update := bson.M{
+		"$set": bson.M{
+			"revoked":    true,
+			"updated_at": time.Now(),
+		},
+	}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return session.ErrFailedRevokeRefreshTokens
+	}
+
+	return nil
+}
+
+func (r *Repository) RevokeAllByUserID(ctx context.Context, userId uuid.UUID) error {
+	filter := bson.M{"user_id": userId.String(), "revoked": false}
+	update := bson.M{
+		"$set": bson.M{
+			"revoked":    true,
+			"updated_at": time.Now(),
+		},
+	}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return session.ErrFailedRevokeRefreshTokens
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/mongo"
+)
+
+var (
+	MONGODB_PORT = ""
+)
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	resource, err := pool.Run("mongo", "latest", []string{})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+
+	err = pool.Retry(func() error {
+		MONGODB_PORT = resource.GetPort("27017/tcp")
+		_, err := net.Dial("tcp", net.JoinHostPort("localhost", MONGODB_PORT))
+		return err
+	})
+
+	if err != nil {
+		log.Fatalf("Could not connect to database: %s", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+func TestRepositoryGetByHash(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	rt, raw, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("GetByHash() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	err = r.EnsureIndexes(context.Background())
+	if err != nil {
+		t.Errorf("EnsureIndexes() err = '%v'", err)
+	}
+
+	// Check if a token exists in the DB: should fail
+	_, err = r.GetByHash(context.Background(), session.HashToken(raw))
+	if !errors.Is(err, session.ErrRefreshTokenNotFound) {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", err, session.ErrRefreshTokenNotFound)
+	}
+
+	// Save the token into the DB
+	err = r.Save(context.Background(), rt)
+	if err != nil {
+		t.Errorf("GetByHash() failed to save a new refresh token: err = '%v'", err)
+	}
+
+	// Check if a token exists in the DB: should succeed
+	found, err := r.GetByHash(context.Background(), session.HashToken(raw))
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v', want = '%v'", err, nil)
+	}
+
+	if found.ID != rt.ID {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", found.ID, rt.ID)
+	}
+
+	if found.FamilyID != rt.FamilyID {
+		t.Errorf("GetByHash() got = '%v', want = '%v'", found.FamilyID, rt.FamilyID)
+	}
+}
+
+func TestRepositoryRotate(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	rt, _, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("Rotate() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	next, raw, err := session.NewRefreshToken(rt.UserID, rt.FamilyID, time.Hour)
+	if err != nil {
+		t.Errorf("Rotate() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	err = r.Save(context.Background(), rt)
+	if err != nil {
+		t.Errorf("Rotate() failed to save a new refresh token: err = '%v'", err)
+	}
+
+	// Rotate the token: should succeed
+	rt.MarkUsed()
+	err = r.Rotate(context.Background(), rt, next)
+	if err != nil {
+		t.Errorf("Rotate() err = '%v'", err)
+	}
+
+	found, err := r.GetByHash(context.Background(), rt.TokenHash)
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if !found.Used {
+		t.Error("Rotate() the rotated token must be marked as used")
+	}
+
+	_, err = r.GetByHash(context.Background(), session.HashToken(raw))
+	if err != nil {
+		t.Errorf("Rotate() the next token must be saved: err = '%v'", err)
+	}
+
+	// Rotate the token again: should fail
+	another, _, err := session.NewRefreshToken(rt.UserID, rt.FamilyID, time.Hour)
+	if err != nil {
+		t.Errorf("Rotate() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	err = r.Rotate(context.Background(), rt, another)
+	if !errors.Is(err, session.ErrRefreshTokenReused) {
+		t.Errorf("Rotate() got = '%v', want = '%v'", err, session.ErrRefreshTokenReused)
+	}
+}
+
+func TestRepositoryRevokeFamily(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	rt, _, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("RevokeFamily() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	other, _, err := session.NewRefreshToken(rt.UserID, uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("RevokeFamily() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	for _, ti := range []session.RefreshToken{rt, other} {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("RevokeFamily() failed to save a new refresh token: err = '%v'", err)
+		}
+	}
+
+	err = r.RevokeFamily(context.Background(), rt.FamilyID)
+	if err != nil {
+		t.Errorf("RevokeFamily() err = '%v'", err)
+	}
+
+	found, err := r.GetByHash(context.Background(), rt.TokenHash)
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if !found.Revoked {
+		t.Error("RevokeFamily() the token must be revoked")
+	}
+
+	found, err = r.GetByHash(context.Background(), other.TokenHash)
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if found.Revoked {
+		t.Error("RevokeFamily() a token of another family must not be revoked")
+	}
+}
+
+func TestRepositoryRevokeAllByUserID(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	rt, _, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("RevokeAllByUserID() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	sameUser, _, err := session.NewRefreshToken(rt.UserID, uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("RevokeAllByUserID() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	otherUser, _, err := session.NewRefreshToken(uuid.New(), uuid.Nil, time.Hour)
+	if err != nil {
+		t.Errorf("RevokeAllByUserID() failed to create a new refresh token: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	for _, ti := range []session.RefreshToken{rt, sameUser, otherUser} {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("RevokeAllByUserID() failed to save a new refresh token: err = '%v'", err)
+		}
+	}
+
+	err = r.RevokeAllByUserID(context.Background(), rt.UserID)
+	if err != nil {
+		t.Errorf("RevokeAllByUserID() err = '%v'", err)
+	}
+
+	for _, ti := range []session.RefreshToken{rt, sameUser} {
+		found, err := r.GetByHash(context.Background(), ti.TokenHash)
+		if err != nil {
+			t.Errorf("GetByHash() err = '%v'", err)
+		}
+
+		if !found.Revoked {
+			t.Error("RevokeAllByUserID() the tokens of the user must be revoked")
+		}
+	}
+
+	found, err := r.GetByHash(context.Background(), otherUser.TokenHash)
+	if err != nil {
+		t.Errorf("GetByHash() err = '%v'", err)
+	}
+
+	if found.Revoked {
+		t.Error("RevokeAllByUserID() a token of another user must not be revoked")
+	}
+}
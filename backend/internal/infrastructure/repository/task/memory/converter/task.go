@@ -1,6 +1,8 @@
 package converter
 
 import (
+	"slices"
+
 	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/internal/domain/task"
 	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/memory/model"
@@ -8,22 +10,99 @@ import (
 
 func ToTaskFromRepo(t repoModel.Task) task.Task {
 	return task.Task{
-		ID:        uuid.MustParse(t.ID),
-		Text:      t.Text,
-		Completed: t.Completed,
-		UserID:    uuid.MustParse(t.UserID),
-		CreatedAt: t.CreatedAt,
-		UpdatedAt: t.UpdatedAt,
+		ID:             uuid.MustParse(t.ID),
+		Text:           t.Text,
+		Completed:      t.Completed,
+		UserID:         uuid.MustParse(t.UserID),
+		ListID:         toUUIDPtr(t.ListID),
+		ParentID:       toUUIDPtr(t.ParentID),
+		DueAt:          t.DueAt,
+		ReminderOffset: t.ReminderOffset,
+		Recurrence:     toRecurrencePtr(t.Recurrence),
+		Tags:           slices.Clone(t.Tags),
+		DeletedAt:      t.DeletedAt,
+		Position:       t.Position,
+		Priority:       task.Priority(t.Priority),
+		Eisenhower:     toEisenhowerPtr(t.Important, t.Urgent),
+		Version:        t.Version,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
 	}
 }
 
 func ToRepoFromTask(t task.Task) repoModel.Task {
+	var important, urgent *bool
+	if t.Eisenhower != nil {
+		i, u := t.Eisenhower.Important, t.Eisenhower.Urgent
+		important, urgent = &i, &u
+	}
+
 	return repoModel.Task{
-		ID:        t.ID.String(),
-		Text:      t.Text,
-		Completed: t.Completed,
-		UserID:    t.UserID.String(),
-		CreatedAt: t.CreatedAt,
-		UpdatedAt: t.UpdatedAt,
+		ID:             t.ID.String(),
+		Text:           t.Text,
+		Completed:      t.Completed,
+		UserID:         t.UserID.String(),
+		ListID:         toStringPtr(t.ListID),
+		ParentID:       toStringPtr(t.ParentID),
+		DueAt:          t.DueAt,
+		ReminderOffset: t.ReminderOffset,
+		Recurrence:     toRulePtr(t.Recurrence),
+		Tags:           slices.Clone(t.Tags),
+		DeletedAt:      t.DeletedAt,
+		Position:       t.Position,
+		Priority:       int(t.Priority),
+		Important:      important,
+		Urgent:         urgent,
+		Version:        t.Version,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+	}
+}
+
+func toUUIDPtr(s *string) *uuid.UUID {
+	if s == nil {
+		return nil
+	}
+
+	id := uuid.MustParse(*s)
+
+	return &id
+}
+
+func toStringPtr(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
 	}
+
+	s := id.String()
+
+	return &s
+}
+
+func toRecurrencePtr(s *string) *task.Recurrence {
+	if s == nil {
+		return nil
+	}
+
+	r := task.MustParseRecurrence(*s)
+
+	return &r
+}
+
+func toRulePtr(r *task.Recurrence) *string {
+	if r == nil {
+		return nil
+	}
+
+	s := r.String()
+
+	return &s
+}
+
+func toEisenhowerPtr(important, urgent *bool) *task.Eisenhower {
+	if important == nil || urgent == nil {
+		return nil
+	}
+
+	return &task.Eisenhower{Important: *important, Urgent: *urgent}
 }
@@ -5,10 +5,22 @@ import (
 )
 
 type Task struct {
-	ID        string
-	Text      string
-	Completed bool
-	UserID    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID             string
+	Text           string
+	Completed      bool
+	UserID         string
+	ListID         *string
+	ParentID       *string
+	DueAt          *time.Time
+	ReminderOffset *time.Duration
+	Recurrence     *string
+	Tags           []string
+	DeletedAt      *time.Time
+	Position       float64
+	Priority       int
+	Important      *bool
+	Urgent         *bool
+	Version        int64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
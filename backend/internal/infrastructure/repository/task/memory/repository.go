@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/config"
@@ -27,6 +28,24 @@ func NewRepository(_ config.Config) *Repository {
 	}
 }
 
+// Snapshot saves the tasks and returns a function bringing them back.
+func (r *Repository) Snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := make(map[uuid.UUID]repoModel.Task, len(r.tasks))
+	for id, t := range r.tasks {
+		tasks[id] = t
+	}
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.tasks = tasks
+	}
+}
+
 func (r *Repository) GetByID(_ context.Context, id uuid.UUID) (task.Task, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -52,7 +71,176 @@ func (r *Repository) GetAllByUserID(_ context.Context, userId uuid.UUID) ([]task
 
 	var tasks []task.Task
 	for _, ti := range r.tasks {
-		if ti.UserID == userId.String() {
+		if ti.UserID == userId.String() && ti.DeletedAt == nil {
+			tasks = append(tasks, converter.ToTaskFromRepo(ti))
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return task.ComparePosition(tasks[i], tasks[j]) < 0 })
+
+	return tasks, nil
+}
+
+func (r *Repository) GetListing(_ context.Context, userId uuid.UUID, listId *uuid.UUID) ([]task.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	tasks := []task.Task{}
+	for _, ti := range r.tasks {
+		if ti.DeletedAt != nil {
+			continue
+		}
+
+		if listId == nil && ti.UserID == userId.String() && ti.ListID == nil ||
+			listId != nil && ti.ListID != nil && *ti.ListID == listId.String() {
+			tasks = append(tasks, converter.ToTaskFromRepo(ti))
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return task.ComparePosition(tasks[i], tasks[j]) < 0 })
+
+	return tasks, nil
+}
+
+func (r *Repository) GetOpenByUserID(_ context.Context, userId uuid.UUID) ([]task.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	tasks := []task.Task{}
+	for _, ti := range r.tasks {
+		if ti.UserID == userId.String() && !ti.Completed && ti.DeletedAt == nil {
+			tasks = append(tasks, converter.ToTaskFromRepo(ti))
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return task.ComparePriority(tasks[i], tasks[j]) < 0 })
+
+	return tasks, nil
+}
+
+func (r *Repository) GetTrashByUserID(_ context.Context, userId uuid.UUID) ([]task.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	tasks := []task.Task{}
+	for _, ti := range r.tasks {
+		if ti.UserID == userId.String() && ti.DeletedAt != nil {
+			tasks = append(tasks, converter.ToTaskFromRepo(ti))
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DeletedAt.Equal(*tasks[j].DeletedAt) {
+			return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+		}
+
+		return tasks[i].ID.String() < tasks[j].ID.String()
+	})
+
+	return tasks, nil
+}
+
+func (r *Repository) Find(_ context.Context, q task.Query) (task.Page, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	c, err := q.DecodeCursor()
+	if err != nil {
+		return task.Page{}, err
+	}
+
+	tasks := []task.Task{}
+	for _, ti := range r.tasks {
+		t := converter.ToTaskFromRepo(ti)
+		if !q.Matches(t) {
+			continue
+		}
+
+		if c != nil && !q.After(t, *c) {
+			continue
+		}
+
+		tasks = append(tasks, t)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return q.Less(tasks[i], tasks[j]) })
+
+	page := task.Page{Tasks: tasks}
+	if len(tasks) > q.Limit {
+		page.Tasks = tasks[:q.Limit]
+		page.NextCursor = q.EncodeCursor(page.Tasks[q.Limit-1])
+	}
+
+	return page, nil
+}
+
+func (r *Repository) Search(_ context.Context, q task.SearchQuery) ([]task.SearchResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	results := []task.SearchResult{}
+	for _, ti := range r.tasks {
+		if ti.UserID != q.UserID.String() {
+			continue
+		}
+
+		t := converter.ToTaskFromRepo(ti)
+		if score := q.Score(t); score > 0 {
+			results = append(results, task.SearchResult{Task: t, Score: score})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+
+		if !a.Task.CreatedAt.Equal(b.Task.CreatedAt) {
+			return a.Task.CreatedAt.Before(b.Task.CreatedAt)
+		}
+
+		return a.Task.ID.String() < b.Task.ID.String()
+	})
+
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+
+	return results, nil
+}
+
+func (r *Repository) GetChildren(_ context.Context, parentId uuid.UUID) ([]task.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	tasks := []task.Task{}
+	for _, ti := range r.tasks {
+		if ti.ParentID != nil && *ti.ParentID == parentId.String() {
 			tasks = append(tasks, converter.ToTaskFromRepo(ti))
 		}
 	}
@@ -62,6 +250,101 @@ func (r *Repository) GetAllByUserID(_ context.Context, userId uuid.UUID) ([]task
 	return tasks, nil
 }
 
+func (r *Repository) GetAllDueByUserID(_ context.Context, userId uuid.UUID, from time.Time, to time.Time) ([]task.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	tasks := []task.Task{}
+	for _, ti := range r.tasks {
+		if ti.UserID != userId.String() || ti.Completed || ti.DueAt == nil || ti.DeletedAt != nil {
+			continue
+		}
+
+		if (!from.IsZero() && ti.DueAt.Before(from)) || !ti.DueAt.Before(to) {
+			continue
+		}
+
+		tasks = append(tasks, converter.ToTaskFromRepo(ti))
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DueAt.Equal(*tasks[j].DueAt) {
+			return tasks[i].DueAt.Before(*tasks[j].DueAt)
+		}
+
+		return tasks[i].ID.String() < tasks[j].ID.String()
+	})
+
+	return tasks, nil
+}
+
+func (r *Repository) GetTagCountsByUserID(_ context.Context, userId uuid.UUID) ([]task.TagCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	counts := make(map[string]int)
+	for _, ti := range r.tasks {
+		if ti.UserID != userId.String() || ti.ListID != nil || ti.DeletedAt != nil {
+			continue
+		}
+
+		for _, tag := range ti.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]task.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, task.TagCount{Tag: tag, Count: count})
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	return tags, nil
+}
+
+func (r *Repository) RenameTag(_ context.Context, userId uuid.UUID, from string, to string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	// The renamed tasks share the update time, as with a single update of the tasks in Mongo.
+	now := time.Now()
+	changed := 0
+	for id, ti := range r.tasks {
+		if ti.UserID != userId.String() || ti.ListID != nil {
+			continue
+		}
+
+		t := converter.ToTaskFromRepo(ti)
+		if t.RenameTag(from, to) {
+			t.Version++
+			t.UpdatedAt = now
+			r.tasks[id] = converter.ToRepoFromTask(t)
+			changed++
+		}
+	}
+
+	return changed, nil
+}
+
 func (r *Repository) Save(_ context.Context, ti task.Task) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -87,10 +370,16 @@ func (r *Repository) Update(_ context.Context, ti task.Task) error {
 		r.tasks = make(map[uuid.UUID]repoModel.Task)
 	}
 
-	if _, ok := r.tasks[ti.ID]; !ok {
+	current, ok := r.tasks[ti.ID]
+	if !ok {
 		return fmt.Errorf("task does not exist: %w", task.ErrFailedToSaveTask)
 	}
 
+	if current.Version != ti.Version {
+		return task.ErrVersionMismatch
+	}
+
+	ti.Version++
 	r.tasks[ti.ID] = converter.ToRepoFromTask(ti)
 
 	return nil
@@ -112,3 +401,41 @@ func (r *Repository) Delete(_ context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+func (r *Repository) DeleteAllByListID(_ context.Context, listId uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	deleted := 0
+	for id, ti := range r.tasks {
+		if ti.ListID != nil && *ti.ListID == listId.String() {
+			delete(r.tasks, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (r *Repository) PurgeTrash(_ context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tasks == nil {
+		r.tasks = make(map[uuid.UUID]repoModel.Task)
+	}
+
+	purged := 0
+	for id, ti := range r.tasks {
+		if ti.DeletedAt != nil && ti.DeletedAt.Before(before) {
+			delete(r.tasks, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -3,7 +3,10 @@ package repository_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/config"
@@ -236,3 +239,773 @@ func TestRepositoryDelete(t *testing.T) {
 		t.Errorf("GetByID() err = '%v', want = '%v'", err, nil)
 	}
 }
+
+func TestRepositoryDeleteAllByListID(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+	listId := uuid.New()
+
+	active, err := task.NewTask("active task", userId, task.WithList(listId))
+	if err != nil {
+		t.Errorf("DeleteAllByListID() failed to create a new task: err = '%v'", err)
+	}
+	trashed, err := task.NewTask("trashed task", userId, task.WithList(listId))
+	if err != nil {
+		t.Errorf("DeleteAllByListID() failed to create a new task: err = '%v'", err)
+	}
+	err = trashed.MoveToTrash(time.Now())
+	if err != nil {
+		t.Errorf("DeleteAllByListID() failed to trash a task: err = '%v'", err)
+	}
+	personal, err := task.NewTask("personal task", userId)
+	if err != nil {
+		t.Errorf("DeleteAllByListID() failed to create a new task: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	for _, ti := range []task.Task{active, trashed, personal} {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("DeleteAllByListID() failed to save a new task: err = '%v'", err)
+		}
+	}
+
+	deleted, err := r.DeleteAllByListID(context.Background(), listId)
+	if err != nil || deleted != 2 {
+		t.Errorf("DeleteAllByListID() got = '%v', '%v', want = '%v'", deleted, err, 2)
+	}
+
+	for _, id := range []uuid.UUID{active.ID, trashed.ID} {
+		_, err = r.GetByID(context.Background(), id)
+		if !errors.Is(err, task.ErrTaskNotFound) {
+			t.Errorf("GetByID() err = '%v', want = '%v'", err, task.ErrTaskNotFound)
+		}
+	}
+
+	_, err = r.GetByID(context.Background(), personal.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v'", err)
+	}
+}
+
+func TestRepositoryFind(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+	createdAt := time.Now().Add(-time.Hour)
+
+	var tasks []task.Task
+	for i := 0; i < 5; i++ {
+		ti, err := task.NewTask(fmt.Sprintf("task text %d", i), userId)
+		if err != nil {
+			t.Errorf("Find() failed to create a new task: err = '%v'", err)
+		}
+		ti.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+		ti.Completed = i%2 == 0
+		tasks = append(tasks, ti)
+	}
+
+	other, err := task.NewTask("task text 0", uuid.New())
+	if err != nil {
+		t.Errorf("Find() failed to create a new task: err = '%v'", err)
+	}
+
+	// Add the tasks to the tasks collection
+	r := repository.NewRepository(cfg)
+	for _, ti := range append(tasks, other) {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("Find() failed to save new tasks: err = '%v'", err)
+		}
+	}
+
+	// Walk all pages in descending order
+	q := task.NewQuery(userId)
+	q.SortDirection = task.SortDesc
+	q.Limit = 2
+
+	var found []task.Task
+	for pages := 0; pages < 5; pages++ {
+		page, err := r.Find(context.Background(), q)
+		if err != nil {
+			t.Fatalf("Find() err = '%v'", err)
+		}
+
+		found = append(found, page.Tasks...)
+
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	if len(found) != len(tasks) {
+		t.Fatalf("Find() got = '%v', want = '%v'", len(found), len(tasks))
+	}
+
+	for i, ft := range found {
+		if want := tasks[len(tasks)-1-i]; ft.ID != want.ID {
+			t.Errorf("Find() got = '%v', want = '%v'", ft.Text, want.Text)
+		}
+	}
+
+	// Filter by completion and text
+	completed := true
+	q = task.NewQuery(userId)
+	q.Completed = &completed
+	q.Text = "TEXT 4"
+
+	page, err := r.Find(context.Background(), q)
+	if err != nil {
+		t.Errorf("Find() err = '%v'", err)
+	}
+
+	if len(page.Tasks) != 1 || page.Tasks[0].ID != tasks[4].ID {
+		t.Errorf("Find() got = '%v', want = '%v'", page.Tasks, tasks[4:])
+	}
+
+	if page.NextCursor != "" {
+		t.Errorf("Find() NextCursor = '%v', want = ''", page.NextCursor)
+	}
+}
+
+func TestRepositoryGetAllDueByUserID(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+	now := time.Now()
+
+	overdue, err := task.NewTask("overdue", userId, task.WithDueDate(now.Add(-time.Hour)))
+	if err != nil {
+		t.Errorf("GetAllDueByUserID() failed to create a new task: err = '%v'", err)
+	}
+
+	dueSoon, err := task.NewTask("due soon", userId, task.WithDueDate(now.Add(time.Hour)))
+	if err != nil {
+		t.Errorf("GetAllDueByUserID() failed to create a new task: err = '%v'", err)
+	}
+
+	dueLater, err := task.NewTask("due later", userId, task.WithDueDate(now.Add(48*time.Hour)))
+	if err != nil {
+		t.Errorf("GetAllDueByUserID() failed to create a new task: err = '%v'", err)
+	}
+
+	completed, err := task.NewTask("completed", userId, task.WithDueDate(now.Add(-time.Hour)))
+	if err != nil {
+		t.Errorf("GetAllDueByUserID() failed to create a new task: err = '%v'", err)
+	}
+	completed.MarkCompleted()
+
+	noDueDate, err := task.NewTask("no due date", userId)
+	if err != nil {
+		t.Errorf("GetAllDueByUserID() failed to create a new task: err = '%v'", err)
+	}
+
+	// Add the tasks to the tasks collection
+	r := repository.NewRepository(cfg)
+	for _, ti := range []task.Task{overdue, dueSoon, dueLater, completed, noDueDate} {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("GetAllDueByUserID() failed to save new tasks: err = '%v'", err)
+		}
+	}
+
+	// Overdue tasks
+	found, err := r.GetAllDueByUserID(context.Background(), userId, time.Time{}, now)
+	if err != nil {
+		t.Errorf("GetAllDueByUserID() err = '%v'", err)
+	}
+
+	if len(found) != 1 || found[0].ID != overdue.ID {
+		t.Errorf("GetAllDueByUserID() got = '%v', want = '%v'", found, []task.Task{overdue})
+	}
+
+	// Tasks due within a day
+	found, err = r.GetAllDueByUserID(context.Background(), userId, now, now.Add(24*time.Hour))
+	if err != nil {
+		t.Errorf("GetAllDueByUserID() err = '%v'", err)
+	}
+
+	if len(found) != 1 || found[0].ID != dueSoon.ID {
+		t.Errorf("GetAllDueByUserID() got = '%v', want = '%v'", found, []task.Task{dueSoon})
+	}
+
+	if len(found) == 1 && (found[0].DueAt == nil || found[0].DueAt.Sub(*dueSoon.DueAt).Abs() > time.Millisecond) {
+		t.Errorf("GetAllDueByUserID() DueAt = '%v', want = '%v'", found[0].DueAt, dueSoon.DueAt)
+	}
+}
+
+func TestRepositoryGetChildren(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+
+	parent, err := task.NewTask("parent", userId)
+	if err != nil {
+		t.Errorf("GetChildren() failed to create a new task: err = '%v'", err)
+	}
+
+	child1, err := task.NewTask("child 1", userId, task.WithParent(parent.ID))
+	if err != nil {
+		t.Errorf("GetChildren() failed to create a new task: err = '%v'", err)
+	}
+	child1.CreatedAt = parent.CreatedAt.Add(time.Second)
+
+	child2, err := task.NewTask("child 2", userId, task.WithParent(parent.ID))
+	if err != nil {
+		t.Errorf("GetChildren() failed to create a new task: err = '%v'", err)
+	}
+	child2.CreatedAt = parent.CreatedAt.Add(2 * time.Second)
+
+	grandchild, err := task.NewTask("grandchild", userId, task.WithParent(child1.ID))
+	if err != nil {
+		t.Errorf("GetChildren() failed to create a new task: err = '%v'", err)
+	}
+
+	// Add the tasks to the tasks collection
+	r := repository.NewRepository(cfg)
+	for _, ti := range []task.Task{parent, child2, child1, grandchild} {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("GetChildren() failed to save new tasks: err = '%v'", err)
+		}
+	}
+
+	found, err := r.GetChildren(context.Background(), parent.ID)
+	if err != nil {
+		t.Errorf("GetChildren() err = '%v'", err)
+	}
+
+	if len(found) != 2 {
+		t.Fatalf("GetChildren() got = '%v', want = '%v'", len(found), 2)
+	}
+
+	if found[0].ID != child1.ID || found[1].ID != child2.ID {
+		t.Errorf("GetChildren() got = '%v', want = '%v'", found, []task.Task{child1, child2})
+	}
+
+	if found[0].ParentID == nil || *found[0].ParentID != parent.ID {
+		t.Errorf("GetChildren() ParentID = '%v', want = '%v'", found[0].ParentID, parent.ID)
+	}
+
+	// Move the grandchild to the top level
+	grandchild.ClearParent()
+	err = r.Update(context.Background(), grandchild)
+	if err != nil {
+		t.Errorf("Update() err = '%v'", err)
+	}
+
+	found, err = r.GetChildren(context.Background(), child1.ID)
+	if err != nil {
+		t.Errorf("GetChildren() err = '%v'", err)
+	}
+
+	if len(found) != 0 {
+		t.Errorf("GetChildren() got = '%v', want = '%v'", len(found), 0)
+	}
+}
+
+func TestRepositoryTags(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+
+	work, err := task.NewTask("work", userId, task.WithTags("work", "urgent"))
+	if err != nil {
+		t.Errorf("Tags() failed to create a new task: err = '%v'", err)
+	}
+
+	job, err := task.NewTask("job", userId, task.WithTags("job"))
+	if err != nil {
+		t.Errorf("Tags() failed to create a new task: err = '%v'", err)
+	}
+
+	home, err := task.NewTask("home", userId, task.WithTags("home", "urgent"))
+	if err != nil {
+		t.Errorf("Tags() failed to create a new task: err = '%v'", err)
+	}
+
+	other, err := task.NewTask("other", uuid.New(), task.WithTags("job"))
+	if err != nil {
+		t.Errorf("Tags() failed to create a new task: err = '%v'", err)
+	}
+
+	listed, err := task.NewTask("listed", userId, task.WithList(uuid.New()), task.WithTags("job", "urgent"))
+	if err != nil {
+		t.Errorf("Tags() failed to create a new task: err = '%v'", err)
+	}
+
+	job.UpdatedAt = job.UpdatedAt.Add(-time.Hour)
+
+	// Add the tasks to the tasks collection
+	r := repository.NewRepository(cfg)
+	for _, ti := range []task.Task{work, job, home, other, listed} {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("Tags() failed to save new tasks: err = '%v'", err)
+		}
+	}
+
+	// Any of the tags
+	q := task.NewQuery(userId)
+	q.Tags = []string{"home", "work"}
+
+	page, err := r.Find(context.Background(), q)
+	if err != nil {
+		t.Errorf("Find() err = '%v'", err)
+	}
+
+	if len(page.Tasks) != 2 {
+		t.Errorf("Find() got = '%v', want = '%v'", len(page.Tasks), 2)
+	}
+
+	// All of the tags
+	q.Tags = []string{"urgent", "work"}
+	q.TagMatch = task.TagMatchAll
+
+	page, err = r.Find(context.Background(), q)
+	if err != nil {
+		t.Errorf("Find() err = '%v'", err)
+	}
+
+	if len(page.Tasks) != 1 || page.Tasks[0].ID != work.ID {
+		t.Errorf("Find() got = '%v', want = '%v'", page.Tasks, work.ID)
+	}
+
+	// Merge job into work
+	n, err := r.RenameTag(context.Background(), userId, "job", "work")
+	if err != nil {
+		t.Errorf("RenameTag() err = '%v'", err)
+	}
+
+	if n != 1 {
+		t.Errorf("RenameTag() got = '%v', want = '%v'", n, 1)
+	}
+
+	found, err := r.GetByID(context.Background(), job.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v'", err)
+	}
+
+	if !reflect.DeepEqual(found.Tags, []string{"work"}) {
+		t.Errorf("RenameTag() got = '%v', want = '%v'", found.Tags, []string{"work"})
+	}
+
+	if found.Version != job.Version+1 || !found.UpdatedAt.After(job.UpdatedAt) {
+		t.Errorf("RenameTag() got = '%v' '%v', want the version and the update time bumped", found.Version, found.UpdatedAt)
+	}
+
+	found, err = r.GetByID(context.Background(), other.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v'", err)
+	}
+
+	if !reflect.DeepEqual(found.Tags, []string{"job"}) {
+		t.Errorf("RenameTag() must not change the tasks of another user: got = '%v'", found.Tags)
+	}
+
+	found, err = r.GetByID(context.Background(), listed.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v'", err)
+	}
+
+	if !reflect.DeepEqual(found.Tags, []string{"job", "urgent"}) {
+		t.Errorf("RenameTag() must not change the tasks of a list: got = '%v'", found.Tags)
+	}
+
+	counts, err := r.GetTagCountsByUserID(context.Background(), userId)
+	if err != nil {
+		t.Errorf("GetTagCountsByUserID() err = '%v'", err)
+	}
+
+	want := []task.TagCount{{Tag: "urgent", Count: 2}, {Tag: "work", Count: 2}, {Tag: "home", Count: 1}}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("GetTagCountsByUserID() got = '%v', want = '%v'", counts, want)
+	}
+}
+
+func TestRepositorySearch(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+
+	once, err := task.NewTask("get some milk from the shop", userId)
+	if err != nil {
+		t.Errorf("Search() failed to create a new task: err = '%v'", err)
+	}
+
+	twice, err := task.NewTask("bread and milk", userId)
+	if err != nil {
+		t.Errorf("Search() failed to create a new task: err = '%v'", err)
+	}
+
+	unrelated, err := task.NewTask("call mom", userId)
+	if err != nil {
+		t.Errorf("Search() failed to create a new task: err = '%v'", err)
+	}
+
+	other, err := task.NewTask("bread and milk", uuid.New())
+	if err != nil {
+		t.Errorf("Search() failed to create a new task: err = '%v'", err)
+	}
+
+	// Add the tasks to the tasks collection
+	r := repository.NewRepository(cfg)
+	for _, ti := range []task.Task{once, twice, unrelated, other} {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("Search() failed to save new tasks: err = '%v'", err)
+		}
+	}
+
+	results, err := r.Search(context.Background(), task.NewSearchQuery(userId, "Milk bread"))
+	if err != nil {
+		t.Errorf("Search() err = '%v'", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Search() got = '%v', want = '%v'", len(results), 2)
+	}
+
+	if results[0].Task.ID != twice.ID || results[1].Task.ID != once.ID {
+		t.Errorf("Search() got = '%v', want = '%v'", results, []task.Task{twice, once})
+	}
+
+	if results[0].Score <= results[1].Score {
+		t.Errorf("Search() scores = '%v', '%v', want descending", results[0].Score, results[1].Score)
+	}
+}
+
+func TestRepositoryTrash(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+	now := time.Now().Truncate(time.Millisecond)
+
+	active, err := task.NewTask("active task", userId)
+	if err != nil {
+		t.Errorf("Trash() failed to create a new task: err = '%v'", err)
+	}
+
+	recent, err := task.NewTask("recently deleted task", userId)
+	if err != nil {
+		t.Errorf("Trash() failed to create a new task: err = '%v'", err)
+	}
+	_ = recent.MoveToTrash(now.Add(-time.Hour))
+
+	old, err := task.NewTask("long ago deleted task", userId)
+	if err != nil {
+		t.Errorf("Trash() failed to create a new task: err = '%v'", err)
+	}
+	_ = old.MoveToTrash(now.Add(-48 * time.Hour))
+
+	// Add the tasks to the tasks collection
+	r := repository.NewRepository(cfg)
+	for _, ti := range []task.Task{active, recent, old} {
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("Trash() failed to save new tasks: err = '%v'", err)
+		}
+	}
+
+	tasks, err := r.GetAllByUserID(context.Background(), userId)
+	if err != nil {
+		t.Errorf("GetAllByUserID() err = '%v'", err)
+	}
+
+	if len(tasks) != 1 || tasks[0].ID != active.ID {
+		t.Errorf("GetAllByUserID() got = '%v', want = '%v'", tasks, []task.Task{active})
+	}
+
+	trash, err := r.GetTrashByUserID(context.Background(), userId)
+	if err != nil {
+		t.Errorf("GetTrashByUserID() err = '%v'", err)
+	}
+
+	if len(trash) != 2 || trash[0].ID != recent.ID || trash[1].ID != old.ID {
+		t.Errorf("GetTrashByUserID() got = '%v', want = '%v'", trash, []task.Task{recent, old})
+	}
+
+	_, err = r.PurgeTrash(context.Background(), now.Add(-24*time.Hour))
+	if err != nil {
+		t.Errorf("PurgeTrash() err = '%v'", err)
+	}
+
+	_, err = r.GetByID(context.Background(), old.ID)
+	if !errors.Is(err, task.ErrTaskNotFound) {
+		t.Errorf("GetByID() err = '%v', want = '%v'", err, task.ErrTaskNotFound)
+	}
+
+	for _, id := range []uuid.UUID{active.ID, recent.ID} {
+		_, err = r.GetByID(context.Background(), id)
+		if err != nil {
+			t.Errorf("GetByID() err = '%v'", err)
+		}
+	}
+}
+
+func TestRepositoryRecurrence(t *testing.T) {
+	cfg := config.Config{}
+
+	ti, err := task.NewTask(
+		"task text",
+		uuid.New(),
+		task.WithDueDate(time.Now()),
+		task.WithRecurrence(task.MustParseRecurrence("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;UNTIL=20301231")),
+	)
+	if err != nil {
+		t.Errorf("Recurrence() failed to create a new task: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	err = r.Save(context.Background(), ti)
+	if err != nil {
+		t.Errorf("Save() err = '%v'", err)
+	}
+
+	got, err := r.GetByID(context.Background(), ti.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v'", err)
+	}
+
+	if got.Recurrence == nil || got.Recurrence.String() != ti.Recurrence.String() {
+		t.Errorf("GetByID() got = '%v', want = '%v'", got.Recurrence, ti.Recurrence)
+	}
+
+	got.ClearRecurrence()
+	err = r.Update(context.Background(), got)
+	if err != nil {
+		t.Errorf("Update() err = '%v'", err)
+	}
+
+	got, err = r.GetByID(context.Background(), ti.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v'", err)
+	}
+
+	if got.Recurrence != nil {
+		t.Errorf("GetByID() got = '%v', want = '%v'", got.Recurrence, nil)
+	}
+}
+
+func TestRepositoryUpdateVersion(t *testing.T) {
+	cfg := config.Config{}
+
+	ti, err := task.NewTask("task text", uuid.New())
+	if err != nil {
+		t.Errorf("Update() failed to create a new task: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	err = r.Save(context.Background(), ti)
+	if err != nil {
+		t.Errorf("Update() failed to save a new task: err = '%v'", err)
+	}
+
+	// The first writer wins
+	first, second := ti, ti
+	_ = first.SetText("first")
+	_ = second.SetText("second")
+
+	err = r.Update(context.Background(), first)
+	if err != nil {
+		t.Errorf("Update() err = '%v'", err)
+	}
+
+	err = r.Update(context.Background(), second)
+	if !errors.Is(err, task.ErrVersionMismatch) {
+		t.Errorf("Update() err = '%v', want = '%v'", err, task.ErrVersionMismatch)
+	}
+
+	got, err := r.GetByID(context.Background(), ti.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v'", err)
+	}
+
+	if got.Text != "first" || got.Version != ti.Version+1 {
+		t.Errorf("GetByID() got = '%v' at version %v, want = '%v' at version %v", got.Text, got.Version, "first", ti.Version+1)
+	}
+
+	// Updating a task that does not exist is not a version mismatch
+	missing, err := task.NewTask("task text", uuid.New())
+	if err != nil {
+		t.Errorf("Update() failed to create a new task: err = '%v'", err)
+	}
+
+	err = r.Update(context.Background(), missing)
+	if err == nil || errors.Is(err, task.ErrVersionMismatch) {
+		t.Errorf("Update() err = '%v', want an error other than '%v'", err, task.ErrVersionMismatch)
+	}
+}
+
+func TestRepositoryPosition(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+	r := repository.NewRepository(cfg)
+
+	// Saved in one order, ranked in another
+	var want []uuid.UUID
+	for i, position := range []float64{3 * task.PositionGap, task.PositionGap, 2 * task.PositionGap} {
+		ti, err := task.NewTask(fmt.Sprintf("task %d", i), userId)
+		if err != nil {
+			t.Errorf("GetAllByUserID() failed to create a new task: err = '%v'", err)
+		}
+		ti.MoveTo(position)
+
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("GetAllByUserID() failed to save a new task: err = '%v'", err)
+		}
+
+		want = append(want, ti.ID)
+	}
+	want = []uuid.UUID{want[1], want[2], want[0]}
+
+	ids := func(tasks []task.Task) []uuid.UUID {
+		var ids []uuid.UUID
+		for _, ti := range tasks {
+			ids = append(ids, ti.ID)
+		}
+		return ids
+	}
+
+	got, err := r.GetAllByUserID(context.Background(), userId)
+	if err != nil {
+		t.Errorf("GetAllByUserID() err = '%v'", err)
+	}
+
+	if !reflect.DeepEqual(ids(got), want) {
+		t.Errorf("GetAllByUserID() got = '%v', want = '%v'", ids(got), want)
+	}
+
+	q := task.NewQuery(userId)
+	q.SortBy = task.SortByPosition
+	q.Limit = 2
+
+	page, err := r.Find(context.Background(), q)
+	if err != nil {
+		t.Errorf("Find() err = '%v'", err)
+	}
+
+	q.Cursor = page.NextCursor
+	next, err := r.Find(context.Background(), q)
+	if err != nil {
+		t.Errorf("Find() err = '%v'", err)
+	}
+
+	if found := append(ids(page.Tasks), ids(next.Tasks)...); !reflect.DeepEqual(found, want) {
+		t.Errorf("Find() got = '%v', want = '%v'", found, want)
+	}
+
+	listing, err := r.GetListing(context.Background(), userId, nil)
+	if err != nil {
+		t.Errorf("GetListing() err = '%v'", err)
+	}
+
+	if !reflect.DeepEqual(ids(listing), want) {
+		t.Errorf("GetListing() got = '%v', want = '%v'", ids(listing), want)
+	}
+
+	// A task of a list is only in the listing of the list
+	listId := uuid.New()
+	listed, err := task.NewTask("listed", userId)
+	if err != nil {
+		t.Errorf("GetListing() failed to create a new task: err = '%v'", err)
+	}
+	listed.ListID = &listId
+
+	err = r.Save(context.Background(), listed)
+	if err != nil {
+		t.Errorf("GetListing() failed to save a new task: err = '%v'", err)
+	}
+
+	listing, err = r.GetListing(context.Background(), userId, &listId)
+	if err != nil {
+		t.Errorf("GetListing() err = '%v'", err)
+	}
+
+	if !reflect.DeepEqual(ids(listing), []uuid.UUID{listed.ID}) {
+		t.Errorf("GetListing() got = '%v', want = '%v'", ids(listing), []uuid.UUID{listed.ID})
+	}
+
+	err = r.Delete(context.Background(), listed.ID)
+	if err != nil {
+		t.Errorf("Delete() err = '%v'", err)
+	}
+
+	// Moving the last task to the front
+	last := got[2]
+	last.MoveTo(got[0].Position - task.PositionGap)
+
+	err = r.Update(context.Background(), last)
+	if err != nil {
+		t.Errorf("Update() err = '%v'", err)
+	}
+
+	got, err = r.GetAllByUserID(context.Background(), userId)
+	if err != nil {
+		t.Errorf("GetAllByUserID() err = '%v'", err)
+	}
+
+	want = []uuid.UUID{want[2], want[0], want[1]}
+	if !reflect.DeepEqual(ids(got), want) {
+		t.Errorf("GetAllByUserID() got = '%v', want = '%v'", ids(got), want)
+	}
+}
+
+func TestRepositoryGetOpenByUserID(t *testing.T) {
+	cfg := config.Config{}
+
+	userId := uuid.New()
+	r := repository.NewRepository(cfg)
+
+	type args struct {
+		priority  task.Priority
+		position  float64
+		completed bool
+	}
+
+	var ids []uuid.UUID
+	for i, a := range []args{
+		{priority: task.PriorityLow, position: 1, completed: false},
+		{priority: task.PriorityHigh, position: 2, completed: false},
+		{priority: task.PriorityLow, position: 0, completed: false},
+		{priority: task.PriorityHigh, position: 0, completed: true},
+	} {
+		ti, err := task.NewTask(fmt.Sprintf("task %d", i), userId, task.WithPriority(a.priority), task.WithEisenhower(task.Eisenhower{Important: true, Urgent: false}))
+		if err != nil {
+			t.Errorf("GetOpenByUserID() failed to create a new task: err = '%v'", err)
+		}
+		ti.MoveTo(a.position)
+		if a.completed {
+			ti.MarkCompleted()
+		}
+
+		err = r.Save(context.Background(), ti)
+		if err != nil {
+			t.Errorf("GetOpenByUserID() failed to save a new task: err = '%v'", err)
+		}
+
+		ids = append(ids, ti.ID)
+	}
+
+	got, err := r.GetOpenByUserID(context.Background(), userId)
+	if err != nil {
+		t.Errorf("GetOpenByUserID() err = '%v'", err)
+	}
+
+	var gotIds []uuid.UUID
+	for _, ti := range got {
+		gotIds = append(gotIds, ti.ID)
+	}
+
+	if want := []uuid.UUID{ids[1], ids[2], ids[0]}; !reflect.DeepEqual(gotIds, want) {
+		t.Errorf("GetOpenByUserID() got = '%v', want = '%v'", gotIds, want)
+	}
+
+	if len(got) > 0 && (got[0].Priority != task.PriorityHigh || got[0].Eisenhower == nil || !got[0].Eisenhower.Important) {
+		t.Errorf("GetOpenByUserID() got = '%v', want a classified task of high priority", got[0])
+	}
+}
@@ -5,10 +5,22 @@ import (
 )
 
 type Task struct {
-	ID        string    `bson:"_id"`
-	Text      string    `bson:"text"`
-	Completed bool      `bson:"completed"`
-	UserID    string    `bson:"user_id"`
-	CreatedAt time.Time `bson:"created_at"`
-	UpdatedAt time.Time `bson:"updated_at"`
+	ID             string         `bson:"_id"`
+	Text           string         `bson:"text"`
+	Completed      bool           `bson:"completed"`
+	UserID         string         `bson:"user_id"`
+	ListID         *string        `bson:"list_id,omitempty"`
+	ParentID       *string        `bson:"parent_id,omitempty"`
+	DueAt          *time.Time     `bson:"due_at,omitempty"`
+	ReminderOffset *time.Duration `bson:"reminder_offset,omitempty"`
+	Recurrence     *string        `bson:"recurrence,omitempty"`
+	Tags           []string       `bson:"tags,omitempty"`
+	DeletedAt      *time.Time     `bson:"deleted_at,omitempty"`
+	Position       float64        `bson:"position"`
+	Priority       int            `bson:"priority"`
+	Important      *bool          `bson:"important,omitempty"`
+	Urgent         *bool          `bson:"urgent,omitempty"`
+	Version        int64          `bson:"version"`
+	CreatedAt      time.Time      `bson:"created_at"`
+	UpdatedAt      time.Time      `bson:"updated_at"`
 }
@@ -3,15 +3,18 @@ package repository
 import (
 	"context"
 	"errors"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/config"
 	"github.com/ozaitsev92/tododdd/internal/domain/task"
 	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/mongo/converter"
 	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"gopkg.in/mgo.v2/bson"
 
 	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/mongo/model"
 )
@@ -30,6 +33,35 @@ func NewRepository(cfg config.Config) *Repository {
 	}
 }
 
+// EnsureIndexes creates the indexes the repository relies on.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "tags", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "list_id", Value: 1}, {Key: "tags", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "position", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "completed", Value: 1}, {Key: "priority", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// No stemming and no stop words, so the search matches whole words like the memory repository.
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "text", Value: "text"}},
+			Options: options.Index().SetDefaultLanguage("none"),
+		},
+	})
+
+	return err
+}
+
 func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (task.Task, error) {
 	var mongoTask repoModel.Task
 
@@ -45,8 +77,8 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (task.Task, erro
 }
 
 func (r *Repository) GetAllByUserID(ctx context.Context, userId uuid.UUID) ([]task.Task, error) {
-	filter := bson.M{"user_id": userId.String()}
-	sort := options.Find().SetSort(bson.M{"created_at": 1})
+	filter := bson.M{"user_id": userId.String(), "deleted_at": nil}
+	sort := options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
 
 	cursor, err := r.collection.Find(ctx, filter, sort)
 	if err != nil {
@@ -71,6 +103,325 @@ func (r *Repository) GetAllByUserID(ctx context.Context, userId uuid.UUID) ([]ta
 	return tasks, nil
 }
 
+func (r *Repository) GetListing(ctx context.Context, userId uuid.UUID, listId *uuid.UUID) ([]task.Task, error) {
+	filter := bson.M{"user_id": userId.String(), "list_id": nil, "deleted_at": nil}
+	if listId != nil {
+		filter = bson.M{"list_id": listId.String(), "deleted_at": nil}
+	}
+	sort := options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, sort)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	var mongoTasks []repoModel.Task
+
+	err = cursor.All(ctx, &mongoTasks)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	tasks := make([]task.Task, 0, len(mongoTasks))
+	for _, mongoTask := range mongoTasks {
+		tasks = append(tasks, converter.ToTaskFromRepo(mongoTask))
+	}
+
+	return tasks, nil
+}
+
+func (r *Repository) GetOpenByUserID(ctx context.Context, userId uuid.UUID) ([]task.Task, error) {
+	filter := bson.M{"user_id": userId.String(), "completed": false, "deleted_at": nil}
+	sort := options.Find().SetSort(bson.D{
+		{Key: "priority", Value: -1},
+		{Key: "position", Value: 1},
+		{Key: "created_at", Value: 1},
+		{Key: "_id", Value: 1},
+	})
+
+	cursor, err := r.collection.Find(ctx, filter, sort)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	var mongoTasks []repoModel.Task
+
+	err = cursor.All(ctx, &mongoTasks)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	tasks := make([]task.Task, 0, len(mongoTasks))
+	for _, mongoTask := range mongoTasks {
+		tasks = append(tasks, converter.ToTaskFromRepo(mongoTask))
+	}
+
+	return tasks, nil
+}
+
+func (r *Repository) GetTrashByUserID(ctx context.Context, userId uuid.UUID) ([]task.Task, error) {
+	filter := bson.M{"user_id": userId.String(), "deleted_at": bson.M{"$ne": nil}}
+	sort := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, sort)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	var mongoTasks []repoModel.Task
+
+	err = cursor.All(ctx, &mongoTasks)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	tasks := make([]task.Task, 0, len(mongoTasks))
+	for _, mongoTask := range mongoTasks {
+		tasks = append(tasks, converter.ToTaskFromRepo(mongoTask))
+	}
+
+	return tasks, nil
+}
+
+func (r *Repository) Find(ctx context.Context, q task.Query) (task.Page, error) {
+	c, err := q.DecodeCursor()
+	if err != nil {
+		return task.Page{}, err
+	}
+
+	filter := bson.M{"user_id": q.UserID.String(), "list_id": nil, "deleted_at": nil}
+	if q.ListID != nil {
+		filter = bson.M{"list_id": q.ListID.String(), "deleted_at": nil}
+	}
+
+	if q.Completed != nil {
+		filter["completed"] = *q.Completed
+	}
+
+	if rng := dateRange(q.CreatedFrom, q.CreatedTo); rng != nil {
+		filter["created_at"] = rng
+	}
+
+	if rng := dateRange(q.UpdatedFrom, q.UpdatedTo); rng != nil {
+		filter["updated_at"] = rng
+	}
+
+	if q.Text != "" {
+		filter["text"] = bson.M{"$regex": regexp.QuoteMeta(q.Text), "$options": "i"}
+	}
+
+	if len(q.Tags) > 0 {
+		op := "$in"
+		if q.TagMatch == task.TagMatchAll {
+			op = "$all"
+		}
+		filter["tags"] = bson.M{op: q.Tags}
+	}
+
+	field := string(q.SortBy)
+	order, op := 1, "$gt"
+	if q.SortDirection == task.SortDesc {
+		order, op = -1, "$lt"
+	}
+
+	if c != nil {
+		var key any = c.Time
+		switch q.SortBy {
+		case task.SortByText:
+			key = c.Text
+		case task.SortByPosition:
+			key = c.Position
+		}
+
+		filter["$or"] = []bson.M{
+			{field: bson.M{op: key}},
+			{field: key, "_id": bson.M{op: c.ID.String()}},
+		}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: field, Value: order}, {Key: "_id", Value: order}}).SetLimit(int64(q.Limit) + 1)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return task.Page{}, err
+	}
+
+	var mongoTasks []repoModel.Task
+
+	err = cursor.All(ctx, &mongoTasks)
+	if err != nil {
+		return task.Page{}, err
+	}
+
+	tasks := make([]task.Task, 0, len(mongoTasks))
+	for _, mongoTask := range mongoTasks {
+		tasks = append(tasks, converter.ToTaskFromRepo(mongoTask))
+	}
+
+	page := task.Page{Tasks: tasks}
+	if len(tasks) > q.Limit {
+		page.Tasks = tasks[:q.Limit]
+		page.NextCursor = q.EncodeCursor(page.Tasks[q.Limit-1])
+	}
+
+	return page, nil
+}
+
+func (r *Repository) Search(ctx context.Context, q task.SearchQuery) ([]task.SearchResult, error) {
+	filter := bson.M{
+		"user_id":    q.UserID.String(),
+		"deleted_at": nil,
+		"$text":      bson.M{"$search": strings.Join(q.Terms(), " ")},
+	}
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return []task.SearchResult{}, err
+	}
+
+	var mongoResults []struct {
+		Task  repoModel.Task `bson:",inline"`
+		Score float64        `bson:"score"`
+	}
+
+	err = cursor.All(ctx, &mongoResults)
+	if err != nil {
+		return []task.SearchResult{}, err
+	}
+
+	results := make([]task.SearchResult, 0, len(mongoResults))
+	for _, mongoResult := range mongoResults {
+		results = append(results, task.SearchResult{
+			Task:  converter.ToTaskFromRepo(mongoResult.Task),
+			Score: mongoResult.Score,
+		})
+	}
+
+	return results, nil
+}
+
+func (r *Repository) GetChildren(ctx context.Context, parentId uuid.UUID) ([]task.Task, error) {
+	filter := bson.M{"parent_id": parentId.String()}
+	sort := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, sort)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	var mongoTasks []repoModel.Task
+
+	err = cursor.All(ctx, &mongoTasks)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	tasks := make([]task.Task, 0, len(mongoTasks))
+	for _, mongoTask := range mongoTasks {
+		tasks = append(tasks, converter.ToTaskFromRepo(mongoTask))
+	}
+
+	return tasks, nil
+}
+
+func (r *Repository) GetAllDueByUserID(ctx context.Context, userId uuid.UUID, from time.Time, to time.Time) ([]task.Task, error) {
+	due := bson.M{"$lt": to}
+	if !from.IsZero() {
+		due["$gte"] = from
+	}
+
+	filter := bson.M{
+		"user_id":    userId.String(),
+		"completed":  false,
+		"due_at":     due,
+		"deleted_at": nil,
+	}
+	sort := options.Find().SetSort(bson.D{{Key: "due_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, sort)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	var mongoTasks []repoModel.Task
+
+	err = cursor.All(ctx, &mongoTasks)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	tasks := make([]task.Task, 0, len(mongoTasks))
+	for _, mongoTask := range mongoTasks {
+		tasks = append(tasks, converter.ToTaskFromRepo(mongoTask))
+	}
+
+	return tasks, nil
+}
+
+func (r *Repository) GetTagCountsByUserID(ctx context.Context, userId uuid.UUID) ([]task.TagCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userId.String(), "list_id": nil, "deleted_at": nil}}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return []task.TagCount{}, err
+	}
+
+	var counts []struct {
+		Tag   string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+
+	err = cursor.All(ctx, &counts)
+	if err != nil {
+		return []task.TagCount{}, err
+	}
+
+	tags := make([]task.TagCount, 0, len(counts))
+	for _, c := range counts {
+		tags = append(tags, task.TagCount{Tag: c.Tag, Count: c.Count})
+	}
+
+	return tags, nil
+}
+
+func (r *Repository) RenameTag(ctx context.Context, userId uuid.UUID, from string, to string) (int, error) {
+	filter := bson.M{"user_id": userId.String(), "list_id": nil, "tags": from}
+
+	// The tags are kept sorted and unique, as in the domain.
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tags": bson.M{"$sortArray": bson.M{
+				"input": bson.M{"$setUnion": bson.A{
+					bson.M{"$setDifference": bson.A{"$tags", bson.A{from}}},
+					bson.A{to},
+				}},
+				"sortBy": 1,
+			}},
+			"version":    bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}},
+			"updated_at": time.Now(),
+		}}},
+	}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, task.ErrFailedUpdateTask
+	}
+
+	return int(result.ModifiedCount), nil
+}
+
 func (r *Repository) Save(ctx context.Context, t task.Task) error {
 	mongoItem := converter.ToRepoFromTask(t)
 	_, err := r.collection.InsertOne(ctx, mongoItem)
@@ -82,18 +433,36 @@ func (r *Repository) Save(ctx context.Context, t task.Task) error {
 }
 
 func (r *Repository) Update(ctx context.Context, t task.Task) error {
-	filter := bson.M{"_id": t.ID.String()}
+	mongoTask := converter.ToRepoFromTask(t)
+	filter := bson.M{"_id": mongoTask.ID, "version": mongoTask.Version}
+	if mongoTask.Version == 0 {
+		// Tasks stored before versioning have no version field.
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+
 	update := bson.M{
 		"$set": bson.M{
-			"text":      t.Text,
-			"completed": t.Completed,
+			"text":            mongoTask.Text,
+			"completed":       mongoTask.Completed,
+			"parent_id":       mongoTask.ParentID,
+			"due_at":          mongoTask.DueAt,
+			"reminder_offset": mongoTask.ReminderOffset,
+			"recurrence":      mongoTask.Recurrence,
+			"tags":            mongoTask.Tags,
+			"position":        mongoTask.Position,
+			"priority":        mongoTask.Priority,
+			"important":       mongoTask.Important,
+			"urgent":          mongoTask.Urgent,
+			"deleted_at":      mongoTask.DeletedAt,
+			"version":         mongoTask.Version + 1,
+			"updated_at":      mongoTask.UpdatedAt,
 		},
 	}
 
 	result := r.collection.FindOneAndUpdate(ctx, filter, update)
 	if result.Err() != nil {
 		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
-			return task.ErrTaskNotFound
+			return r.versionMismatchOrNotFound(ctx, mongoTask.ID)
 		}
 
 		return task.ErrFailedUpdateTask
@@ -102,6 +471,20 @@ func (r *Repository) Update(ctx context.Context, t task.Task) error {
 	return nil
 }
 
+// versionMismatchOrNotFound tells why an update of the task matched no document.
+func (r *Repository) versionMismatchOrNotFound(ctx context.Context, id string) error {
+	n, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return task.ErrFailedUpdateTask
+	}
+
+	if n == 0 {
+		return task.ErrTaskNotFound
+	}
+
+	return task.ErrVersionMismatch
+}
+
 func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
 	result := r.collection.FindOneAndDelete(ctx, bson.M{"_id": id.String()})
 	if result.Err() != nil {
@@ -114,3 +497,38 @@ func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+func (r *Repository) DeleteAllByListID(ctx context.Context, listId uuid.UUID) (int, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"list_id": listId.String()})
+	if err != nil {
+		return 0, task.ErrFailedDeleteTask
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+func (r *Repository) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, task.ErrFailedDeleteTask
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+func dateRange(from, to time.Time) bson.M {
+	if from.IsZero() && to.IsZero() {
+		return nil
+	}
+
+	r := bson.M{}
+	if !from.IsZero() {
+		r["$gte"] = from
+	}
+
+	if !to.IsZero() {
+		r["$lte"] = to
+	}
+
+	return r
+}
@@ -0,0 +1,60 @@
+package transaction
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+var _ usecase.Transactor = (*Transactor)(nil)
+
+// transactionKey marks the context of a running transaction with its Transactor.
+type transactionKey struct{}
+
+// Participant is a memory repository that can take part in a transaction.
+type Participant interface {
+	// Snapshot saves the state of the repository and returns a function bringing it back.
+	Snapshot() (restore func())
+}
+
+// Transactor runs the transactions one at a time and rolls a failed one back by restoring
+// the participants to their state at its start. Writes made outside of the transactions
+// while one is running are lost on its rollback.
+type Transactor struct {
+	participants []Participant
+	mu           sync.Mutex
+}
+
+func NewTransactor(participants ...Participant) *Transactor {
+	return &Transactor{
+		participants: participants,
+	}
+}
+
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(transactionKey{}) == t {
+		return fn(ctx)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ctx = context.WithValue(ctx, transactionKey{}, t)
+
+	restores := make([]func(), 0, len(t.participants))
+	for _, p := range t.participants {
+		restores = append(restores, p.Snapshot())
+	}
+
+	err := fn(ctx)
+	if err != nil {
+		for _, restore := range restores {
+			restore()
+		}
+
+		return err
+	}
+
+	return nil
+}
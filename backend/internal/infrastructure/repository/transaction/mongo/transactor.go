@@ -0,0 +1,42 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var _ usecase.Transactor = (*Transactor)(nil)
+
+// Transactor runs the transactions as Mongo multi-document transactions,
+// which require a replica set or a sharded cluster.
+type Transactor struct {
+	client *mongo.Client
+}
+
+func NewTransactor(cfg config.Config) *Transactor {
+	return &Transactor{
+		client: mongodb.NewOrGetSingleton(cfg).Client(),
+	}
+}
+
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+
+	return err
+}
@@ -8,20 +8,24 @@ import (
 
 func ToUserFromRepo(u repoModel.User) user.User {
 	return user.User{
-		ID:        uuid.MustParse(u.ID),
-		Email:     u.Email,
-		Password:  u.Password,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:                 uuid.MustParse(u.ID),
+		Email:              u.Email,
+		Password:           u.Password,
+		VerifiedAt:         u.VerifiedAt,
+		VerificationSentAt: u.VerificationSentAt,
+		CreatedAt:          u.CreatedAt,
+		UpdatedAt:          u.UpdatedAt,
 	}
 }
 
 func ToRepoFromUser(u user.User) repoModel.User {
 	return repoModel.User{
-		ID:        u.ID.String(),
-		Email:     u.Email,
-		Password:  u.Password,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:                 u.ID.String(),
+		Email:              u.Email,
+		Password:           u.Password,
+		VerifiedAt:         u.VerifiedAt,
+		VerificationSentAt: u.VerificationSentAt,
+		CreatedAt:          u.CreatedAt,
+		UpdatedAt:          u.UpdatedAt,
 	}
 }
@@ -5,9 +5,11 @@ import (
 )
 
 type User struct {
-	ID        string
-	Email     string
-	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                 string
+	Email              string
+	Password           string
+	VerifiedAt         *time.Time
+	VerificationSentAt time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
 }
@@ -25,6 +25,24 @@ func NewRepository(_ config.Config) *Repository {
 	}
 }
 
+// Snapshot saves the users and returns a function bringing them back.
+func (r *Repository) Snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make(map[uuid.UUID]repoModel.User, len(r.users))
+	for id, u := range r.users {
+		users[id] = u
+	}
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.users = users
+	}
+}
+
 func (r *Repository) GetByID(_ context.Context, id uuid.UUID) (user.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -73,3 +91,37 @@ func (r *Repository) Save(_ context.Context, u user.User) error {
 
 	return nil
 }
+
+func (r *Repository) Update(_ context.Context, u user.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.users == nil {
+		r.users = make(map[uuid.UUID]repoModel.User)
+	}
+
+	if _, ok := r.users[u.ID]; !ok {
+		return user.ErrUserNotFound
+	}
+
+	r.users[u.ID] = converter.ToRepoFromUser(u)
+
+	return nil
+}
+
+func (r *Repository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.users == nil {
+		r.users = make(map[uuid.UUID]repoModel.User)
+	}
+
+	if _, ok := r.users[id]; !ok {
+		return user.ErrUserNotFound
+	}
+
+	delete(r.users, id)
+
+	return nil
+}
@@ -117,3 +117,81 @@ func TestRepositorySave(t *testing.T) {
 		t.Errorf("GetByID() got = '%v', want = '%v'", foundUser.Email, u.Email)
 	}
 }
+
+func TestRepositoryUpdate(t *testing.T) {
+	cfg := config.Config{}
+
+	u, err := user.NewUser("test4@example.com", "Password123")
+	if err != nil {
+		t.Errorf("Update() failed to create a new user: err = '%v'", err)
+	}
+
+	// Update a user missing in the DB: should fail
+	r := repository.NewRepository(cfg)
+	err = r.Update(context.Background(), u)
+	if !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("Update() got = '%v', want = '%v'", err, user.ErrUserNotFound)
+	}
+
+	err = r.Save(context.Background(), u)
+	if err != nil {
+		t.Errorf("Update() failed to save a new user: err = '%v'", err)
+	}
+
+	err = u.SetEmail("test5@example.com")
+	if err != nil {
+		t.Errorf("Update() failed to change the email: err = '%v'", err)
+	}
+	u.Verify()
+
+	// Update the user: should succeed
+	err = r.Update(context.Background(), u)
+	if err != nil {
+		t.Errorf("Update() err = '%v'", err)
+	}
+
+	foundUser, err := r.GetByID(context.Background(), u.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v', want = '%v'", err, nil)
+	}
+
+	if foundUser.Email != "test5@example.com" {
+		t.Errorf("Update() got = '%v', want = '%v'", foundUser.Email, "test5@example.com")
+	}
+
+	if !foundUser.IsVerified() {
+		t.Error("Update() the verification of the email is not saved")
+	}
+}
+
+func TestRepositoryDelete(t *testing.T) {
+	cfg := config.Config{}
+
+	u, err := user.NewUser("test6@example.com", "Password123")
+	if err != nil {
+		t.Errorf("Delete() failed to create a new user: err = '%v'", err)
+	}
+
+	r := repository.NewRepository(cfg)
+	err = r.Save(context.Background(), u)
+	if err != nil {
+		t.Errorf("Delete() failed to save a new user: err = '%v'", err)
+	}
+
+	// Delete the user: should succeed
+	err = r.Delete(context.Background(), u.ID)
+	if err != nil {
+		t.Errorf("Delete() err = '%v'", err)
+	}
+
+	_, err = r.GetByID(context.Background(), u.ID)
+	if !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("GetByID() got = '%v', want = '%v'", err, user.ErrUserNotFound)
+	}
+
+	// Delete the user again: should fail
+	err = r.Delete(context.Background(), u.ID)
+	if !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("Delete() got = '%v', want = '%v'", err, user.ErrUserNotFound)
+	}
+}
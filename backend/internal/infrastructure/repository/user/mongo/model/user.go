@@ -5,9 +5,11 @@ import (
 )
 
 type User struct {
-	ID        string    `bson:"_id"`
-	Email     string    `bson:"email"`
-	Password  string    `bson:"password"`
-	CreatedAt time.Time `bson:"created_at"`
-	UpdatedAt time.Time `bson:"updated_at"`
+	ID                 string     `bson:"_id"`
+	Email              string     `bson:"email"`
+	Password           string     `bson:"password"`
+	VerifiedAt         *time.Time `bson:"verified_at,omitempty"`
+	VerificationSentAt time.Time  `bson:"verification_sent_at"`
+	CreatedAt          time.Time  `bson:"created_at"`
+	UpdatedAt          time.Time  `bson:"updated_at"`
 }
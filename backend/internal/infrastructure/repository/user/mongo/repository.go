@@ -67,3 +67,41 @@ func (r *Repository) Save(ctx context.Context, t user.User) error {
 
 	return nil
 }
+
+func (r *Repository) Update(ctx context.Context, u user.User) error {
+	mongoUser := converter.ToRepoFromUser(u)
+	filter := bson.M{"_id": mongoUser.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"email":                mongoUser.Email,
+			"password":             mongoUser.Password,
+			"verified_at":          mongoUser.VerifiedAt,
+			"verification_sent_at": mongoUser.VerificationSentAt,
+			"updated_at":           mongoUser.UpdatedAt,
+		},
+	}
+
+	result := r.collection.FindOneAndUpdate(ctx, filter, update)
+	if result.Err() != nil {
+		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+			return user.ErrUserNotFound
+		}
+
+		return user.ErrFailedToStoreUser
+	}
+
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.collection.FindOneAndDelete(ctx, bson.M{"_id": id.String()})
+	if result.Err() != nil {
+		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+			return user.ErrUserNotFound
+		}
+
+		return user.ErrFailedToDeleteUser
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package converter
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/memory/model"
+)
+
+func ToWebhookFromRepo(w repoModel.Webhook) webhook.Webhook {
+	events := make([]task.EventType, 0, len(w.Events))
+	for _, e := range w.Events {
+		events = append(events, task.EventType(e))
+	}
+
+	return webhook.Webhook{
+		ID:        uuid.MustParse(w.ID),
+		UserID:    uuid.MustParse(w.UserID),
+		URL:       w.URL,
+		Events:    events,
+		Secret:    w.Secret,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+func ToRepoFromWebhook(w webhook.Webhook) repoModel.Webhook {
+	events := make([]string, 0, len(w.Events))
+	for _, e := range w.Events {
+		events = append(events, string(e))
+	}
+
+	return repoModel.Webhook{
+		ID:        w.ID.String(),
+		UserID:    w.UserID.String(),
+		URL:       w.URL,
+		Events:    events,
+		Secret:    w.Secret,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+func ToDeliveryFromRepo(d repoModel.Delivery) webhook.Delivery {
+	attempts := make([]webhook.Attempt, 0, len(d.Attempts))
+	for _, a := range d.Attempts {
+		attempts = append(attempts, webhook.Attempt{
+			At:         a.At,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			Duration:   a.Duration,
+		})
+	}
+
+	return webhook.Delivery{
+		ID:            uuid.MustParse(d.ID),
+		WebhookID:     uuid.MustParse(d.WebhookID),
+		UserID:        uuid.MustParse(d.UserID),
+		EventID:       uuid.MustParse(d.EventID),
+		EventType:     task.EventType(d.EventType),
+		Payload:       slices.Clone(d.Payload),
+		Status:        webhook.Status(d.Status),
+		Attempts:      attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		LeasedUntil:   d.LeasedUntil,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+func ToRepoFromDelivery(d webhook.Delivery) repoModel.Delivery {
+	attempts := make([]repoModel.Attempt, 0, len(d.Attempts))
+	for _, a := range d.Attempts {
+		attempts = append(attempts, repoModel.Attempt{
+			At:         a.At,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			Duration:   a.Duration,
+		})
+	}
+
+	return repoModel.Delivery{
+		ID:            d.ID.String(),
+		WebhookID:     d.WebhookID.String(),
+		UserID:        d.UserID.String(),
+		EventID:       d.EventID.String(),
+		EventType:     string(d.EventType),
+		Payload:       slices.Clone(d.Payload),
+		Status:        string(d.Status),
+		Attempts:      attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		LeasedUntil:   d.LeasedUntil,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/memory/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/memory/model"
+)
+
+var _ webhook.DeliveryRepository = (*DeliveryRepository)(nil)
+
+type DeliveryRepository struct {
+	deliveries map[uuid.UUID]repoModel.Delivery
+	mu         sync.RWMutex
+}
+
+func NewDeliveryRepository(_ config.Config) *DeliveryRepository {
+	return &DeliveryRepository{
+		deliveries: make(map[uuid.UUID]repoModel.Delivery),
+	}
+}
+
+func (r *DeliveryRepository) Save(_ context.Context, d webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.deliveries == nil {
+		r.deliveries = make(map[uuid.UUID]repoModel.Delivery)
+	}
+
+	if _, ok := r.deliveries[d.ID]; ok {
+		return fmt.Errorf("delivery already exists: %w", webhook.ErrFailedToSaveDelivery)
+	}
+
+	r.deliveries[d.ID] = converter.ToRepoFromDelivery(d)
+
+	return nil
+}
+
+func (r *DeliveryRepository) Update(_ context.Context, d webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.deliveries == nil {
+		r.deliveries = make(map[uuid.UUID]repoModel.Delivery)
+	}
+
+	if _, ok := r.deliveries[d.ID]; !ok {
+		return webhook.ErrDeliveryNotFound
+	}
+
+	r.deliveries[d.ID] = converter.ToRepoFromDelivery(d)
+
+	return nil
+}
+
+func (r *DeliveryRepository) GetDue(_ context.Context, now time.Time, limit int) ([]webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.deliveries == nil {
+		r.deliveries = make(map[uuid.UUID]repoModel.Delivery)
+	}
+
+	deliveries := []webhook.Delivery{}
+	for _, d := range r.deliveries {
+		if delivery := converter.ToDeliveryFromRepo(d); delivery.IsDue(now) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt) })
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (r *DeliveryRepository) Claim(_ context.Context, id uuid.UUID, now time.Time, until time.Time) (webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.deliveries[id]
+	if !ok {
+		return webhook.Delivery{}, webhook.ErrDeliveryNotFound
+	}
+
+	delivery := converter.ToDeliveryFromRepo(d)
+	if !delivery.IsDue(now) {
+		return webhook.Delivery{}, webhook.ErrDeliveryClaimed
+	}
+
+	delivery.LeasedUntil = until
+	r.deliveries[id] = converter.ToRepoFromDelivery(delivery)
+
+	return delivery, nil
+}
+
+func (r *DeliveryRepository) GetAllByWebhookID(_ context.Context, webhookId uuid.UUID, limit int) ([]webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.deliveries == nil {
+		r.deliveries = make(map[uuid.UUID]repoModel.Delivery)
+	}
+
+	deliveries := []webhook.Delivery{}
+	for _, d := range r.deliveries {
+		if d.WebhookID == webhookId.String() {
+			deliveries = append(deliveries, converter.ToDeliveryFromRepo(d))
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (r *DeliveryRepository) DeleteByWebhookID(_ context.Context, webhookId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.deliveries == nil {
+		r.deliveries = make(map[uuid.UUID]repoModel.Delivery)
+	}
+
+	for id, d := range r.deliveries {
+		if d.WebhookID == webhookId.String() {
+			delete(r.deliveries, id)
+		}
+	}
+
+	return nil
+}
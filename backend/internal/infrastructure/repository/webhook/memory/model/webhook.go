@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+type Webhook struct {
+	ID        string
+	UserID    string
+	URL       string
+	Events    []string
+	Secret    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Attempt struct {
+	At         time.Time
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+}
+
+type Delivery struct {
+	ID            string
+	WebhookID     string
+	UserID        string
+	EventID       string
+	EventType     string
+	Payload       []byte
+	Status        string
+	Attempts      []Attempt
+	NextAttemptAt time.Time
+	LeasedUntil   time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
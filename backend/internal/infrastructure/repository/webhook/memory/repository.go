@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/memory/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/memory/model"
+)
+
+var _ webhook.Repository = (*Repository)(nil)
+
+type Repository struct {
+	webhooks map[uuid.UUID]repoModel.Webhook
+	mu       sync.RWMutex
+}
+
+func NewRepository(_ config.Config) *Repository {
+	return &Repository{
+		webhooks: make(map[uuid.UUID]repoModel.Webhook),
+	}
+}
+
+func (r *Repository) GetByID(_ context.Context, id uuid.UUID) (webhook.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.webhooks == nil {
+		r.webhooks = make(map[uuid.UUID]repoModel.Webhook)
+	}
+
+	if w, ok := r.webhooks[id]; ok {
+		return converter.ToWebhookFromRepo(w), nil
+	}
+
+	return webhook.Webhook{}, webhook.ErrWebhookNotFound
+}
+
+func (r *Repository) GetAllByUserID(_ context.Context, userId uuid.UUID) ([]webhook.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.webhooks == nil {
+		r.webhooks = make(map[uuid.UUID]repoModel.Webhook)
+	}
+
+	webhooks := []webhook.Webhook{}
+	for _, w := range r.webhooks {
+		if w.UserID == userId.String() {
+			webhooks = append(webhooks, converter.ToWebhookFromRepo(w))
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].CreatedAt.UnixNano() < webhooks[j].CreatedAt.UnixNano() })
+
+	return webhooks, nil
+}
+
+func (r *Repository) Save(_ context.Context, w webhook.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.webhooks == nil {
+		r.webhooks = make(map[uuid.UUID]repoModel.Webhook)
+	}
+
+	if _, ok := r.webhooks[w.ID]; ok {
+		return fmt.Errorf("webhook already exists: %w", webhook.ErrFailedToSaveWebhook)
+	}
+
+	r.webhooks[w.ID] = converter.ToRepoFromWebhook(w)
+
+	return nil
+}
+
+func (r *Repository) Update(_ context.Context, w webhook.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.webhooks == nil {
+		r.webhooks = make(map[uuid.UUID]repoModel.Webhook)
+	}
+
+	if _, ok := r.webhooks[w.ID]; !ok {
+		return webhook.ErrWebhookNotFound
+	}
+
+	r.webhooks[w.ID] = converter.ToRepoFromWebhook(w)
+
+	return nil
+}
+
+func (r *Repository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.webhooks == nil {
+		r.webhooks = make(map[uuid.UUID]repoModel.Webhook)
+	}
+
+	if _, ok := r.webhooks[id]; !ok {
+		return webhook.ErrWebhookNotFound
+	}
+
+	delete(r.webhooks, id)
+
+	return nil
+}
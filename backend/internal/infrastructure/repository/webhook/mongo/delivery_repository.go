@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/mongo/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/mongo/model"
+	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ webhook.DeliveryRepository = (*DeliveryRepository)(nil)
+
+type DeliveryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewDeliveryRepository(cfg config.Config) *DeliveryRepository {
+	collection := mongodb.NewOrGetSingleton(cfg).Collection("webhook_deliveries")
+
+	return &DeliveryRepository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on.
+func (r *DeliveryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+
+	return err
+}
+
+func (r *DeliveryRepository) Save(ctx context.Context, d webhook.Delivery) error {
+	_, err := r.collection.InsertOne(ctx, converter.ToRepoFromDelivery(d))
+	if err != nil {
+		return webhook.ErrFailedToSaveDelivery
+	}
+
+	return nil
+}
+
+func (r *DeliveryRepository) Update(ctx context.Context, d webhook.Delivery) error {
+	mongoDelivery := converter.ToRepoFromDelivery(d)
+	filter := bson.M{"_id": mongoDelivery.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"status":          mongoDelivery.Status,
+			"attempts":        mongoDelivery.Attempts,
+			"next_attempt_at": mongoDelivery.NextAttemptAt,
+			"leased_until":    mongoDelivery.LeasedUntil,
+			"updated_at":      mongoDelivery.UpdatedAt,
+		},
+	}
+
+	result := r.collection.FindOneAndUpdate(ctx, filter, update)
+	if result.Err() != nil {
+		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+			return webhook.ErrDeliveryNotFound
+		}
+
+		return webhook.ErrFailedUpdateDelivery
+	}
+
+	return nil
+}
+
+func (r *DeliveryRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]webhook.Delivery, error) {
+	opts := options.Find().SetSort(bson.M{"next_attempt_at": 1}).SetLimit(int64(limit))
+
+	return r.find(ctx, dueFilter(now), opts)
+}
+
+// Claim sets the lease with a single update of a due delivery, so no two workers get it.
+func (r *DeliveryRepository) Claim(ctx context.Context, id uuid.UUID, now time.Time, until time.Time) (webhook.Delivery, error) {
+	filter := dueFilter(now)
+	filter["_id"] = id.String()
+	update := bson.M{"$set": bson.M{"leased_until": until}}
+
+	var d repoModel.Delivery
+
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&d)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return webhook.Delivery{}, webhook.ErrDeliveryClaimed
+		}
+
+		return webhook.Delivery{}, webhook.ErrFailedUpdateDelivery
+	}
+
+	return converter.ToDeliveryFromRepo(d), nil
+}
+
+func (r *DeliveryRepository) GetAllByWebhookID(ctx context.Context, webhookId uuid.UUID, limit int) ([]webhook.Delivery, error) {
+	filter := bson.M{"webhook_id": webhookId.String()}
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(int64(limit))
+
+	return r.find(ctx, filter, opts)
+}
+
+func (r *DeliveryRepository) DeleteByWebhookID(ctx context.Context, webhookId uuid.UUID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"webhook_id": webhookId.String()})
+
+	return err
+}
+
+// dueFilter matches the deliveries due at the time, see webhook.Delivery.IsDue. The deliveries saved
+// before the leases have none.
+func dueFilter(now time.Time) bson.M {
+	return bson.M{
+		"status":          string(webhook.StatusPending),
+		"next_attempt_at": bson.M{"$lte": now},
+		"leased_until":    bson.M{"$not": bson.M{"$gt": now}},
+	}
+}
+
+func (r *DeliveryRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]webhook.Delivery, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return []webhook.Delivery{}, err
+	}
+
+	var mongoDeliveries []repoModel.Delivery
+
+	err = cursor.All(ctx, &mongoDeliveries)
+	if err != nil {
+		return []webhook.Delivery{}, err
+	}
+
+	deliveries := make([]webhook.Delivery, 0, len(mongoDeliveries))
+	for _, mongoDelivery := range mongoDeliveries {
+		deliveries = append(deliveries, converter.ToDeliveryFromRepo(mongoDelivery))
+	}
+
+	return deliveries, nil
+}
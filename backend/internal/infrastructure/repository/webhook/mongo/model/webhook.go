@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+)
+
+type Webhook struct {
+	ID        string    `bson:"_id"`
+	UserID    string    `bson:"user_id"`
+	URL       string    `bson:"url"`
+	Events    []string  `bson:"events"`
+	Secret    string    `bson:"secret"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type Attempt struct {
+	At         time.Time `bson:"at"`
+	StatusCode int       `bson:"status_code"`
+	Error      string    `bson:"error,omitempty"`
+	// DurationMs is the duration of the attempt in milliseconds.
+	DurationMs int64 `bson:"duration_ms"`
+}
+
+type Delivery struct {
+	ID            string    `bson:"_id"`
+	WebhookID     string    `bson:"webhook_id"`
+	UserID        string    `bson:"user_id"`
+	EventID       string    `bson:"event_id"`
+	EventType     string    `bson:"event_type"`
+	Payload       []byte    `bson:"payload"`
+	Status        string    `bson:"status"`
+	Attempts      []Attempt `bson:"attempts"`
+	NextAttemptAt time.Time `bson:"next_attempt_at"`
+	LeasedUntil   time.Time `bson:"leased_until"`
+	CreatedAt     time.Time `bson:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at"`
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/mongo/converter"
+	repoModel "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/mongo/model"
+	"github.com/ozaitsev92/tododdd/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ webhook.Repository = (*Repository)(nil)
+
+type Repository struct {
+	collection *mongo.Collection
+}
+
+func NewRepository(cfg config.Config) *Repository {
+	collection := mongodb.NewOrGetSingleton(cfg).Collection("webhooks")
+
+	return &Repository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+
+	return err
+}
+
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (webhook.Webhook, error) {
+	var mongoWebhook repoModel.Webhook
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&mongoWebhook)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return webhook.Webhook{}, webhook.ErrWebhookNotFound
+		}
+
+		return webhook.Webhook{}, err
+	}
+
+	return converter.ToWebhookFromRepo(mongoWebhook), nil
+}
+
+func (r *Repository) GetAllByUserID(ctx context.Context, userId uuid.UUID) ([]webhook.Webhook, error) {
+	filter := bson.M{"user_id": userId.String()}
+	sort := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, sort)
+	if err != nil {
+		return []webhook.Webhook{}, err
+	}
+
+	var mongoWebhooks []repoModel.Webhook
+
+	err = cursor.All(ctx, &mongoWebhooks)
+	if err != nil {
+		return []webhook.Webhook{}, err
+	}
+
+	webhooks := make([]webhook.Webhook, 0, len(mongoWebhooks))
+	for _, mongoWebhook := range mongoWebhooks {
+		webhooks = append(webhooks, converter.ToWebhookFromRepo(mongoWebhook))
+	}
+
+	return webhooks, nil
+}
+
+func (r *Repository) Save(ctx context.Context, w webhook.Webhook) error {
+	_, err := r.collection.InsertOne(ctx, converter.ToRepoFromWebhook(w))
+	if err != nil {
+		return webhook.ErrFailedToSaveWebhook
+	}
+
+	return nil
+}
+
+func (r *Repository) Update(ctx context.Context, w webhook.Webhook) error {
+	mongoWebhook := converter.ToRepoFromWebhook(w)
+	filter := bson.M{"_id": mongoWebhook.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"url":        mongoWebhook.URL,
+			"events":     mongoWebhook.Events,
+			"updated_at": mongoWebhook.UpdatedAt,
+		},
+	}
+
+	result := r.collection.FindOneAndUpdate(ctx, filter, update)
+	if result.Err() != nil {
+		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+			return webhook.ErrWebhookNotFound
+		}
+
+		return webhook.ErrFailedUpdateWebhook
+	}
+
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.collection.FindOneAndDelete(ctx, bson.M{"_id": id.String()})
+	if result.Err() != nil {
+		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+			return webhook.ErrWebhookNotFound
+		}
+
+		return webhook.ErrFailedDeleteWebhook
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+	repository "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/mongo"
+)
+
+var (
+	MONGODB_PORT = ""
+)
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	resource, err := pool.Run("mongo", "latest", []string{})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+
+	err = pool.Retry(func() error {
+		MONGODB_PORT = resource.GetPort("27017/tcp")
+		_, err := net.Dial("tcp", net.JoinHostPort("localhost", MONGODB_PORT))
+		return err
+	})
+
+	if err != nil {
+		log.Fatalf("Could not connect to database: %s", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+func TestRepositoryWebhook(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	userId := uuid.New()
+
+	w, err := webhook.NewWebhook("https://example.com/hook", []task.EventType{task.EventCreated}, userId)
+	if err != nil {
+		t.Fatalf("NewWebhook() error = '%v'", err)
+	}
+
+	// Check if a webhook exists in the DB: should fail
+	r := repository.NewRepository(cfg)
+	_, err = r.GetByID(context.Background(), w.ID)
+	if !errors.Is(err, webhook.ErrWebhookNotFound) {
+		t.Errorf("GetByID() got = '%v', want = '%v'", err, webhook.ErrWebhookNotFound)
+	}
+
+	err = r.Save(context.Background(), w)
+	if err != nil {
+		t.Errorf("Save() err = '%v'", err)
+	}
+
+	other, err := webhook.NewWebhook("https://example.com/other", []task.EventType{task.EventDeleted}, uuid.New())
+	if err != nil {
+		t.Fatalf("NewWebhook() error = '%v'", err)
+	}
+
+	err = r.Save(context.Background(), other)
+	if err != nil {
+		t.Errorf("Save() err = '%v'", err)
+	}
+
+	err = w.Update("https://example.com/updated", []task.EventType{task.EventCompleted, task.EventDeleted})
+	if err != nil {
+		t.Fatalf("Update() error = '%v'", err)
+	}
+
+	err = r.Update(context.Background(), w)
+	if err != nil {
+		t.Errorf("Update() err = '%v'", err)
+	}
+
+	found, err := r.GetByID(context.Background(), w.ID)
+	if err != nil {
+		t.Errorf("GetByID() err = '%v', want = '%v'", err, nil)
+	}
+
+	if found.URL != w.URL || !reflect.DeepEqual(found.Events, w.Events) || found.Secret != w.Secret || found.UserID != userId {
+		t.Errorf("GetByID() got = '%v', want = '%v'", found, w)
+	}
+
+	webhooks, err := r.GetAllByUserID(context.Background(), userId)
+	if err != nil {
+		t.Errorf("GetAllByUserID() err = '%v'", err)
+	}
+
+	if len(webhooks) != 1 || webhooks[0].ID != w.ID {
+		t.Errorf("GetAllByUserID() got = '%v', want = '%v'", webhooks, []webhook.Webhook{w})
+	}
+
+	err = r.Delete(context.Background(), w.ID)
+	if err != nil {
+		t.Errorf("Delete() err = '%v'", err)
+	}
+
+	_, err = r.GetByID(context.Background(), w.ID)
+	if !errors.Is(err, webhook.ErrWebhookNotFound) {
+		t.Errorf("GetByID() got = '%v', want = '%v'", err, webhook.ErrWebhookNotFound)
+	}
+}
+
+func TestDeliveryRepository(t *testing.T) {
+	cfg := config.Config{}
+	cfg.MongoDBName = "todo_test"
+	cfg.MongoUrl = fmt.Sprintf("mongodb://localhost:%s", MONGODB_PORT)
+
+	userId := uuid.New()
+
+	w, err := webhook.NewWebhook("https://example.com/hook", []task.EventType{task.EventCreated}, userId)
+	if err != nil {
+		t.Fatalf("NewWebhook() error = '%v'", err)
+	}
+
+	ti, err := task.NewTask("test", userId)
+	if err != nil {
+		t.Fatalf("NewTask() error = '%v'", err)
+	}
+
+	r := repository.NewDeliveryRepository(cfg)
+
+	var deliveries []webhook.Delivery
+	for i := 0; i < 3; i++ {
+		d, err := webhook.NewDelivery(w, task.NewEvent(task.EventCreated, ti, userId))
+		if err != nil {
+			t.Fatalf("NewDelivery() error = '%v'", err)
+		}
+		d.CreatedAt = d.CreatedAt.Add(time.Duration(i) * time.Second)
+		d.NextAttemptAt = d.NextAttemptAt.Add(-time.Duration(i) * time.Minute)
+
+		err = r.Save(context.Background(), d)
+		if err != nil {
+			t.Errorf("Save() err = '%v'", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	// The first delivery is retried later
+	b := webhook.Backoff{Base: time.Hour, Max: time.Hour, MaxAttempts: 5}
+	err = deliveries[0].RecordAttempt(webhook.Attempt{At: time.Now(), StatusCode: 500, Duration: 250 * time.Millisecond}, b)
+	if err != nil {
+		t.Fatalf("RecordAttempt() error = '%v'", err)
+	}
+
+	err = r.Update(context.Background(), deliveries[0])
+	if err != nil {
+		t.Errorf("Update() err = '%v'", err)
+	}
+
+	due, err := r.GetDue(context.Background(), time.Now(), 10)
+	if err != nil {
+		t.Errorf("GetDue() err = '%v'", err)
+	}
+
+	if len(due) != 2 || due[0].ID != deliveries[2].ID || due[1].ID != deliveries[1].ID {
+		t.Errorf("GetDue() got = '%v', want deliveries 2 and 1", due)
+	}
+
+	if len(due) > 0 && !bytes.Equal(due[0].Payload, deliveries[2].Payload) {
+		t.Errorf("GetDue() Payload = '%s', want = '%s'", due[0].Payload, deliveries[2].Payload)
+	}
+
+	due, err = r.GetDue(context.Background(), time.Now(), 1)
+	if err != nil || len(due) != 1 {
+		t.Errorf("GetDue() got = '%v', '%v', want 1 delivery", due, err)
+	}
+
+	// A claimed delivery is not due until the lease ends
+	now := time.Now()
+	claimed, err := r.Claim(context.Background(), deliveries[2].ID, now, now.Add(time.Minute))
+	if err != nil || claimed.LeasedUntil.Sub(now.Add(time.Minute)).Abs() > time.Millisecond {
+		t.Errorf("Claim() got = '%v', '%v', want it leased for a minute", claimed.LeasedUntil, err)
+	}
+
+	_, err = r.Claim(context.Background(), deliveries[2].ID, now, now.Add(time.Minute))
+	if !errors.Is(err, webhook.ErrDeliveryClaimed) {
+		t.Errorf("Claim() err = '%v', want = '%v'", err, webhook.ErrDeliveryClaimed)
+	}
+
+	due, err = r.GetDue(context.Background(), now, 10)
+	if err != nil || len(due) != 1 || due[0].ID != deliveries[1].ID {
+		t.Errorf("GetDue() got = '%v', '%v', want delivery 1", due, err)
+	}
+
+	_, err = r.Claim(context.Background(), deliveries[2].ID, now.Add(2*time.Minute), now.Add(3*time.Minute))
+	if err != nil {
+		t.Errorf("Claim() after the lease err = '%v'", err)
+	}
+
+	all, err := r.GetAllByWebhookID(context.Background(), w.ID, 10)
+	if err != nil {
+		t.Errorf("GetAllByWebhookID() err = '%v'", err)
+	}
+
+	if len(all) != 3 || all[0].ID != deliveries[2].ID || all[2].ID != deliveries[0].ID {
+		t.Errorf("GetAllByWebhookID() got = '%v', want the latest first", all)
+	}
+
+	if len(all) == 3 && (len(all[2].Attempts) != 1 || all[2].Attempts[0].StatusCode != 500 || all[2].Attempts[0].Duration != 250*time.Millisecond) {
+		t.Errorf("GetAllByWebhookID() Attempts = '%v'", all[2].Attempts)
+	}
+
+	err = r.DeleteByWebhookID(context.Background(), w.ID)
+	if err != nil {
+		t.Errorf("DeleteByWebhookID() err = '%v'", err)
+	}
+
+	all, err = r.GetAllByWebhookID(context.Background(), w.ID, 10)
+	if err != nil || len(all) != 0 {
+		t.Errorf("GetAllByWebhookID() got = '%v', '%v', want none", all, err)
+	}
+}
@@ -0,0 +1,148 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+const (
+	_defaultTimeout = 10 * time.Second
+	// maxResponseSize is how much of a response body is read so the connection can be reused.
+	maxResponseSize = 64 << 10
+	userAgent       = "tododdd-webhooks"
+)
+
+var (
+	ErrForbiddenDestination = errors.New("webhook destination is not a public address")
+)
+
+// nonPublicNetworks are the special-purpose networks not covered by the net/netip predicates.
+var nonPublicNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+var _ usecase.WebhookSender = (*HTTPSender)(nil)
+
+// HTTPSender posts webhook deliveries over HTTP. Redirects are not followed. Only public addresses are
+// connected to, besides the allowed networks, so the webhooks cannot reach the internal services. The address
+// is checked once resolved, so a host name resolving to another address later does not get around it.
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(cfg config.Config) (*HTTPSender, error) {
+	timeout := _defaultTimeout
+	if cfg.WebhookTimeout > 0 {
+		timeout = time.Duration(cfg.WebhookTimeout) * time.Second
+	}
+
+	allowed := make([]netip.Prefix, 0, len(cfg.WebhookAllowedNetworks))
+	for _, network := range cfg.WebhookAllowedNetworks {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, fmt.Errorf("webhook allowed network: %w", err)
+		}
+		allowed = append(allowed, prefix.Masked())
+	}
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			return checkDestination(address, allowed)
+		},
+	}
+
+	// No proxy, the address of the receiver itself is checked
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &HTTPSender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// Timeout returns how long an attempt may take at most.
+func (s *HTTPSender) Timeout() time.Duration {
+	return s.client.Timeout
+}
+
+func (s *HTTPSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	return resp.StatusCode, nil
+}
+
+// checkDestination returns ErrForbiddenDestination if the resolved address is not public and not in the allowed networks.
+func checkDestination(address string, allowed []netip.Prefix) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return ErrForbiddenDestination
+	}
+	ip := addrPort.Addr().Unmap()
+
+	for _, prefix := range allowed {
+		if prefix.Contains(ip) {
+			return nil
+		}
+	}
+
+	if !isPublic(ip) {
+		return ErrForbiddenDestination
+	}
+
+	return nil
+}
+
+// isPublic returns true if the address is a global unicast one outside the private and special-purpose networks.
+func isPublic(ip netip.Addr) bool {
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range nonPublicNetworks {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
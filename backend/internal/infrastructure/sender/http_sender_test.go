@@ -0,0 +1,58 @@
+package sender_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/sender"
+)
+
+func TestHTTPSenderDestination(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	s, err := sender.NewHTTPSender(config.Config{})
+	if err != nil {
+		t.Fatalf("NewHTTPSender() error = %v", err)
+	}
+
+	// Not public
+	for _, url := range []string{server.URL, "http://169.254.169.254/latest/meta-data", "http://10.0.0.1:27017", "http://[::1]:8080"} {
+		_, err := s.Send(context.Background(), url, nil, nil)
+		if !errors.Is(err, sender.ErrForbiddenDestination) {
+			t.Errorf("Send(%v) error = %v, wantErr %v", url, err, sender.ErrForbiddenDestination)
+		}
+	}
+
+	if requests != 0 {
+		t.Errorf("server got %v requests, want none", requests)
+	}
+
+	// Allowed
+	s, err = sender.NewHTTPSender(config.Config{WebhookAllowedNetworks: []string{"127.0.0.1/32"}})
+	if err != nil {
+		t.Fatalf("NewHTTPSender() error = %v", err)
+	}
+
+	status, err := s.Send(context.Background(), server.URL, nil, nil)
+	if err != nil || status != http.StatusNoContent {
+		t.Errorf("Send() got = %v, %v, want %v", status, err, http.StatusNoContent)
+	}
+
+	_, err = s.Send(context.Background(), "http://169.254.169.254/latest/meta-data", nil, nil)
+	if !errors.Is(err, sender.ErrForbiddenDestination) {
+		t.Errorf("Send() error = %v outside the allowed networks, wantErr %v", err, sender.ErrForbiddenDestination)
+	}
+
+	if _, err := sender.NewHTTPSender(config.Config{WebhookAllowedNetworks: []string{"localhost"}}); err == nil {
+		t.Error("NewHTTPSender() error = nil for an invalid network")
+	}
+}
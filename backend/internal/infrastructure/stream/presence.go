@@ -0,0 +1,161 @@
+package stream
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/pkg/pubsub"
+)
+
+// PresenceState is what a member connected to a list is doing.
+type PresenceState string
+
+const (
+	PresenceViewing PresenceState = "viewing"
+	PresenceEditing PresenceState = "editing"
+)
+
+var (
+	ErrInvalidPresence = errors.New("presence is invalid")
+)
+
+// Presence is a connection of a member to the collaboration channel of a list.
+type Presence struct {
+	SessionID uuid.UUID
+	UserID    uuid.UUID
+	// TaskID is the task the member is viewing or editing, nil for the list itself.
+	TaskID *uuid.UUID
+	State  PresenceState
+	// Since is when the state was set.
+	Since time.Time
+}
+
+// PresenceHub keeps track of the members connected to each list and sends the members
+// of a list the whole presence of the list on every change.
+type PresenceHub struct {
+	mu    sync.Mutex
+	lists map[uuid.UUID]map[uuid.UUID]Presence
+	hub   *pubsub.Hub[[]Presence]
+}
+
+func NewPresenceHub() *PresenceHub {
+	return &PresenceHub{
+		lists: make(map[uuid.UUID]map[uuid.UUID]Presence),
+		hub:   pubsub.New[[]Presence](pubsub.ReplaySize(0)),
+	}
+}
+
+// Subscribe subscribes to the presence changes of the list.
+func (h *PresenceHub) Subscribe(listId uuid.UUID) (*pubsub.Subscription[[]Presence], error) {
+	sub, _, err := h.hub.Subscribe(listId.String(), 0, false)
+
+	return sub, err
+}
+
+// Join adds a connection of the user viewing the list and returns its session ID.
+func (h *PresenceHub) Join(listId uuid.UUID, userId uuid.UUID) uuid.UUID {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sessions, ok := h.lists[listId]
+	if !ok {
+		sessions = make(map[uuid.UUID]Presence)
+		h.lists[listId] = sessions
+	}
+
+	p := Presence{
+		SessionID: uuid.New(),
+		UserID:    userId,
+		State:     PresenceViewing,
+		Since:     time.Now(),
+	}
+	sessions[p.SessionID] = p
+
+	h.publish(listId)
+
+	return p.SessionID
+}
+
+// Set changes what the connection is doing.
+func (h *PresenceHub) Set(listId uuid.UUID, sessionId uuid.UUID, taskId *uuid.UUID, state PresenceState) error {
+	if state != PresenceViewing && state != PresenceEditing {
+		return ErrInvalidPresence
+	}
+
+	if state == PresenceEditing && taskId == nil {
+		return ErrInvalidPresence
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	p, ok := h.lists[listId][sessionId]
+	if !ok {
+		return ErrInvalidPresence
+	}
+
+	p.TaskID = taskId
+	p.State = state
+	p.Since = time.Now()
+	h.lists[listId][sessionId] = p
+
+	h.publish(listId)
+
+	return nil
+}
+
+// Leave removes the connection.
+func (h *PresenceHub) Leave(listId uuid.UUID, sessionId uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sessions, ok := h.lists[listId]
+	if !ok {
+		return
+	}
+
+	delete(sessions, sessionId)
+	if len(sessions) == 0 {
+		delete(h.lists, listId)
+	}
+
+	h.publish(listId)
+}
+
+// Members returns the connections to the list ordered by user.
+func (h *PresenceHub) Members(listId uuid.UUID) []Presence {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.members(listId)
+}
+
+// Close ends all the subscriptions.
+func (h *PresenceHub) Close() {
+	h.hub.Close()
+}
+
+func (h *PresenceHub) publish(listId uuid.UUID) {
+	h.hub.Publish(listId.String(), h.members(listId))
+}
+
+func (h *PresenceHub) members(listId uuid.UUID) []Presence {
+	members := make([]Presence, 0, len(h.lists[listId]))
+	for _, p := range h.lists[listId] {
+		members = append(members, p)
+	}
+
+	slices.SortFunc(members, func(a, b Presence) int {
+		if c := strings.Compare(a.UserID.String(), b.UserID.String()); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.SessionID.String(), b.SessionID.String())
+	})
+
+	return members
+}
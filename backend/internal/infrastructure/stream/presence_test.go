@@ -0,0 +1,75 @@
+package stream_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/stream"
+)
+
+func TestPresenceHub(t *testing.T) {
+	h := stream.NewPresenceHub()
+	defer h.Close()
+
+	listId := uuid.New()
+	userId := uuid.New()
+
+	sub, err := h.Subscribe(listId)
+	if err != nil {
+		t.Fatalf("h.Subscribe() error = %v", err)
+	}
+	defer sub.Close()
+
+	receive := func() []stream.Presence {
+		select {
+		case m := <-sub.C:
+			return m.Data
+		case <-time.After(time.Second):
+			t.Fatal("sub.C got nothing")
+		}
+
+		return nil
+	}
+
+	sessionId := h.Join(listId, userId)
+	if got := receive(); len(got) != 1 || got[0].SessionID != sessionId || got[0].State != stream.PresenceViewing || got[0].TaskID != nil {
+		t.Errorf("h.Join() published %v", got)
+	}
+
+	// Other lists are not affected
+	other := h.Join(uuid.New(), userId)
+	if got := h.Members(listId); len(got) != 1 {
+		t.Errorf("h.Members() got = %v, want 1 member", got)
+	}
+
+	taskId := uuid.New()
+	if err := h.Set(listId, sessionId, &taskId, stream.PresenceEditing); err != nil {
+		t.Fatalf("h.Set() error = %v", err)
+	}
+	if got := receive(); len(got) != 1 || got[0].State != stream.PresenceEditing || *got[0].TaskID != taskId {
+		t.Errorf("h.Set() published %v", got)
+	}
+
+	tests := []struct {
+		name      string
+		sessionId uuid.UUID
+		taskId    *uuid.UUID
+		state     stream.PresenceState
+	}{
+		{name: "Unknown state", sessionId: sessionId, taskId: &taskId, state: "typing"},
+		{name: "Editing nothing", sessionId: sessionId, taskId: nil, state: stream.PresenceEditing},
+		{name: "Session of another list", sessionId: other, taskId: nil, state: stream.PresenceViewing},
+	}
+	for _, tt := range tests {
+		if err := h.Set(listId, tt.sessionId, tt.taskId, tt.state); !errors.Is(err, stream.ErrInvalidPresence) {
+			t.Errorf("%s: h.Set() error = %v, wantErr %v", tt.name, err, stream.ErrInvalidPresence)
+		}
+	}
+
+	h.Leave(listId, sessionId)
+	if got := receive(); len(got) != 0 {
+		t.Errorf("h.Leave() published %v", got)
+	}
+}
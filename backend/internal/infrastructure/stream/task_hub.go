@@ -0,0 +1,65 @@
+package stream
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+	"github.com/ozaitsev92/tododdd/pkg/pubsub"
+)
+
+var _ usecase.TaskEventPublisher = (*TaskHub)(nil)
+
+// TaskHub delivers the task events to the streams of the task authors and,
+// for the tasks of a list, to the collaboration channels of the list.
+type TaskHub struct {
+	hub *pubsub.Hub[task.Event]
+}
+
+func NewTaskHub(cfg config.Config) *TaskHub {
+	var opts []pubsub.Option
+	if cfg.StreamReplaySize > 0 {
+		opts = append(opts, pubsub.ReplaySize(cfg.StreamReplaySize))
+	}
+	if cfg.StreamReplayWindow > 0 {
+		opts = append(opts, pubsub.ReplayWindow(time.Duration(cfg.StreamReplayWindow)*time.Second))
+	}
+
+	return &TaskHub{
+		hub: pubsub.New[task.Event](opts...),
+	}
+}
+
+func (h *TaskHub) Publish(_ context.Context, e task.Event) error {
+	h.hub.Publish(e.Task.UserID.String(), e)
+	if e.Task.ListID != nil {
+		h.hub.Publish(listTopic(*e.Task.ListID), e)
+	}
+
+	return nil
+}
+
+// Subscribe subscribes to the events of the user's tasks. With resume it also returns the kept events
+// published after lastEventId.
+func (h *TaskHub) Subscribe(userId uuid.UUID, lastEventId uint64, resume bool) (*pubsub.Subscription[task.Event], []pubsub.Message[task.Event], error) {
+	return h.hub.Subscribe(userId.String(), lastEventId, resume)
+}
+
+// SubscribeList subscribes to the events of the tasks of the list.
+func (h *TaskHub) SubscribeList(listId uuid.UUID) (*pubsub.Subscription[task.Event], error) {
+	sub, _, err := h.hub.Subscribe(listTopic(listId), 0, false)
+
+	return sub, err
+}
+
+// Close ends all the streams.
+func (h *TaskHub) Close() {
+	h.hub.Close()
+}
+
+func listTopic(listId uuid.UUID) string {
+	return "list:" + listId.String()
+}
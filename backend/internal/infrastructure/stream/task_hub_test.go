@@ -0,0 +1,179 @@
+package stream_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/stream"
+)
+
+func newEvent(t *testing.T, userId uuid.UUID) task.Event {
+	ti, err := task.NewTask("test", userId)
+	if err != nil {
+		t.Fatalf("task.NewTask() error = %v", err)
+	}
+
+	return task.NewEvent(task.EventCreated, ti, userId)
+}
+
+func TestTaskHubPublish(t *testing.T) {
+	h := stream.NewTaskHub(config.Config{})
+	defer h.Close()
+
+	userId := uuid.New()
+
+	sub, missed, err := h.Subscribe(userId, 0, false)
+	if err != nil {
+		t.Fatalf("h.Subscribe() error = %v", err)
+	}
+	defer sub.Close()
+
+	if len(missed) != 0 {
+		t.Errorf("h.Subscribe() missed = %v, want none", missed)
+	}
+
+	// Events of other users are not delivered
+	h.Publish(context.Background(), newEvent(t, uuid.New()))
+	e := newEvent(t, userId)
+	h.Publish(context.Background(), e)
+
+	select {
+	case m := <-sub.C:
+		if m.Data.ID != e.ID {
+			t.Errorf("sub.C got = %v, want %v", m.Data.ID, e.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("sub.C got nothing")
+	}
+}
+
+func TestTaskHubResume(t *testing.T) {
+	h := stream.NewTaskHub(config.Config{StreamReplaySize: 2})
+	defer h.Close()
+
+	userId := uuid.New()
+
+	var events []task.Event
+	for i := 0; i < 3; i++ {
+		e := newEvent(t, userId)
+		events = append(events, e)
+		h.Publish(context.Background(), e)
+	}
+
+	sub, missed, err := h.Subscribe(userId, 0, true)
+	if err != nil {
+		t.Fatalf("h.Subscribe() error = %v", err)
+	}
+	defer sub.Close()
+
+	// Only the kept events are replayed
+	if len(missed) != 2 || missed[0].Data.ID != events[1].ID || missed[1].Data.ID != events[2].ID {
+		t.Fatalf("h.Subscribe() missed = %v, want the last 2 events", missed)
+	}
+
+	sub2, missed, err := h.Subscribe(userId, missed[0].ID, true)
+	if err != nil {
+		t.Fatalf("h.Subscribe() error = %v", err)
+	}
+	defer sub2.Close()
+
+	if len(missed) != 1 || missed[0].Data.ID != events[2].ID {
+		t.Errorf("h.Subscribe() missed = %v, want the last event", missed)
+	}
+}
+
+func TestTaskHubClose(t *testing.T) {
+	h := stream.NewTaskHub(config.Config{})
+	userId := uuid.New()
+
+	sub, _, err := h.Subscribe(userId, 0, false)
+	if err != nil {
+		t.Fatalf("h.Subscribe() error = %v", err)
+	}
+
+	h.Close()
+
+	if _, ok := <-sub.C; ok {
+		t.Error("sub.C is open after h.Close()")
+	}
+
+	// Closing the subscription after the hub is safe
+	sub.Close()
+
+	if _, _, err := h.Subscribe(userId, 0, false); err == nil {
+		t.Error("h.Subscribe() after h.Close() error = nil")
+	}
+}
+
+func TestTaskHubDropsSlowSubscribers(t *testing.T) {
+	h := stream.NewTaskHub(config.Config{})
+	defer h.Close()
+
+	userId := uuid.New()
+
+	sub, _, err := h.Subscribe(userId, 0, false)
+	if err != nil {
+		t.Fatalf("h.Subscribe() error = %v", err)
+	}
+	defer sub.Close()
+
+	// Publish more events than the subscriber buffers without reading them
+	for i := 0; i < 100; i++ {
+		h.Publish(context.Background(), newEvent(t, userId))
+	}
+
+	n := 0
+	for range sub.C {
+		n++
+	}
+
+	if n == 0 || n >= 100 {
+		t.Errorf("received %v events before being dropped", n)
+	}
+}
+
+func TestTaskHubReplayWindow(t *testing.T) {
+	h := stream.NewTaskHub(config.Config{StreamReplayWindow: 1})
+	defer h.Close()
+
+	idleId := uuid.New()
+	activeId := uuid.New()
+
+	sub, _, err := h.Subscribe(activeId, 0, false)
+	if err != nil {
+		t.Fatalf("h.Subscribe() error = %v", err)
+	}
+	defer sub.Close()
+
+	h.Publish(context.Background(), newEvent(t, idleId))
+	e := newEvent(t, activeId)
+	h.Publish(context.Background(), e)
+
+	time.Sleep(1100 * time.Millisecond)
+
+	// The events of a user without streams are gone once the window passes
+	idle, missed, err := h.Subscribe(idleId, 0, true)
+	if err != nil {
+		t.Fatalf("h.Subscribe() error = %v", err)
+	}
+	defer idle.Close()
+
+	if len(missed) != 0 {
+		t.Errorf("h.Subscribe() missed = %v, want none after the replay window", missed)
+	}
+
+	// The events of a user with a stream are kept
+	active, missed, err := h.Subscribe(activeId, 0, true)
+	if err != nil {
+		t.Fatalf("h.Subscribe() error = %v", err)
+	}
+	defer active.Close()
+
+	if len(missed) != 1 || missed[0].Data.ID != e.ID {
+		t.Errorf("h.Subscribe() missed = %v, want the event", missed)
+	}
+}
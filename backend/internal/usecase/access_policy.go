@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+// Action is what a user wants to do with a task or a list.
+type Action int
+
+const (
+	// ActionView reads a task or a list.
+	ActionView Action = iota
+	// ActionEdit changes a task or adds one to a list.
+	ActionEdit
+	// ActionManage changes a list itself or its members.
+	ActionManage
+)
+
+// AccessPolicy decides whether a user may perform an action on a task or a list.
+// A personal task is accessible only to its author, a task of a shared list
+// to the list members according to their roles.
+type AccessPolicy struct {
+	listRepository list.Repository
+}
+
+// NewAccessPolicy creates an new instance of the AccessPolicy.
+func NewAccessPolicy(listRepository list.Repository) *AccessPolicy {
+	return &AccessPolicy{
+		listRepository: listRepository,
+	}
+}
+
+// AuthorizeTask returns ErrUnauthorizedAction if the user may not perform the action on the task.
+func (p *AccessPolicy) AuthorizeTask(ctx context.Context, t task.Task, userId uuid.UUID, action Action) error {
+	if t.ListID == nil {
+		if t.UserID != userId {
+			return ErrUnauthorizedAction
+		}
+
+		return nil
+	}
+
+	return p.AuthorizeListID(ctx, *t.ListID, userId, action)
+}
+
+// AuthorizeListID loads the list and returns ErrUnauthorizedAction if the user may not perform the action on it.
+// A list that does not exist is reported as unauthorized so its existence is not disclosed.
+func (p *AccessPolicy) AuthorizeListID(ctx context.Context, listId uuid.UUID, userId uuid.UUID, action Action) error {
+	l, err := p.listRepository.GetByID(ctx, listId)
+	if err != nil {
+		if errors.Is(err, list.ErrListNotFound) {
+			return ErrUnauthorizedAction
+		}
+
+		return err
+	}
+
+	return p.AuthorizeList(l, userId, action)
+}
+
+// AuthorizeList returns ErrUnauthorizedAction if the user may not perform the action on the list.
+func (p *AccessPolicy) AuthorizeList(l list.List, userId uuid.UUID, action Action) error {
+	role, ok := l.RoleOf(userId)
+	if !ok {
+		return ErrUnauthorizedAction
+	}
+
+	var allowed bool
+	switch action {
+	case ActionView:
+		allowed = role.CanView()
+	case ActionEdit:
+		allowed = role.CanEdit()
+	case ActionManage:
+		allowed = role.CanManage()
+	}
+
+	if !allowed {
+		return ErrUnauthorizedAction
+	}
+
+	return nil
+}
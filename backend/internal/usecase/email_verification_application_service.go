@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/user"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("the verification link is invalid or has expired")
+	ErrVerificationThrottled    = errors.New("a verification email has been sent recently")
+)
+
+type EmailVerificationUseCase struct {
+	userRepository user.Repository
+	mailer         Mailer
+	signingKey     []byte
+	tokenTTL       time.Duration
+	resendInterval time.Duration
+	verifyURL      string
+}
+
+// NewEmailVerificationUseCase creates an new instance of the EmailVerificationUseCase. The mailed links lead
+// to verifyURL with a token signed with signingKey in the token query parameter and can be used within tokenTTL.
+// Another verification email is sent to a user on request no sooner than resendInterval after the last one.
+func NewEmailVerificationUseCase(userRepository user.Repository, mailer Mailer, signingKey []byte, tokenTTL time.Duration, resendInterval time.Duration, verifyURL string) *EmailVerificationUseCase {
+	return &EmailVerificationUseCase{
+		userRepository: userRepository,
+		mailer:         mailer,
+		signingKey:     signingKey,
+		tokenTTL:       tokenTTL,
+		resendInterval: resendInterval,
+		verifyURL:      verifyURL,
+	}
+}
+
+// SendVerification mails a verification link to the user unless their email is verified already.
+func (s *EmailVerificationUseCase) SendVerification(ctx context.Context, u user.User) error {
+	if u.IsVerified() {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(s.tokenTTL)
+	link := s.verifyURL + "?" + url.Values{"token": {s.token(u.ID, u.Email, expiresAt.Unix())}}.Encode()
+
+	err := s.mailer.Send(ctx, Mail{
+		To:      u.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Welcome! Open the link below to verify your email. It can be used until %s.\n\n%s\n\n"+
+				"If you have not signed up with this email, ignore this email.\n",
+			expiresAt.UTC().Format(time.RFC1123),
+			link,
+		),
+	})
+	if err != nil {
+		return err
+	}
+
+	u.MarkVerificationSent()
+
+	return s.userRepository.Update(ctx, u)
+}
+
+// ResendVerification mails a new verification link to the unverified user registered with the email.
+// Nothing is done for an email no user is registered with or which is verified already, and no error tells it apart.
+// If the last link has been sent to the user within the resend interval, ErrVerificationThrottled is returned
+// together with how long to wait before requesting another one.
+func (s *EmailVerificationUseCase) ResendVerification(ctx context.Context, email string) (time.Duration, error) {
+	u, err := s.userRepository.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	if u.IsVerified() {
+		return 0, nil
+	}
+
+	if wait := u.VerificationResendWait(s.resendInterval); wait > 0 {
+		return wait, ErrVerificationThrottled
+	}
+
+	return 0, s.SendVerification(ctx, u)
+}
+
+// VerifyEmail marks the email of the user the token has been issued to as verified. A token is bound
+// to the email it has been sent to, changing the email invalidates it.
+func (s *EmailVerificationUseCase) VerifyEmail(ctx context.Context, raw string) (user.User, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return user.User{}, ErrInvalidVerificationToken
+	}
+
+	userId, err := uuid.Parse(parts[0])
+	if err != nil {
+		return user.User{}, ErrInvalidVerificationToken
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return user.User{}, ErrInvalidVerificationToken
+	}
+
+	u, err := s.userRepository.GetByID(ctx, userId)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return user.User{}, ErrInvalidVerificationToken
+		}
+
+		return user.User{}, err
+	}
+
+	if !hmac.Equal([]byte(raw), []byte(s.token(u.ID, u.Email, expiresAt))) {
+		return user.User{}, ErrInvalidVerificationToken
+	}
+
+	if u.IsVerified() {
+		return u, nil
+	}
+
+	u.Verify()
+
+	err = s.userRepository.Update(ctx, u)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	return u, nil
+}
+
+// token returns the verification token of the email of the user, which is the user id and the expiry time
+// followed by their signature together with the email.
+func (s *EmailVerificationUseCase) token(userId uuid.UUID, email string, expiresAt int64) string {
+	payload := userId.String() + "." + strconv.FormatInt(expiresAt, 10)
+
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(payload + "." + email))
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
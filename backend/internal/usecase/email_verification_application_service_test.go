@@ -0,0 +1,156 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/user"
+	repo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/user/memory"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+func TestEmailVerificationUseCase(t *testing.T) {
+	ur := repo.NewRepository(config.Config{})
+	mails := &mailbox{}
+
+	s := usecase.NewEmailVerificationUseCase(ur, mails, []byte("test-key"), time.Hour, time.Hour, "http://localhost/verify-email")
+
+	u, err := user.NewUser("test@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("user.NewUser() error = %v", err)
+	}
+	err = ur.Save(context.Background(), u)
+	if err != nil {
+		t.Fatalf("ur.Save() error = %v", err)
+	}
+
+	err = s.SendVerification(context.Background(), u)
+	if err != nil {
+		t.Fatalf("SendVerification() error = %v", err)
+	}
+
+	received := mails.received()
+	if len(received) != 1 || received[0].To != "test@example.com" {
+		t.Fatalf("SendVerification() mails = %v, want one to test@example.com", received)
+	}
+	link := linkIn(t, received[0])
+	if link.Path != "/verify-email" {
+		t.Errorf("SendVerification() link = %v, want one to /verify-email", link)
+	}
+	token := link.Query().Get("token")
+
+	// Unknown emails are not told apart
+	wait, err := s.ResendVerification(context.Background(), "unknown@example.com")
+	if err != nil || wait != 0 {
+		t.Errorf("ResendVerification() = %v, %v for an unknown email, want 0, nil", wait, err)
+	}
+
+	// Another link is not sent right away
+	wait, err = s.ResendVerification(context.Background(), "test@example.com")
+	if !errors.Is(err, usecase.ErrVerificationThrottled) {
+		t.Errorf("ResendVerification() error = %v, wantErr %v", err, usecase.ErrVerificationThrottled)
+	}
+	if wait <= 0 || wait > time.Hour {
+		t.Errorf("ResendVerification() wait = %v, want up to %v", wait, time.Hour)
+	}
+	if len(mails.received()) != 1 {
+		t.Errorf("ResendVerification() sent a mail while throttled")
+	}
+
+	// Tampered tokens are rejected
+	for _, raw := range []string{"", "invalid", token + "x", u.ID.String() + ".9999999999." + token[len(token)-43:]} {
+		_, err = s.VerifyEmail(context.Background(), raw)
+		if !errors.Is(err, usecase.ErrInvalidVerificationToken) {
+			t.Errorf("VerifyEmail(%q) error = %v, wantErr %v", raw, err, usecase.ErrInvalidVerificationToken)
+		}
+	}
+
+	verified, err := s.VerifyEmail(context.Background(), token)
+	if err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if !verified.IsVerified() {
+		t.Error("VerifyEmail() user is not verified")
+	}
+
+	found, err := ur.GetByID(context.Background(), u.ID)
+	if err != nil {
+		t.Fatalf("ur.GetByID() error = %v", err)
+	}
+	if !found.IsVerified() {
+		t.Error("VerifyEmail() the verification is not saved")
+	}
+
+	// The link can be opened again
+	_, err = s.VerifyEmail(context.Background(), token)
+	if err != nil {
+		t.Errorf("VerifyEmail() error = %v for a verified user", err)
+	}
+
+	// Verified users are not sent another link
+	_, err = s.ResendVerification(context.Background(), "test@example.com")
+	if err != nil {
+		t.Errorf("ResendVerification() error = %v for a verified user", err)
+	}
+	if len(mails.received()) != 1 {
+		t.Errorf("ResendVerification() sent a mail to a verified user")
+	}
+
+	// A new email invalidates the links sent to the previous one
+	err = found.SetEmail("new@example.com")
+	if err != nil {
+		t.Fatalf("SetEmail() error = %v", err)
+	}
+	err = ur.Update(context.Background(), found)
+	if err != nil {
+		t.Fatalf("ur.Update() error = %v", err)
+	}
+
+	_, err = s.VerifyEmail(context.Background(), token)
+	if !errors.Is(err, usecase.ErrInvalidVerificationToken) {
+		t.Errorf("VerifyEmail() error = %v for the previous email, wantErr %v", err, usecase.ErrInvalidVerificationToken)
+	}
+
+	_, err = s.ResendVerification(context.Background(), "new@example.com")
+	if err != nil {
+		t.Fatalf("ResendVerification() error = %v", err)
+	}
+	received = mails.received()
+	if len(received) != 2 || received[1].To != "new@example.com" {
+		t.Fatalf("ResendVerification() mails = %v, want another one to new@example.com", received)
+	}
+
+	_, err = s.VerifyEmail(context.Background(), linkIn(t, received[1]).Query().Get("token"))
+	if err != nil {
+		t.Errorf("VerifyEmail() error = %v", err)
+	}
+}
+
+func TestEmailVerificationUseCaseExpiredToken(t *testing.T) {
+	ur := repo.NewRepository(config.Config{})
+	mails := &mailbox{}
+
+	s := usecase.NewEmailVerificationUseCase(ur, mails, []byte("test-key"), -time.Second, 0, "http://localhost/verify-email")
+
+	u, err := user.NewUser("test@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("user.NewUser() error = %v", err)
+	}
+	err = ur.Save(context.Background(), u)
+	if err != nil {
+		t.Fatalf("ur.Save() error = %v", err)
+	}
+
+	_, err = s.ResendVerification(context.Background(), "test@example.com")
+	if err != nil {
+		t.Fatalf("ResendVerification() error = %v", err)
+	}
+
+	_, err = s.VerifyEmail(context.Background(), linkIn(t, mails.received()[0]).Query().Get("token"))
+	if !errors.Is(err, usecase.ErrInvalidVerificationToken) {
+		t.Errorf("VerifyEmail() error = %v, wantErr %v", err, usecase.ErrInvalidVerificationToken)
+	}
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+var (
+	ErrInvalidFeedToken = errors.New("invalid feed token")
+)
+
+type FeedUseCase struct {
+	feedRepository feed.Repository
+	taskRepository task.Repository
+}
+
+// NewFeedUseCase creates an new instance of the FeedUseCase.
+func NewFeedUseCase(feedRepository feed.Repository, taskRepository task.Repository) *FeedUseCase {
+	return &FeedUseCase{
+		feedRepository: feedRepository,
+		taskRepository: taskRepository,
+	}
+}
+
+// CreateFeed creates a new calendar feed of the user and returns its raw token.
+// The token of the feed it replaces, if any, stops working.
+func (s *FeedUseCase) CreateFeed(ctx context.Context, userId uuid.UUID) (string, feed.Feed, error) {
+	f, raw, err := feed.NewFeed(userId)
+	if err != nil {
+		return "", feed.Feed{}, err
+	}
+
+	err = s.feedRepository.Save(ctx, f)
+	if err != nil {
+		return "", feed.Feed{}, err
+	}
+
+	return raw, f, nil
+}
+
+// GetFeed returns the calendar feed of the user.
+func (s *FeedUseCase) GetFeed(ctx context.Context, userId uuid.UUID) (feed.Feed, error) {
+	return s.feedRepository.GetByUserID(ctx, userId)
+}
+
+// RevokeFeed deletes the calendar feed of the user, its token stops working.
+func (s *FeedUseCase) RevokeFeed(ctx context.Context, userId uuid.UUID) error {
+	return s.feedRepository.DeleteByUserID(ctx, userId)
+}
+
+// GetFeedTasks returns the tasks with a due date of the user whose feed has the raw token, and the last
+// time a task of the user has changed. Tasks in the trash count for the latter since they have left the feed.
+func (s *FeedUseCase) GetFeedTasks(ctx context.Context, raw string) ([]task.Task, time.Time, error) {
+	f, err := s.feedRepository.GetByHash(ctx, feed.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, feed.ErrFeedNotFound) {
+			return []task.Task{}, time.Time{}, ErrInvalidFeedToken
+		}
+
+		return []task.Task{}, time.Time{}, err
+	}
+
+	tasks, err := s.taskRepository.GetAllByUserID(ctx, f.UserID)
+	if err != nil {
+		return []task.Task{}, time.Time{}, err
+	}
+
+	trash, err := s.taskRepository.GetTrashByUserID(ctx, f.UserID)
+	if err != nil {
+		return []task.Task{}, time.Time{}, err
+	}
+
+	var lastModified time.Time
+	for _, t := range slices.Concat(tasks, trash) {
+		if t.UpdatedAt.After(lastModified) {
+			lastModified = t.UpdatedAt
+		}
+	}
+
+	due := make([]task.Task, 0, len(tasks))
+	for _, t := range tasks {
+		if t.DueAt != nil {
+			due = append(due, t)
+		}
+	}
+
+	return due, lastModified, nil
+}
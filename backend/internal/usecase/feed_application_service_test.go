@@ -0,0 +1,87 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	feedRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/memory"
+	repo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/memory"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+func TestFeedUseCaseCreateAndRevokeFeed(t *testing.T) {
+	s := usecase.NewFeedUseCase(feedRepo.NewRepository(config.Config{}), repo.NewRepository(config.Config{}))
+	userId := uuid.New()
+
+	first, _, err := s.CreateFeed(context.Background(), userId)
+	if err != nil {
+		t.Fatalf("s.CreateFeed() error = %v", err)
+	}
+
+	// Creating the feed again replaces the token
+	second, f, err := s.CreateFeed(context.Background(), userId)
+	if err != nil {
+		t.Fatalf("s.CreateFeed() error = %v", err)
+	}
+	if f.TokenHash != feed.HashToken(second) {
+		t.Errorf("s.CreateFeed() TokenHash = %v, want %v", f.TokenHash, feed.HashToken(second))
+	}
+
+	if _, _, err := s.GetFeedTasks(context.Background(), first); !errors.Is(err, usecase.ErrInvalidFeedToken) {
+		t.Errorf("s.GetFeedTasks() with the replaced token error = %v, wantErr %v", err, usecase.ErrInvalidFeedToken)
+	}
+	if _, _, err := s.GetFeedTasks(context.Background(), second); err != nil {
+		t.Errorf("s.GetFeedTasks() error = %v", err)
+	}
+
+	err = s.RevokeFeed(context.Background(), userId)
+	if err != nil {
+		t.Fatalf("s.RevokeFeed() error = %v", err)
+	}
+
+	if _, _, err := s.GetFeedTasks(context.Background(), second); !errors.Is(err, usecase.ErrInvalidFeedToken) {
+		t.Errorf("s.GetFeedTasks() with the revoked token error = %v, wantErr %v", err, usecase.ErrInvalidFeedToken)
+	}
+	if _, err := s.GetFeed(context.Background(), userId); !errors.Is(err, feed.ErrFeedNotFound) {
+		t.Errorf("s.GetFeed() error = %v, wantErr %v", err, feed.ErrFeedNotFound)
+	}
+}
+
+func TestFeedUseCaseGetFeedTasks(t *testing.T) {
+	taskRepository := repo.NewRepository(config.Config{})
+	s := usecase.NewFeedUseCase(feedRepo.NewRepository(config.Config{}), taskRepository)
+	userId := uuid.New()
+
+	raw, _, err := s.CreateFeed(context.Background(), userId)
+	if err != nil {
+		t.Fatalf("s.CreateFeed() error = %v", err)
+	}
+
+	due, _ := task.NewTask("due", userId, task.WithDueDate(time.Now().Add(time.Hour)))
+	undated, _ := task.NewTask("undated", userId)
+	trashed, _ := task.NewTask("trashed", userId, task.WithDueDate(time.Now().Add(time.Hour)))
+	_ = trashed.MoveToTrash(time.Now().Add(time.Minute))
+	for _, ti := range []task.Task{due, undated, trashed} {
+		if err := taskRepository.Save(context.Background(), ti); err != nil {
+			t.Fatalf("taskRepository.Save() error = %v", err)
+		}
+	}
+
+	tasks, lastModified, err := s.GetFeedTasks(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("s.GetFeedTasks() error = %v", err)
+	}
+
+	if len(tasks) != 1 || tasks[0].ID != due.ID {
+		t.Errorf("s.GetFeedTasks() = %v, want only the task with a due date", tasks)
+	}
+	if !lastModified.Equal(trashed.UpdatedAt) {
+		t.Errorf("s.GetFeedTasks() lastModified = %v, want %v", lastModified, trashed.UpdatedAt)
+	}
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/domain/user"
+)
+
+var (
+	ErrInviteeNotFound = errors.New("no user is registered with the email")
+)
+
+type ListUseCase struct {
+	listRepository list.Repository
+	taskRepository task.Repository
+	userUseCase    *UserUseCase
+	policy         *AccessPolicy
+}
+
+// NewListUseCase creates an new instance of the ListUseCase.
+func NewListUseCase(listRepository list.Repository, taskRepository task.Repository, userUseCase *UserUseCase, policy *AccessPolicy) *ListUseCase {
+	return &ListUseCase{
+		listRepository: listRepository,
+		taskRepository: taskRepository,
+		userUseCase:    userUseCase,
+		policy:         policy,
+	}
+}
+
+// CreateList creates a new list owned by the user and saves it to the list repository.
+func (s *ListUseCase) CreateList(ctx context.Context, name string, userId uuid.UUID) (list.List, error) {
+	l, err := list.NewList(name, userId)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	err = s.listRepository.Save(ctx, l)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	return l, nil
+}
+
+// GetListsForUser returns all lists the user is a member of.
+func (s *ListUseCase) GetListsForUser(ctx context.Context, userId uuid.UUID) ([]list.List, error) {
+	l, err := s.listRepository.GetAllByMemberID(ctx, userId)
+	if err != nil {
+		return []list.List{}, err
+	}
+
+	return l, nil
+}
+
+// GetList returns a list the user is a member of.
+func (s *ListUseCase) GetList(ctx context.Context, id uuid.UUID, userId uuid.UUID) (list.List, error) {
+	return s.getAuthorized(ctx, id, userId, ActionView)
+}
+
+// RenameList sets a new name of the list.
+func (s *ListUseCase) RenameList(ctx context.Context, id uuid.UUID, name string, userId uuid.UUID) (list.List, error) {
+	l, err := s.getAuthorized(ctx, id, userId, ActionManage)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	err = l.SetName(name)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	err = s.listRepository.Update(ctx, l)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	return l, nil
+}
+
+// InviteMember gives a registered user, found by email, access to the list with the role.
+func (s *ListUseCase) InviteMember(ctx context.Context, id uuid.UUID, email string, role list.Role, userId uuid.UUID) (list.List, error) {
+	l, err := s.getAuthorized(ctx, id, userId, ActionManage)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	invitee, err := s.userUseCase.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return list.List{}, ErrInviteeNotFound
+		}
+
+		return list.List{}, err
+	}
+
+	err = l.AddMember(invitee.ID, role)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	err = s.listRepository.Update(ctx, l)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	return l, nil
+}
+
+// SetMemberRole changes the role of a list member.
+func (s *ListUseCase) SetMemberRole(ctx context.Context, id uuid.UUID, memberId uuid.UUID, role list.Role, userId uuid.UUID) (list.List, error) {
+	l, err := s.getAuthorized(ctx, id, userId, ActionManage)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	err = l.SetMemberRole(memberId, role)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	err = s.listRepository.Update(ctx, l)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	return l, nil
+}
+
+// RemoveMember takes the access to the list away from a member. Any member may leave the list on their own.
+func (s *ListUseCase) RemoveMember(ctx context.Context, id uuid.UUID, memberId uuid.UUID, userId uuid.UUID) (list.List, error) {
+	action := ActionManage
+	if memberId == userId {
+		action = ActionView
+	}
+
+	l, err := s.getAuthorized(ctx, id, userId, action)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	err = l.RemoveMember(memberId)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	err = s.listRepository.Update(ctx, l)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	return l, nil
+}
+
+// DeleteList deletes the list together with all its tasks.
+func (s *ListUseCase) DeleteList(ctx context.Context, id uuid.UUID, userId uuid.UUID) error {
+	l, err := s.getAuthorized(ctx, id, userId, ActionManage)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.taskRepository.DeleteAllByListID(ctx, l.ID)
+	if err != nil {
+		return err
+	}
+
+	err = s.listRepository.Delete(ctx, l.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *ListUseCase) getAuthorized(ctx context.Context, id uuid.UUID, userId uuid.UUID, action Action) (list.List, error) {
+	l, err := s.listRepository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, list.ErrListNotFound) {
+			return list.List{}, ErrUnauthorizedAction
+		}
+
+		return list.List{}, err
+	}
+
+	err = s.policy.AuthorizeList(l, userId, action)
+	if err != nil {
+		return list.List{}, err
+	}
+
+	return l, nil
+}
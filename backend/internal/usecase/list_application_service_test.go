@@ -0,0 +1,167 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	listRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/memory"
+	taskRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/memory"
+	userRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/user/memory"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+func TestListUseCaseSharing(t *testing.T) {
+	lr := listRepo.NewRepository(config.Config{})
+	tr := taskRepo.NewRepository(config.Config{})
+	policy := usecase.NewAccessPolicy(lr)
+
+	us := newUserUseCase(userRepo.NewRepository(config.Config{}), tr, lr)
+	ls := usecase.NewListUseCase(lr, tr, us, policy)
+	ts, _ := newTaskUseCase(tr, policy)
+
+	owner, err := us.RegisterNewUser(context.Background(), "owner@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("us.RegisterNewUser() error = %v", err)
+	}
+
+	editor, err := us.RegisterNewUser(context.Background(), "editor@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("us.RegisterNewUser() error = %v", err)
+	}
+
+	viewer, err := us.RegisterNewUser(context.Background(), "viewer@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("us.RegisterNewUser() error = %v", err)
+	}
+
+	l, err := ls.CreateList(context.Background(), "Team", owner.ID)
+	if err != nil {
+		t.Fatalf("ls.CreateList() error = %v", err)
+	}
+
+	// Invitations go to registered users only
+	_, err = ls.InviteMember(context.Background(), l.ID, "nobody@example.com", list.RoleEditor, owner.ID)
+	if !errors.Is(err, usecase.ErrInviteeNotFound) {
+		t.Errorf("ls.InviteMember() error = %v, wantErr %v", err, usecase.ErrInviteeNotFound)
+	}
+
+	_, err = ls.InviteMember(context.Background(), l.ID, editor.Email, list.RoleEditor, owner.ID)
+	if err != nil {
+		t.Errorf("ls.InviteMember() error = %v", err)
+	}
+
+	// Only owners manage the members
+	_, err = ls.InviteMember(context.Background(), l.ID, viewer.Email, list.RoleViewer, editor.ID)
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("ls.InviteMember() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	_, err = ls.InviteMember(context.Background(), l.ID, viewer.Email, list.RoleViewer, owner.ID)
+	if err != nil {
+		t.Errorf("ls.InviteMember() error = %v", err)
+	}
+
+	// Editors change the tasks of the list
+	ti, err := ts.CreateTask(context.Background(), "shared task", editor.ID, task.WithList(l.ID))
+	if err != nil {
+		t.Fatalf("ts.CreateTask() error = %v", err)
+	}
+
+	_, err = ts.MarkTaskCompleted(context.Background(), ti.ID, owner.ID)
+	if err != nil {
+		t.Errorf("ts.MarkTaskCompleted() error = %v", err)
+	}
+
+	// Viewers only read them
+	q := task.NewQuery(viewer.ID)
+	q.ListID = &l.ID
+
+	p, err := ts.FindTasksForUser(context.Background(), q, viewer.ID)
+	if err != nil {
+		t.Errorf("ts.FindTasksForUser() error = %v", err)
+	}
+	if len(p.Tasks) != 1 || p.Tasks[0].ID != ti.ID {
+		t.Errorf("ts.FindTasksForUser() got = %v, want %v", p.Tasks, ti.ID)
+	}
+
+	_, err = ts.UpdateTask(context.Background(), ti.ID, task.AnyVersion, "changed", viewer.ID)
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("ts.UpdateTask() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	_, err = ts.CreateTask(context.Background(), "viewer task", viewer.ID, task.WithList(l.ID))
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("ts.CreateTask() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	// Removed members lose the access
+	_, err = ls.RemoveMember(context.Background(), l.ID, viewer.ID, viewer.ID)
+	if err != nil {
+		t.Errorf("ls.RemoveMember() error = %v", err)
+	}
+
+	_, err = ts.FindTasksForUser(context.Background(), q, viewer.ID)
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("ts.FindTasksForUser() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	// Deleting the list deletes its tasks
+	err = ls.DeleteList(context.Background(), l.ID, editor.ID)
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("ls.DeleteList() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	err = ls.DeleteList(context.Background(), l.ID, owner.ID)
+	if err != nil {
+		t.Errorf("ls.DeleteList() error = %v", err)
+	}
+
+	_, err = tr.GetByID(context.Background(), ti.ID)
+	if !errors.Is(err, task.ErrTaskNotFound) {
+		t.Errorf("ls.DeleteList() error = %v, wantErr %v", err, task.ErrTaskNotFound)
+	}
+}
+
+func TestAccessPolicyAuthorizeList(t *testing.T) {
+	owner, editor, viewer, stranger := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	l, err := list.NewList("Team", owner)
+	if err != nil {
+		t.Fatalf("list.NewList() error = %v", err)
+	}
+	_ = l.AddMember(editor, list.RoleEditor)
+	_ = l.AddMember(viewer, list.RoleViewer)
+
+	type testCase struct {
+		name    string
+		userId  uuid.UUID
+		action  usecase.Action
+		wantErr error
+	}
+
+	tests := []testCase{
+		{name: "Owner manages", userId: owner, action: usecase.ActionManage, wantErr: nil},
+		{name: "Editor edits", userId: editor, action: usecase.ActionEdit, wantErr: nil},
+		{name: "Editor cannot manage", userId: editor, action: usecase.ActionManage, wantErr: usecase.ErrUnauthorizedAction},
+		{name: "Viewer views", userId: viewer, action: usecase.ActionView, wantErr: nil},
+		{name: "Viewer cannot edit", userId: viewer, action: usecase.ActionEdit, wantErr: usecase.ErrUnauthorizedAction},
+		{name: "Stranger cannot view", userId: stranger, action: usecase.ActionView, wantErr: usecase.ErrUnauthorizedAction},
+	}
+
+	p := usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := p.AuthorizeList(l, tt.userId, tt.action)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AuthorizeList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
+)
+
+var (
+	ErrLoginThrottled = errors.New("too many failed login attempts, try again later")
+)
+
+// Lockout is a lockout of an account or an IP address started by a failed login attempt.
+type Lockout struct {
+	Scope    login.Scope
+	Failures int
+	Until    time.Time
+}
+
+// LoginGuardUseCase slows down guessing passwords. The failed login attempts are counted for the email,
+// whether a user is registered with it or not, and for the IP address, each with its own policy.
+type LoginGuardUseCase struct {
+	attemptsRepository login.Repository
+	accountPolicy      login.Policy
+	ipPolicy           login.Policy
+}
+
+// NewLoginGuardUseCase creates an new instance of the LoginGuardUseCase.
+func NewLoginGuardUseCase(attemptsRepository login.Repository, accountPolicy login.Policy, ipPolicy login.Policy) *LoginGuardUseCase {
+	return &LoginGuardUseCase{
+		attemptsRepository: attemptsRepository,
+		accountPolicy:      accountPolicy,
+		ipPolicy:           ipPolicy,
+	}
+}
+
+// StartLogin counts the attempt to log in with the email from the IP address as a failed one before the password
+// is checked, so the attempts made in parallel can not get past the limits. The attempt is counted only if
+// the attempts it has been allowed by are still the stored ones, and they are read again otherwise.
+// It returns ErrLoginThrottled and how long to wait if the email may not be tried from the IP address now.
+// The password must not be checked then, or the guessing would go on.
+func (s *LoginGuardUseCase) StartLogin(ctx context.Context, email, ip string) (LoginAttempt, time.Duration, error) {
+	var attempt LoginAttempt
+	var wait time.Duration
+
+	for _, g := range s.guards(email, ip) {
+		a, w, err := countAttempt(ctx, s.attemptsRepository, g.key, g.policy)
+		if err != nil {
+			return LoginAttempt{}, 0, errors.Join(err, s.takeBack(ctx, attempt))
+		}
+
+		if w > 0 {
+			wait = max(wait, w)
+			continue
+		}
+
+		attempt.counted = append(attempt.counted, countedFailure{g, a})
+	}
+
+	if wait > 0 {
+		// A failure to take the attempt back only leaves it counted
+		_ = s.takeBack(ctx, attempt)
+
+		return LoginAttempt{}, wait, ErrLoginThrottled
+	}
+
+	return attempt, 0, nil
+}
+
+// LoginFailed returns the lockouts the failed attempt has started. The attempt has been counted by StartLogin.
+func (s *LoginGuardUseCase) LoginFailed(attempt LoginAttempt) []Lockout {
+	var lockouts []Lockout
+
+	for _, c := range attempt.counted {
+		if c.guard.policy.IsLockout(c.attempts.Failures) {
+			lockouts = append(lockouts, Lockout{
+				Scope:    c.guard.scope,
+				Failures: c.attempts.Failures,
+				Until:    c.attempts.LastFailureAt.Add(c.guard.policy.Delay(c.attempts.Failures)),
+			})
+		}
+	}
+
+	return lockouts
+}
+
+// LoginSucceeded forgets the failed attempts with the email and takes back the attempt counted for the IP address.
+// The earlier failures from the IP address are kept, as logging in to an account of their own would let
+// an attacker try other ones again.
+func (s *LoginGuardUseCase) LoginSucceeded(ctx context.Context, attempt LoginAttempt) error {
+	var errs []error
+
+	for _, c := range attempt.counted {
+		if c.guard.scope == login.ScopeAccount {
+			errs = append(errs, s.attemptsRepository.Reset(ctx, c.guard.key))
+		} else {
+			errs = append(errs, s.attemptsRepository.RemoveFailure(ctx, c.guard.key))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// LoginAttempt is an attempt to log in counted as a failed one by StartLogin.
+type LoginAttempt struct {
+	counted []countedFailure
+}
+
+// countedFailure is the attempts of a guard with the failure of a LoginAttempt counted.
+type countedFailure struct {
+	guard    loginGuard
+	attempts login.Attempts
+}
+
+// maxLoginRaces is how many times the attempts of a key are read again when other attempts have been counted
+// in the meantime. An attempt losing more races is throttled for loginRaceWait, as that many parallel
+// attempts are guessing.
+const (
+	maxLoginRaces = 3
+	loginRaceWait = time.Second
+)
+
+// countAttempt counts an attempt of the key, unless the attempts so far make it wait under the policy,
+// and returns the attempts with it, or how long to wait.
+func countAttempt(ctx context.Context, attemptsRepository login.Repository, key string, policy login.Policy) (login.Attempts, time.Duration, error) {
+	for i := 0; i <= maxLoginRaces; i++ {
+		seen, err := attemptsRepository.Get(ctx, key)
+		if err != nil {
+			return login.Attempts{}, 0, err
+		}
+
+		now := time.Now()
+		if wait := policy.Wait(seen, now); wait > 0 {
+			return login.Attempts{}, wait, nil
+		}
+
+		a, err := attemptsRepository.AddFailure(ctx, seen, now, policy.Window)
+		if errors.Is(err, login.ErrAttemptsChanged) {
+			continue
+		}
+		if err != nil {
+			return login.Attempts{}, 0, err
+		}
+
+		return a, 0, nil
+	}
+
+	return login.Attempts{}, loginRaceWait, nil
+}
+
+// takeBack takes back the failures counted for the attempt.
+func (s *LoginGuardUseCase) takeBack(ctx context.Context, attempt LoginAttempt) error {
+	var errs []error
+
+	for _, c := range attempt.counted {
+		errs = append(errs, s.attemptsRepository.RemoveFailure(ctx, c.guard.key))
+	}
+
+	return errors.Join(errs...)
+}
+
+// loginGuard is the key of the attempts of a scope with its policy.
+type loginGuard struct {
+	scope  login.Scope
+	key    string
+	policy login.Policy
+}
+
+// guards returns the keys the attempts with the email from the IP address are counted for. An empty email
+// or IP address is not counted.
+func (s *LoginGuardUseCase) guards(email, ip string) []loginGuard {
+	var guards []loginGuard
+
+	if key, err := login.NewKey(login.ScopeAccount, email); err == nil {
+		guards = append(guards, loginGuard{login.ScopeAccount, key, s.accountPolicy})
+	}
+	if key, err := login.NewKey(login.ScopeIP, ip); err == nil {
+		guards = append(guards, loginGuard{login.ScopeIP, key, s.ipPolicy})
+	}
+
+	return guards
+}
@@ -0,0 +1,153 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
+	loginRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/memory"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+func TestLoginGuardUseCaseAccount(t *testing.T) {
+	s := usecase.NewLoginGuardUseCase(
+		loginRepo.NewRepository(config.Config{}),
+		login.Policy{FreeAttempts: 2, LockoutThreshold: 3, LockoutDuration: time.Hour, Window: time.Hour},
+		login.Policy{},
+	)
+
+	// The free attempts are not throttled, whether a user is registered with the email or not
+	for i := 0; i < 2; i++ {
+		attempt, _, err := s.StartLogin(context.Background(), "unknown@example.com", "192.0.2.1")
+		if err != nil {
+			t.Fatalf("StartLogin() error = %v on the attempt %d", err, i+1)
+		}
+
+		if lockouts := s.LoginFailed(attempt); len(lockouts) != 0 {
+			t.Fatalf("LoginFailed() = %v on the attempt %d, want no lockouts", lockouts, i+1)
+		}
+	}
+
+	attempt, _, err := s.StartLogin(context.Background(), "Unknown@Example.com", "192.0.2.2")
+	if err != nil {
+		t.Fatalf("StartLogin() error = %v", err)
+	}
+	lockouts := s.LoginFailed(attempt)
+	if len(lockouts) != 1 || lockouts[0].Scope != login.ScopeAccount || lockouts[0].Failures != 3 {
+		t.Fatalf("LoginFailed() lockouts = %v, want one of the account after 3 failures", lockouts)
+	}
+	if time.Until(lockouts[0].Until) <= 59*time.Minute {
+		t.Errorf("LoginFailed() lockout until = %v, want in an hour", lockouts[0].Until)
+	}
+
+	// The account is locked out from any IP address
+	_, wait, err := s.StartLogin(context.Background(), "unknown@example.com", "192.0.2.3")
+	if !errors.Is(err, usecase.ErrLoginThrottled) {
+		t.Errorf("StartLogin() error = %v, wantErr %v", err, usecase.ErrLoginThrottled)
+	}
+	if wait <= 59*time.Minute || wait > time.Hour {
+		t.Errorf("StartLogin() wait = %v, want up to %v", wait, time.Hour)
+	}
+
+	// Other accounts are not
+	other, _, err := s.StartLogin(context.Background(), "other@example.com", "192.0.2.1")
+	if err != nil {
+		t.Errorf("StartLogin() error = %v for another account", err)
+	}
+
+	// A successful login forgets the failures
+	err = s.LoginSucceeded(context.Background(), other)
+	if err != nil {
+		t.Fatalf("LoginSucceeded() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, _, err := s.StartLogin(context.Background(), "other@example.com", "192.0.2.1"); err != nil {
+			t.Errorf("StartLogin() error = %v on the attempt %d after a successful login", err, i+1)
+		}
+	}
+}
+
+func TestLoginGuardUseCaseIP(t *testing.T) {
+	s := usecase.NewLoginGuardUseCase(
+		loginRepo.NewRepository(config.Config{}),
+		login.Policy{},
+		login.Policy{FreeAttempts: 2, Backoff: time.Minute, MaxBackoff: time.Hour, Window: time.Hour},
+	)
+
+	// A successful login does not count for the IP address
+	attempt, _, err := s.StartLogin(context.Background(), "first@example.com", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("StartLogin() error = %v", err)
+	}
+	err = s.LoginSucceeded(context.Background(), attempt)
+	if err != nil {
+		t.Fatalf("LoginSucceeded() error = %v", err)
+	}
+
+	// The backoff is for the IP address whatever the email
+	for _, email := range []string{"first@example.com", "second@example.com", "third@example.com"} {
+		attempt, _, err := s.StartLogin(context.Background(), email, "192.0.2.1")
+		if err != nil {
+			t.Fatalf("StartLogin() error = %v", err)
+		}
+
+		if lockouts := s.LoginFailed(attempt); len(lockouts) != 0 {
+			t.Fatalf("LoginFailed() = %v, want no lockouts", lockouts)
+		}
+	}
+
+	_, wait, err := s.StartLogin(context.Background(), "fourth@example.com", "192.0.2.1")
+	if !errors.Is(err, usecase.ErrLoginThrottled) {
+		t.Errorf("StartLogin() error = %v, wantErr %v", err, usecase.ErrLoginThrottled)
+	}
+	if wait <= 59*time.Second || wait > time.Minute {
+		t.Errorf("StartLogin() wait = %v, want up to %v", wait, time.Minute)
+	}
+
+	// Other IP addresses are not throttled
+	if _, _, err := s.StartLogin(context.Background(), "second@example.com", "192.0.2.2"); err != nil {
+		t.Errorf("StartLogin() error = %v from another IP address", err)
+	}
+}
+
+func TestLoginGuardUseCaseParallel(t *testing.T) {
+	s := usecase.NewLoginGuardUseCase(
+		loginRepo.NewRepository(config.Config{}),
+		login.Policy{FreeAttempts: 3, Backoff: time.Minute, Window: time.Hour},
+		login.Policy{},
+	)
+
+	// Only the free failures and the attempt after them get to check the password, however many are made at once
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	started := 0
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, _, err := s.StartLogin(context.Background(), "test@example.com", "192.0.2.1")
+			if errors.Is(err, usecase.ErrLoginThrottled) {
+				return
+			}
+			if err != nil {
+				t.Errorf("StartLogin() error = %v", err)
+				return
+			}
+
+			mu.Lock()
+			started++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if started > 4 {
+		t.Errorf("StartLogin() let %d attempts check the password, want at most %d", started, 4)
+	}
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+)
+
+// Mail is a plain text email to a single recipient.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, m Mail) error
+}
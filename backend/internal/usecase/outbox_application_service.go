@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/outbox"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+const (
+	// outboxRelayBatch is the largest number of events read from the outbox at once.
+	outboxRelayBatch = 100
+)
+
+// TaskEventPublisher is told about the changes of tasks once they are saved. Publish must not block.
+// The same event can be published more than once, see OutboxUseCase.
+type TaskEventPublisher interface {
+	Publish(ctx context.Context, e task.Event) error
+}
+
+// OutboxUseCase relays the task events from the outbox to the publishers. An event is taken out of
+// the outbox only after it has been published to every publisher, so the events whose relay is cut short,
+// by a crash or a publisher failing for instance, are published again: every event is published at least once.
+type OutboxUseCase struct {
+	outboxRepository outbox.Repository
+	publishers       []TaskEventPublisher
+	pending          chan struct{}
+}
+
+// NewOutboxUseCase creates an new instance of the OutboxUseCase.
+func NewOutboxUseCase(outboxRepository outbox.Repository) *OutboxUseCase {
+	return &OutboxUseCase{
+		outboxRepository: outboxRepository,
+		pending:          make(chan struct{}, 1),
+	}
+}
+
+// AddPublisher makes the use case relay the events to the publisher.
+// It must be called before the use case is used.
+func (s *OutboxUseCase) AddPublisher(p TaskEventPublisher) {
+	s.publishers = append(s.publishers, p)
+}
+
+// Notify tells the relay that events have been added to the outbox. It does not block.
+func (s *OutboxUseCase) Notify() {
+	select {
+	case s.pending <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns the channel signalled by Notify, waking the relay up.
+func (s *OutboxUseCase) Pending() <-chan struct{} {
+	return s.pending
+}
+
+// Relay publishes the events of the outbox, the oldest first, until there are none left
+// and returns how many of them were published. It stops at the first event a publisher fails to publish,
+// which is left in the outbox with the later ones for the next relay.
+func (s *OutboxUseCase) Relay(ctx context.Context) (int, error) {
+	relayed := 0
+
+	for ctx.Err() == nil {
+		events, err := s.outboxRepository.GetPending(ctx, outboxRelayBatch)
+		if err != nil {
+			return relayed, err
+		}
+
+		if len(events) == 0 {
+			break
+		}
+
+		ids := make([]uuid.UUID, 0, len(events))
+		var publishErr error
+		for _, e := range events {
+			if ctx.Err() != nil {
+				break
+			}
+
+			publishErr = s.publish(ctx, e)
+			if publishErr != nil {
+				break
+			}
+
+			ids = append(ids, e.ID)
+		}
+
+		if len(ids) > 0 {
+			err = s.outboxRepository.MarkDispatched(ctx, ids)
+			if err != nil {
+				return relayed, err
+			}
+		}
+
+		relayed += len(ids)
+
+		if publishErr != nil {
+			return relayed, publishErr
+		}
+	}
+
+	return relayed, nil
+}
+
+// publish publishes the event to every publisher. The ones after a failing publisher are not told about it,
+// they are when the event is relayed again.
+func (s *OutboxUseCase) publish(ctx context.Context, e task.Event) error {
+	for _, p := range s.publishers {
+		err := p.Publish(ctx, e)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
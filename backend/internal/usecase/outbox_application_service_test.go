@@ -0,0 +1,175 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	outboxRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/memory"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+type recordingPublisher struct {
+	events []task.Event
+	// failAt is the number of the event from which Publish fails, counting from 1, none if zero.
+	failAt int
+	err    error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e task.Event) error {
+	if p.failAt > 0 && len(p.events)+1 >= p.failAt {
+		return p.err
+	}
+
+	p.events = append(p.events, e)
+
+	return nil
+}
+
+func TestOutboxUseCaseRelay(t *testing.T) {
+	outbox := outboxRepo.NewRepository(config.Config{})
+
+	ti, err := task.NewTask("test", uuid.New())
+	if err != nil {
+		t.Fatalf("task.NewTask() error = %v", err)
+	}
+
+	// More events than are read at once
+	var added []task.Event
+	for range 150 {
+		e := task.NewEvent(task.EventUpdated, ti, ti.UserID)
+		added = append(added, e)
+
+		err = outbox.Add(context.Background(), e)
+		if err != nil {
+			t.Fatalf("outbox.Add() error = %v", err)
+		}
+	}
+
+	s := usecase.NewOutboxUseCase(outbox)
+	publishers := []*recordingPublisher{{}, {}}
+	for _, p := range publishers {
+		s.AddPublisher(p)
+	}
+
+	n, err := s.Relay(context.Background())
+	if err != nil || n != len(added) {
+		t.Fatalf("s.Relay() got = %v, %v, want %v", n, err, len(added))
+	}
+
+	for i, p := range publishers {
+		if len(p.events) != len(added) {
+			t.Fatalf("publisher %d got %v events, want %v", i, len(p.events), len(added))
+		}
+		for j, e := range p.events {
+			if e.ID != added[j].ID {
+				t.Errorf("publisher %d event %d = %v, want %v", i, j, e.ID, added[j].ID)
+			}
+		}
+	}
+
+	n, err = s.Relay(context.Background())
+	if err != nil || n != 0 {
+		t.Errorf("second s.Relay() got = %v, %v, want nothing relayed", n, err)
+	}
+}
+
+func TestOutboxUseCaseNotify(t *testing.T) {
+	s := usecase.NewOutboxUseCase(outboxRepo.NewRepository(config.Config{}))
+
+	// Notify does not block while the relay is busy
+	s.Notify()
+	s.Notify()
+
+	select {
+	case <-s.Pending():
+	default:
+		t.Fatal("s.Pending() not signalled after s.Notify()")
+	}
+
+	select {
+	case <-s.Pending():
+		t.Error("s.Pending() signalled twice")
+	default:
+	}
+}
+
+func TestOutboxUseCaseRelayCancelled(t *testing.T) {
+	outbox := outboxRepo.NewRepository(config.Config{})
+
+	ti, err := task.NewTask("test", uuid.New())
+	if err != nil {
+		t.Fatalf("task.NewTask() error = %v", err)
+	}
+
+	err = outbox.Add(context.Background(), task.NewEvent(task.EventCreated, ti, ti.UserID))
+	if err != nil {
+		t.Fatalf("outbox.Add() error = %v", err)
+	}
+
+	s := usecase.NewOutboxUseCase(outbox)
+	p := &recordingPublisher{}
+	s.AddPublisher(p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	n, err := s.Relay(ctx)
+	if err != nil || n != 0 || len(p.events) != 0 {
+		t.Fatalf("s.Relay() got = %v, %v, want nothing relayed", n, err)
+	}
+
+	// The event is kept for the next relay
+	n, err = s.Relay(context.Background())
+	if err != nil || n != 1 || len(p.events) != 1 {
+		t.Errorf("s.Relay() got = %v, %v, want %v", n, err, 1)
+	}
+}
+
+func TestOutboxUseCaseRelayFailed(t *testing.T) {
+	outbox := outboxRepo.NewRepository(config.Config{})
+
+	ti, err := task.NewTask("test", uuid.New())
+	if err != nil {
+		t.Fatalf("task.NewTask() error = %v", err)
+	}
+
+	for range 3 {
+		err = outbox.Add(context.Background(), task.NewEvent(task.EventUpdated, ti, ti.UserID))
+		if err != nil {
+			t.Fatalf("outbox.Add() error = %v", err)
+		}
+	}
+
+	errPublish := errors.New("publish failed")
+
+	s := usecase.NewOutboxUseCase(outbox)
+	first := &recordingPublisher{}
+	failing := &recordingPublisher{failAt: 2, err: errPublish}
+	s.AddPublisher(first)
+	s.AddPublisher(failing)
+
+	n, err := s.Relay(context.Background())
+	if !errors.Is(err, errPublish) || n != 1 {
+		t.Fatalf("s.Relay() got = %v, %v, want %v, %v", n, err, 1, errPublish)
+	}
+
+	// The failed event and the later ones are kept for the next relay
+	failing.failAt = 0
+
+	n, err = s.Relay(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("s.Relay() got = %v, %v, want %v", n, err, 2)
+	}
+
+	if len(failing.events) != 3 {
+		t.Errorf("failing publisher got %v events, want %v", len(failing.events), 3)
+	}
+	// The failed event is published again to the publishers it was published to
+	if len(first.events) != 4 || first.events[1].ID != first.events[2].ID {
+		t.Errorf("first publisher got %v events, want %v with the failed one twice", len(first.events), 4)
+	}
+}
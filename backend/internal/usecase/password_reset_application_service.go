@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
+	"github.com/ozaitsev92/tododdd/internal/domain/reset"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+	"github.com/ozaitsev92/tododdd/internal/domain/user"
+)
+
+const (
+	// passwordResetQueueSize is the largest number of password reset requests waiting to be sent.
+	passwordResetQueueSize = 100
+)
+
+var (
+	ErrInvalidResetToken      = errors.New("the password reset token is invalid or has expired")
+	ErrResetRequestsQueued    = errors.New("too many password reset requests are waiting to be sent")
+	ErrResetRequestsThrottled = errors.New("too many password reset requests, try again later")
+)
+
+type PasswordResetUseCase struct {
+	resetRepository    reset.Repository
+	userRepository     user.Repository
+	sessionRepository  session.Repository
+	transactor         Transactor
+	mailer             Mailer
+	tokenTTL           time.Duration
+	resendInterval     time.Duration
+	resetURL           string
+	passwords          user.Passwords
+	attemptsRepository login.Repository
+	accountPolicy      login.Policy
+	ipPolicy           login.Policy
+	requests           chan string
+	pending            chan struct{}
+}
+
+// NewPasswordResetUseCase creates an new instance of the PasswordResetUseCase. The mailed links lead
+// to resetURL with the raw token in the token query parameter and can be used within tokenTTL.
+// Another link is mailed to a user no sooner than resendInterval after the last one.
+// The new passwords are checked and hashed with passwords. The requests are counted in attemptsRepository
+// for the email and for the IP address, and throttled under accountPolicy and ipPolicy.
+func NewPasswordResetUseCase(resetRepository reset.Repository, userRepository user.Repository, sessionRepository session.Repository, transactor Transactor, mailer Mailer, tokenTTL time.Duration, resendInterval time.Duration, resetURL string, passwords user.Passwords, attemptsRepository login.Repository, accountPolicy login.Policy, ipPolicy login.Policy) *PasswordResetUseCase {
+	return &PasswordResetUseCase{
+		resetRepository:    resetRepository,
+		userRepository:     userRepository,
+		sessionRepository:  sessionRepository,
+		transactor:         transactor,
+		mailer:             mailer,
+		tokenTTL:           tokenTTL,
+		resendInterval:     resendInterval,
+		resetURL:           resetURL,
+		passwords:          passwords,
+		attemptsRepository: attemptsRepository,
+		accountPolicy:      accountPolicy,
+		ipPolicy:           ipPolicy,
+		requests:           make(chan string, passwordResetQueueSize),
+		pending:            make(chan struct{}, 1),
+	}
+}
+
+// RequestPasswordReset queues a request for a password reset link for the email from the IP address,
+// mailed by SendRequested. It does not look the email up, so whether a user is registered with it does not show,
+// not even in the time it takes. It returns ErrResetRequestsThrottled and how long to wait if too many requests
+// have been made for the email or from the IP address, and ErrResetRequestsQueued if too many are waiting.
+func (s *PasswordResetUseCase) RequestPasswordReset(ctx context.Context, email, ip string) (time.Duration, error) {
+	wait, err := s.countRequest(ctx, email, ip)
+	if err != nil {
+		return 0, err
+	}
+	if wait > 0 {
+		return wait, ErrResetRequestsThrottled
+	}
+
+	select {
+	case s.requests <- email:
+	default:
+		return 0, ErrResetRequestsQueued
+	}
+
+	select {
+	case s.pending <- struct{}{}:
+	default:
+	}
+
+	return 0, nil
+}
+
+// countRequest counts the request for the email and for the IP address and returns how long it has to wait
+// under the policies, zero if it is allowed. An empty email or IP address is not counted.
+func (s *PasswordResetUseCase) countRequest(ctx context.Context, email, ip string) (time.Duration, error) {
+	var wait time.Duration
+
+	guards := []struct {
+		scope  login.Scope
+		value  string
+		policy login.Policy
+	}{
+		{login.ScopeResetAccount, email, s.accountPolicy},
+		{login.ScopeResetIP, ip, s.ipPolicy},
+	}
+	for _, g := range guards {
+		key, err := login.NewKey(g.scope, g.value)
+		if err != nil {
+			continue
+		}
+
+		_, w, err := countAttempt(ctx, s.attemptsRepository, key, g.policy)
+		if err != nil {
+			return 0, err
+		}
+
+		wait = max(wait, w)
+	}
+
+	return wait, nil
+}
+
+// Pending returns the channel signalled by RequestPasswordReset, waking the sender up.
+func (s *PasswordResetUseCase) Pending() <-chan struct{} {
+	return s.pending
+}
+
+// SendRequested mails a password reset link for each of the queued requests and returns how many were mailed.
+// Nothing is mailed for an email no user is registered with, nor to a user who has been mailed one
+// less than the resend interval ago.
+func (s *PasswordResetUseCase) SendRequested(ctx context.Context) (int, error) {
+	var (
+		sent int
+		errs []error
+	)
+
+	for ctx.Err() == nil {
+		var email string
+		select {
+		case email = <-s.requests:
+		default:
+			return sent, errors.Join(errs...)
+		}
+
+		ok, err := s.sendReset(ctx, email)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// sendReset mails a password reset link to the user registered with the email and returns whether it has.
+func (s *PasswordResetUseCase) sendReset(ctx context.Context, email string) (bool, error) {
+	u, err := s.userRepository.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	latest, err := s.resetRepository.GetLatestByUserID(ctx, u.ID)
+	if err == nil && time.Since(latest.CreatedAt) < s.resendInterval {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, reset.ErrTokenNotFound) {
+		return false, err
+	}
+
+	t, raw, err := reset.NewToken(u.ID, s.tokenTTL)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.resetRepository.Save(ctx, t)
+	if err != nil {
+		return false, err
+	}
+
+	link := s.resetURL + "?" + url.Values{"token": {raw}}.Encode()
+
+	err = s.mailer.Send(ctx, Mail{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"A password reset has been requested for your account.\n\n"+
+				"Open the link below to choose a new password. It can be used once, until %s.\n\n%s\n\n"+
+				"If you have not requested it, ignore this email and your password stays the same.\n",
+			t.ExpiresAt.UTC().Format(time.RFC1123),
+			link,
+		),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ResetPassword sets a new password for the user the token has been issued to and ends all their sessions.
+// The token is used up unless the password is invalid, and so are the other tokens of the user.
+func (s *PasswordResetUseCase) ResetPassword(ctx context.Context, raw string, password string) error {
+	t, err := s.resetRepository.GetByHash(ctx, reset.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, reset.ErrTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+
+		return err
+	}
+
+	if !t.IsUsable() {
+		return ErrInvalidResetToken
+	}
+
+	u, err := s.userRepository.GetByID(ctx, t.UserID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return ErrInvalidResetToken
+		}
+
+		return err
+	}
+
+	err = u.SetPassword(password, user.WithPasswords(s.passwords))
+	if err != nil {
+		return err
+	}
+
+	t.MarkUsed()
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.resetRepository.Use(ctx, t)
+		if err != nil {
+			if errors.Is(err, reset.ErrTokenUsed) {
+				return ErrInvalidResetToken
+			}
+
+			return err
+		}
+
+		err = s.resetRepository.UseAllByUserID(ctx, u.ID, t.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		err = s.userRepository.Update(ctx, u)
+		if err != nil {
+			return err
+		}
+
+		return s.sessionRepository.RevokeAllByUserID(ctx, u.ID)
+	})
+}
@@ -0,0 +1,279 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/login"
+	"github.com/ozaitsev92/tododdd/internal/domain/user"
+	loginRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/login/memory"
+	resetRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/memory"
+	sessionRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/memory"
+	transaction "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/transaction/memory"
+	repo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/user/memory"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+// mailbox is a Mailer keeping the mails it is given.
+type mailbox struct {
+	mu    sync.Mutex
+	mails []usecase.Mail
+}
+
+func (m *mailbox) Send(_ context.Context, mail usecase.Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.mails = append(m.mails, mail)
+
+	return nil
+}
+
+func (m *mailbox) received() []usecase.Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]usecase.Mail(nil), m.mails...)
+}
+
+var linkPattern = regexp.MustCompile(`https?://\S+`)
+
+// linkIn returns the first link in the body of the mail.
+func linkIn(t *testing.T, mail usecase.Mail) *url.URL {
+	t.Helper()
+
+	link, err := url.Parse(linkPattern.FindString(mail.Body))
+	if err != nil || link.Host == "" {
+		t.Fatalf("no link in the mail body %q", mail.Body)
+	}
+
+	return link
+}
+
+func TestPasswordResetUseCase(t *testing.T) {
+	ur := repo.NewRepository(config.Config{})
+	sr := sessionRepo.NewRepository(config.Config{})
+	rr := resetRepo.NewRepository(config.Config{})
+	mails := &mailbox{}
+
+	passwords := user.Passwords{Policy: user.NewPasswordPolicy(8, []string{"TestPassword9"}, false)}
+	s := usecase.NewPasswordResetUseCase(rr, ur, sr, transaction.NewTransactor(rr, ur, sr), mails, time.Hour, 0, "http://localhost/reset-password", passwords, loginRepo.NewRepository(config.Config{}), login.Policy{}, login.Policy{})
+	ss := usecase.NewSessionUseCase(sr, time.Hour)
+
+	u, err := user.NewUser("test@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("user.NewUser() error = %v", err)
+	}
+	err = ur.Save(context.Background(), u)
+	if err != nil {
+		t.Fatalf("ur.Save() error = %v", err)
+	}
+
+	refreshToken, _, err := ss.StartSession(context.Background(), u.ID)
+	if err != nil {
+		t.Fatalf("ss.StartSession() error = %v", err)
+	}
+
+	// Unknown emails are not told apart
+	_, err = s.RequestPasswordReset(context.Background(), "unknown@example.com", "192.0.2.1")
+	if err != nil {
+		t.Errorf("s.RequestPasswordReset() error = %v", err)
+	}
+
+	n, err := s.SendRequested(context.Background())
+	if err != nil || n != 0 || len(mails.received()) != 0 {
+		t.Errorf("s.SendRequested() got = %v, %v, want no mails for an unknown email", n, err)
+	}
+
+	// The mails are sent off the request
+	for range 2 {
+		_, err = s.RequestPasswordReset(context.Background(), u.Email, "192.0.2.1")
+		if err != nil {
+			t.Fatalf("s.RequestPasswordReset() error = %v", err)
+		}
+	}
+	if len(mails.received()) != 0 {
+		t.Errorf("s.RequestPasswordReset() sent %d mails, want them sent by s.SendRequested()", len(mails.received()))
+	}
+
+	n, err = s.SendRequested(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("s.SendRequested() got = %v, %v, want %v", n, err, 2)
+	}
+
+	received := mails.received()
+	if len(received) != 2 || received[1].To != u.Email {
+		t.Fatalf("s.SendRequested() sent %v, want mails to %v", received, u.Email)
+	}
+
+	link := linkIn(t, received[1])
+	if link.Path != "/reset-password" {
+		t.Errorf("s.SendRequested() link = %v, want it to lead to /reset-password", link)
+	}
+	token := link.Query().Get("token")
+	earlier := linkIn(t, received[0]).Query().Get("token")
+
+	// The token is not used up by an invalid password
+	err = s.ResetPassword(context.Background(), token, "")
+	if !errors.Is(err, user.ErrInvalidPassword) {
+		t.Errorf("s.ResetPassword() error = %v, wantErr %v", err, user.ErrInvalidPassword)
+	}
+
+	err = s.ResetPassword(context.Background(), token, "testpassword9")
+	if !errors.Is(err, user.ErrPasswordTooCommon) {
+		t.Errorf("s.ResetPassword() error = %v, wantErr %v", err, user.ErrPasswordTooCommon)
+	}
+
+	err = s.ResetPassword(context.Background(), "unknown", "TestPassword2")
+	if !errors.Is(err, usecase.ErrInvalidResetToken) {
+		t.Errorf("s.ResetPassword() error = %v, wantErr %v", err, usecase.ErrInvalidResetToken)
+	}
+
+	err = s.ResetPassword(context.Background(), token, "TestPassword2")
+	if err != nil {
+		t.Fatalf("s.ResetPassword() error = %v", err)
+	}
+
+	got, err := ur.GetByID(context.Background(), u.ID)
+	if err != nil {
+		t.Fatalf("ur.GetByID() error = %v", err)
+	}
+	if got.ComparePassword("TestPassword1") || !got.ComparePassword("TestPassword2") {
+		t.Error("s.ResetPassword() password is not replaced")
+	}
+
+	// The sessions of the user are ended
+	_, _, err = ss.RefreshSession(context.Background(), refreshToken)
+	if !errors.Is(err, usecase.ErrInvalidRefreshToken) {
+		t.Errorf("ss.RefreshSession() error = %v, wantErr %v", err, usecase.ErrInvalidRefreshToken)
+	}
+
+	// The token is single-use, and the other tokens of the user are used up with it
+	for _, used := range []string{token, earlier} {
+		err = s.ResetPassword(context.Background(), used, "TestPassword3")
+		if !errors.Is(err, usecase.ErrInvalidResetToken) {
+			t.Errorf("s.ResetPassword() error = %v, wantErr %v", err, usecase.ErrInvalidResetToken)
+		}
+	}
+}
+
+func TestPasswordResetUseCaseThrottled(t *testing.T) {
+	ur := repo.NewRepository(config.Config{})
+	sr := sessionRepo.NewRepository(config.Config{})
+	rr := resetRepo.NewRepository(config.Config{})
+	mails := &mailbox{}
+
+	s := usecase.NewPasswordResetUseCase(rr, ur, sr, transaction.NewTransactor(rr, ur, sr), mails, time.Hour, time.Hour, "http://localhost/reset-password", user.Passwords{}, loginRepo.NewRepository(config.Config{}), login.Policy{}, login.Policy{})
+
+	u, err := user.NewUser("test@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("user.NewUser() error = %v", err)
+	}
+	err = ur.Save(context.Background(), u)
+	if err != nil {
+		t.Fatalf("ur.Save() error = %v", err)
+	}
+
+	for range 3 {
+		_, err = s.RequestPasswordReset(context.Background(), u.Email, "192.0.2.1")
+		if err != nil {
+			t.Fatalf("s.RequestPasswordReset() error = %v", err)
+		}
+	}
+
+	// Only one mail is sent within the resend interval
+	n, err := s.SendRequested(context.Background())
+	if err != nil || n != 1 || len(mails.received()) != 1 {
+		t.Errorf("s.SendRequested() got = %v, %v, want %v", n, err, 1)
+	}
+
+	// The requests beyond the queue are refused
+	for i := 0; ; i++ {
+		_, err = s.RequestPasswordReset(context.Background(), u.Email, "192.0.2.1")
+		if errors.Is(err, usecase.ErrResetRequestsQueued) {
+			break
+		}
+		if err != nil || i > 1000 {
+			t.Fatalf("s.RequestPasswordReset() error = %v after %d requests, wantErr %v", err, i, usecase.ErrResetRequestsQueued)
+		}
+	}
+}
+
+func TestPasswordResetUseCaseRequestsThrottled(t *testing.T) {
+	ur := repo.NewRepository(config.Config{})
+	sr := sessionRepo.NewRepository(config.Config{})
+	rr := resetRepo.NewRepository(config.Config{})
+
+	s := usecase.NewPasswordResetUseCase(
+		rr, ur, sr, transaction.NewTransactor(rr, ur, sr), &mailbox{}, time.Hour, 0, "http://localhost/reset-password", user.Passwords{},
+		loginRepo.NewRepository(config.Config{}),
+		login.Policy{LockoutThreshold: 2, LockoutDuration: time.Hour, Window: time.Hour},
+		login.Policy{LockoutThreshold: 3, LockoutDuration: time.Hour, Window: time.Hour},
+	)
+
+	tests := []struct {
+		name    string
+		email   string
+		ip      string
+		wantErr error
+	}{
+		{"First for the email", "test@example.com", "192.0.2.1", nil},
+		{"Second for the email", "test@example.com", "192.0.2.1", nil},
+		{"Third for the email", "test@example.com", "192.0.2.2", usecase.ErrResetRequestsThrottled},
+		{"Email is case insensitive", "Test@Example.com", "192.0.2.2", usecase.ErrResetRequestsThrottled},
+		{"Third from the IP address", "other@example.com", "192.0.2.1", nil},
+		{"Fourth from the IP address", "another@example.com", "192.0.2.1", usecase.ErrResetRequestsThrottled},
+		{"From another IP address", "another@example.com", "192.0.2.2", nil},
+	}
+
+	for _, tt := range tests {
+		wait, err := s.RequestPasswordReset(context.Background(), tt.email, tt.ip)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: s.RequestPasswordReset() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if (wait > 0) != (tt.wantErr != nil) {
+			t.Errorf("%s: s.RequestPasswordReset() wait = %v", tt.name, wait)
+		}
+	}
+}
+
+func TestPasswordResetUseCaseExpiredToken(t *testing.T) {
+	ur := repo.NewRepository(config.Config{})
+	sr := sessionRepo.NewRepository(config.Config{})
+	rr := resetRepo.NewRepository(config.Config{})
+	mails := &mailbox{}
+
+	s := usecase.NewPasswordResetUseCase(rr, ur, sr, transaction.NewTransactor(rr, ur, sr), mails, time.Nanosecond, 0, "http://localhost/reset-password", user.Passwords{}, loginRepo.NewRepository(config.Config{}), login.Policy{}, login.Policy{})
+
+	u, err := user.NewUser("test@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("user.NewUser() error = %v", err)
+	}
+	err = ur.Save(context.Background(), u)
+	if err != nil {
+		t.Fatalf("ur.Save() error = %v", err)
+	}
+
+	_, err = s.RequestPasswordReset(context.Background(), u.Email, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("s.RequestPasswordReset() error = %v", err)
+	}
+
+	_, err = s.SendRequested(context.Background())
+	if err != nil {
+		t.Fatalf("s.SendRequested() error = %v", err)
+	}
+
+	time.Sleep(time.Millisecond)
+
+	err = s.ResetPassword(context.Background(), linkIn(t, mails.received()[0]).Query().Get("token"), "TestPassword2")
+	if !errors.Is(err, usecase.ErrInvalidResetToken) {
+		t.Errorf("s.ResetPassword() error = %v, wantErr %v", err, usecase.ErrInvalidResetToken)
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+)
+
+type SessionUseCase struct {
+	sessionRepository  session.Repository
+	refreshTokenLength time.Duration
+}
+
+// NewSessionUseCase creates an new instance of the SessionUseCase.
+func NewSessionUseCase(sessionRepository session.Repository, refreshTokenLength time.Duration) *SessionUseCase {
+	return &SessionUseCase{
+		sessionRepository:  sessionRepository,
+		refreshTokenLength: refreshTokenLength,
+	}
+}
+
+// StartSession issues the first refresh token of a new token family for the user.
+func (s *SessionUseCase) StartSession(ctx context.Context, userId uuid.UUID) (string, session.RefreshToken, error) {
+	t, raw, err := session.NewRefreshToken(userId, uuid.Nil, s.refreshTokenLength)
+	if err != nil {
+		return "", session.RefreshToken{}, err
+	}
+
+	err = s.sessionRepository.Save(ctx, t)
+	if err != nil {
+		return "", session.RefreshToken{}, err
+	}
+
+	return raw, t, nil
+}
+
+// RefreshSession exchanges a refresh token for a new one of the same family.
+// Presenting a token that has already been exchanged revokes the whole family.
+func (s *SessionUseCase) RefreshSession(ctx context.Context, raw string) (string, session.RefreshToken, error) {
+	t, err := s.sessionRepository.GetByHash(ctx, session.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, session.ErrRefreshTokenNotFound) {
+			return "", session.RefreshToken{}, ErrInvalidRefreshToken
+		}
+
+		return "", session.RefreshToken{}, err
+	}
+
+	if t.Revoked || t.IsExpired() {
+		return "", session.RefreshToken{}, ErrInvalidRefreshToken
+	}
+
+	if t.Used {
+		return "", session.RefreshToken{}, s.revokeReusedFamily(ctx, t)
+	}
+
+	next, nextRaw, err := session.NewRefreshToken(t.UserID, t.FamilyID, s.refreshTokenLength)
+	if err != nil {
+		return "", session.RefreshToken{}, err
+	}
+
+	t.MarkUsed()
+
+	err = s.sessionRepository.Rotate(ctx, t, next)
+	if err != nil {
+		if errors.Is(err, session.ErrRefreshTokenReused) {
+			return "", session.RefreshToken{}, s.revokeReusedFamily(ctx, t)
+		}
+
+		return "", session.RefreshToken{}, err
+	}
+
+	return nextRaw, next, nil
+}
+
+// EndSession revokes the token family the refresh token belongs to.
+func (s *SessionUseCase) EndSession(ctx context.Context, raw string) error {
+	t, err := s.sessionRepository.GetByHash(ctx, session.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, session.ErrRefreshTokenNotFound) {
+			return ErrInvalidRefreshToken
+		}
+
+		return err
+	}
+
+	return s.sessionRepository.RevokeFamily(ctx, t.FamilyID)
+}
+
+func (s *SessionUseCase) revokeReusedFamily(ctx context.Context, t session.RefreshToken) error {
+	err := s.sessionRepository.RevokeFamily(ctx, t.FamilyID)
+	if err != nil {
+		return err
+	}
+
+	return session.ErrRefreshTokenReused
+}
@@ -0,0 +1,115 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+	repo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/memory"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+func TestSessionUseCaseStartSession(t *testing.T) {
+	type testCase struct {
+		name    string
+		userId  uuid.UUID
+		wantErr error
+	}
+
+	tests := []testCase{
+		{
+			name:    "Success",
+			userId:  uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			wantErr: nil,
+		},
+		{
+			name:    "Empty userId",
+			userId:  uuid.Nil,
+			wantErr: session.ErrInvalidUserID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := repo.NewRepository(config.Config{})
+
+			s := usecase.NewSessionUseCase(repo, time.Hour)
+			raw, rt, err := s.StartSession(context.Background(), tt.userId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("s.StartSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && rt.UserID != tt.userId {
+				t.Errorf("s.StartSession() UserID = %v, want %v", rt.UserID, tt.userId)
+			}
+			if err == nil {
+				if _, err := repo.GetByHash(context.Background(), session.HashToken(raw)); err != nil {
+					t.Errorf("s.StartSession() the token must be saved: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionUseCaseRefreshSession(t *testing.T) {
+	repo := repo.NewRepository(config.Config{})
+	s := usecase.NewSessionUseCase(repo, time.Hour)
+
+	raw, first, err := s.StartSession(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("s.StartSession() error = %v", err)
+	}
+
+	// The token is rotated within the same family
+	nextRaw, next, err := s.RefreshSession(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("s.RefreshSession() error = %v", err)
+	}
+	if nextRaw == raw || next.ID == first.ID {
+		t.Error("s.RefreshSession() must issue a new token")
+	}
+	if next.FamilyID != first.FamilyID || next.UserID != first.UserID {
+		t.Errorf("s.RefreshSession() FamilyID = %v, want %v", next.FamilyID, first.FamilyID)
+	}
+
+	// Reusing the rotated token revokes the whole family
+	_, _, err = s.RefreshSession(context.Background(), raw)
+	if !errors.Is(err, session.ErrRefreshTokenReused) {
+		t.Errorf("s.RefreshSession() error = %v, wantErr %v", err, session.ErrRefreshTokenReused)
+	}
+
+	_, _, err = s.RefreshSession(context.Background(), nextRaw)
+	if !errors.Is(err, usecase.ErrInvalidRefreshToken) {
+		t.Errorf("s.RefreshSession() error = %v, wantErr %v", err, usecase.ErrInvalidRefreshToken)
+	}
+
+	// Unknown tokens are rejected
+	_, _, err = s.RefreshSession(context.Background(), "unknown")
+	if !errors.Is(err, usecase.ErrInvalidRefreshToken) {
+		t.Errorf("s.RefreshSession() error = %v, wantErr %v", err, usecase.ErrInvalidRefreshToken)
+	}
+}
+
+func TestSessionUseCaseEndSession(t *testing.T) {
+	repo := repo.NewRepository(config.Config{})
+	s := usecase.NewSessionUseCase(repo, time.Hour)
+
+	raw, _, err := s.StartSession(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("s.StartSession() error = %v", err)
+	}
+
+	err = s.EndSession(context.Background(), raw)
+	if err != nil {
+		t.Errorf("s.EndSession() error = %v", err)
+	}
+
+	_, _, err = s.RefreshSession(context.Background(), raw)
+	if !errors.Is(err, usecase.ErrInvalidRefreshToken) {
+		t.Errorf("s.RefreshSession() error = %v, wantErr %v", err, usecase.ErrInvalidRefreshToken)
+	}
+}
@@ -3,34 +3,89 @@ package usecase
 import (
 	"context"
 	"errors"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/history"
+	"github.com/ozaitsev92/tododdd/internal/domain/outbox"
 	"github.com/ozaitsev92/tododdd/internal/domain/task"
 )
 
+const (
+	// MaxDueSoonWindow is the longest period the due soon listing can look ahead.
+	MaxDueSoonWindow = 30 * 24 * time.Hour
+	// MaxOccurrencesPreview is the largest number of upcoming occurrences of a recurring task that can be previewed.
+	MaxOccurrencesPreview = 100
+)
+
+// SubtaskPolicy tells DeleteTask what to do with the subtasks of a deleted task.
+type SubtaskPolicy int
+
+const (
+	// DeleteSubtasks deletes the whole subtree.
+	DeleteSubtasks SubtaskPolicy = iota
+	// ReparentSubtasks moves the direct subtasks to the parent of the deleted task.
+	ReparentSubtasks
+)
+
 var (
 	ErrUnauthorizedAction = errors.New("unauthorized action")
+	ErrInvalidDueWindow   = errors.New("due window is invalid")
+	ErrInvalidOccurrences = errors.New("number of occurrences is invalid")
 )
 
 type TaskUseCase struct {
-	taskRepository task.Repository
+	taskRepository    task.Repository
+	historyRepository history.Repository
+	outboxRepository  outbox.Repository
+	transactor        Transactor
+	policy            *AccessPolicy
+	eventsAdded       func()
 }
 
-// NewTaskUseCase creates an new instance of the TaskUseCase.
-func NewTaskUseCase(taskRepository task.Repository) *TaskUseCase {
+// NewTaskUseCase creates an new instance of the TaskUseCase. Every change of a task is saved together
+// with its history and the event reporting it, which is added to the outbox, in a transaction.
+func NewTaskUseCase(taskRepository task.Repository, historyRepository history.Repository, outboxRepository outbox.Repository, transactor Transactor, policy *AccessPolicy) *TaskUseCase {
 	return &TaskUseCase{
-		taskRepository: taskRepository,
+		taskRepository:    taskRepository,
+		historyRepository: historyRepository,
+		outboxRepository:  outboxRepository,
+		transactor:        transactor,
+		policy:            policy,
+		eventsAdded:       func() {},
 	}
 }
 
+// OnEventsAdded sets the function told that events have been added to the outbox,
+// see OutboxUseCase.Notify. It must be called before the use case is used.
+func (s *TaskUseCase) OnEventsAdded(f func()) {
+	s.eventsAdded = f
+}
+
 // CreateTask creates a new task and saves it to the task repository.
-func (s *TaskUseCase) CreateTask(ctx context.Context, text string, userId uuid.UUID) (task.Task, error) {
-	t, err := task.NewTask(text, userId)
+func (s *TaskUseCase) CreateTask(ctx context.Context, text string, userId uuid.UUID, opts ...task.Option) (task.Task, error) {
+	t, err := task.NewTask(text, userId, opts...)
 	if err != nil {
 		return task.Task{}, err
 	}
 
-	err = s.taskRepository.Save(ctx, t)
+	err = s.create(ctx, &t, userId)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	return t, nil
+}
+
+// GetTask returns the task if the user can view it. Tasks in the trash are not returned.
+func (s *TaskUseCase) GetTask(ctx context.Context, id uuid.UUID, userId uuid.UUID) (task.Task, error) {
+	t, err := s.getActiveTask(ctx, id)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionView)
 	if err != nil {
 		return task.Task{}, err
 	}
@@ -48,15 +103,115 @@ func (s *TaskUseCase) GetAllTasksForUser(ctx context.Context, userId uuid.UUID)
 	return t, nil
 }
 
-// UpdateTask updates the task and saves it to the taskRepository.
-func (s *TaskUseCase) UpdateTask(ctx context.Context, id uuid.UUID, text string, userId uuid.UUID) (task.Task, error) {
-	t, err := s.taskRepository.GetByID(ctx, id)
+// FindTasksForUser returns a page of the user's personal tasks, or of the tasks of a list
+// the user is a member of, matching the query.
+func (s *TaskUseCase) FindTasksForUser(ctx context.Context, q task.Query, userId uuid.UUID) (task.Page, error) {
+	q.UserID = userId
+
+	tags, err := task.NormalizeTags(q.Tags)
+	if err != nil {
+		return task.Page{}, err
+	}
+	q.Tags = tags
+
+	err = q.Validate()
+	if err != nil {
+		return task.Page{}, err
+	}
+
+	if q.ListID != nil {
+		err = s.policy.AuthorizeListID(ctx, *q.ListID, userId, ActionView)
+		if err != nil {
+			return task.Page{}, err
+		}
+	}
+
+	p, err := s.taskRepository.Find(ctx, q)
+	if err != nil {
+		return task.Page{}, err
+	}
+
+	return p, nil
+}
+
+// SearchTasksForUser returns the user's tasks matching the search text, most relevant first,
+// with the matched terms highlighted.
+func (s *TaskUseCase) SearchTasksForUser(ctx context.Context, q task.SearchQuery, userId uuid.UUID) ([]task.SearchResult, error) {
+	q.UserID = userId
+
+	err := q.Validate()
+	if err != nil {
+		return []task.SearchResult{}, err
+	}
+
+	results, err := s.taskRepository.Search(ctx, q)
+	if err != nil {
+		return []task.SearchResult{}, err
+	}
+
+	terms := q.Terms()
+	for i := range results {
+		results[i].Highlights = task.Highlight(results[i].Task.Text, terms)
+	}
+
+	return results, nil
+}
+
+// GetEisenhowerMatrixForUser returns the user's open tasks bucketed into the quadrants of the
+// Eisenhower matrix, highest priority first inside each quadrant.
+func (s *TaskUseCase) GetEisenhowerMatrixForUser(ctx context.Context, userId uuid.UUID) (map[task.Quadrant][]task.Task, error) {
+	t, err := s.taskRepository.GetOpenByUserID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return task.GroupByQuadrant(t, time.Now()), nil
+}
+
+// GetOverdueTasksForUser returns the user's not completed tasks whose due date has passed.
+func (s *TaskUseCase) GetOverdueTasksForUser(ctx context.Context, userId uuid.UUID) ([]task.Task, error) {
+	t, err := s.taskRepository.GetAllDueByUserID(ctx, userId, time.Time{}, time.Now())
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	return t, nil
+}
+
+// GetDueSoonTasksForUser returns the user's not completed tasks due within the given period from now.
+func (s *TaskUseCase) GetDueSoonTasksForUser(ctx context.Context, userId uuid.UUID, within time.Duration) ([]task.Task, error) {
+	if within <= 0 || within > MaxDueSoonWindow {
+		return []task.Task{}, ErrInvalidDueWindow
+	}
+
+	now := time.Now()
+
+	t, err := s.taskRepository.GetAllDueByUserID(ctx, userId, now, now.Add(within))
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	return t, nil
+}
+
+// UpdateTask updates the text and, with the options, further attributes of the task and saves it
+// to the taskRepository. The task must be at the expected version, see task.AnyVersion.
+func (s *TaskUseCase) UpdateTask(ctx context.Context, id uuid.UUID, version int64, text string, userId uuid.UUID, opts ...task.Option) (task.Task, error) {
+	t, err := s.getActiveTask(ctx, id)
 	if err != nil {
 		return task.Task{}, err
 	}
 
-	if t.UserID != userId {
-		return task.Task{}, ErrUnauthorizedAction
+	before := history.StateOf(t)
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionEdit)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	err = t.CheckVersion(version)
+	if err != nil {
+		return task.Task{}, err
 	}
 
 	err = t.SetText(text)
@@ -64,7 +219,17 @@ func (s *TaskUseCase) UpdateTask(ctx context.Context, id uuid.UUID, text string,
 		return task.Task{}, err
 	}
 
-	err = s.taskRepository.Update(ctx, t)
+	op := history.OpUpdateText
+	for _, opt := range opts {
+		err = opt(&t)
+		if err != nil {
+			return task.Task{}, err
+		}
+
+		op = history.OpUpdate
+	}
+
+	err = s.update(ctx, before, &t, userId, op)
 	if err != nil {
 		return task.Task{}, err
 	}
@@ -72,41 +237,259 @@ func (s *TaskUseCase) UpdateTask(ctx context.Context, id uuid.UUID, text string,
 	return t, nil
 }
 
+// SetTaskTags replaces the tags of the task.
+func (s *TaskUseCase) SetTaskTags(ctx context.Context, id uuid.UUID, tags []string, userId uuid.UUID) (task.Task, error) {
+	t, err := s.getActiveTask(ctx, id)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	before := history.StateOf(t)
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionEdit)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	err = t.SetTags(tags)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	err = s.update(ctx, before, &t, userId, history.OpSetTags)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	return t, nil
+}
+
+// GetTagsForUser returns the tags of the user's personal tasks with usage counts, most used first.
+func (s *TaskUseCase) GetTagsForUser(ctx context.Context, userId uuid.UUID) ([]task.TagCount, error) {
+	tags, err := s.taskRepository.GetTagCountsByUserID(ctx, userId)
+	if err != nil {
+		return []task.TagCount{}, err
+	}
+
+	return tags, nil
+}
+
+// RenameTag renames the tag on all the user's personal tasks. Renaming to a tag the user already has merges the two.
+// The tasks of the lists are left as they are, as the user may no longer be allowed to edit them.
+// It returns the number of tasks changed.
+func (s *TaskUseCase) RenameTag(ctx context.Context, from string, to string, userId uuid.UUID) (int, error) {
+	from, err := task.NormalizeTag(from)
+	if err != nil {
+		return 0, err
+	}
+
+	to, err = task.NormalizeTag(to)
+	if err != nil {
+		return 0, err
+	}
+
+	if from == to {
+		return 0, nil
+	}
+
+	var n int
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		tagged, err := s.tasksWithTag(ctx, userId, from)
+		if err != nil {
+			return err
+		}
+
+		n, err = s.taskRepository.RenameTag(ctx, userId, from, to)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range tagged {
+			before := history.StateOf(t)
+			t.RenameTag(from, to)
+			t.Version++
+
+			err = s.addEvent(ctx, &t, userId)
+			if err != nil {
+				return err
+			}
+
+			err = s.record(ctx, t, userId, history.OpRenameTag, &before)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.eventsAdded()
+
+	return n, nil
+}
+
+// SetTaskDueDate sets or, when dueAt is nil, clears the task due date and reminder offset.
+func (s *TaskUseCase) SetTaskDueDate(ctx context.Context, id uuid.UUID, dueAt *time.Time, reminderOffset *time.Duration, userId uuid.UUID) (task.Task, error) {
+	t, err := s.getActiveTask(ctx, id)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	before := history.StateOf(t)
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionEdit)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	if dueAt == nil {
+		if reminderOffset != nil {
+			return task.Task{}, task.ErrReminderWithoutDueDate
+		}
+
+		t.ClearDueDate()
+	} else {
+		err = t.SetDueDate(*dueAt)
+		if err != nil {
+			return task.Task{}, err
+		}
+
+		if reminderOffset == nil {
+			t.ClearReminder()
+		} else if err = t.SetReminder(*reminderOffset); err != nil {
+			return task.Task{}, err
+		}
+	}
+
+	err = s.update(ctx, before, &t, userId, history.OpSetDueDate)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	return t, nil
+}
+
+// SetTaskRecurrence sets or, when r is nil, clears the recurrence rule of the task.
+func (s *TaskUseCase) SetTaskRecurrence(ctx context.Context, id uuid.UUID, r *task.Recurrence, userId uuid.UUID) (task.Task, error) {
+	t, err := s.getActiveTask(ctx, id)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	before := history.StateOf(t)
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionEdit)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	if r == nil {
+		t.ClearRecurrence()
+	} else if err = t.SetRecurrence(*r); err != nil {
+		return task.Task{}, err
+	}
+
+	err = s.update(ctx, before, &t, userId, history.OpSetRecurrence)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	return t, nil
+}
+
+// PreviewTaskOccurrences returns the due dates of at most n occurrences following the current one.
+func (s *TaskUseCase) PreviewTaskOccurrences(ctx context.Context, id uuid.UUID, n int, userId uuid.UUID) ([]time.Time, error) {
+	if n < 1 || n > MaxOccurrencesPreview {
+		return []time.Time{}, ErrInvalidOccurrences
+	}
+
+	t, err := s.getActiveTask(ctx, id)
+	if err != nil {
+		return []time.Time{}, err
+	}
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionView)
+	if err != nil {
+		return []time.Time{}, err
+	}
+
+	occurrences := t.NextOccurrences(n)
+	if occurrences == nil {
+		return []time.Time{}, nil
+	}
+
+	return occurrences, nil
+}
+
 // MarkTaskCompleted marks the task as completed and saves it to the taskRepository.
+// Completing an occurrence of a recurring task creates the next occurrence,
+// which takes the recurrence rule over.
 func (s *TaskUseCase) MarkTaskCompleted(ctx context.Context, id uuid.UUID, userId uuid.UUID) (task.Task, error) {
-	t, err := s.taskRepository.GetByID(ctx, id)
+	t, err := s.getActiveTask(ctx, id)
 	if err != nil {
 		return task.Task{}, err
 	}
 
-	if t.UserID != userId {
-		return task.Task{}, ErrUnauthorizedAction
+	before := history.StateOf(t)
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionEdit)
+	if err != nil {
+		return task.Task{}, err
 	}
 
-	t.MarkCompleted()
+	// The next occurrence is saved with the completed task, or neither is.
+	completed := t
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The transaction may be retried, every attempt starts over.
+		completed = t
 
-	err = s.taskRepository.Update(ctx, t)
+		if !completed.Completed {
+			if next, ok := completed.NextOccurrence(); ok {
+				err := s.save(ctx, &next, userId)
+				if err != nil {
+					return err
+				}
+			}
+
+			if completed.Recurrence != nil {
+				completed.ClearRecurrence()
+			}
+		}
+
+		completed.MarkCompleted()
+
+		return s.update(ctx, before, &completed, userId, history.OpComplete)
+	})
 	if err != nil {
 		return task.Task{}, err
 	}
 
-	return t, nil
+	s.eventsAdded()
+
+	return completed, nil
 }
 
 // MarkTaskNotCompleted marks the task as NOT completed and saves it to the taskRepository.
 func (s *TaskUseCase) MarkTaskNotCompleted(ctx context.Context, id uuid.UUID, userId uuid.UUID) (task.Task, error) {
-	t, err := s.taskRepository.GetByID(ctx, id)
+	t, err := s.getActiveTask(ctx, id)
 	if err != nil {
 		return task.Task{}, err
 	}
 
-	if t.UserID != userId {
-		return task.Task{}, ErrUnauthorizedAction
+	before := history.StateOf(t)
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionEdit)
+	if err != nil {
+		return task.Task{}, err
 	}
 
 	t.MarkNotCompleted()
 
-	err = s.taskRepository.Update(ctx, t)
+	err = s.update(ctx, before, &t, userId, history.OpUncomplete)
 	if err != nil {
 		return task.Task{}, err
 	}
@@ -114,21 +497,656 @@ func (s *TaskUseCase) MarkTaskNotCompleted(ctx context.Context, id uuid.UUID, us
 	return t, nil
 }
 
-// DeleteTask deletes the task.
-func (s *TaskUseCase) DeleteTask(ctx context.Context, id uuid.UUID, userId uuid.UUID) error {
+// SetTaskParent moves the task under another task of the same list or, when parentId is nil, to the top level.
+func (s *TaskUseCase) SetTaskParent(ctx context.Context, id uuid.UUID, parentId *uuid.UUID, userId uuid.UUID) (task.Task, error) {
+	t, err := s.getActiveTask(ctx, id)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	before := history.StateOf(t)
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionEdit)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	if parentId == nil {
+		t.ClearParent()
+	} else {
+		err = t.SetParent(*parentId)
+		if err != nil {
+			return task.Task{}, err
+		}
+
+		err = s.checkParent(ctx, t, *parentId, userId)
+		if err != nil {
+			return task.Task{}, err
+		}
+	}
+
+	err = s.update(ctx, before, &t, userId, history.OpSetParent)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	return t, nil
+}
+
+// MoveTask moves the task in the manual order of its listing, the user's personal tasks or the tasks
+// of its list, right after the prev task or right before the next task. When both are given they must
+// be neighbours in the listing. The listing is rebalanced when there is no room left between them.
+func (s *TaskUseCase) MoveTask(ctx context.Context, id uuid.UUID, prevId *uuid.UUID, nextId *uuid.UUID, userId uuid.UUID) (task.Task, error) {
+	if prevId == nil && nextId == nil {
+		return task.Task{}, task.ErrInvalidMove
+	}
+
+	t, err := s.getActiveTask(ctx, id)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	before := history.StateOf(t)
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionEdit)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	listing, err := s.taskRepository.GetListing(ctx, t.UserID, t.ListID)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	others := slices.DeleteFunc(listing, func(o task.Task) bool {
+		return o.ID == t.ID
+	})
+
+	at, err := insertionIndex(others, prevId, nextId)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	var prev, next *task.Task
+	if at > 0 {
+		prev = &others[at-1]
+	}
+	if at < len(others) {
+		next = &others[at]
+	}
+
+	position, ok := task.PositionBetween(prev, next)
+	if !ok {
+		position, err = s.rebalance(ctx, others, at, userId)
+		if err != nil {
+			return task.Task{}, err
+		}
+	}
+
+	t.MoveTo(position)
+
+	err = s.update(ctx, before, &t, userId, history.OpMove)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	return t, nil
+}
+
+// GetTaskProgress returns how many of the task's subtasks, at any depth, are completed.
+func (s *TaskUseCase) GetTaskProgress(ctx context.Context, id uuid.UUID, userId uuid.UUID) (task.Progress, error) {
+	t, err := s.getActiveTask(ctx, id)
+	if err != nil {
+		return task.Progress{}, err
+	}
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionView)
+	if err != nil {
+		return task.Progress{}, err
+	}
+
+	subtasks, err := s.descendants(ctx, t.ID)
+	if err != nil {
+		return task.Progress{}, err
+	}
+
+	return task.NewProgress(subtasks), nil
+}
+
+// GetTaskHistory returns the changes made to the task, the oldest first. The history of a task in the trash is available too.
+func (s *TaskUseCase) GetTaskHistory(ctx context.Context, id uuid.UUID, userId uuid.UUID) ([]history.Event, error) {
+	t, err := s.taskRepository.GetByID(ctx, id)
+	if err != nil {
+		return []history.Event{}, err
+	}
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionView)
+	if err != nil {
+		return []history.Event{}, err
+	}
+
+	events, err := s.historyRepository.GetByTaskID(ctx, t.ID)
+	if err != nil {
+		return []history.Event{}, err
+	}
+
+	return events, nil
+}
+
+// DeleteTask moves the task to the trash and handles its subtasks according to the policy.
+// The task must be at the expected version, see task.AnyVersion.
+func (s *TaskUseCase) DeleteTask(ctx context.Context, id uuid.UUID, version int64, userId uuid.UUID, policy SubtaskPolicy) error {
+	t, err := s.getActiveTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionEdit)
+	if err != nil {
+		return err
+	}
+
+	err = t.CheckVersion(version)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	// The task and its subtasks are trashed or reparented together, or none of them is.
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		switch policy {
+		case ReparentSubtasks:
+			children, err := s.taskRepository.GetChildren(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+
+			for _, child := range children {
+				before := history.StateOf(child)
+				if t.ParentID == nil {
+					child.ClearParent()
+				} else if err := child.SetParent(*t.ParentID); err != nil {
+					return err
+				}
+
+				err = s.update(ctx, before, &child, userId, history.OpSetParent)
+				if err != nil {
+					return err
+				}
+			}
+		default:
+			subtasks, err := s.descendants(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+
+			// Trash the deepest subtasks first so no visible subtask is left without a parent.
+			// The whole subtree shares the deletion time so it can be restored together.
+			for i := len(subtasks) - 1; i >= 0; i-- {
+				err = s.moveToTrash(ctx, subtasks[i], now, userId)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return s.moveToTrash(ctx, t, now, userId)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.eventsAdded()
+
+	return nil
+}
+
+// GetTrashForUser returns the user's tasks in the trash, the most recently deleted first.
+func (s *TaskUseCase) GetTrashForUser(ctx context.Context, userId uuid.UUID) ([]task.Task, error) {
+	t, err := s.taskRepository.GetTrashByUserID(ctx, userId)
+	if err != nil {
+		return []task.Task{}, err
+	}
+
+	return t, nil
+}
+
+// RestoreTask takes the task out of the trash together with the subtasks deleted along with it.
+// If the parent of the task is no longer available, the task is restored to the top level.
+func (s *TaskUseCase) RestoreTask(ctx context.Context, id uuid.UUID, userId uuid.UUID) (task.Task, error) {
 	t, err := s.taskRepository.GetByID(ctx, id)
 	if err != nil {
+		return task.Task{}, err
+	}
+
+	err = s.policy.AuthorizeTask(ctx, t, userId, ActionEdit)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	if !t.IsTrashed() {
+		return task.Task{}, task.ErrTaskNotInTrash
+	}
+
+	deletedAt := *t.DeletedAt
+
+	subtasks, err := s.trashedDescendants(ctx, t.ID, deletedAt)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	toTopLevel := false
+	if t.ParentID != nil {
+		parent, err := s.getActiveTask(ctx, *t.ParentID)
+		if err != nil && !errors.Is(err, task.ErrTaskNotFound) {
+			return task.Task{}, err
+		}
+
+		toTopLevel = err != nil || !sameList(parent, t)
+	}
+
+	// The subtree is restored together, or not at all.
+	restored := t
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, ti := range append([]task.Task{t}, subtasks...) {
+			before := history.StateOf(ti)
+			if ti.ID == t.ID && toTopLevel {
+				ti.ClearParent()
+			}
+
+			err := ti.Restore()
+			if err != nil {
+				return err
+			}
+
+			err = s.update(ctx, before, &ti, userId, history.OpRestore)
+			if err != nil {
+				return err
+			}
+
+			if ti.ID == t.ID {
+				restored = ti
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	s.eventsAdded()
+
+	return restored, nil
+}
+
+// PurgeTrash permanently removes the tasks that have been in the trash longer than the retention period.
+func (s *TaskUseCase) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	n, err := s.taskRepository.PurgeTrash(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// getActiveTask returns the task unless it is in the trash, in which case task.ErrTaskNotFound is returned.
+func (s *TaskUseCase) getActiveTask(ctx context.Context, id uuid.UUID) (task.Task, error) {
+	t, err := s.taskRepository.GetByID(ctx, id)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	if t.IsTrashed() {
+		return task.Task{}, task.ErrTaskNotFound
+	}
+
+	return t, nil
+}
+
+func (s *TaskUseCase) moveToTrash(ctx context.Context, t task.Task, at time.Time, actorId uuid.UUID) error {
+	before := history.StateOf(t)
+
+	err := t.MoveToTrash(at)
+	if err != nil {
+		return err
+	}
+
+	return s.update(ctx, before, &t, actorId, history.OpDelete)
+}
+
+// create checks that the user can add the new task to its list and under its parent, and saves it.
+func (s *TaskUseCase) create(ctx context.Context, t *task.Task, userId uuid.UUID) error {
+	if t.ListID != nil {
+		err := s.policy.AuthorizeListID(ctx, *t.ListID, userId, ActionEdit)
+		if err != nil {
+			return err
+		}
+	}
+
+	if t.ParentID != nil {
+		err := s.checkParent(ctx, *t, *t.ParentID, userId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.save(ctx, t, userId)
+}
+
+// save saves the new task, starts its history and adds its creation to the outbox in a transaction.
+func (s *TaskUseCase) save(ctx context.Context, t *task.Task, actorId uuid.UUID) error {
+	saved := *t
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The transaction may be retried, every attempt starts over.
+		saved = *t
+
+		err := s.taskRepository.Save(ctx, saved)
+		if err != nil {
+			return err
+		}
+
+		err = s.addEvent(ctx, &saved, actorId)
+		if err != nil {
+			return err
+		}
+
+		return s.record(ctx, saved, actorId, history.OpCreate, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	*t = saved
+	s.eventsAdded()
+
+	return nil
+}
+
+// update saves the changed task, appends the change to its history and adds it to the outbox
+// in a transaction. The version of the task is brought in line with the stored one.
+func (s *TaskUseCase) update(ctx context.Context, before history.State, t *task.Task, actorId uuid.UUID, op history.Operation) error {
+	updated := *t
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The transaction may be retried, every attempt starts over.
+		updated = *t
+
+		err := s.taskRepository.Update(ctx, updated)
+		if err != nil {
+			return err
+		}
+
+		updated.Version++
+
+		err = s.addEvent(ctx, &updated, actorId)
+		if err != nil {
+			return err
+		}
+
+		return s.record(ctx, updated, actorId, op, &before)
+	})
+	if err != nil {
+		return err
+	}
+
+	*t = updated
+	s.eventsAdded()
+
+	return nil
+}
+
+// addEvent adds the event reporting the change made to the task, if any, to the outbox.
+func (s *TaskUseCase) addEvent(ctx context.Context, t *task.Task, actorId uuid.UUID) error {
+	e, ok := t.PullEvent(actorId)
+	if !ok {
+		return nil
+	}
+
+	return s.outboxRepository.Add(ctx, e)
+}
+
+// record appends the change of the task to its history. A nil before records the task creation.
+func (s *TaskUseCase) record(ctx context.Context, t task.Task, actorId uuid.UUID, op history.Operation, before *history.State) error {
+	after := history.StateOf(t)
+
+	e, err := history.NewEvent(t.ID, actorId, op, before, &after)
+	if err != nil {
+		return err
+	}
+
+	return s.historyRepository.Append(ctx, e)
+}
+
+// rebalance spreads the positions of the ordered tasks apart, leaving room for a task at the given index,
+// and returns the position of that room. The moved tasks are saved in a transaction without recording
+// their history since the user has not changed them, their changes are still added to the outbox.
+func (s *TaskUseCase) rebalance(ctx context.Context, tasks []task.Task, at int, actorId uuid.UUID) (float64, error) {
+	var position float64
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The zero task holds the room, it is not saved.
+		ordered := slices.Insert(slices.Clone(tasks), at, task.Task{})
+
+		for _, moved := range task.Rebalance(ordered) {
+			if moved.ID == uuid.Nil {
+				continue
+			}
+
+			err := s.taskRepository.Update(ctx, moved)
+			if err != nil {
+				return err
+			}
+
+			moved.Version++
+
+			err = s.addEvent(ctx, &moved, actorId)
+			if err != nil {
+				return err
+			}
+		}
+
+		position = ordered[at].Position
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.eventsAdded()
+
+	return position, nil
+}
+
+// tasksWithTag returns the user's personal tasks, the trashed ones included, having the tag.
+func (s *TaskUseCase) tasksWithTag(ctx context.Context, userId uuid.UUID, tag string) ([]task.Task, error) {
+	active, err := s.taskRepository.GetAllByUserID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	trashed, err := s.taskRepository.GetTrashByUserID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagged []task.Task
+	for _, t := range append(active, trashed...) {
+		if t.ListID == nil && t.HasTag(tag) {
+			tagged = append(tagged, t)
+		}
+	}
+
+	return tagged, nil
+}
+
+// checkParent checks that the parent is in the same list as the task and can be changed by the user,
+// and that moving the task under it neither creates a cycle nor makes the tree deeper than task.MaxDepth.
+func (s *TaskUseCase) checkParent(ctx context.Context, t task.Task, parentId uuid.UUID, userId uuid.UUID) error {
+	parent, err := s.getActiveTask(ctx, parentId)
+	if err != nil {
+		if errors.Is(err, task.ErrTaskNotFound) {
+			return task.ErrInvalidParent
+		}
+
 		return err
 	}
 
-	if t.UserID != userId {
-		return ErrUnauthorizedAction
+	if !sameList(parent, t) {
+		return task.ErrInvalidParent
 	}
 
-	err = s.taskRepository.Delete(ctx, id)
+	err = s.policy.AuthorizeTask(ctx, parent, userId, ActionEdit)
 	if err != nil {
 		return err
 	}
 
+	depth := 1
+	for current := parent; current.ParentID != nil; depth++ {
+		if *current.ParentID == t.ID {
+			return task.ErrParentCycle
+		}
+
+		if depth >= task.MaxDepth {
+			return task.ErrMaxDepthExceeded
+		}
+
+		current, err = s.taskRepository.GetByID(ctx, *current.ParentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	height, err := s.subtreeHeight(ctx, t.ID, depth+1)
+	if err != nil {
+		return err
+	}
+
+	if depth+height > task.MaxDepth {
+		return task.ErrMaxDepthExceeded
+	}
+
 	return nil
 }
+
+// subtreeHeight returns the number of levels in the subtree of the task, the task included.
+func (s *TaskUseCase) subtreeHeight(ctx context.Context, id uuid.UUID, level int) (int, error) {
+	if level > task.MaxDepth {
+		return 0, task.ErrMaxDepthExceeded
+	}
+
+	children, err := s.taskRepository.GetChildren(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	height := 1
+	for _, child := range children {
+		h, err := s.subtreeHeight(ctx, child.ID, level+1)
+		if err != nil {
+			return 0, err
+		}
+
+		height = max(height, h+1)
+	}
+
+	return height, nil
+}
+
+// descendants returns all subtasks of the task not in the trash, level by level.
+func (s *TaskUseCase) descendants(ctx context.Context, id uuid.UUID) ([]task.Task, error) {
+	var result []task.Task
+
+	level := []uuid.UUID{id}
+	for depth := 0; len(level) > 0 && depth < task.MaxDepth; depth++ {
+		var next []uuid.UUID
+		for _, parentId := range level {
+			children, err := s.taskRepository.GetChildren(ctx, parentId)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, child := range children {
+				if child.IsTrashed() {
+					continue
+				}
+
+				result = append(result, child)
+				next = append(next, child.ID)
+			}
+		}
+
+		level = next
+	}
+
+	return result, nil
+}
+
+func sameList(a, b task.Task) bool {
+	if a.ListID == nil || b.ListID == nil {
+		return a.ListID == nil && b.ListID == nil
+	}
+
+	return *a.ListID == *b.ListID
+}
+
+// trashedDescendants returns the subtasks of the task moved to the trash at the given time, level by level.
+func (s *TaskUseCase) trashedDescendants(ctx context.Context, id uuid.UUID, deletedAt time.Time) ([]task.Task, error) {
+	var result []task.Task
+
+	level := []uuid.UUID{id}
+	for depth := 0; len(level) > 0 && depth < task.MaxDepth; depth++ {
+		var next []uuid.UUID
+		for _, parentId := range level {
+			children, err := s.taskRepository.GetChildren(ctx, parentId)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, child := range children {
+				if !child.IsTrashed() || !child.DeletedAt.Equal(deletedAt) {
+					continue
+				}
+
+				result = append(result, child)
+				next = append(next, child.ID)
+			}
+		}
+
+		level = next
+	}
+
+	return result, nil
+}
+
+// insertionIndex returns the index in the ordered tasks a task moved right after prevId
+// or right before nextId goes to.
+func insertionIndex(tasks []task.Task, prevId *uuid.UUID, nextId *uuid.UUID) (int, error) {
+	indexOf := func(id uuid.UUID) int {
+		return slices.IndexFunc(tasks, func(t task.Task) bool {
+			return t.ID == id
+		})
+	}
+
+	at := -1
+	if prevId != nil {
+		i := indexOf(*prevId)
+		if i < 0 {
+			return 0, task.ErrInvalidMove
+		}
+		at = i + 1
+	}
+
+	if nextId != nil {
+		i := indexOf(*nextId)
+		if i < 0 || (at >= 0 && at != i) {
+			return 0, task.ErrInvalidMove
+		}
+		at = i
+	}
+
+	return at, nil
+}
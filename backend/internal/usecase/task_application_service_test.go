@@ -3,15 +3,34 @@ package usecase_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/history"
 	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	historyRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/history/memory"
+	listRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/memory"
+	outboxRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/memory"
 	repo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/memory"
+	transaction "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/transaction/memory"
 	"github.com/ozaitsev92/tododdd/internal/usecase"
 )
 
+// newTaskUseCase creates a TaskUseCase saving the tasks to the repository, with the history and the outbox
+// in memory, and returns it together with the outbox.
+func newTaskUseCase(taskRepository *repo.Repository, policy *usecase.AccessPolicy) (*usecase.TaskUseCase, *outboxRepo.Repository) {
+	historyRepository := historyRepo.NewRepository(config.Config{})
+	outboxRepository := outboxRepo.NewRepository(config.Config{})
+	transactor := transaction.NewTransactor(taskRepository, historyRepository, outboxRepository)
+
+	return usecase.NewTaskUseCase(taskRepository, historyRepository, outboxRepository, transactor, policy), outboxRepository
+}
+
 func TestTaskUseCaseCreate(t *testing.T) {
 	type args struct {
 		text   string
@@ -64,7 +83,7 @@ func TestTaskUseCaseCreate(t *testing.T) {
 			t.Parallel()
 			repo := repo.NewRepository(config.Config{})
 
-			s := usecase.NewTaskUseCase(repo)
+			s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
 			newTask, err := s.CreateTask(context.Background(), tt.args.text, tt.args.userId)
 
 			if !errors.Is(err, tt.wantErr) {
@@ -143,7 +162,7 @@ func TestTaskGetAllTasksForUser(t *testing.T) {
 				}
 			}
 
-			s := usecase.NewTaskUseCase(repo)
+			s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
 			allTasks, err := s.GetAllTasksForUser(context.Background(), tt.userId)
 			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("s.GetAllTasksForUser() error = %v, wantErr %v", err, tt.wantErr)
@@ -155,6 +174,94 @@ func TestTaskGetAllTasksForUser(t *testing.T) {
 	}
 }
 
+func TestTaskUseCaseFindTasksForUser(t *testing.T) {
+	type testCase struct {
+		name     string
+		tasks    []task.Task
+		userId   uuid.UUID
+		limit    int
+		sortBy   task.SortField
+		want     []string
+		wantNext bool
+		wantErr  error
+	}
+
+	tests := []testCase{
+		{
+			name: "Success",
+			tasks: []task.Task{
+				{
+					ID:     uuid.New(),
+					Text:   "b",
+					UserID: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				},
+				{
+					ID:     uuid.New(),
+					Text:   "a",
+					UserID: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				},
+				{
+					ID:     uuid.New(),
+					Text:   "c",
+					UserID: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				},
+				{
+					ID:     uuid.New(),
+					Text:   "a",
+					UserID: uuid.MustParse("842efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				},
+			},
+			userId:   uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			limit:    2,
+			sortBy:   task.SortByText,
+			want:     []string{"a", "b"},
+			wantNext: true,
+			wantErr:  nil,
+		},
+		{
+			name:    "Invalid limit",
+			userId:  uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			limit:   -1,
+			sortBy:  task.SortByText,
+			wantErr: task.ErrInvalidLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := repo.NewRepository(config.Config{})
+
+			for _, aTask := range tt.tasks {
+				if err := repo.Save(context.Background(), aTask); err != nil {
+					t.Error(err)
+				}
+			}
+
+			q := task.NewQuery(uuid.Nil)
+			q.Limit = tt.limit
+			q.SortBy = tt.sortBy
+
+			s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+			page, err := s.FindTasksForUser(context.Background(), q, tt.userId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("s.FindTasksForUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(page.Tasks) != len(tt.want) {
+				t.Fatalf("s.FindTasksForUser() number of tasks = %v, expected %v", len(page.Tasks), len(tt.want))
+			}
+			for i := range tt.want {
+				if page.Tasks[i].Text != tt.want[i] {
+					t.Errorf("s.FindTasksForUser() Text = %v, want %v", page.Tasks[i].Text, tt.want[i])
+				}
+			}
+			if err == nil && (page.NextCursor != "") != tt.wantNext {
+				t.Errorf("s.FindTasksForUser() NextCursor = %v, want next %v", page.NextCursor, tt.wantNext)
+			}
+		})
+	}
+}
+
 func TestTaskUseCaseUpdateTask(t *testing.T) {
 	type testCase struct {
 		name    string
@@ -190,8 +297,8 @@ func TestTaskUseCaseUpdateTask(t *testing.T) {
 				t.Error(err)
 			}
 
-			s := usecase.NewTaskUseCase(repo)
-			updatedTask, err := s.UpdateTask(context.Background(), tt.task.ID, tt.want.Text, tt.task.UserID)
+			s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+			updatedTask, err := s.UpdateTask(context.Background(), tt.task.ID, task.AnyVersion, tt.want.Text, tt.task.UserID)
 
 			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("s.UpdateTask() error = %v, wantErr %v", err, tt.wantErr)
@@ -209,6 +316,130 @@ func TestTaskUseCaseUpdateTask(t *testing.T) {
 	}
 }
 
+func TestTaskUseCaseGetOverdueAndDueSoonTasks(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+	now := time.Now()
+
+	repo := repo.NewRepository(config.Config{})
+	s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	overdue, err := s.CreateTask(context.Background(), "overdue", userId, task.WithDueDate(now.Add(-time.Hour)))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	dueSoon, err := s.CreateTask(context.Background(), "due soon", userId, task.WithDueDate(now.Add(time.Hour)), task.WithReminder(time.Minute))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	tasks, err := s.GetOverdueTasksForUser(context.Background(), userId)
+	if err != nil {
+		t.Errorf("s.GetOverdueTasksForUser() error = %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != overdue.ID {
+		t.Errorf("s.GetOverdueTasksForUser() got = %v, want %v", tasks, []task.Task{overdue})
+	}
+
+	tasks, err = s.GetDueSoonTasksForUser(context.Background(), userId, 2*time.Hour)
+	if err != nil {
+		t.Errorf("s.GetDueSoonTasksForUser() error = %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != dueSoon.ID {
+		t.Errorf("s.GetDueSoonTasksForUser() got = %v, want %v", tasks, []task.Task{dueSoon})
+	}
+
+	_, err = s.GetDueSoonTasksForUser(context.Background(), userId, 0)
+	if !errors.Is(err, usecase.ErrInvalidDueWindow) {
+		t.Errorf("s.GetDueSoonTasksForUser() error = %v, wantErr %v", err, usecase.ErrInvalidDueWindow)
+	}
+}
+
+func TestTaskUseCaseSetTaskDueDate(t *testing.T) {
+	dueAt := time.Now().Add(time.Hour)
+	offset := 10 * time.Minute
+
+	type testCase struct {
+		name           string
+		task           task.Task
+		userId         uuid.UUID
+		dueAt          *time.Time
+		reminderOffset *time.Duration
+		wantErr        error
+	}
+
+	tests := []testCase{
+		{
+			name: "Success",
+			task: task.Task{
+				ID:     uuid.New(),
+				Text:   "test",
+				UserID: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			},
+			userId:         uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			dueAt:          &dueAt,
+			reminderOffset: &offset,
+			wantErr:        nil,
+		},
+		{
+			name: "Clear",
+			task: task.Task{
+				ID:     uuid.New(),
+				Text:   "test",
+				UserID: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+				DueAt:  &dueAt,
+			},
+			userId:  uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			wantErr: nil,
+		},
+		{
+			name: "Reminder without due date",
+			task: task.Task{
+				ID:     uuid.New(),
+				Text:   "test",
+				UserID: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			},
+			userId:         uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			reminderOffset: &offset,
+			wantErr:        task.ErrReminderWithoutDueDate,
+		},
+		{
+			name: "Another user",
+			task: task.Task{
+				ID:     uuid.New(),
+				Text:   "test",
+				UserID: uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			},
+			userId:  uuid.MustParse("842efa19-5926-4cac-8ca5-2fcb7d8056b1"),
+			dueAt:   &dueAt,
+			wantErr: usecase.ErrUnauthorizedAction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := repo.NewRepository(config.Config{})
+
+			if err := repo.Save(context.Background(), tt.task); err != nil {
+				t.Error(err)
+			}
+
+			s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+			updatedTask, err := s.SetTaskDueDate(context.Background(), tt.task.ID, tt.dueAt, tt.reminderOffset, tt.userId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("s.SetTaskDueDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (updatedTask.DueAt == nil) != (tt.dueAt == nil) {
+				t.Errorf("s.SetTaskDueDate() DueAt = %v, want %v", updatedTask.DueAt, tt.dueAt)
+			}
+			if err == nil && (updatedTask.ReminderOffset == nil) != (tt.reminderOffset == nil) {
+				t.Errorf("s.SetTaskDueDate() ReminderOffset = %v, want %v", updatedTask.ReminderOffset, tt.reminderOffset)
+			}
+		})
+	}
+}
+
 func TestTaskUseCaseMarkTaskCompleted(t *testing.T) {
 	type testCase struct {
 		name    string
@@ -244,7 +475,7 @@ func TestTaskUseCaseMarkTaskCompleted(t *testing.T) {
 				t.Error(err)
 			}
 
-			s := usecase.NewTaskUseCase(repo)
+			s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
 			updatedTask, err := s.MarkTaskCompleted(context.Background(), tt.task.ID, tt.task.UserID)
 
 			if !errors.Is(err, tt.wantErr) {
@@ -298,7 +529,7 @@ func TestTaskUseCaseMarkTaskNotCompleted(t *testing.T) {
 				t.Error(err)
 			}
 
-			s := usecase.NewTaskUseCase(repo)
+			s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
 			updatedTask, err := s.MarkTaskNotCompleted(context.Background(), tt.task.ID, tt.task.UserID)
 
 			if !errors.Is(err, tt.wantErr) {
@@ -321,7 +552,6 @@ func TestTaskUseCaseDeleteTask(t *testing.T) {
 	type testCase struct {
 		name    string
 		task    task.Task
-		want    task.Task
 		wantErr error
 	}
 
@@ -334,7 +564,6 @@ func TestTaskUseCaseDeleteTask(t *testing.T) {
 				Completed: false,
 				UserID:    uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1"),
 			},
-			want:    task.Task{},
 			wantErr: nil,
 		},
 	}
@@ -348,20 +577,826 @@ func TestTaskUseCaseDeleteTask(t *testing.T) {
 				t.Error(err)
 			}
 
-			s := usecase.NewTaskUseCase(repo)
-			err := s.DeleteTask(context.Background(), tt.task.ID, tt.task.UserID)
+			s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+			err := s.DeleteTask(context.Background(), tt.task.ID, task.AnyVersion, tt.task.UserID, usecase.DeleteSubtasks)
 
 			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("s.DeleteTask() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
 			deletedTask, err := repo.GetByID(context.Background(), tt.task.ID)
-			if !errors.Is(err, task.ErrTaskNotFound) {
-				t.Errorf("s.DeleteTask() error = %v, wantErr %v", err, tt.wantErr)
+			if err != nil {
+				t.Errorf("repo.GetByID() error = %v", err)
+			}
+			if err == nil && !deletedTask.IsTrashed() {
+				t.Errorf("s.DeleteTask() DeletedAt = %v, want the task in the trash", deletedTask.DeletedAt)
+			}
+
+			tasks, err := s.GetAllTasksForUser(context.Background(), tt.task.UserID)
+			if err != nil {
+				t.Errorf("s.GetAllTasksForUser() error = %v", err)
 			}
-			if err == nil && deletedTask != tt.want {
-				t.Errorf("s.DeleteTask() Text = %v, want %v", deletedTask, tt.want)
+			if len(tasks) != 0 {
+				t.Errorf("s.GetAllTasksForUser() got = %v, want no tasks", tasks)
+			}
+
+			_, err = s.UpdateTask(context.Background(), tt.task.ID, task.AnyVersion, "changed", tt.task.UserID)
+			if !errors.Is(err, task.ErrTaskNotFound) {
+				t.Errorf("s.UpdateTask() error = %v, wantErr %v", err, task.ErrTaskNotFound)
 			}
 		})
 	}
 }
+
+func TestTaskUseCaseSubtasks(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	repo := repo.NewRepository(config.Config{})
+	s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	// Build a chain of task.MaxDepth levels
+	var chain []task.Task
+	for i := 0; i < task.MaxDepth; i++ {
+		var opts []task.Option
+		if i > 0 {
+			opts = append(opts, task.WithParent(chain[i-1].ID))
+		}
+
+		ti, err := s.CreateTask(context.Background(), fmt.Sprintf("level %d", i+1), userId, opts...)
+		if err != nil {
+			t.Fatalf("s.CreateTask() error = %v", err)
+		}
+		chain = append(chain, ti)
+	}
+
+	// One more level is too deep
+	_, err := s.CreateTask(context.Background(), "too deep", userId, task.WithParent(chain[task.MaxDepth-1].ID))
+	if !errors.Is(err, task.ErrMaxDepthExceeded) {
+		t.Errorf("s.CreateTask() error = %v, wantErr %v", err, task.ErrMaxDepthExceeded)
+	}
+
+	// A task cannot be moved under its own subtask
+	_, err = s.SetTaskParent(context.Background(), chain[0].ID, &chain[2].ID, userId)
+	if !errors.Is(err, task.ErrParentCycle) {
+		t.Errorf("s.SetTaskParent() error = %v, wantErr %v", err, task.ErrParentCycle)
+	}
+
+	// A subtree cannot be moved under a task of another user
+	other, err := s.CreateTask(context.Background(), "other", uuid.New())
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	_, err = s.CreateTask(context.Background(), "foreign parent", userId, task.WithParent(other.ID))
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("s.CreateTask() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	// Progress counts all subtasks
+	_, err = s.MarkTaskCompleted(context.Background(), chain[1].ID, userId)
+	if err != nil {
+		t.Fatalf("s.MarkTaskCompleted() error = %v", err)
+	}
+
+	progress, err := s.GetTaskProgress(context.Background(), chain[0].ID, userId)
+	if err != nil {
+		t.Errorf("s.GetTaskProgress() error = %v", err)
+	}
+	if progress.Completed != 1 || progress.Total != task.MaxDepth-1 {
+		t.Errorf("s.GetTaskProgress() got = %v, want %v", progress, task.Progress{Completed: 1, Total: task.MaxDepth - 1})
+	}
+
+	// Reparenting moves the children to the grandparent
+	err = s.DeleteTask(context.Background(), chain[1].ID, task.AnyVersion, userId, usecase.ReparentSubtasks)
+	if err != nil {
+		t.Errorf("s.DeleteTask() error = %v", err)
+	}
+
+	moved, err := repo.GetByID(context.Background(), chain[2].ID)
+	if err != nil {
+		t.Errorf("repo.GetByID() error = %v", err)
+	}
+	if moved.ParentID == nil || *moved.ParentID != chain[0].ID {
+		t.Errorf("s.DeleteTask() ParentID = %v, want %v", moved.ParentID, chain[0].ID)
+	}
+
+	// Cascading trashes the whole subtree
+	err = s.DeleteTask(context.Background(), chain[0].ID, task.AnyVersion, userId, usecase.DeleteSubtasks)
+	if err != nil {
+		t.Errorf("s.DeleteTask() error = %v", err)
+	}
+
+	for _, ti := range chain {
+		if found, err := repo.GetByID(context.Background(), ti.ID); err != nil || !found.IsTrashed() {
+			t.Errorf("s.DeleteTask() task %v must be in the trash, error = %v", ti.Text, err)
+		}
+	}
+
+	// Restoring brings back the subtree deleted together, but not the subtask deleted before
+	_, err = s.RestoreTask(context.Background(), chain[0].ID, userId)
+	if err != nil {
+		t.Errorf("s.RestoreTask() error = %v", err)
+	}
+
+	for i, ti := range chain {
+		found, err := repo.GetByID(context.Background(), ti.ID)
+		if err != nil {
+			t.Errorf("repo.GetByID() error = %v", err)
+		}
+		if found.IsTrashed() != (i == 1) {
+			t.Errorf("s.RestoreTask() task %v IsTrashed() = %v, want %v", ti.Text, found.IsTrashed(), i == 1)
+		}
+	}
+}
+
+func TestTaskUseCaseTrash(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	repo := repo.NewRepository(config.Config{})
+	s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	parent, err := s.CreateTask(context.Background(), "parent", userId)
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	child, err := s.CreateTask(context.Background(), "child", userId, task.WithParent(parent.ID))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	_, err = s.RestoreTask(context.Background(), child.ID, userId)
+	if !errors.Is(err, task.ErrTaskNotInTrash) {
+		t.Errorf("s.RestoreTask() error = %v, wantErr %v", err, task.ErrTaskNotInTrash)
+	}
+
+	// The child is restored to the top level while its parent is in the trash
+	err = s.DeleteTask(context.Background(), child.ID, task.AnyVersion, userId, usecase.DeleteSubtasks)
+	if err != nil {
+		t.Errorf("s.DeleteTask() error = %v", err)
+	}
+
+	err = s.DeleteTask(context.Background(), parent.ID, task.AnyVersion, userId, usecase.DeleteSubtasks)
+	if err != nil {
+		t.Errorf("s.DeleteTask() error = %v", err)
+	}
+
+	trash, err := s.GetTrashForUser(context.Background(), userId)
+	if err != nil {
+		t.Errorf("s.GetTrashForUser() error = %v", err)
+	}
+	if len(trash) != 2 || trash[0].ID != parent.ID {
+		t.Errorf("s.GetTrashForUser() got = %v, want the parent first", trash)
+	}
+
+	_, err = s.RestoreTask(context.Background(), child.ID, uuid.New())
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("s.RestoreTask() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	restored, err := s.RestoreTask(context.Background(), child.ID, userId)
+	if err != nil {
+		t.Errorf("s.RestoreTask() error = %v", err)
+	}
+	if restored.IsTrashed() || restored.ParentID != nil {
+		t.Errorf("s.RestoreTask() got = %v, want a top-level task out of the trash", restored)
+	}
+
+	// Purging removes only the tasks older than the retention period
+	n, err := s.PurgeTrash(context.Background(), time.Hour)
+	if err != nil || n != 0 {
+		t.Errorf("s.PurgeTrash() got = %v, error = %v, want %v", n, err, 0)
+	}
+
+	n, err = s.PurgeTrash(context.Background(), 0)
+	if err != nil || n != 1 {
+		t.Errorf("s.PurgeTrash() got = %v, error = %v, want %v", n, err, 1)
+	}
+
+	_, err = repo.GetByID(context.Background(), parent.ID)
+	if !errors.Is(err, task.ErrTaskNotFound) {
+		t.Errorf("s.PurgeTrash() error = %v, wantErr %v", err, task.ErrTaskNotFound)
+	}
+}
+
+func TestTaskUseCaseTags(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	repo := repo.NewRepository(config.Config{})
+	s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	ti, err := s.CreateTask(context.Background(), "test", userId, task.WithTags("Job"))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	_, err = s.SetTaskTags(context.Background(), ti.ID, []string{"job"}, uuid.New())
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("s.SetTaskTags() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	ti, err = s.SetTaskTags(context.Background(), ti.ID, []string{"Job", "Home"}, userId)
+	if err != nil {
+		t.Errorf("s.SetTaskTags() error = %v", err)
+	}
+	if !reflect.DeepEqual(ti.Tags, []string{"home", "job"}) {
+		t.Errorf("s.SetTaskTags() got = %v, want %v", ti.Tags, []string{"home", "job"})
+	}
+
+	// A task the user added to a list they may no longer edit
+	listed, err := task.NewTask("listed", userId, task.WithList(uuid.New()), task.WithTags("job"))
+	if err != nil {
+		t.Fatalf("task.NewTask() error = %v", err)
+	}
+	err = repo.Save(context.Background(), listed)
+	if err != nil {
+		t.Fatalf("repo.Save() error = %v", err)
+	}
+
+	n, err := s.RenameTag(context.Background(), " JOB ", "Work", userId)
+	if err != nil {
+		t.Errorf("s.RenameTag() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("s.RenameTag() got = %v, want %v", n, 1)
+	}
+
+	found, err := repo.GetByID(context.Background(), listed.ID)
+	if err != nil || !reflect.DeepEqual(found.Tags, []string{"job"}) || found.Version != listed.Version {
+		t.Errorf("s.RenameTag() changed the task of a list: got = %v, error = %v", found.Tags, err)
+	}
+
+	q := task.NewQuery(userId)
+	q.Tags = []string{"Work"}
+
+	page, err := s.FindTasksForUser(context.Background(), q, userId)
+	if err != nil {
+		t.Errorf("s.FindTasksForUser() error = %v", err)
+	}
+	if len(page.Tasks) != 1 || page.Tasks[0].ID != ti.ID {
+		t.Errorf("s.FindTasksForUser() got = %v, want %v", page.Tasks, ti.ID)
+	}
+
+	tags, err := s.GetTagsForUser(context.Background(), userId)
+	if err != nil {
+		t.Errorf("s.GetTagsForUser() error = %v", err)
+	}
+	if want := []task.TagCount{{Tag: "home", Count: 1}, {Tag: "work", Count: 1}}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("s.GetTagsForUser() got = %v, want %v", tags, want)
+	}
+}
+
+func TestTaskUseCaseSearch(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	repo := repo.NewRepository(config.Config{})
+	s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	ti, err := s.CreateTask(context.Background(), "Buy milk", userId)
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	_, err = s.SearchTasksForUser(context.Background(), task.NewSearchQuery(uuid.Nil, "   "), userId)
+	if !errors.Is(err, task.ErrInvalidSearchText) {
+		t.Errorf("s.SearchTasksForUser() error = %v, wantErr %v", err, task.ErrInvalidSearchText)
+	}
+
+	results, err := s.SearchTasksForUser(context.Background(), task.NewSearchQuery(uuid.Nil, "MILK"), userId)
+	if err != nil {
+		t.Errorf("s.SearchTasksForUser() error = %v", err)
+	}
+
+	if len(results) != 1 || results[0].Task.ID != ti.ID {
+		t.Fatalf("s.SearchTasksForUser() got = %v, want %v", results, ti.ID)
+	}
+
+	want := []task.Fragment{{Text: "Buy ", Match: false}, {Text: "milk", Match: true}}
+	if !reflect.DeepEqual(results[0].Highlights, want) {
+		t.Errorf("s.SearchTasksForUser() Highlights = %v, want %v", results[0].Highlights, want)
+	}
+}
+
+func TestTaskUseCaseRecurring(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+	dueAt := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+
+	repo := repo.NewRepository(config.Config{})
+	s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	ti, err := s.CreateTask(context.Background(), "water the plants", userId, task.WithDueDate(dueAt))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	_, err = s.SetTaskRecurrence(context.Background(), ti.ID, new(task.Recurrence), userId)
+	if !errors.Is(err, task.ErrInvalidRecurrence) {
+		t.Errorf("s.SetTaskRecurrence() error = %v, wantErr %v", err, task.ErrInvalidRecurrence)
+	}
+
+	rule := task.MustParseRecurrence("FREQ=WEEKLY;BYDAY=MO,TH;COUNT=2")
+	ti, err = s.SetTaskRecurrence(context.Background(), ti.ID, &rule, userId)
+	if err != nil {
+		t.Fatalf("s.SetTaskRecurrence() error = %v", err)
+	}
+
+	occurrences, err := s.PreviewTaskOccurrences(context.Background(), ti.ID, 5, userId)
+	if err != nil {
+		t.Errorf("s.PreviewTaskOccurrences() error = %v", err)
+	}
+	if want := []time.Time{dueAt.AddDate(0, 0, 3)}; !reflect.DeepEqual(occurrences, want) {
+		t.Errorf("s.PreviewTaskOccurrences() got = %v, want %v", occurrences, want)
+	}
+
+	_, err = s.PreviewTaskOccurrences(context.Background(), ti.ID, usecase.MaxOccurrencesPreview+1, userId)
+	if !errors.Is(err, usecase.ErrInvalidOccurrences) {
+		t.Errorf("s.PreviewTaskOccurrences() error = %v, wantErr %v", err, usecase.ErrInvalidOccurrences)
+	}
+
+	// Completing the first occurrence creates the last one
+	completed, err := s.MarkTaskCompleted(context.Background(), ti.ID, userId)
+	if err != nil {
+		t.Errorf("s.MarkTaskCompleted() error = %v", err)
+	}
+	if !completed.Completed || completed.Recurrence != nil {
+		t.Errorf("s.MarkTaskCompleted() got = %v, want a completed one-off task", completed)
+	}
+
+	tasks, err := s.GetAllTasksForUser(context.Background(), userId)
+	if err != nil {
+		t.Errorf("s.GetAllTasksForUser() error = %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("s.GetAllTasksForUser() got = %v, want 2 tasks", len(tasks))
+	}
+
+	var next task.Task
+	for _, tt := range tasks {
+		if tt.ID != ti.ID {
+			next = tt
+		}
+	}
+	if next.Completed || next.DueAt == nil || !next.DueAt.Equal(dueAt.AddDate(0, 0, 3)) {
+		t.Errorf("s.MarkTaskCompleted() next occurrence = %v", next)
+	}
+
+	// Completing the task again does not repeat it
+	_, err = s.MarkTaskNotCompleted(context.Background(), ti.ID, userId)
+	if err != nil {
+		t.Errorf("s.MarkTaskNotCompleted() error = %v", err)
+	}
+	_, err = s.MarkTaskCompleted(context.Background(), ti.ID, userId)
+	if err != nil {
+		t.Errorf("s.MarkTaskCompleted() error = %v", err)
+	}
+
+	// Completing the last occurrence ends the series
+	_, err = s.MarkTaskCompleted(context.Background(), next.ID, userId)
+	if err != nil {
+		t.Errorf("s.MarkTaskCompleted() error = %v", err)
+	}
+
+	tasks, err = s.GetAllTasksForUser(context.Background(), userId)
+	if err != nil {
+		t.Errorf("s.GetAllTasksForUser() error = %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("s.GetAllTasksForUser() got = %v, want 2 tasks", len(tasks))
+	}
+}
+
+// failingUpdateRepository is a task repository failing to update the tasks.
+type failingUpdateRepository struct {
+	*repo.Repository
+	err error
+}
+
+func (r *failingUpdateRepository) Update(context.Context, task.Task) error {
+	return r.err
+}
+
+func TestTaskUseCaseRecurringFailed(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+	dueAt := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+
+	taskRepository := repo.NewRepository(config.Config{})
+	policy := usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{}))
+	s, _ := newTaskUseCase(taskRepository, policy)
+
+	rule := task.MustParseRecurrence("FREQ=DAILY")
+	ti, err := s.CreateTask(context.Background(), "water the plants", userId, task.WithDueDate(dueAt), task.WithRecurrence(rule))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	historyRepository := historyRepo.NewRepository(config.Config{})
+	outboxRepository := outboxRepo.NewRepository(config.Config{})
+	failing := usecase.NewTaskUseCase(
+		&failingUpdateRepository{taskRepository, task.ErrVersionMismatch},
+		historyRepository,
+		outboxRepository,
+		transaction.NewTransactor(taskRepository, historyRepository, outboxRepository),
+		policy,
+	)
+
+	_, err = failing.MarkTaskCompleted(context.Background(), ti.ID, userId)
+	if !errors.Is(err, task.ErrVersionMismatch) {
+		t.Fatalf("s.MarkTaskCompleted() error = %v, wantErr %v", err, task.ErrVersionMismatch)
+	}
+
+	// The next occurrence is not left behind
+	tasks, err := s.GetAllTasksForUser(context.Background(), userId)
+	if err != nil {
+		t.Errorf("s.GetAllTasksForUser() error = %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Completed {
+		t.Errorf("s.GetAllTasksForUser() got = %v, want the task not completed alone", tasks)
+	}
+}
+
+// failingTaskUpdateRepository is a task repository failing to update one task.
+type failingTaskUpdateRepository struct {
+	*repo.Repository
+	id  uuid.UUID
+	err error
+}
+
+func (r *failingTaskUpdateRepository) Update(ctx context.Context, t task.Task) error {
+	if t.ID == r.id {
+		return r.err
+	}
+
+	return r.Repository.Update(ctx, t)
+}
+
+func TestTaskUseCaseTrashFailed(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	taskRepository := repo.NewRepository(config.Config{})
+	policy := usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{}))
+	s, _ := newTaskUseCase(taskRepository, policy)
+
+	parent, err := s.CreateTask(context.Background(), "parent", userId)
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	child, err := s.CreateTask(context.Background(), "child", userId, task.WithParent(parent.ID))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	_, err = s.CreateTask(context.Background(), "grandchild", userId, task.WithParent(child.ID))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	failingOn := func(id uuid.UUID) *usecase.TaskUseCase {
+		historyRepository := historyRepo.NewRepository(config.Config{})
+		outboxRepository := outboxRepo.NewRepository(config.Config{})
+
+		return usecase.NewTaskUseCase(
+			&failingTaskUpdateRepository{taskRepository, id, task.ErrFailedUpdateTask},
+			historyRepository,
+			outboxRepository,
+			transaction.NewTransactor(taskRepository, historyRepository, outboxRepository),
+			policy,
+		)
+	}
+
+	// The parent is trashed last, the subtasks trashed before are restored
+	err = failingOn(parent.ID).DeleteTask(context.Background(), parent.ID, task.AnyVersion, userId, usecase.DeleteSubtasks)
+	if !errors.Is(err, task.ErrFailedUpdateTask) {
+		t.Fatalf("s.DeleteTask() error = %v, wantErr %v", err, task.ErrFailedUpdateTask)
+	}
+
+	tasks, err := s.GetAllTasksForUser(context.Background(), userId)
+	if err != nil || len(tasks) != 3 {
+		t.Errorf("s.GetAllTasksForUser() got = %v, error = %v, want the 3 tasks", len(tasks), err)
+	}
+
+	err = s.DeleteTask(context.Background(), parent.ID, task.AnyVersion, userId, usecase.DeleteSubtasks)
+	if err != nil {
+		t.Fatalf("s.DeleteTask() error = %v", err)
+	}
+
+	// The subtasks are restored after the parent, the parent is put back in the trash
+	_, err = failingOn(child.ID).RestoreTask(context.Background(), parent.ID, userId)
+	if !errors.Is(err, task.ErrFailedUpdateTask) {
+		t.Fatalf("s.RestoreTask() error = %v, wantErr %v", err, task.ErrFailedUpdateTask)
+	}
+
+	trash, err := s.GetTrashForUser(context.Background(), userId)
+	if err != nil || len(trash) != 3 {
+		t.Errorf("s.GetTrashForUser() got = %v, error = %v, want the 3 tasks", len(trash), err)
+	}
+}
+
+func TestTaskUseCaseHistory(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	repo := repo.NewRepository(config.Config{})
+	s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	ti, err := s.CreateTask(context.Background(), "first", userId, task.WithTags("home"))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	_, err = s.UpdateTask(context.Background(), ti.ID, task.AnyVersion, "second", userId)
+	if err != nil {
+		t.Errorf("s.UpdateTask() error = %v", err)
+	}
+
+	_, err = s.RenameTag(context.Background(), "home", "work", userId)
+	if err != nil {
+		t.Errorf("s.RenameTag() error = %v", err)
+	}
+
+	_, err = s.MarkTaskCompleted(context.Background(), ti.ID, userId)
+	if err != nil {
+		t.Errorf("s.MarkTaskCompleted() error = %v", err)
+	}
+
+	err = s.DeleteTask(context.Background(), ti.ID, task.AnyVersion, userId, usecase.DeleteSubtasks)
+	if err != nil {
+		t.Errorf("s.DeleteTask() error = %v", err)
+	}
+
+	_, err = s.GetTaskHistory(context.Background(), ti.ID, uuid.New())
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("s.GetTaskHistory() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	events, err := s.GetTaskHistory(context.Background(), ti.ID, userId)
+	if err != nil {
+		t.Fatalf("s.GetTaskHistory() error = %v", err)
+	}
+
+	ops := make([]history.Operation, 0, len(events))
+	for _, e := range events {
+		if e.ActorID != userId || e.TaskID != ti.ID {
+			t.Errorf("s.GetTaskHistory() got = %v, want events of the task by the user", e)
+		}
+		ops = append(ops, e.Operation)
+	}
+
+	want := []history.Operation{history.OpCreate, history.OpUpdateText, history.OpRenameTag, history.OpComplete, history.OpDelete}
+	if !reflect.DeepEqual(ops, want) {
+		t.Fatalf("s.GetTaskHistory() got = %v, want %v", ops, want)
+	}
+
+	if events[1].Before.Text != "first" || events[1].After.Text != "second" {
+		t.Errorf("s.GetTaskHistory() got = %v -> %v, want first -> second", events[1].Before.Text, events[1].After.Text)
+	}
+
+	if !reflect.DeepEqual(events[2].After.Tags, []string{"work"}) {
+		t.Errorf("s.GetTaskHistory() got = %v, want %v", events[2].After.Tags, []string{"work"})
+	}
+}
+
+func TestTaskUseCaseVersion(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	repo := repo.NewRepository(config.Config{})
+	s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	ti, err := s.CreateTask(context.Background(), "first", userId)
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	updated, err := s.UpdateTask(context.Background(), ti.ID, ti.Version, "second", userId)
+	if err != nil {
+		t.Fatalf("s.UpdateTask() error = %v", err)
+	}
+	if updated.Version != ti.Version+1 {
+		t.Errorf("s.UpdateTask() Version = %v, want %v", updated.Version, ti.Version+1)
+	}
+
+	_, err = s.UpdateTask(context.Background(), ti.ID, ti.Version, "third", userId)
+	if !errors.Is(err, task.ErrVersionMismatch) {
+		t.Errorf("s.UpdateTask() error = %v, wantErr %v", err, task.ErrVersionMismatch)
+	}
+
+	completed, err := s.MarkTaskCompleted(context.Background(), ti.ID, userId)
+	if err != nil {
+		t.Fatalf("s.MarkTaskCompleted() error = %v", err)
+	}
+
+	err = s.DeleteTask(context.Background(), ti.ID, updated.Version, userId, usecase.DeleteSubtasks)
+	if !errors.Is(err, task.ErrVersionMismatch) {
+		t.Errorf("s.DeleteTask() error = %v, wantErr %v", err, task.ErrVersionMismatch)
+	}
+
+	err = s.DeleteTask(context.Background(), ti.ID, completed.Version, userId, usecase.DeleteSubtasks)
+	if err != nil {
+		t.Errorf("s.DeleteTask() error = %v", err)
+	}
+}
+
+func TestTaskUseCaseMoveTask(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	repo := repo.NewRepository(config.Config{})
+	s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	// The first two tasks leave no room between them
+	var tasks []task.Task
+	for i, position := range []float64{1, math.Nextafter(1, 2), 5000} {
+		ti, err := task.NewTask(fmt.Sprintf("task %d", i), userId)
+		if err != nil {
+			t.Fatalf("task.NewTask() error = %v", err)
+		}
+		ti.MoveTo(position)
+
+		err = repo.Save(context.Background(), ti)
+		if err != nil {
+			t.Fatalf("repo.Save() error = %v", err)
+		}
+
+		tasks = append(tasks, ti)
+	}
+	a, b, c := tasks[0].ID, tasks[1].ID, tasks[2].ID
+
+	order := func() []uuid.UUID {
+		all, err := s.GetAllTasksForUser(context.Background(), userId)
+		if err != nil {
+			t.Fatalf("s.GetAllTasksForUser() error = %v", err)
+		}
+
+		var ids []uuid.UUID
+		for _, ti := range all {
+			ids = append(ids, ti.ID)
+		}
+		return ids
+	}
+
+	type testCase struct {
+		name      string
+		id        uuid.UUID
+		prevId    *uuid.UUID
+		nextId    *uuid.UUID
+		userId    uuid.UUID
+		wantOrder []uuid.UUID
+		wantErr   error
+	}
+
+	// The cases run in order, each one sees the changes of the previous ones
+	tests := []testCase{
+		{name: "No neighbour", id: c, prevId: nil, nextId: nil, userId: userId, wantOrder: []uuid.UUID{a, b, c}, wantErr: task.ErrInvalidMove},
+		{name: "Itself", id: c, prevId: &c, nextId: nil, userId: userId, wantOrder: []uuid.UUID{a, b, c}, wantErr: task.ErrInvalidMove},
+		{name: "Not neighbours", id: c, prevId: &a, nextId: &c, userId: userId, wantOrder: []uuid.UUID{a, b, c}, wantErr: task.ErrInvalidMove},
+		{name: "Another user", id: c, prevId: &a, nextId: nil, userId: uuid.New(), wantOrder: []uuid.UUID{a, b, c}, wantErr: usecase.ErrUnauthorizedAction},
+		{name: "Rebalance", id: c, prevId: &a, nextId: &b, userId: userId, wantOrder: []uuid.UUID{a, c, b}, wantErr: nil},
+		{name: "To the front", id: b, prevId: nil, nextId: &a, userId: userId, wantOrder: []uuid.UUID{b, a, c}, wantErr: nil},
+		{name: "To the end", id: b, prevId: &c, nextId: nil, userId: userId, wantOrder: []uuid.UUID{a, c, b}, wantErr: nil},
+		{name: "Between", id: b, prevId: &a, nextId: &c, userId: userId, wantOrder: []uuid.UUID{a, b, c}, wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		_, err := s.MoveTask(context.Background(), tt.id, tt.prevId, tt.nextId, tt.userId)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: s.MoveTask() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+
+		if got := order(); !reflect.DeepEqual(got, tt.wantOrder) {
+			t.Errorf("%s: s.MoveTask() order = %v, want %v", tt.name, got, tt.wantOrder)
+		}
+	}
+
+	// The rebalance moved the task that has not been moved since
+	first, err := s.GetTask(context.Background(), a, userId)
+	if err != nil {
+		t.Fatalf("s.GetTask() error = %v", err)
+	}
+
+	if first.Position != task.PositionGap {
+		t.Errorf("s.MoveTask() Position = %v, want %v", first.Position, task.PositionGap)
+	}
+}
+
+func TestTaskUseCaseEisenhowerMatrix(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	repo := repo.NewRepository(config.Config{})
+	s, _ := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	low, err := s.CreateTask(context.Background(), "low", userId, task.WithPriority(task.PriorityLow), task.WithDueDate(time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	high, err := s.CreateTask(context.Background(), "high", userId, task.WithPriority(task.PriorityHigh), task.WithEisenhower(task.Eisenhower{Important: false, Urgent: true}))
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	plain, err := s.CreateTask(context.Background(), "plain", userId)
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	// Updating with options changes the priority and records a generic update
+	updated, err := s.UpdateTask(context.Background(), plain.ID, plain.Version, "plain", userId, task.WithPriority(task.PriorityHigh))
+	if err != nil {
+		t.Fatalf("s.UpdateTask() error = %v", err)
+	}
+	if updated.Priority != task.PriorityHigh {
+		t.Errorf("s.UpdateTask() Priority = %v, want %v", updated.Priority, task.PriorityHigh)
+	}
+
+	_, err = s.UpdateTask(context.Background(), plain.ID, task.AnyVersion, "plain", userId, task.WithPriority(task.Priority(-1)))
+	if !errors.Is(err, task.ErrInvalidPriority) {
+		t.Errorf("s.UpdateTask() error = %v, wantErr %v", err, task.ErrInvalidPriority)
+	}
+
+	events, err := s.GetTaskHistory(context.Background(), plain.ID, userId)
+	if err != nil {
+		t.Fatalf("s.GetTaskHistory() error = %v", err)
+	}
+	if last := events[len(events)-1]; last.Operation != history.OpUpdate || !reflect.DeepEqual(last.ChangedFields(), []string{"priority"}) {
+		t.Errorf("s.GetTaskHistory() last = %v %v, want %v %v", last.Operation, last.ChangedFields(), history.OpUpdate, []string{"priority"})
+	}
+
+	groups, err := s.GetEisenhowerMatrixForUser(context.Background(), userId)
+	if err != nil {
+		t.Fatalf("s.GetEisenhowerMatrixForUser() error = %v", err)
+	}
+
+	ids := func(tasks []task.Task) []uuid.UUID {
+		ids := []uuid.UUID{}
+		for _, ti := range tasks {
+			ids = append(ids, ti.ID)
+		}
+		return ids
+	}
+
+	want := map[task.Quadrant][]uuid.UUID{
+		task.QuadrantDoFirst:   {},
+		task.QuadrantSchedule:  {plain.ID},
+		task.QuadrantDelegate:  {high.ID, low.ID},
+		task.QuadrantEliminate: {},
+	}
+	for q, wantIds := range want {
+		if got := ids(groups[q]); !reflect.DeepEqual(got, wantIds) {
+			t.Errorf("s.GetEisenhowerMatrixForUser() %s = %v, want %v", q, got, wantIds)
+		}
+	}
+}
+
+func TestTaskUseCaseOutbox(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+
+	repo := repo.NewRepository(config.Config{})
+	s, outbox := newTaskUseCase(repo, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+
+	ti, err := s.CreateTask(context.Background(), "test", userId)
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	_, err = s.UpdateTask(context.Background(), ti.ID, task.AnyVersion, "updated", userId)
+	if err != nil {
+		t.Fatalf("s.UpdateTask() error = %v", err)
+	}
+
+	_, err = s.MarkTaskCompleted(context.Background(), ti.ID, userId)
+	if err != nil {
+		t.Fatalf("s.MarkTaskCompleted() error = %v", err)
+	}
+
+	// A failed change adds no event
+	_, err = s.UpdateTask(context.Background(), ti.ID, ti.Version, "stale", userId)
+	if !errors.Is(err, task.ErrVersionMismatch) {
+		t.Fatalf("s.UpdateTask() error = %v, wantErr %v", err, task.ErrVersionMismatch)
+	}
+
+	err = s.DeleteTask(context.Background(), ti.ID, task.AnyVersion, userId, usecase.DeleteSubtasks)
+	if err != nil {
+		t.Fatalf("s.DeleteTask() error = %v", err)
+	}
+
+	events, err := outbox.GetPending(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("outbox.GetPending() error = %v", err)
+	}
+
+	want := []task.EventType{task.EventCreated, task.EventUpdated, task.EventCompleted, task.EventDeleted}
+	if len(events) != len(want) {
+		t.Fatalf("outbox.GetPending() len = %v, want %v", len(events), len(want))
+	}
+
+	for i, e := range events {
+		if e.Type != want[i] || e.Task.ID != ti.ID || e.ActorID != userId {
+			t.Errorf("event %d got = %v %v %v, want %v %v %v", i, e.Type, e.Task.ID, e.ActorID, want[i], ti.ID, userId)
+		}
+		if e.Task.Version != ti.Version+int64(i) {
+			t.Errorf("event %d Task.Version = %v, want %v", i, e.Task.Version, ti.Version+int64(i))
+		}
+	}
+
+	if events[1].Task.Text != "updated" {
+		t.Errorf("updated event Task.Text = %v, want %v", events[1].Task.Text, "updated")
+	}
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+const (
+	// MaxBatchSize is the largest number of operations a batch can have.
+	MaxBatchSize = 100
+)
+
+// BatchOperation is the kind of change a BatchItem makes.
+type BatchOperation string
+
+const (
+	BatchCreate     BatchOperation = "create"
+	BatchUpdateText BatchOperation = "update_text"
+	BatchComplete   BatchOperation = "complete"
+	BatchUncomplete BatchOperation = "uncomplete"
+	BatchDelete     BatchOperation = "delete"
+)
+
+var (
+	ErrInvalidBatchSize      = errors.New("batch size is invalid")
+	ErrInvalidBatchOperation = errors.New("batch operation is invalid")
+	ErrBatchRolledBack       = errors.New("the operation was rolled back")
+)
+
+// BatchItem is one operation of a batch.
+type BatchItem struct {
+	Operation BatchOperation
+	// TaskID is the task to change, unused by BatchCreate.
+	TaskID uuid.UUID
+	// Text is the text of a created or updated task.
+	Text string
+	// Version is the version the task must be at for BatchUpdateText and BatchDelete, see task.AnyVersion.
+	Version int64
+	// Options are the options of a created task.
+	Options []task.Option
+}
+
+// BatchResult is the outcome of one operation of a batch.
+type BatchResult struct {
+	// Task is the created or changed task, nil for BatchDelete or on failure.
+	Task *task.Task
+	Err  error
+}
+
+type TaskBatchUseCase struct {
+	taskUseCase *TaskUseCase
+	transactor  Transactor
+}
+
+// NewTaskBatchUseCase creates an new instance of the TaskBatchUseCase.
+func NewTaskBatchUseCase(taskUseCase *TaskUseCase, transactor Transactor) *TaskBatchUseCase {
+	return &TaskBatchUseCase{
+		taskUseCase: taskUseCase,
+		transactor:  transactor,
+	}
+}
+
+// ApplyBatch applies the operations in order on behalf of the user, authorizing each of them
+// like the single task operations do, and returns a result per operation.
+// Without atomic every operation stands on its own. With atomic the first failure stops the batch
+// and rolls the operations applied before it back, and their results carry ErrBatchRolledBack.
+func (s *TaskBatchUseCase) ApplyBatch(ctx context.Context, items []BatchItem, atomic bool, userId uuid.UUID) ([]BatchResult, error) {
+	if len(items) == 0 || len(items) > MaxBatchSize {
+		return []BatchResult{}, ErrInvalidBatchSize
+	}
+
+	if !atomic {
+		results := make([]BatchResult, 0, len(items))
+		for _, item := range items {
+			results = append(results, s.apply(ctx, item, userId))
+		}
+
+		return results, nil
+	}
+
+	var results []BatchResult
+	failed := -1
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The transaction may be retried, every attempt starts over.
+		results = make([]BatchResult, 0, len(items))
+		failed = -1
+
+		for i, item := range items {
+			result := s.apply(ctx, item, userId)
+			results = append(results, result)
+
+			if result.Err != nil {
+				failed = i
+				return result.Err
+			}
+		}
+
+		return nil
+	})
+	if err == nil {
+		// The events of the operations are added to the outbox on commit.
+		s.taskUseCase.eventsAdded()
+
+		return results, nil
+	}
+
+	if failed < 0 {
+		// The transaction itself failed, nothing has been applied.
+		results = make([]BatchResult, 0, len(items))
+		for range items {
+			results = append(results, BatchResult{Err: err})
+		}
+
+		return results, nil
+	}
+
+	for i := range items {
+		switch {
+		case i < failed:
+			results[i] = BatchResult{Err: ErrBatchRolledBack}
+		case i > failed:
+			results = append(results, BatchResult{Err: ErrBatchRolledBack})
+		}
+	}
+
+	return results, nil
+}
+
+func (s *TaskBatchUseCase) apply(ctx context.Context, item BatchItem, userId uuid.UUID) BatchResult {
+	var (
+		t   task.Task
+		err error
+	)
+
+	switch item.Operation {
+	case BatchCreate:
+		t, err = s.taskUseCase.CreateTask(ctx, item.Text, userId, item.Options...)
+	case BatchUpdateText:
+		t, err = s.taskUseCase.UpdateTask(ctx, item.TaskID, item.Version, item.Text, userId)
+	case BatchComplete:
+		t, err = s.taskUseCase.MarkTaskCompleted(ctx, item.TaskID, userId)
+	case BatchUncomplete:
+		t, err = s.taskUseCase.MarkTaskNotCompleted(ctx, item.TaskID, userId)
+	case BatchDelete:
+		err = s.taskUseCase.DeleteTask(ctx, item.TaskID, item.Version, userId, DeleteSubtasks)
+		if err == nil {
+			return BatchResult{}
+		}
+	default:
+		err = ErrInvalidBatchOperation
+	}
+
+	if err != nil {
+		return BatchResult{Err: err}
+	}
+
+	return BatchResult{Task: &t}
+}
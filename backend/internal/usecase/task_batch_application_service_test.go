@@ -0,0 +1,146 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	historyRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/history/memory"
+	listRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/memory"
+	outboxRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/outbox/memory"
+	repo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/memory"
+	transaction "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/transaction/memory"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+func TestTaskBatchUseCaseApplyBatch(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+	otherUserId := uuid.MustParse("b6f5ab12-3f47-4c8a-9c0e-1f2d0f6c3a11")
+
+	type testCase struct {
+		name       string
+		atomic     bool
+		items      func(own, foreign task.Task) []usecase.BatchItem
+		wantErrs   []error
+		wantTasks  int
+		wantEvents int
+	}
+
+	tests := []testCase{
+		{
+			name:   "Non-atomic applies every operation on its own",
+			atomic: false,
+			items: func(own, foreign task.Task) []usecase.BatchItem {
+				return []usecase.BatchItem{
+					{Operation: usecase.BatchCreate, Text: "created"},
+					{Operation: usecase.BatchComplete, TaskID: own.ID},
+					{Operation: usecase.BatchComplete, TaskID: foreign.ID},
+					{Operation: usecase.BatchUpdateText, TaskID: own.ID, Version: task.AnyVersion, Text: ""},
+				}
+			},
+			wantErrs:   []error{nil, nil, usecase.ErrUnauthorizedAction, task.ErrInvalidText},
+			wantTasks:  2,
+			wantEvents: 4,
+		},
+		{
+			name:   "Atomic applies every operation",
+			atomic: true,
+			items: func(own, foreign task.Task) []usecase.BatchItem {
+				return []usecase.BatchItem{
+					{Operation: usecase.BatchCreate, Text: "created"},
+					{Operation: usecase.BatchDelete, TaskID: own.ID, Version: own.Version},
+				}
+			},
+			wantErrs:   []error{nil, nil},
+			wantTasks:  1,
+			wantEvents: 4,
+		},
+		{
+			name:   "Atomic rolls back on the first failure",
+			atomic: true,
+			items: func(own, foreign task.Task) []usecase.BatchItem {
+				return []usecase.BatchItem{
+					{Operation: usecase.BatchCreate, Text: "created"},
+					{Operation: usecase.BatchDelete, TaskID: own.ID, Version: task.AnyVersion},
+					{Operation: "archive", TaskID: own.ID},
+					{Operation: usecase.BatchComplete, TaskID: own.ID},
+				}
+			},
+			wantErrs:   []error{usecase.ErrBatchRolledBack, usecase.ErrBatchRolledBack, usecase.ErrInvalidBatchOperation, usecase.ErrBatchRolledBack},
+			wantTasks:  1,
+			wantEvents: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			taskRepo := repo.NewRepository(config.Config{})
+			historyRepo := historyRepo.NewRepository(config.Config{})
+			outboxRepo := outboxRepo.NewRepository(config.Config{})
+			transactor := transaction.NewTransactor(taskRepo, historyRepo, outboxRepo)
+
+			s := usecase.NewTaskUseCase(taskRepo, historyRepo, outboxRepo, transactor, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+			b := usecase.NewTaskBatchUseCase(s, transactor)
+
+			own, err := s.CreateTask(context.Background(), "own", userId)
+			if err != nil {
+				t.Fatalf("s.CreateTask() error = %v", err)
+			}
+			foreign, err := s.CreateTask(context.Background(), "foreign", otherUserId)
+			if err != nil {
+				t.Fatalf("s.CreateTask() error = %v", err)
+			}
+
+			results, err := b.ApplyBatch(context.Background(), tt.items(own, foreign), tt.atomic, userId)
+			if err != nil {
+				t.Fatalf("b.ApplyBatch() error = %v", err)
+			}
+
+			if len(results) != len(tt.wantErrs) {
+				t.Fatalf("b.ApplyBatch() len = %v, want %v", len(results), len(tt.wantErrs))
+			}
+			for i, r := range results {
+				if !errors.Is(r.Err, tt.wantErrs[i]) {
+					t.Errorf("b.ApplyBatch() [%d] error = %v, wantErr %v", i, r.Err, tt.wantErrs[i])
+				}
+			}
+
+			tasks, err := taskRepo.GetAllByUserID(context.Background(), userId)
+			if err != nil {
+				t.Fatalf("taskRepo.GetAllByUserID() error = %v", err)
+			}
+			if len(tasks) != tt.wantTasks {
+				t.Errorf("taskRepo.GetAllByUserID() len = %v, want %v", len(tasks), tt.wantTasks)
+			}
+
+			// The events of the rolled back operations are rolled back too
+			events, err := outboxRepo.GetPending(context.Background(), 100)
+			if err != nil {
+				t.Fatalf("outboxRepo.GetPending() error = %v", err)
+			}
+			if len(events) != tt.wantEvents {
+				t.Errorf("outboxRepo.GetPending() len = %v, want %v", len(events), tt.wantEvents)
+			}
+		})
+	}
+
+	t.Run("Invalid size", func(t *testing.T) {
+		t.Parallel()
+		taskRepo := repo.NewRepository(config.Config{})
+		historyRepo := historyRepo.NewRepository(config.Config{})
+		outboxRepo := outboxRepo.NewRepository(config.Config{})
+		transactor := transaction.NewTransactor(taskRepo, historyRepo, outboxRepo)
+
+		s := usecase.NewTaskUseCase(taskRepo, historyRepo, outboxRepo, transactor, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+		b := usecase.NewTaskBatchUseCase(s, transactor)
+
+		_, err := b.ApplyBatch(context.Background(), nil, false, userId)
+		if !errors.Is(err, usecase.ErrInvalidBatchSize) {
+			t.Errorf("b.ApplyBatch() error = %v, wantErr %v", err, usecase.ErrInvalidBatchSize)
+		}
+	})
+}
@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/history"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+)
+
+const (
+	// MaxImportSize is the largest number of rows an import can have.
+	MaxImportSize = 1000
+)
+
+// DuplicatePolicy tells ImportTasks what to do with a row whose id is the id of an existing task.
+type DuplicatePolicy string
+
+const (
+	// SkipDuplicates leaves the existing task as it is.
+	SkipDuplicates DuplicatePolicy = "skip"
+	// UpdateDuplicates overwrites the existing task with the row.
+	UpdateDuplicates DuplicatePolicy = "update"
+)
+
+// ImportStatus is what has been done with an imported row.
+type ImportStatus string
+
+const (
+	ImportCreated   ImportStatus = "created"
+	ImportUpdated   ImportStatus = "updated"
+	ImportUnchanged ImportStatus = "unchanged"
+	ImportSkipped   ImportStatus = "skipped"
+	ImportFailed    ImportStatus = "failed"
+)
+
+var (
+	ErrInvalidImportSize      = errors.New("import size is invalid")
+	ErrInvalidDuplicatePolicy = errors.New("duplicate policy is invalid")
+)
+
+// ImportRow is one task of an import.
+type ImportRow struct {
+	// ID is the id the task had in the export, uuid.Nil for a task without one.
+	ID        uuid.UUID
+	Text      string
+	Completed bool
+	// Options are the further attributes of the task.
+	Options []task.Option
+	// Err is the error reading the row from the imported file, the row fails with it when set.
+	Err error
+}
+
+// ImportResult is the outcome of one row of an import.
+type ImportResult struct {
+	Status ImportStatus
+	// Task is the created, updated or skipped task, nil on failure.
+	Task *task.Task
+	Err  error
+}
+
+type TaskImportUseCase struct {
+	taskUseCase *TaskUseCase
+}
+
+// NewTaskImportUseCase creates an new instance of the TaskImportUseCase.
+func NewTaskImportUseCase(taskUseCase *TaskUseCase) *TaskImportUseCase {
+	return &TaskImportUseCase{
+		taskUseCase: taskUseCase,
+	}
+}
+
+// ImportTasks adds the rows to the tasks of the user and returns a result per row, every row stands on its own.
+// Each row is validated like a new task and created with its id, so that a parent is found by the id
+// its subtasks refer to. Parents are imported before their subtasks wherever they are in the rows.
+// A row with the id of an existing task is handled by the duplicate policy. An updated task keeps
+// its list and its parent, the rest of its attributes are overwritten.
+func (s *TaskImportUseCase) ImportTasks(ctx context.Context, rows []ImportRow, duplicates DuplicatePolicy, userId uuid.UUID) ([]ImportResult, error) {
+	if len(rows) == 0 || len(rows) > MaxImportSize {
+		return []ImportResult{}, ErrInvalidImportSize
+	}
+
+	if duplicates != SkipDuplicates && duplicates != UpdateDuplicates {
+		return []ImportResult{}, ErrInvalidDuplicatePolicy
+	}
+
+	results := make([]ImportResult, len(rows))
+	tasks := make([]task.Task, len(rows))
+	for i, row := range rows {
+		t, err := newImportedTask(row, userId)
+		if err != nil {
+			results[i] = ImportResult{Status: ImportFailed, Err: err}
+			continue
+		}
+
+		tasks[i] = t
+	}
+
+	for _, i := range importOrder(tasks) {
+		if results[i].Err != nil {
+			continue
+		}
+
+		results[i] = s.importTask(ctx, tasks[i], rows[i].ID != uuid.Nil, duplicates, userId)
+	}
+
+	return results, nil
+}
+
+func (s *TaskImportUseCase) importTask(ctx context.Context, t task.Task, hasID bool, duplicates DuplicatePolicy, userId uuid.UUID) ImportResult {
+	if !hasID {
+		return s.create(ctx, t, userId)
+	}
+
+	existing, err := s.taskUseCase.taskRepository.GetByID(ctx, t.ID)
+	if errors.Is(err, task.ErrTaskNotFound) {
+		return s.create(ctx, t, userId)
+	}
+	if err != nil {
+		return ImportResult{Status: ImportFailed, Err: err}
+	}
+
+	if duplicates == SkipDuplicates {
+		err = s.taskUseCase.policy.AuthorizeTask(ctx, existing, userId, ActionView)
+		if err != nil {
+			return ImportResult{Status: ImportFailed, Err: err}
+		}
+
+		return ImportResult{Status: ImportSkipped, Task: &existing}
+	}
+
+	err = s.taskUseCase.policy.AuthorizeTask(ctx, existing, userId, ActionEdit)
+	if err != nil {
+		return ImportResult{Status: ImportFailed, Err: err}
+	}
+
+	if existing.IsTrashed() {
+		return ImportResult{Status: ImportFailed, Err: task.ErrTaskInTrash}
+	}
+
+	before := history.StateOf(existing)
+
+	changed, err := overwrite(&existing, t)
+	if err != nil {
+		return ImportResult{Status: ImportFailed, Err: err}
+	}
+
+	if !changed {
+		return ImportResult{Status: ImportUnchanged, Task: &existing}
+	}
+
+	err = s.taskUseCase.update(ctx, before, &existing, userId, history.OpUpdate)
+	if err != nil {
+		return ImportResult{Status: ImportFailed, Err: err}
+	}
+
+	return ImportResult{Status: ImportUpdated, Task: &existing}
+}
+
+func (s *TaskImportUseCase) create(ctx context.Context, t task.Task, userId uuid.UUID) ImportResult {
+	err := s.taskUseCase.create(ctx, &t, userId)
+	if err != nil {
+		return ImportResult{Status: ImportFailed, Err: err}
+	}
+
+	return ImportResult{Status: ImportCreated, Task: &t}
+}
+
+// newImportedTask validates the row by creating the task it describes.
+func newImportedTask(row ImportRow, userId uuid.UUID) (task.Task, error) {
+	if row.Err != nil {
+		return task.Task{}, row.Err
+	}
+
+	var opts []task.Option
+	if row.ID != uuid.Nil {
+		opts = append(opts, task.WithID(row.ID))
+	}
+
+	opts = append(opts, row.Options...)
+
+	if row.Completed {
+		opts = append(opts, func(t *task.Task) error {
+			t.MarkCompleted()
+
+			return nil
+		})
+	}
+
+	return task.NewTask(row.Text, userId, opts...)
+}
+
+// importOrder returns the indexes of the tasks with the parents among them before their subtasks,
+// the order of the tasks is kept otherwise. Tasks that failed validation have a nil id and are ignored as parents.
+func importOrder(tasks []task.Task) []int {
+	index := make(map[uuid.UUID]int, len(tasks))
+	for i := len(tasks) - 1; i >= 0; i-- {
+		if tasks[i].ID != uuid.Nil {
+			index[tasks[i].ID] = i
+		}
+	}
+
+	depths := make([]int, len(tasks))
+	for i, t := range tasks {
+		// The depth is bounded by the number of tasks, in case the parents form a cycle.
+		for current := t; current.ParentID != nil && depths[i] < len(tasks); depths[i]++ {
+			parent, ok := index[*current.ParentID]
+			if !ok {
+				break
+			}
+
+			current = tasks[parent]
+		}
+	}
+
+	order := make([]int, len(tasks))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return depths[a] - depths[b]
+	})
+
+	return order
+}
+
+// overwrite sets the attributes of the imported task, but its list and its parent, on the existing one.
+// It reports whether the existing task has changed.
+func overwrite(t *task.Task, imported task.Task) (bool, error) {
+	changed := false
+
+	if t.Text != imported.Text {
+		err := t.SetText(imported.Text)
+		if err != nil {
+			return false, err
+		}
+
+		changed = true
+	}
+
+	if imported.DueAt == nil {
+		if t.DueAt != nil {
+			t.ClearDueDate()
+			changed = true
+		}
+	} else if t.DueAt == nil || !t.DueAt.Equal(*imported.DueAt) {
+		err := t.SetDueDate(*imported.DueAt)
+		if err != nil {
+			return false, err
+		}
+
+		changed = true
+	}
+
+	if imported.ReminderOffset == nil {
+		if t.ReminderOffset != nil {
+			t.ClearReminder()
+			changed = true
+		}
+	} else if t.ReminderOffset == nil || *t.ReminderOffset != *imported.ReminderOffset {
+		err := t.SetReminder(*imported.ReminderOffset)
+		if err != nil {
+			return false, err
+		}
+
+		changed = true
+	}
+
+	if imported.Recurrence == nil {
+		if t.Recurrence != nil {
+			t.ClearRecurrence()
+			changed = true
+		}
+	} else if t.Recurrence == nil || t.Recurrence.String() != imported.Recurrence.String() {
+		err := t.SetRecurrence(*imported.Recurrence)
+		if err != nil {
+			return false, err
+		}
+
+		changed = true
+	}
+
+	if !slices.Equal(t.Tags, imported.Tags) {
+		err := t.SetTags(imported.Tags)
+		if err != nil {
+			return false, err
+		}
+
+		changed = true
+	}
+
+	if t.Priority != imported.Priority {
+		err := t.SetPriority(imported.Priority)
+		if err != nil {
+			return false, err
+		}
+
+		changed = true
+	}
+
+	switch {
+	case imported.Eisenhower == nil && t.Eisenhower != nil:
+		t.ClearEisenhower()
+		changed = true
+	case imported.Eisenhower != nil && (t.Eisenhower == nil || *t.Eisenhower != *imported.Eisenhower):
+		t.SetEisenhower(*imported.Eisenhower)
+		changed = true
+	}
+
+	switch {
+	case imported.Completed && !t.Completed:
+		t.MarkCompleted()
+		changed = true
+	case !imported.Completed && t.Completed:
+		t.MarkNotCompleted()
+		changed = true
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,143 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	listRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/memory"
+	repo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/memory"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+func TestTaskImportUseCaseImportTasks(t *testing.T) {
+	userId := uuid.MustParse("731efa19-5926-4cac-8ca5-2fcb7d8056b1")
+	otherUserId := uuid.MustParse("b6f5ab12-3f47-4c8a-9c0e-1f2d0f6c3a11")
+	newId := uuid.MustParse("0e3f6f0c-7f0a-4bb5-8a52-2f4b7c0f1d01")
+
+	type testCase struct {
+		name       string
+		duplicates usecase.DuplicatePolicy
+		rows       func(own, foreign task.Task) []usecase.ImportRow
+		wantStatus []usecase.ImportStatus
+		wantErrs   []error
+		wantText   string
+	}
+
+	tests := []testCase{
+		{
+			name:       "Skip keeps the duplicates",
+			duplicates: usecase.SkipDuplicates,
+			rows: func(own, foreign task.Task) []usecase.ImportRow {
+				return []usecase.ImportRow{
+					{ID: own.ID, Text: "changed"},
+					{Text: "new"},
+					{Text: ""},
+					{ID: foreign.ID, Text: "foreign"},
+				}
+			},
+			wantStatus: []usecase.ImportStatus{usecase.ImportSkipped, usecase.ImportCreated, usecase.ImportFailed, usecase.ImportFailed},
+			wantErrs:   []error{nil, nil, task.ErrInvalidText, usecase.ErrUnauthorizedAction},
+			wantText:   "own",
+		},
+		{
+			name:       "Update overwrites the duplicates",
+			duplicates: usecase.UpdateDuplicates,
+			rows: func(own, foreign task.Task) []usecase.ImportRow {
+				return []usecase.ImportRow{
+					{ID: own.ID, Text: "changed", Completed: true},
+					{ID: own.ID, Text: "changed", Completed: true},
+					{ID: foreign.ID, Text: "foreign"},
+					{Text: "unreadable", Err: task.ErrInvalidDueDate},
+				}
+			},
+			wantStatus: []usecase.ImportStatus{usecase.ImportUpdated, usecase.ImportUnchanged, usecase.ImportFailed, usecase.ImportFailed},
+			wantErrs:   []error{nil, nil, usecase.ErrUnauthorizedAction, task.ErrInvalidDueDate},
+			wantText:   "changed",
+		},
+		{
+			name:       "Parents are imported before their subtasks",
+			duplicates: usecase.SkipDuplicates,
+			rows: func(own, foreign task.Task) []usecase.ImportRow {
+				return []usecase.ImportRow{
+					{Text: "subtask", Options: []task.Option{task.WithParent(newId)}},
+					{ID: newId, Text: "parent", Completed: true},
+					{Text: "orphan", Options: []task.Option{task.WithParent(uuid.New())}},
+				}
+			},
+			wantStatus: []usecase.ImportStatus{usecase.ImportCreated, usecase.ImportCreated, usecase.ImportFailed},
+			wantErrs:   []error{nil, nil, task.ErrInvalidParent},
+			wantText:   "own",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			taskRepository := repo.NewRepository(config.Config{})
+			s, _ := newTaskUseCase(taskRepository, usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+			im := usecase.NewTaskImportUseCase(s)
+
+			own, err := s.CreateTask(context.Background(), "own", userId)
+			if err != nil {
+				t.Fatalf("s.CreateTask() error = %v", err)
+			}
+			foreign, err := s.CreateTask(context.Background(), "foreign", otherUserId)
+			if err != nil {
+				t.Fatalf("s.CreateTask() error = %v", err)
+			}
+
+			results, err := im.ImportTasks(context.Background(), tt.rows(own, foreign), tt.duplicates, userId)
+			if err != nil {
+				t.Fatalf("im.ImportTasks() error = %v", err)
+			}
+
+			if len(results) != len(tt.wantStatus) {
+				t.Fatalf("im.ImportTasks() returned %d results, want %d", len(results), len(tt.wantStatus))
+			}
+			for i, result := range results {
+				if result.Status != tt.wantStatus[i] {
+					t.Errorf("im.ImportTasks() result %d Status = %v, want %v", i, result.Status, tt.wantStatus[i])
+				}
+				if !errors.Is(result.Err, tt.wantErrs[i]) {
+					t.Errorf("im.ImportTasks() result %d error = %v, wantErr %v", i, result.Err, tt.wantErrs[i])
+				}
+			}
+
+			got, err := taskRepository.GetByID(context.Background(), own.ID)
+			if err != nil {
+				t.Fatalf("taskRepository.GetByID() error = %v", err)
+			}
+			if got.Text != tt.wantText {
+				t.Errorf("own task Text = %v, want %v", got.Text, tt.wantText)
+			}
+
+			for _, result := range results {
+				if result.Task == nil || result.Task.ParentID == nil {
+					continue
+				}
+				if *result.Task.ParentID != newId {
+					t.Errorf("imported subtask ParentID = %v, want %v", *result.Task.ParentID, newId)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskImportUseCaseImportTasksInvalid(t *testing.T) {
+	s, _ := newTaskUseCase(repo.NewRepository(config.Config{}), usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{})))
+	im := usecase.NewTaskImportUseCase(s)
+	userId := uuid.New()
+
+	if _, err := im.ImportTasks(context.Background(), nil, usecase.SkipDuplicates, userId); !errors.Is(err, usecase.ErrInvalidImportSize) {
+		t.Errorf("im.ImportTasks() error = %v, wantErr %v", err, usecase.ErrInvalidImportSize)
+	}
+
+	rows := []usecase.ImportRow{{Text: "test"}}
+	if _, err := im.ImportTasks(context.Background(), rows, "merge", userId); !errors.Is(err, usecase.ErrInvalidDuplicatePolicy) {
+		t.Errorf("im.ImportTasks() error = %v, wantErr %v", err, usecase.ErrInvalidDuplicatePolicy)
+	}
+}
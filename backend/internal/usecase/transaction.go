@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+)
+
+// Transactor runs a unit of work atomically.
+type Transactor interface {
+	// WithinTransaction runs fn in a transaction. The repository calls made with the context passed to fn
+	// are committed together when fn returns nil and rolled back otherwise. fn may be retried.
+	// Called with the context of a running transaction, it runs fn as a part of that transaction.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -3,23 +3,88 @@ package usecase
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+	"github.com/ozaitsev92/tododdd/internal/domain/reset"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
 	"github.com/ozaitsev92/tododdd/internal/domain/user"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
 )
 
 var (
-	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrUserAlreadyExists  = errors.New("user already exists")
+	ErrEmailAlreadyInUse  = errors.New("the email is already in use")
+	ErrWrongPassword      = errors.New("the current password is wrong")
+	ErrEmailNotVerified   = errors.New("the email is not verified")
+	ErrInvalidCredentials = errors.New("invalid login and/or password")
 )
 
+// UnverifiedAccess is what the users who have not verified their email yet may do.
+type UnverifiedAccess string
+
+const (
+	// UnverifiedAccessFull lets unverified users do everything verified ones can.
+	UnverifiedAccessFull UnverifiedAccess = "full"
+	// UnverifiedAccessLimited lets unverified users log in and view their tasks and lists, but not change them.
+	UnverifiedAccessLimited UnverifiedAccess = "limited"
+	// UnverifiedAccessNone does not let unverified users log in.
+	UnverifiedAccessNone UnverifiedAccess = "none"
+)
+
+// IsValid returns true if the policy is a known one. The empty policy is UnverifiedAccessFull.
+func (a UnverifiedAccess) IsValid() bool {
+	switch a {
+	case "", UnverifiedAccessFull, UnverifiedAccessLimited, UnverifiedAccessNone:
+		return true
+	default:
+		return false
+	}
+}
+
 type UserUseCase struct {
-	userRepository user.Repository
+	userRepository     user.Repository
+	taskRepository     task.Repository
+	listRepository     list.Repository
+	sessionRepository  session.Repository
+	resetRepository    reset.Repository
+	webhookRepository  webhook.Repository
+	deliveryRepository webhook.DeliveryRepository
+	feedRepository     feed.Repository
+	transactor         Transactor
+	unverifiedAccess   UnverifiedAccess
+	passwords          user.Passwords
+	// dummyHash is compared with the passwords tried for unknown emails, so they take as long as the others.
+	dummyHash string
 }
 
-// NewUserUseCase creates an new instance of the UserUseCase.
-func NewUserUseCase(userRepository user.Repository) *UserUseCase {
+// NewUserUseCase creates an new instance of the UserUseCase. The repositories other than the user one are used
+// to delete the data of a deleted user in a transaction with the user. An empty unverifiedAccess
+// is UnverifiedAccessFull. The new passwords are checked and hashed with passwords, and the hashes
+// made otherwise are replaced on login.
+func NewUserUseCase(userRepository user.Repository, taskRepository task.Repository, listRepository list.Repository, sessionRepository session.Repository, resetRepository reset.Repository, webhookRepository webhook.Repository, deliveryRepository webhook.DeliveryRepository, feedRepository feed.Repository, transactor Transactor, unverifiedAccess UnverifiedAccess, passwords user.Passwords) *UserUseCase {
+	if unverifiedAccess == "" {
+		unverifiedAccess = UnverifiedAccessFull
+	}
+
+	dummyHash, _ := passwords.Hasher.Hash(uuid.NewString())
+
 	return &UserUseCase{
-		userRepository: userRepository,
+		userRepository:     userRepository,
+		taskRepository:     taskRepository,
+		listRepository:     listRepository,
+		sessionRepository:  sessionRepository,
+		resetRepository:    resetRepository,
+		webhookRepository:  webhookRepository,
+		deliveryRepository: deliveryRepository,
+		feedRepository:     feedRepository,
+		transactor:         transactor,
+		unverifiedAccess:   unverifiedAccess,
+		passwords:          passwords,
+		dummyHash:          dummyHash,
 	}
 }
 
@@ -30,7 +95,7 @@ func (s *UserUseCase) RegisterNewUser(ctx context.Context, email, password strin
 		return user.User{}, ErrUserAlreadyExists
 	}
 
-	u, err := user.NewUser(email, password)
+	u, err := user.NewUser(email, password, user.WithPasswords(s.passwords))
 	if err != nil {
 		return user.User{}, err
 	}
@@ -43,6 +108,68 @@ func (s *UserUseCase) RegisterNewUser(ctx context.Context, email, password strin
 	return u, nil
 }
 
+// Authenticate returns the user registered with the email if the password is theirs, ErrInvalidCredentials otherwise.
+// Unknown emails are not told apart, not even by the time it takes.
+// A password hashed with another algorithm or cost than the configured ones is hashed again.
+func (s *UserUseCase) Authenticate(ctx context.Context, email, password string) (user.User, error) {
+	u, err := s.userRepository.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			s.passwords.Hasher.Compare(s.dummyHash, password)
+
+			return user.User{}, ErrInvalidCredentials
+		}
+
+		return user.User{}, err
+	}
+
+	if !u.ComparePassword(password) {
+		return user.User{}, ErrInvalidCredentials
+	}
+
+	rehashed, err := u.RehashPassword(password, s.passwords.Hasher)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	if rehashed {
+		err = s.userRepository.Update(ctx, u)
+		if err != nil {
+			return user.User{}, err
+		}
+	}
+
+	return u, nil
+}
+
+// AuthorizeLogin returns ErrEmailNotVerified if the user may not log in before verifying their email.
+func (s *UserUseCase) AuthorizeLogin(u user.User) error {
+	if u.IsVerified() || s.unverifiedAccess != UnverifiedAccessNone {
+		return nil
+	}
+
+	return ErrEmailNotVerified
+}
+
+// AuthorizeUnverified returns ErrEmailNotVerified if the user may not perform the action on their tasks
+// and lists before verifying their email.
+func (s *UserUseCase) AuthorizeUnverified(u user.User, action Action) error {
+	if u.IsVerified() {
+		return nil
+	}
+
+	switch s.unverifiedAccess {
+	case UnverifiedAccessFull:
+		return nil
+	case UnverifiedAccessLimited:
+		if action == ActionView {
+			return nil
+		}
+	}
+
+	return ErrEmailNotVerified
+}
+
 // GetUserByID returns a user by id.
 func (s *UserUseCase) GetUserByID(ctx context.Context, id uuid.UUID) (user.User, error) {
 	u, err := s.userRepository.GetByID(ctx, id)
@@ -62,3 +189,194 @@ func (s *UserUseCase) GetUserByEmail(ctx context.Context, email string) (user.Us
 
 	return u, nil
 }
+
+// ChangeEmail changes the email of the user. The current password of the user is required.
+func (s *UserUseCase) ChangeEmail(ctx context.Context, id uuid.UUID, password string, email string) (user.User, error) {
+	u, err := s.getWithPassword(ctx, id, password)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	err = u.SetEmail(email)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	existing, err := s.userRepository.GetByEmail(ctx, u.Email)
+	if err == nil && existing.ID != u.ID {
+		return user.User{}, ErrEmailAlreadyInUse
+	}
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		return user.User{}, err
+	}
+
+	err = s.userRepository.Update(ctx, u)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	return u, nil
+}
+
+// ChangePassword replaces the password of the user. The current password of the user is required.
+func (s *UserUseCase) ChangePassword(ctx context.Context, id uuid.UUID, password string, newPassword string) (user.User, error) {
+	u, err := s.getWithPassword(ctx, id, password)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	err = u.SetPassword(newPassword, user.WithPasswords(s.passwords))
+	if err != nil {
+		return user.User{}, err
+	}
+
+	err = s.userRepository.Update(ctx, u)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	return u, nil
+}
+
+// DeleteUser deletes the user together with their personal tasks, trashed ones included.
+// The current password of the user is required.
+// The lists the user is the only member of are deleted with their tasks. The user leaves the other lists,
+// handing the ownership over to the longest standing member if they are the last owner, and the tasks they
+// have added there stay with the list. Those tasks are left with the id of the deleted user only,
+// which no longer leads to any of their details.
+// The sessions of the user are ended, their reset tokens used up, and their webhooks with the pending
+// deliveries and their calendar feed removed.
+func (s *UserUseCase) DeleteUser(ctx context.Context, id uuid.UUID, password string) error {
+	u, err := s.getWithPassword(ctx, id, password)
+	if err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		lists, err := s.listRepository.GetAllByMemberID(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+
+		deletedLists := make(map[uuid.UUID]bool, len(lists))
+		for _, l := range lists {
+			deleted, err := s.leaveList(ctx, l, u.ID)
+			if err != nil {
+				return err
+			}
+
+			deletedLists[l.ID] = deleted
+		}
+
+		active, err := s.taskRepository.GetAllByUserID(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+
+		trashed, err := s.taskRepository.GetTrashByUserID(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range append(active, trashed...) {
+			if t.ListID != nil && !deletedLists[*t.ListID] {
+				continue
+			}
+
+			err = s.taskRepository.Delete(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.deleteAccess(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+
+		return s.userRepository.Delete(ctx, u.ID)
+	})
+}
+
+// deleteAccess removes whatever lets the deleted user, or anyone they have shared it with, reach their data:
+// the sessions, the reset tokens, the webhooks with their secrets and the calendar feed.
+func (s *UserUseCase) deleteAccess(ctx context.Context, userId uuid.UUID) error {
+	err := s.sessionRepository.RevokeAllByUserID(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.resetRepository.UseAllByUserID(ctx, userId, time.Now())
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := s.webhookRepository.GetAllByUserID(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range webhooks {
+		err = s.webhookRepository.Delete(ctx, w.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.deliveryRepository.DeleteByWebhookID(ctx, w.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.feedRepository.DeleteByUserID(ctx, userId)
+	if err != nil && !errors.Is(err, feed.ErrFeedNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// getWithPassword returns the user if the password is theirs and ErrWrongPassword otherwise.
+func (s *UserUseCase) getWithPassword(ctx context.Context, id uuid.UUID, password string) (user.User, error) {
+	u, err := s.userRepository.GetByID(ctx, id)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	if !u.ComparePassword(password) {
+		return user.User{}, ErrWrongPassword
+	}
+
+	return u, nil
+}
+
+// leaveList removes the deleted user from the list, or deletes the list with its tasks if they are its only member.
+// It reports whether the list has been deleted.
+func (s *UserUseCase) leaveList(ctx context.Context, l list.List, userId uuid.UUID) (bool, error) {
+	if len(l.Members) == 1 {
+		_, err := s.taskRepository.DeleteAllByListID(ctx, l.ID)
+		if err != nil {
+			return false, err
+		}
+
+		return true, s.listRepository.Delete(ctx, l.ID)
+	}
+
+	err := l.RemoveMember(userId)
+	if errors.Is(err, list.ErrLastOwner) {
+		for _, m := range l.Members {
+			if m.UserID != userId {
+				err = l.SetMemberRole(m.UserID, list.RoleOwner)
+				break
+			}
+		}
+		if err == nil {
+			err = l.RemoveMember(userId)
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return false, s.listRepository.Update(ctx, l)
+}
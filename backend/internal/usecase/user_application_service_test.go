@@ -4,13 +4,58 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/feed"
+	"github.com/ozaitsev92/tododdd/internal/domain/list"
+	"github.com/ozaitsev92/tododdd/internal/domain/reset"
+	"github.com/ozaitsev92/tododdd/internal/domain/session"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
 	"github.com/ozaitsev92/tododdd/internal/domain/user"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+	feedRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/feed/memory"
+	listRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/memory"
+	resetRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/reset/memory"
+	sessionRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/session/memory"
+	taskRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/memory"
+	transaction "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/transaction/memory"
 	repo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/user/memory"
+	webhookRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/memory"
 	"github.com/ozaitsev92/tododdd/internal/usecase"
 )
 
+// userAccessRepositories are the repositories of what lets a user reach their data besides their password.
+type userAccessRepositories struct {
+	sessions   *sessionRepo.Repository
+	resets     *resetRepo.Repository
+	webhooks   *webhookRepo.Repository
+	deliveries *webhookRepo.DeliveryRepository
+	feeds      *feedRepo.Repository
+}
+
+// newUserUseCase creates a UserUseCase saving the users, the tasks and the lists to the repositories.
+func newUserUseCase(userRepository *repo.Repository, taskRepository *taskRepo.Repository, listRepository *listRepo.Repository) *usecase.UserUseCase {
+	s, _ := newUserUseCaseWithAccess(userRepository, taskRepository, listRepository)
+
+	return s
+}
+
+// newUserUseCaseWithAccess is newUserUseCase also returning the repositories of the users' access.
+func newUserUseCaseWithAccess(userRepository *repo.Repository, taskRepository *taskRepo.Repository, listRepository *listRepo.Repository) (*usecase.UserUseCase, userAccessRepositories) {
+	a := userAccessRepositories{
+		sessions:   sessionRepo.NewRepository(config.Config{}),
+		resets:     resetRepo.NewRepository(config.Config{}),
+		webhooks:   webhookRepo.NewRepository(config.Config{}),
+		deliveries: webhookRepo.NewDeliveryRepository(config.Config{}),
+		feeds:      feedRepo.NewRepository(config.Config{}),
+	}
+	transactor := transaction.NewTransactor(userRepository, taskRepository, listRepository, a.sessions, a.resets)
+
+	return usecase.NewUserUseCase(userRepository, taskRepository, listRepository, a.sessions, a.resets, a.webhooks, a.deliveries, a.feeds, transactor, usecase.UnverifiedAccessFull, user.Passwords{}), a
+}
+
 func TestRegisterNewUser(t *testing.T) {
 	type args struct {
 		email    string
@@ -62,7 +107,7 @@ func TestRegisterNewUser(t *testing.T) {
 			t.Parallel()
 			repo := repo.NewRepository(config.Config{})
 
-			s := usecase.NewUserUseCase(repo)
+			s := newUserUseCase(repo, taskRepo.NewRepository(config.Config{}), listRepo.NewRepository(config.Config{}))
 			newUser, err := s.RegisterNewUser(context.Background(), tt.args.email, tt.args.password)
 
 			if !errors.Is(err, tt.wantErr) {
@@ -123,7 +168,7 @@ func TestGetUserByID(t *testing.T) {
 				t.Errorf("s.RegisterNewUser() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			s := usecase.NewUserUseCase(repo)
+			s := newUserUseCase(repo, taskRepo.NewRepository(config.Config{}), listRepo.NewRepository(config.Config{}))
 			foundUser, err := s.GetUserByID(context.Background(), newUser.ID)
 
 			if !errors.Is(err, tt.wantErr) {
@@ -184,7 +229,7 @@ func TestGetUserByEmail(t *testing.T) {
 				t.Errorf("s.RegisterNewUser() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			s := usecase.NewUserUseCase(repo)
+			s := newUserUseCase(repo, taskRepo.NewRepository(config.Config{}), listRepo.NewRepository(config.Config{}))
 			foundUser, err := s.GetUserByEmail(context.Background(), newUser.Email)
 
 			if !errors.Is(err, tt.wantErr) {
@@ -202,3 +247,474 @@ func TestGetUserByEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestUserUseCaseChangeEmail(t *testing.T) {
+	type testCase struct {
+		name     string
+		password string
+		email    string
+		want     string
+		wantErr  error
+	}
+
+	tests := []testCase{
+		{
+			name:     "Success",
+			password: "TestPassword1",
+			email:    "changed@example.com",
+			want:     "changed@example.com",
+		},
+		{
+			name:     "Same email",
+			password: "TestPassword1",
+			email:    "test@example.com",
+			want:     "test@example.com",
+		},
+		{
+			name:     "Wrong password",
+			password: "WrongPassword",
+			email:    "changed@example.com",
+			want:     "test@example.com",
+			wantErr:  usecase.ErrWrongPassword,
+		},
+		{
+			name:     "Invalid email",
+			password: "TestPassword1",
+			email:    "invalid email",
+			want:     "test@example.com",
+			wantErr:  user.ErrInvalidEmail,
+		},
+		{
+			name:     "Email of another user",
+			password: "TestPassword1",
+			email:    "other@example.com",
+			want:     "test@example.com",
+			wantErr:  usecase.ErrEmailAlreadyInUse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := repo.NewRepository(config.Config{})
+			s := newUserUseCase(repo, taskRepo.NewRepository(config.Config{}), listRepo.NewRepository(config.Config{}))
+
+			u, err := s.RegisterNewUser(context.Background(), "test@example.com", "TestPassword1")
+			if err != nil {
+				t.Fatalf("s.RegisterNewUser() error = %v", err)
+			}
+			_, err = s.RegisterNewUser(context.Background(), "other@example.com", "TestPassword1")
+			if err != nil {
+				t.Fatalf("s.RegisterNewUser() error = %v", err)
+			}
+
+			_, err = s.ChangeEmail(context.Background(), u.ID, tt.password, tt.email)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("s.ChangeEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, err := repo.GetByID(context.Background(), u.ID)
+			if err != nil {
+				t.Fatalf("repo.GetByID() error = %v", err)
+			}
+			if got.Email != tt.want {
+				t.Errorf("s.ChangeEmail() Email = %v, want %v", got.Email, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserUseCaseChangePassword(t *testing.T) {
+	repo := repo.NewRepository(config.Config{})
+	s := newUserUseCase(repo, taskRepo.NewRepository(config.Config{}), listRepo.NewRepository(config.Config{}))
+
+	u, err := s.RegisterNewUser(context.Background(), "test@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("s.RegisterNewUser() error = %v", err)
+	}
+
+	_, err = s.ChangePassword(context.Background(), u.ID, "WrongPassword", "TestPassword2")
+	if !errors.Is(err, usecase.ErrWrongPassword) {
+		t.Errorf("s.ChangePassword() error = %v, wantErr %v", err, usecase.ErrWrongPassword)
+	}
+
+	_, err = s.ChangePassword(context.Background(), u.ID, "TestPassword1", "")
+	if !errors.Is(err, user.ErrInvalidPassword) {
+		t.Errorf("s.ChangePassword() error = %v, wantErr %v", err, user.ErrInvalidPassword)
+	}
+
+	_, err = s.ChangePassword(context.Background(), u.ID, "TestPassword1", "TestPassword2")
+	if err != nil {
+		t.Fatalf("s.ChangePassword() error = %v", err)
+	}
+
+	got, err := repo.GetByID(context.Background(), u.ID)
+	if err != nil {
+		t.Fatalf("repo.GetByID() error = %v", err)
+	}
+	if got.ComparePassword("TestPassword1") || !got.ComparePassword("TestPassword2") {
+		t.Error("s.ChangePassword() password is not replaced")
+	}
+}
+
+func TestUserUseCaseDeleteUser(t *testing.T) {
+	ur := repo.NewRepository(config.Config{})
+	tr := taskRepo.NewRepository(config.Config{})
+	lr := listRepo.NewRepository(config.Config{})
+	policy := usecase.NewAccessPolicy(lr)
+
+	s, a := newUserUseCaseWithAccess(ur, tr, lr)
+	ls := usecase.NewListUseCase(lr, tr, s, policy)
+	ts, _ := newTaskUseCase(tr, policy)
+
+	u, err := s.RegisterNewUser(context.Background(), "test@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("s.RegisterNewUser() error = %v", err)
+	}
+	other, err := s.RegisterNewUser(context.Background(), "other@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("s.RegisterNewUser() error = %v", err)
+	}
+
+	own, err := ls.CreateList(context.Background(), "Own", u.ID)
+	if err != nil {
+		t.Fatalf("ls.CreateList() error = %v", err)
+	}
+	shared, err := ls.CreateList(context.Background(), "Shared", u.ID)
+	if err != nil {
+		t.Fatalf("ls.CreateList() error = %v", err)
+	}
+	_, err = ls.InviteMember(context.Background(), shared.ID, other.Email, list.RoleEditor, u.ID)
+	if err != nil {
+		t.Fatalf("ls.InviteMember() error = %v", err)
+	}
+
+	var deleted []uuid.UUID
+	for _, opts := range [][]task.Option{nil, {task.WithList(own.ID)}} {
+		ti, err := ts.CreateTask(context.Background(), "deleted", u.ID, opts...)
+		if err != nil {
+			t.Fatalf("ts.CreateTask() error = %v", err)
+		}
+
+		deleted = append(deleted, ti.ID)
+	}
+
+	trashed, err := ts.CreateTask(context.Background(), "trashed", u.ID)
+	if err != nil {
+		t.Fatalf("ts.CreateTask() error = %v", err)
+	}
+	err = ts.DeleteTask(context.Background(), trashed.ID, trashed.Version, u.ID, usecase.DeleteSubtasks)
+	if err != nil {
+		t.Fatalf("ts.DeleteTask() error = %v", err)
+	}
+	deleted = append(deleted, trashed.ID)
+
+	kept := make([]uuid.UUID, 0, 2)
+	for _, userId := range []uuid.UUID{u.ID, other.ID} {
+		ti, err := ts.CreateTask(context.Background(), "kept", userId, task.WithList(shared.ID))
+		if err != nil {
+			t.Fatalf("ts.CreateTask() error = %v", err)
+		}
+
+		kept = append(kept, ti.ID)
+	}
+
+	otherTask, err := ts.CreateTask(context.Background(), "other", other.ID)
+	if err != nil {
+		t.Fatalf("ts.CreateTask() error = %v", err)
+	}
+	kept = append(kept, otherTask.ID)
+
+	// A task trashed in the own list by a member who has left it since
+	_, err = ls.InviteMember(context.Background(), own.ID, other.Email, list.RoleEditor, u.ID)
+	if err != nil {
+		t.Fatalf("ls.InviteMember() error = %v", err)
+	}
+	left, err := ts.CreateTask(context.Background(), "left", other.ID, task.WithList(own.ID))
+	if err != nil {
+		t.Fatalf("ts.CreateTask() error = %v", err)
+	}
+	err = ts.DeleteTask(context.Background(), left.ID, left.Version, other.ID, usecase.DeleteSubtasks)
+	if err != nil {
+		t.Fatalf("ts.DeleteTask() error = %v", err)
+	}
+	_, err = ls.RemoveMember(context.Background(), own.ID, other.ID, u.ID)
+	if err != nil {
+		t.Fatalf("ls.RemoveMember() error = %v", err)
+	}
+	deleted = append(deleted, left.ID)
+
+	// What lets the user, or anyone they have shared it with, reach their data
+	refreshToken, _, err := session.NewRefreshToken(u.ID, uuid.Nil, time.Hour)
+	if err != nil {
+		t.Fatalf("session.NewRefreshToken() error = %v", err)
+	}
+	err = a.sessions.Save(context.Background(), refreshToken)
+	if err != nil {
+		t.Fatalf("a.sessions.Save() error = %v", err)
+	}
+
+	resetToken, _, err := reset.NewToken(u.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("reset.NewToken() error = %v", err)
+	}
+	err = a.resets.Save(context.Background(), resetToken)
+	if err != nil {
+		t.Fatalf("a.resets.Save() error = %v", err)
+	}
+
+	hook, err := webhook.NewWebhook("https://example.com/hook", []task.EventType{task.EventCreated}, u.ID)
+	if err != nil {
+		t.Fatalf("webhook.NewWebhook() error = %v", err)
+	}
+	err = a.webhooks.Save(context.Background(), hook)
+	if err != nil {
+		t.Fatalf("a.webhooks.Save() error = %v", err)
+	}
+	d, err := webhook.NewDelivery(hook, task.NewEvent(task.EventCreated, otherTask, u.ID))
+	if err != nil {
+		t.Fatalf("webhook.NewDelivery() error = %v", err)
+	}
+	err = a.deliveries.Save(context.Background(), d)
+	if err != nil {
+		t.Fatalf("a.deliveries.Save() error = %v", err)
+	}
+
+	f, _, err := feed.NewFeed(u.ID)
+	if err != nil {
+		t.Fatalf("feed.NewFeed() error = %v", err)
+	}
+	err = a.feeds.Save(context.Background(), f)
+	if err != nil {
+		t.Fatalf("a.feeds.Save() error = %v", err)
+	}
+
+	// The current password is required
+	err = s.DeleteUser(context.Background(), u.ID, "WrongPassword")
+	if !errors.Is(err, usecase.ErrWrongPassword) {
+		t.Errorf("s.DeleteUser() error = %v, wantErr %v", err, usecase.ErrWrongPassword)
+	}
+	if _, err = ur.GetByID(context.Background(), u.ID); err != nil {
+		t.Errorf("ur.GetByID() error = %v", err)
+	}
+
+	err = s.DeleteUser(context.Background(), u.ID, "TestPassword1")
+	if err != nil {
+		t.Fatalf("s.DeleteUser() error = %v", err)
+	}
+
+	if _, err = ur.GetByID(context.Background(), u.ID); !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("ur.GetByID() error = %v, wantErr %v", err, user.ErrUserNotFound)
+	}
+
+	for _, id := range deleted {
+		if _, err = tr.GetByID(context.Background(), id); !errors.Is(err, task.ErrTaskNotFound) {
+			t.Errorf("tr.GetByID() error = %v, wantErr %v", err, task.ErrTaskNotFound)
+		}
+	}
+
+	for _, id := range kept {
+		if _, err = tr.GetByID(context.Background(), id); err != nil {
+			t.Errorf("tr.GetByID() error = %v", err)
+		}
+	}
+
+	if _, err = lr.GetByID(context.Background(), own.ID); !errors.Is(err, list.ErrListNotFound) {
+		t.Errorf("lr.GetByID() error = %v, wantErr %v", err, list.ErrListNotFound)
+	}
+
+	if got, err := a.sessions.GetByHash(context.Background(), refreshToken.TokenHash); err != nil || !got.Revoked {
+		t.Errorf("a.sessions.GetByHash() got = %v, %v, want the session revoked", got.Revoked, err)
+	}
+
+	if got, err := a.resets.GetByHash(context.Background(), resetToken.TokenHash); err != nil || !got.Used {
+		t.Errorf("a.resets.GetByHash() got = %v, %v, want the token used", got.Used, err)
+	}
+
+	if _, err = a.webhooks.GetByID(context.Background(), hook.ID); !errors.Is(err, webhook.ErrWebhookNotFound) {
+		t.Errorf("a.webhooks.GetByID() error = %v, wantErr %v", err, webhook.ErrWebhookNotFound)
+	}
+
+	if deliveries, err := a.deliveries.GetAllByWebhookID(context.Background(), hook.ID, 10); err != nil || len(deliveries) != 0 {
+		t.Errorf("a.deliveries.GetAllByWebhookID() got = %v, %v, want none", deliveries, err)
+	}
+
+	if _, err = a.feeds.GetByUserID(context.Background(), u.ID); !errors.Is(err, feed.ErrFeedNotFound) {
+		t.Errorf("a.feeds.GetByUserID() error = %v, wantErr %v", err, feed.ErrFeedNotFound)
+	}
+
+	// The ownership of the shared list is handed over to the member left
+	l, err := lr.GetByID(context.Background(), shared.ID)
+	if err != nil {
+		t.Fatalf("lr.GetByID() error = %v", err)
+	}
+	if len(l.Members) != 1 {
+		t.Errorf("shared list has %d members, want 1", len(l.Members))
+	}
+	if role, ok := l.RoleOf(other.ID); !ok || role != list.RoleOwner {
+		t.Errorf("l.RoleOf() = %v, want %v", role, list.RoleOwner)
+	}
+}
+
+func TestUserUseCaseAuthorizeUnverified(t *testing.T) {
+	unverified, err := user.NewUser("test@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("user.NewUser() error = %v", err)
+	}
+	verified := unverified
+	verified.Verify()
+
+	type testCase struct {
+		name      string
+		access    usecase.UnverifiedAccess
+		wantLogin error
+		wantView  error
+		wantEdit  error
+	}
+
+	tests := []testCase{
+		{
+			name:   "Full",
+			access: usecase.UnverifiedAccessFull,
+		},
+		{
+			name:   "Default",
+			access: "",
+		},
+		{
+			name:     "Limited",
+			access:   usecase.UnverifiedAccessLimited,
+			wantEdit: usecase.ErrEmailNotVerified,
+		},
+		{
+			name:      "None",
+			access:    usecase.UnverifiedAccessNone,
+			wantLogin: usecase.ErrEmailNotVerified,
+			wantView:  usecase.ErrEmailNotVerified,
+			wantEdit:  usecase.ErrEmailNotVerified,
+		},
+	}
+
+	if usecase.UnverifiedAccess("partial").IsValid() {
+		t.Error("IsValid() = true for an unknown policy")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.access.IsValid() {
+				t.Errorf("IsValid() = false for %q", tt.access)
+			}
+
+			s := usecase.NewUserUseCase(nil, nil, nil, nil, nil, nil, nil, nil, nil, tt.access, user.Passwords{})
+
+			if err := s.AuthorizeLogin(unverified); !errors.Is(err, tt.wantLogin) {
+				t.Errorf("AuthorizeLogin() error = %v, wantErr %v", err, tt.wantLogin)
+			}
+			if err := s.AuthorizeUnverified(unverified, usecase.ActionView); !errors.Is(err, tt.wantView) {
+				t.Errorf("AuthorizeUnverified(ActionView) error = %v, wantErr %v", err, tt.wantView)
+			}
+			if err := s.AuthorizeUnverified(unverified, usecase.ActionEdit); !errors.Is(err, tt.wantEdit) {
+				t.Errorf("AuthorizeUnverified(ActionEdit) error = %v, wantErr %v", err, tt.wantEdit)
+			}
+
+			// Verified users are not limited by the policy.
+			if err := s.AuthorizeLogin(verified); err != nil {
+				t.Errorf("AuthorizeLogin() error = %v for a verified user", err)
+			}
+			if err := s.AuthorizeUnverified(verified, usecase.ActionManage); err != nil {
+				t.Errorf("AuthorizeUnverified(ActionManage) error = %v for a verified user", err)
+			}
+		})
+	}
+}
+
+func TestUserUseCasePasswords(t *testing.T) {
+	ur := repo.NewRepository(config.Config{})
+	passwords := user.Passwords{
+		Policy: user.NewPasswordPolicy(10, []string{"Password123456"}, true),
+		Hasher: user.PasswordHasher{Algorithm: user.HashArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1},
+	}
+	s := usecase.NewUserUseCase(ur, nil, nil, nil, nil, nil, nil, nil, nil, usecase.UnverifiedAccessFull, passwords)
+
+	for password, wantErr := range map[string]error{
+		"short":              user.ErrPasswordTooShort,
+		"password123456":     user.ErrPasswordTooCommon,
+		"policy@example.com": user.ErrPasswordContainsEmail,
+	} {
+		_, err := s.RegisterNewUser(context.Background(), "policy@example.com", password)
+		if !errors.Is(err, wantErr) {
+			t.Errorf("RegisterNewUser(%q) error = %v, wantErr %v", password, err, wantErr)
+		}
+	}
+
+	u, err := s.RegisterNewUser(context.Background(), "policy@example.com", "correct horse battery")
+	if err != nil {
+		t.Fatalf("RegisterNewUser() error = %v", err)
+	}
+
+	_, err = s.ChangePassword(context.Background(), u.ID, "correct horse battery", "short")
+	if !errors.Is(err, user.ErrPasswordTooShort) {
+		t.Errorf("ChangePassword() error = %v, wantErr %v", err, user.ErrPasswordTooShort)
+	}
+}
+
+func TestUserUseCaseAuthenticate(t *testing.T) {
+	ur := repo.NewRepository(config.Config{})
+	hasher := user.PasswordHasher{Algorithm: user.HashArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1}
+	s := usecase.NewUserUseCase(ur, nil, nil, nil, nil, nil, nil, nil, nil, usecase.UnverifiedAccessFull, user.Passwords{Hasher: hasher})
+
+	// A user registered with the bcrypt hash of the tests
+	u, err := user.NewUser("login@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("user.NewUser() error = %v", err)
+	}
+	err = ur.Save(context.Background(), u)
+	if err != nil {
+		t.Fatalf("ur.Save() error = %v", err)
+	}
+
+	for _, credentials := range [][2]string{{"unknown@example.com", "TestPassword1"}, {"login@example.com", "TestPassword2"}} {
+		_, err = s.Authenticate(context.Background(), credentials[0], credentials[1])
+		if !errors.Is(err, usecase.ErrInvalidCredentials) {
+			t.Errorf("Authenticate(%q, %q) error = %v, wantErr %v", credentials[0], credentials[1], err, usecase.ErrInvalidCredentials)
+		}
+	}
+
+	got, err := ur.GetByID(context.Background(), u.ID)
+	if err != nil {
+		t.Fatalf("ur.GetByID() error = %v", err)
+	}
+	if got.Password != u.Password {
+		t.Error("Authenticate() rehashed the password on a failed login")
+	}
+
+	authenticated, err := s.Authenticate(context.Background(), "login@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if authenticated.ID != u.ID {
+		t.Errorf("Authenticate() got = %v, want %v", authenticated.ID, u.ID)
+	}
+
+	// The password is hashed again with the configured hasher
+	got, err = ur.GetByID(context.Background(), u.ID)
+	if err != nil {
+		t.Fatalf("ur.GetByID() error = %v", err)
+	}
+	if hasher.NeedsRehash(got.Password) || !got.ComparePassword("TestPassword1") {
+		t.Errorf("Authenticate() password = %v, want an argon2id hash of the password", got.Password)
+	}
+
+	rehashed := got.Password
+	_, err = s.Authenticate(context.Background(), "login@example.com", "TestPassword1")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	got, err = ur.GetByID(context.Background(), u.ID)
+	if err != nil {
+		t.Fatalf("ur.GetByID() error = %v", err)
+	}
+	if got.Password != rehashed {
+		t.Error("Authenticate() rehashed an up-to-date password")
+	}
+}
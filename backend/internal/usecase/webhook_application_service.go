@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+)
+
+const (
+	// MaxWebhooksPerUser is the largest number of webhooks a user can register.
+	MaxWebhooksPerUser = 10
+	// MaxWebhookDeliveries is the number of the latest deliveries of a webhook that can be inspected.
+	MaxWebhookDeliveries = 100
+	// webhookDeliveryBatch is the largest number of deliveries attempted by one DeliverDue call.
+	webhookDeliveryBatch = 100
+	// webhookDeliveryConcurrency is the number of deliveries attempted at the same time.
+	webhookDeliveryConcurrency = 8
+)
+
+var (
+	ErrTooManyWebhooks = errors.New("too many webhooks")
+)
+
+// WebhookSender posts the deliveries to the receivers.
+type WebhookSender interface {
+	// Send posts the body to the URL with the headers and returns the status code of the response.
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
+
+type WebhookUseCase struct {
+	webhookRepository  webhook.Repository
+	deliveryRepository webhook.DeliveryRepository
+	sender             WebhookSender
+	backoff            webhook.Backoff
+	lease              time.Duration
+	accessPolicy       *AccessPolicy
+}
+
+// NewWebhookUseCase creates an new instance of the WebhookUseCase. A delivery is claimed by a worker
+// for the lease before it is attempted, which is to be longer than an attempt takes.
+func NewWebhookUseCase(webhookRepository webhook.Repository, deliveryRepository webhook.DeliveryRepository, sender WebhookSender, backoff webhook.Backoff, lease time.Duration, accessPolicy *AccessPolicy) *WebhookUseCase {
+	return &WebhookUseCase{
+		webhookRepository:  webhookRepository,
+		deliveryRepository: deliveryRepository,
+		sender:             sender,
+		backoff:            backoff,
+		lease:              lease,
+		accessPolicy:       accessPolicy,
+	}
+}
+
+// CreateWebhook registers a webhook of the user for the events.
+func (s *WebhookUseCase) CreateWebhook(ctx context.Context, url string, events []task.EventType, userId uuid.UUID) (webhook.Webhook, error) {
+	webhooks, err := s.webhookRepository.GetAllByUserID(ctx, userId)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	if len(webhooks) >= MaxWebhooksPerUser {
+		return webhook.Webhook{}, ErrTooManyWebhooks
+	}
+
+	w, err := webhook.NewWebhook(url, events, userId)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	err = s.webhookRepository.Save(ctx, w)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	return w, nil
+}
+
+// GetWebhooksForUser returns the webhooks of the user.
+func (s *WebhookUseCase) GetWebhooksForUser(ctx context.Context, userId uuid.UUID) ([]webhook.Webhook, error) {
+	webhooks, err := s.webhookRepository.GetAllByUserID(ctx, userId)
+	if err != nil {
+		return []webhook.Webhook{}, err
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook returns a webhook of the user.
+func (s *WebhookUseCase) GetWebhook(ctx context.Context, id uuid.UUID, userId uuid.UUID) (webhook.Webhook, error) {
+	return s.getAuthorized(ctx, id, userId)
+}
+
+// UpdateWebhook changes the URL and the events of a webhook of the user.
+func (s *WebhookUseCase) UpdateWebhook(ctx context.Context, id uuid.UUID, url string, events []task.EventType, userId uuid.UUID) (webhook.Webhook, error) {
+	w, err := s.getAuthorized(ctx, id, userId)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	err = w.Update(url, events)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	err = s.webhookRepository.Update(ctx, w)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	return w, nil
+}
+
+// DeleteWebhook deletes a webhook of the user together with its deliveries.
+func (s *WebhookUseCase) DeleteWebhook(ctx context.Context, id uuid.UUID, userId uuid.UUID) error {
+	w, err := s.getAuthorized(ctx, id, userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.webhookRepository.Delete(ctx, w.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.deliveryRepository.DeleteByWebhookID(ctx, w.ID)
+}
+
+// GetWebhookDeliveries returns the latest deliveries of a webhook of the user with their attempts.
+func (s *WebhookUseCase) GetWebhookDeliveries(ctx context.Context, id uuid.UUID, userId uuid.UUID) ([]webhook.Delivery, error) {
+	w, err := s.getAuthorized(ctx, id, userId)
+	if err != nil {
+		return []webhook.Delivery{}, err
+	}
+
+	deliveries, err := s.deliveryRepository.GetAllByWebhookID(ctx, w.ID, MaxWebhookDeliveries)
+	if err != nil {
+		return []webhook.Delivery{}, err
+	}
+
+	return deliveries, nil
+}
+
+// Publish queues a delivery of the event to every webhook of the task author subscribed to it.
+// The events of the tasks the author may no longer view, such as those of a list they left, are dropped.
+// The deliveries are made by DeliverDue.
+func (s *WebhookUseCase) Publish(ctx context.Context, e task.Event) error {
+	err := s.accessPolicy.AuthorizeTask(ctx, e.Task, e.Task.UserID, ActionView)
+	if errors.Is(err, ErrUnauthorizedAction) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := s.webhookRepository.GetAllByUserID(ctx, e.Task.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range webhooks {
+		if !w.Subscribes(e.Type) {
+			continue
+		}
+
+		d, err := webhook.NewDelivery(w, e)
+		if err != nil {
+			return err
+		}
+
+		err = s.deliveryRepository.Save(ctx, d)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeliverDue attempts the deliveries that are due and returns how many of them were delivered.
+// Failed deliveries are retried after an exponential backoff until they run out of attempts.
+// Every delivery is claimed before it is attempted, so the instances of the app do not attempt the same one.
+func (s *WebhookUseCase) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := s.deliveryRepository.GetDue(ctx, time.Now(), webhookDeliveryBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		delivered int
+		errs      []error
+	)
+
+	sem := make(chan struct{}, webhookDeliveryConcurrency)
+	for _, d := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			// Another worker may have claimed the delivery since it was read
+			now := time.Now()
+			d, err := s.deliveryRepository.Claim(ctx, d.ID, now, now.Add(s.lease))
+			if errors.Is(err, webhook.ErrDeliveryClaimed) {
+				return
+			}
+			if err == nil {
+				err = s.deliver(ctx, &d)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, err)
+			} else if d.Status == webhook.StatusDelivered {
+				delivered++
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return delivered, errors.Join(errs...)
+}
+
+// deliver makes an attempt of the delivery and saves its outcome.
+func (s *WebhookUseCase) deliver(ctx context.Context, d *webhook.Delivery) error {
+	w, err := s.webhookRepository.GetByID(ctx, d.WebhookID)
+	if errors.Is(err, webhook.ErrWebhookNotFound) {
+		d.Fail("the webhook was deleted")
+
+		return s.deliveryRepository.Update(ctx, *d)
+	}
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{
+		"Content-Type":          "application/json",
+		webhook.SignatureHeader: w.Sign(d.Payload),
+		webhook.EventHeader:     string(d.EventType),
+		webhook.DeliveryHeader:  d.ID.String(),
+	}
+
+	a := webhook.Attempt{At: time.Now()}
+	a.StatusCode, err = s.sender.Send(ctx, w.URL, headers, d.Payload)
+	a.Duration = time.Since(a.At)
+	if err != nil {
+		a.Error = err.Error()
+	}
+
+	// An attempt cut short by the shutdown is not counted, the delivery is attempted again once the lease ends
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	err = d.RecordAttempt(a, s.backoff)
+	if err != nil {
+		return err
+	}
+
+	return s.deliveryRepository.Update(ctx, *d)
+}
+
+func (s *WebhookUseCase) getAuthorized(ctx context.Context, id uuid.UUID, userId uuid.UUID) (webhook.Webhook, error) {
+	w, err := s.webhookRepository.GetByID(ctx, id)
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	if w.UserID != userId {
+		return webhook.Webhook{}, ErrUnauthorizedAction
+	}
+
+	return w, nil
+}
@@ -0,0 +1,366 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ozaitsev92/tododdd/config"
+	"github.com/ozaitsev92/tododdd/internal/domain/task"
+	"github.com/ozaitsev92/tododdd/internal/domain/webhook"
+	listRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/list/memory"
+	repo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/task/memory"
+	webhookRepo "github.com/ozaitsev92/tododdd/internal/infrastructure/repository/webhook/memory"
+	"github.com/ozaitsev92/tododdd/internal/infrastructure/sender"
+	"github.com/ozaitsev92/tododdd/internal/usecase"
+)
+
+// receiver is a webhook receiver recording the requests it gets.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	w.WriteHeader(rc.status)
+}
+
+func newWebhookUseCases(backoff webhook.Backoff) (*usecase.TaskUseCase, *usecase.WebhookUseCase) {
+	accessPolicy := usecase.NewAccessPolicy(listRepo.NewRepository(config.Config{}))
+	t, outboxRepository := newTaskUseCase(repo.NewRepository(config.Config{}), accessPolicy)
+
+	// The receivers are test servers on the loopback
+	httpSender, _ := sender.NewHTTPSender(config.Config{WebhookAllowedNetworks: []string{"127.0.0.0/8", "::1/128"}})
+	w := usecase.NewWebhookUseCase(
+		webhookRepo.NewRepository(config.Config{}),
+		webhookRepo.NewDeliveryRepository(config.Config{}),
+		httpSender,
+		backoff,
+		time.Minute,
+		accessPolicy,
+	)
+
+	// The events are relayed right away
+	o := usecase.NewOutboxUseCase(outboxRepository)
+	o.AddPublisher(w)
+	t.OnEventsAdded(func() {
+		_, _ = o.Relay(context.Background())
+	})
+
+	return t, w
+}
+
+func TestWebhookUseCaseDeliverDue(t *testing.T) {
+	userId := uuid.New()
+
+	rc := &receiver{status: http.StatusNoContent}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	s, w := newWebhookUseCases(webhook.Backoff{Base: time.Minute, Max: time.Hour, MaxAttempts: 3})
+
+	hook, err := w.CreateWebhook(context.Background(), server.URL, []task.EventType{task.EventCreated, task.EventCompleted}, userId)
+	if err != nil {
+		t.Fatalf("w.CreateWebhook() error = %v", err)
+	}
+
+	ti, err := s.CreateTask(context.Background(), "test", userId)
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	// Not subscribed to
+	_, err = s.UpdateTask(context.Background(), ti.ID, task.AnyVersion, "updated", userId)
+	if err != nil {
+		t.Fatalf("s.UpdateTask() error = %v", err)
+	}
+
+	_, err = s.MarkTaskCompleted(context.Background(), ti.ID, userId)
+	if err != nil {
+		t.Fatalf("s.MarkTaskCompleted() error = %v", err)
+	}
+
+	// Tasks of other users are not delivered
+	_, err = s.CreateTask(context.Background(), "other", uuid.New())
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	n, err := w.DeliverDue(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("w.DeliverDue() got = %v, %v, want %v", n, err, 2)
+	}
+
+	if len(rc.requests) != 2 {
+		t.Fatalf("receiver got %v requests, want %v", len(rc.requests), 2)
+	}
+
+	events := map[string]bool{}
+	for i, r := range rc.requests {
+		if got := r.Header.Get(webhook.SignatureHeader); got != webhook.Signature(hook.Secret, rc.bodies[i]) {
+			t.Errorf("request %d signature = %v, want %v", i, got, webhook.Signature(hook.Secret, rc.bodies[i]))
+		}
+		if r.Header.Get(webhook.DeliveryHeader) == "" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request %d headers = %v", i, r.Header)
+		}
+		events[r.Header.Get(webhook.EventHeader)] = true
+	}
+
+	if !events[string(task.EventCreated)] || !events[string(task.EventCompleted)] {
+		t.Errorf("receiver got events %v, want %v and %v", events, task.EventCreated, task.EventCompleted)
+	}
+
+	deliveries, err := w.GetWebhookDeliveries(context.Background(), hook.ID, userId)
+	if err != nil {
+		t.Fatalf("w.GetWebhookDeliveries() error = %v", err)
+	}
+
+	for _, d := range deliveries {
+		if d.Status != webhook.StatusDelivered || len(d.Attempts) != 1 || d.Attempts[0].StatusCode != http.StatusNoContent {
+			t.Errorf("delivery got = %v %v", d.Status, d.Attempts)
+		}
+	}
+
+	// Nothing is due anymore
+	n, err = w.DeliverDue(context.Background())
+	if err != nil || n != 0 || len(rc.requests) != 2 {
+		t.Errorf("w.DeliverDue() got = %v, %v, want nothing delivered", n, err)
+	}
+}
+
+func TestWebhookUseCaseDeliverDueClaimed(t *testing.T) {
+	userId := uuid.New()
+
+	rc := &receiver{status: http.StatusNoContent}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	s, w := newWebhookUseCases(webhook.Backoff{Base: time.Minute, Max: time.Hour, MaxAttempts: 3})
+
+	_, err := w.CreateWebhook(context.Background(), server.URL, []task.EventType{task.EventCreated}, userId)
+	if err != nil {
+		t.Fatalf("w.CreateWebhook() error = %v", err)
+	}
+
+	for range 10 {
+		_, err = s.CreateTask(context.Background(), "test", userId)
+		if err != nil {
+			t.Fatalf("s.CreateTask() error = %v", err)
+		}
+	}
+
+	// The instances of the app attempting the due deliveries at once send each of them once
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := w.DeliverDue(context.Background()); err != nil {
+				t.Errorf("w.DeliverDue() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if len(rc.requests) != 10 {
+		t.Errorf("receiver got %v requests, want %v", len(rc.requests), 10)
+	}
+}
+
+func TestWebhookUseCasePublishUnauthorized(t *testing.T) {
+	userId := uuid.New()
+
+	rc := &receiver{status: http.StatusNoContent}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	_, w := newWebhookUseCases(webhook.Backoff{Base: time.Minute, Max: time.Hour, MaxAttempts: 3})
+
+	_, err := w.CreateWebhook(context.Background(), server.URL, []task.EventType{task.EventCreated}, userId)
+	if err != nil {
+		t.Fatalf("w.CreateWebhook() error = %v", err)
+	}
+
+	// The author is not a member of the list of the task, e.g. has left it
+	ti, err := task.NewTask("test", userId)
+	if err != nil {
+		t.Fatalf("task.NewTask() error = %v", err)
+	}
+	listId := uuid.New()
+	ti.ListID = &listId
+
+	err = w.Publish(context.Background(), task.NewEvent(task.EventCreated, ti, uuid.New()))
+	if err != nil {
+		t.Fatalf("w.Publish() error = %v", err)
+	}
+
+	n, err := w.DeliverDue(context.Background())
+	if err != nil || n != 0 {
+		t.Errorf("w.DeliverDue() got = %v, %v, want %v", n, err, 0)
+	}
+
+	if len(rc.requests) != 0 {
+		t.Errorf("receiver got %v requests, want %v", len(rc.requests), 0)
+	}
+}
+
+func TestWebhookUseCaseRetry(t *testing.T) {
+	userId := uuid.New()
+
+	rc := &receiver{status: http.StatusInternalServerError}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	s, w := newWebhookUseCases(webhook.Backoff{Base: 10 * time.Millisecond, Max: 20 * time.Millisecond, MaxAttempts: 3})
+
+	hook, err := w.CreateWebhook(context.Background(), server.URL, []task.EventType{task.EventCreated}, userId)
+	if err != nil {
+		t.Fatalf("w.CreateWebhook() error = %v", err)
+	}
+
+	_, err = s.CreateTask(context.Background(), "test", userId)
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		n, err := w.DeliverDue(context.Background())
+		if err != nil || n != 0 {
+			t.Fatalf("w.DeliverDue() got = %v, %v, want %v", n, err, 0)
+		}
+
+		// Not due again before the backoff is over
+		_, err = w.DeliverDue(context.Background())
+		if err != nil || len(rc.requests) != i+1 {
+			t.Fatalf("receiver got %v requests, want %v", len(rc.requests), i+1)
+		}
+
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	deliveries, err := w.GetWebhookDeliveries(context.Background(), hook.ID, userId)
+	if err != nil {
+		t.Fatalf("w.GetWebhookDeliveries() error = %v", err)
+	}
+
+	if len(deliveries) != 1 || deliveries[0].Status != webhook.StatusFailed || len(deliveries[0].Attempts) != 3 {
+		t.Fatalf("w.GetWebhookDeliveries() got = %v", deliveries)
+	}
+
+	a := deliveries[0].Attempts
+	if a[0].StatusCode != http.StatusInternalServerError || a[1].At.Sub(a[0].At) < 10*time.Millisecond {
+		t.Errorf("attempts got = %v", a)
+	}
+
+	// A receiver that is down is retried too
+	server.Close()
+
+	_, err = s.CreateTask(context.Background(), "test", userId)
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	_, err = w.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatalf("w.DeliverDue() error = %v", err)
+	}
+
+	deliveries, err = w.GetWebhookDeliveries(context.Background(), hook.ID, userId)
+	if err != nil {
+		t.Fatalf("w.GetWebhookDeliveries() error = %v", err)
+	}
+
+	if latest := deliveries[0]; latest.Status != webhook.StatusPending || len(latest.Attempts) != 1 || latest.Attempts[0].Error == "" {
+		t.Errorf("w.GetWebhookDeliveries() latest = %v %v", latest.Status, latest.Attempts)
+	}
+}
+
+func TestWebhookUseCaseManage(t *testing.T) {
+	userId := uuid.New()
+	otherId := uuid.New()
+
+	s, w := newWebhookUseCases(webhook.Backoff{Base: time.Minute, Max: time.Hour, MaxAttempts: 3})
+
+	hook, err := w.CreateWebhook(context.Background(), "https://example.com/hook", []task.EventType{task.EventCreated}, userId)
+	if err != nil {
+		t.Fatalf("w.CreateWebhook() error = %v", err)
+	}
+
+	_, err = w.CreateWebhook(context.Background(), "example.com", []task.EventType{task.EventCreated}, userId)
+	if !errors.Is(err, webhook.ErrInvalidURL) {
+		t.Errorf("w.CreateWebhook() error = %v, wantErr %v", err, webhook.ErrInvalidURL)
+	}
+
+	// Webhooks of other users are out of reach
+	_, err = w.GetWebhook(context.Background(), hook.ID, otherId)
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("w.GetWebhook() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	err = w.DeleteWebhook(context.Background(), hook.ID, otherId)
+	if !errors.Is(err, usecase.ErrUnauthorizedAction) {
+		t.Errorf("w.DeleteWebhook() error = %v, wantErr %v", err, usecase.ErrUnauthorizedAction)
+	}
+
+	updated, err := w.UpdateWebhook(context.Background(), hook.ID, "https://example.com/updated", []task.EventType{task.EventDeleted}, userId)
+	if err != nil || updated.URL != "https://example.com/updated" || updated.Secret != hook.Secret {
+		t.Errorf("w.UpdateWebhook() got = %v, %v", updated, err)
+	}
+
+	for i := 1; i < usecase.MaxWebhooksPerUser; i++ {
+		_, err = w.CreateWebhook(context.Background(), "https://example.com/hook", []task.EventType{task.EventCreated}, userId)
+		if err != nil {
+			t.Fatalf("w.CreateWebhook() error = %v", err)
+		}
+	}
+
+	_, err = w.CreateWebhook(context.Background(), "https://example.com/hook", []task.EventType{task.EventCreated}, userId)
+	if !errors.Is(err, usecase.ErrTooManyWebhooks) {
+		t.Errorf("w.CreateWebhook() error = %v, wantErr %v", err, usecase.ErrTooManyWebhooks)
+	}
+
+	// The deliveries of a deleted webhook go with it
+	ti, err := s.CreateTask(context.Background(), "test", userId)
+	if err != nil {
+		t.Fatalf("s.CreateTask() error = %v", err)
+	}
+
+	err = s.DeleteTask(context.Background(), ti.ID, task.AnyVersion, userId, usecase.DeleteSubtasks)
+	if err != nil {
+		t.Fatalf("s.DeleteTask() error = %v", err)
+	}
+
+	deliveries, err := w.GetWebhookDeliveries(context.Background(), hook.ID, userId)
+	if err != nil || len(deliveries) != 1 || deliveries[0].EventType != task.EventDeleted {
+		t.Fatalf("w.GetWebhookDeliveries() got = %v, %v", deliveries, err)
+	}
+
+	err = w.DeleteWebhook(context.Background(), hook.ID, userId)
+	if err != nil {
+		t.Fatalf("w.DeleteWebhook() error = %v", err)
+	}
+
+	_, err = w.GetWebhookDeliveries(context.Background(), hook.ID, userId)
+	if !errors.Is(err, webhook.ErrWebhookNotFound) {
+		t.Errorf("w.GetWebhookDeliveries() error = %v, wantErr %v", err, webhook.ErrWebhookNotFound)
+	}
+}
@@ -35,3 +35,11 @@ func ShutdownTimeout(timeout time.Duration) Option {
 		s.shutdownTimeout = timeout
 	}
 }
+
+// OnShutdown registers a function to call when the server starts shutting down,
+// e.g. to end long-lived responses the shutdown would otherwise wait for.
+func OnShutdown(f func()) Option {
+	return func(s *Server) {
+		s.server.RegisterOnShutdown(f)
+	}
+}
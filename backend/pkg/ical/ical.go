@@ -0,0 +1,143 @@
+// Package ical writes iCalendar data as specified by RFC 5545.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// StatusNeedsAction is the status of a to-do that is still to be done.
+	StatusNeedsAction = "NEEDS-ACTION"
+	// StatusCompleted is the status of a done to-do.
+	StatusCompleted = "COMPLETED"
+
+	// maxLineLength is the longest content line in octets, longer lines are folded.
+	maxLineLength = 75
+	// dateTimeLayout is the layout of a date with UTC time.
+	dateTimeLayout = "20060102T150405Z"
+)
+
+// Calendar is an iCalendar object.
+type Calendar struct {
+	// ProdID identifies the product that created the object.
+	ProdID string
+	// Name is the name calendar clients show for the calendar, left out when empty.
+	Name  string
+	Todos []Todo
+}
+
+// Todo is a VTODO component.
+type Todo struct {
+	UID     string
+	Summary string
+	Status  string
+	// Due is nil for a to-do without a due date.
+	Due *time.Time
+	// Priority is from 1, the highest, to 9, the lowest, 0 leaves it undefined.
+	Priority   int
+	Categories []string
+	// Sequence is the revision of the to-do, starting at 0.
+	Sequence     int64
+	Created      time.Time
+	LastModified time.Time
+}
+
+// Encode writes the calendar to w.
+func Encode(w io.Writer, c Calendar) error {
+	e := &encoder{w: bufio.NewWriter(w)}
+
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", escapeText(c.ProdID))
+	e.line("CALSCALE", "GREGORIAN")
+	if c.Name != "" {
+		e.line("X-WR-CALNAME", escapeText(c.Name))
+	}
+
+	for _, t := range c.Todos {
+		e.line("BEGIN", "VTODO")
+		e.line("UID", escapeText(t.UID))
+		e.line("DTSTAMP", formatDateTime(t.LastModified))
+		e.line("CREATED", formatDateTime(t.Created))
+		e.line("LAST-MODIFIED", formatDateTime(t.LastModified))
+		e.line("SEQUENCE", strconv.FormatInt(t.Sequence, 10))
+		e.line("SUMMARY", escapeText(t.Summary))
+		e.line("STATUS", t.Status)
+		if t.Due != nil {
+			e.line("DUE", formatDateTime(*t.Due))
+		}
+		if t.Priority > 0 {
+			e.line("PRIORITY", strconv.Itoa(t.Priority))
+		}
+		if len(t.Categories) > 0 {
+			categories := make([]string, 0, len(t.Categories))
+			for _, c := range t.Categories {
+				categories = append(categories, escapeText(c))
+			}
+			e.line("CATEGORIES", strings.Join(categories, ","))
+		}
+		e.line("END", "VTODO")
+	}
+
+	e.line("END", "VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+
+	return e.w.Flush()
+}
+
+// encoder writes content lines, keeping the first error.
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a content line, folding it into lines of at most maxLineLength octets.
+// Every line but the first starts with a space, and lines are never split inside a character.
+func (e *encoder) line(name string, value string) {
+	if e.err != nil {
+		return
+	}
+
+	rest := name + ":" + value
+	limit := maxLineLength
+	for len(rest) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(rest[cut]) {
+			cut--
+		}
+
+		e.write(rest[:cut] + "\r\n ")
+		rest = rest[cut:]
+		// The leading space of the continuation line counts.
+		limit = maxLineLength - 1
+	}
+
+	e.write(rest + "\r\n")
+}
+
+func (e *encoder) write(s string) {
+	if e.err != nil {
+		return
+	}
+
+	_, e.err = e.w.WriteString(s)
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeText escapes a TEXT value.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout)
+}
@@ -0,0 +1,201 @@
+package pubsub
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultReplaySize       = 100
+	_defaultReplayWindow     = 5 * time.Minute
+	_defaultSubscriberBuffer = 16
+)
+
+var (
+	ErrClosed = errors.New("hub is closed")
+)
+
+// Message is a published value with its ID. IDs grow with every message of the hub.
+type Message[T any] struct {
+	ID   uint64
+	Data T
+}
+
+// Hub delivers the messages published to a topic to the subscribers of the topic.
+// It never blocks a publisher: a subscriber that falls too far behind is dropped
+// and is expected to subscribe again from the last message it got. A topic without
+// subscribers is removed with its kept messages once the replay window passes.
+type Hub[T any] struct {
+	options
+	mu     sync.Mutex
+	lastID uint64
+	topics map[string]*topic[T]
+	closed bool
+	// evictedAt is when the idle topics were last removed.
+	evictedAt time.Time
+}
+
+type topic[T any] struct {
+	replay      []Message[T]
+	subscribers map[*Subscription[T]]struct{}
+	// activeAt is when a message was last published or the last subscriber left.
+	activeAt time.Time
+}
+
+// Subscription receives the messages of a topic on C until it is closed.
+// C is closed when the subscription is closed, dropped or the hub is closed.
+type Subscription[T any] struct {
+	C     <-chan Message[T]
+	c     chan Message[T]
+	hub   *Hub[T]
+	topic string
+}
+
+// New -.
+func New[T any](opts ...Option) *Hub[T] {
+	h := &Hub[T]{
+		options: options{
+			replaySize:       _defaultReplaySize,
+			replayWindow:     _defaultReplayWindow,
+			subscriberBuffer: _defaultSubscriberBuffer,
+		},
+		// IDs start from the clock so they keep growing across restarts
+		// and a resuming subscriber does not miss the messages of a new process.
+		lastID: uint64(time.Now().UnixMicro()),
+		topics: make(map[string]*topic[T]),
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(&h.options)
+	}
+
+	return h
+}
+
+// Publish sends the value to the subscribers of the topic and returns the message.
+// Publishing to a closed hub does nothing.
+func (h *Hub[T]) Publish(name string, data T) Message[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	m := Message[T]{ID: h.lastID, Data: data}
+
+	if h.closed {
+		return m
+	}
+
+	now := time.Now()
+	h.evict(now)
+
+	t := h.topic(name, now)
+	t.activeAt = now
+
+	t.replay = append(t.replay, m)
+	if len(t.replay) > h.replaySize {
+		t.replay = t.replay[len(t.replay)-h.replaySize:]
+	}
+
+	for s := range t.subscribers {
+		select {
+		case s.c <- m:
+		default:
+			h.drop(t, s)
+		}
+	}
+
+	return m
+}
+
+// Subscribe subscribes to the topic. With resume it also returns the kept messages published
+// after lastID, the oldest first.
+func (h *Hub[T]) Subscribe(name string, lastID uint64, resume bool) (*Subscription[T], []Message[T], error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, nil, ErrClosed
+	}
+
+	now := time.Now()
+	h.evict(now)
+
+	t := h.topic(name, now)
+
+	var missed []Message[T]
+	if resume {
+		for _, m := range t.replay {
+			if m.ID > lastID {
+				missed = append(missed, m)
+			}
+		}
+	}
+
+	c := make(chan Message[T], h.subscriberBuffer)
+	s := &Subscription[T]{C: c, c: c, hub: h, topic: name}
+	t.subscribers[s] = struct{}{}
+
+	return s, missed, nil
+}
+
+// Close closes the subscription. It is safe to call more than once.
+func (s *Subscription[T]) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if t, ok := s.hub.topics[s.topic]; ok {
+		if _, ok := t.subscribers[s]; ok {
+			s.hub.drop(t, s)
+		}
+	}
+}
+
+// Close closes all the subscriptions and makes the hub refuse new ones.
+func (h *Hub[T]) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, t := range h.topics {
+		for s := range t.subscribers {
+			close(s.c)
+		}
+	}
+	h.topics = make(map[string]*topic[T])
+}
+
+func (h *Hub[T]) topic(name string, now time.Time) *topic[T] {
+	t, ok := h.topics[name]
+	if !ok {
+		t = &topic[T]{subscribers: make(map[*Subscription[T]]struct{}), activeAt: now}
+		h.topics[name] = t
+	}
+
+	return t
+}
+
+func (h *Hub[T]) drop(t *topic[T], s *Subscription[T]) {
+	delete(t.subscribers, s)
+	close(s.c)
+
+	if len(t.subscribers) == 0 {
+		t.activeAt = time.Now()
+	}
+}
+
+// evict removes the topics that have had no subscribers and no messages for the replay window.
+// The topics are checked at most once per window, so an idle topic is kept for two windows at most.
+func (h *Hub[T]) evict(now time.Time) {
+	if now.Sub(h.evictedAt) < h.replayWindow {
+		return
+	}
+	h.evictedAt = now
+
+	for name, t := range h.topics {
+		if len(t.subscribers) == 0 && now.Sub(t.activeAt) >= h.replayWindow {
+			delete(h.topics, name)
+		}
+	}
+}
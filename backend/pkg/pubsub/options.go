@@ -0,0 +1,33 @@
+package pubsub
+
+import "time"
+
+// Option -.
+type Option func(*options)
+
+type options struct {
+	replaySize       int
+	replayWindow     time.Duration
+	subscriberBuffer int
+}
+
+// ReplaySize is the number of the latest messages of a topic kept for resuming subscribers.
+func ReplaySize(size int) Option {
+	return func(o *options) {
+		o.replaySize = size
+	}
+}
+
+// ReplayWindow is how long a topic without subscribers is kept after its last message for resuming subscribers.
+func ReplayWindow(window time.Duration) Option {
+	return func(o *options) {
+		o.replayWindow = window
+	}
+}
+
+// SubscriberBuffer is the number of messages a subscriber can fall behind before it is dropped.
+func SubscriberBuffer(size int) Option {
+	return func(o *options) {
+		o.subscriberBuffer = size
+	}
+}
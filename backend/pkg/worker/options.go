@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"time"
+)
+
+// Option -.
+type Option func(*Worker)
+
+// Interval sets how often the job runs. A non-positive interval is ignored, the default is kept.
+func Interval(interval time.Duration) Option {
+	return func(w *Worker) {
+		if interval > 0 {
+			w.interval = interval
+		}
+	}
+}
+
+// Trigger makes the worker run the job as soon as the channel is signalled, besides the interval.
+func Trigger(c <-chan struct{}) Option {
+	return func(w *Worker) {
+		w.trigger = c
+	}
+}
+
+// ShutdownTimeout sets how long Shutdown waits for the job. A non-positive timeout is ignored, the default is kept.
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(w *Worker) {
+		if timeout > 0 {
+			w.shutdownTimeout = timeout
+		}
+	}
+}
+
+// ErrorHandler is called with every error returned by the job.
+func ErrorHandler(handler func(error)) Option {
+	return func(w *Worker) {
+		w.errorHandler = handler
+	}
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	_defaultInterval        = time.Minute
+	_defaultShutdownTimeout = 3 * time.Second
+)
+
+var (
+	ErrShutdownTimeout = errors.New("worker did not stop in time")
+)
+
+// Job is the work done on every tick. It should return promptly once ctx is cancelled.
+type Job func(ctx context.Context) error
+
+// Worker runs a job in the background at a fixed interval until it is shut down.
+type Worker struct {
+	job             Job
+	interval        time.Duration
+	trigger         <-chan struct{}
+	shutdownTimeout time.Duration
+	errorHandler    func(error)
+	cancel          context.CancelFunc
+	done            chan struct{}
+}
+
+// New -.
+func New(job Job, opts ...Option) *Worker {
+	w := &Worker{
+		job:             job,
+		interval:        _defaultInterval,
+		shutdownTimeout: _defaultShutdownTimeout,
+		errorHandler:    func(error) {},
+		done:            make(chan struct{}),
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.start()
+
+	return w
+}
+
+func (w *Worker) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.job(ctx); err != nil && ctx.Err() == nil {
+				w.errorHandler(err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-w.trigger:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the worker and waits for the running job to return.
+func (w *Worker) Shutdown() error {
+	w.cancel()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-time.After(w.shutdownTimeout):
+		return ErrShutdownTimeout
+	}
+}